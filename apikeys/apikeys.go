@@ -0,0 +1,234 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var (
+	ErrNotFound     = errors.New("apikeys: key not found")
+	ErrInvalidToken = errors.New("apikeys: invalid token")
+	ErrRevoked      = errors.New("apikeys: key revoked")
+)
+
+type keyCtxKey struct{}
+
+type Key struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Hash      []byte     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k *Key) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+type Store interface {
+	Create(ctx context.Context, key *Key) error
+	Get(ctx context.Context, id string) (*Key, error)
+	Update(ctx context.Context, key *Key) error
+	List(ctx context.Context) ([]*Key, error)
+}
+
+type Option func(*Manager)
+
+func WithPrefix(prefix string) Option {
+	return func(m *Manager) {
+		m.prefix = prefix
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) {
+		m.now = now
+	}
+}
+
+type Manager struct {
+	store  Store
+	prefix string
+	now    func() time.Time
+}
+
+func New(store Store, opts ...Option) *Manager {
+	m := &Manager{
+		store:  store,
+		prefix: "sk",
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+func (m *Manager) Issue(ctx context.Context, name string) (*Key, string, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, hash, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &Key{
+		ID:        id,
+		Name:      name,
+		Hash:      hash,
+		CreatedAt: m.now().UTC(),
+	}
+
+	if err := m.store.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, m.token(id, secret), nil
+}
+
+func (m *Manager) Rotate(ctx context.Context, id string) (*Key, string, error) {
+	key, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if key.Revoked() {
+		return nil, "", ErrRevoked
+	}
+
+	secret, hash, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := m.now().UTC()
+	key.Hash, key.RotatedAt = hash, &now
+
+	if err := m.store.Update(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, m.token(id, secret), nil
+}
+
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	key, err := m.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if key.Revoked() {
+		return nil
+	}
+
+	now := m.now().UTC()
+	key.RevokedAt = &now
+
+	return m.store.Update(ctx, key)
+}
+
+func (m *Manager) Verify(ctx context.Context, token string) (*Key, error) {
+	rest, ok := strings.CutPrefix(token, m.prefix+"_")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || id == "" || secret == "" {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := m.store.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(secret))
+	if subtle.ConstantTimeCompare(hash[:], key.Hash) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	if key.Revoked() {
+		return nil, ErrRevoked
+	}
+
+	return key, nil
+}
+
+func (m *Manager) Middleware(next chu.Handler) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		token := r.Header.Get("X-API-Key")
+		if token == "" {
+			token, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+
+		if token == "" {
+			return chu.Errorf(http.StatusUnauthorized, "apikeys: missing API key")
+		}
+
+		key, err := m.Verify(ctx, token)
+		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrRevoked) {
+			return chu.NewError(http.StatusUnauthorized, err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		ctx = context.WithValue(ctx, keyCtxKey{}, key)
+
+		return next(ctx, w, r.WithContext(ctx))
+	}
+}
+
+func FromContext(ctx context.Context) (*Key, bool) {
+	key, ok := ctx.Value(keyCtxKey{}).(*Key)
+	return key, ok
+}
+
+func (m *Manager) token(id, secret string) string {
+	return m.prefix + "_" + id + "_" + secret
+}
+
+func newSecret() (string, []byte, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+	secret = strings.NewReplacer("_", "A", "-", "B").Replace(secret)
+
+	hash := sha256.Sum256([]byte(secret))
+
+	return secret, hash[:], nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
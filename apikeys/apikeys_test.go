@@ -0,0 +1,140 @@
+package apikeys_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/apikeys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newApp(t *testing.T) (*apikeys.Manager, *chu.Router) {
+	t.Helper()
+
+	m := apikeys.New(apikeys.NewMemoryStore())
+
+	r := chu.New()
+	r.Route("/admin/keys", m.Routes)
+	r.Group(func(r *chu.Router) {
+		r.Use(m.Middleware)
+		r.Get("/whoami", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key, ok := apikeys.FromContext(ctx)
+			if !ok {
+				return chu.Errorf(http.StatusInternalServerError, "no key in context")
+			}
+
+			_, err := w.Write([]byte(key.Name))
+			return err
+		})
+	})
+
+	return m, r
+}
+
+type issued struct {
+	Key   apikeys.Key `json:"key"`
+	Token string      `json:"token"`
+}
+
+func call(r http.Handler, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestManager_Lifecycle(t *testing.T) {
+	_, r := newApp(t)
+
+	w := call(r, "POST", "/admin/keys", `{"name":"billing"}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code, "status code should be Created")
+
+	var first issued
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first), "issue response should be valid JSON")
+	assert.True(t, strings.HasPrefix(first.Token, "sk_"+first.Key.ID+"_"), "token should embed prefix and id")
+	assert.NotContains(t, w.Body.String(), "hash", "hash should not be exposed")
+
+	w = call(r, "GET", "/whoami", "", http.Header{"Authorization": {"Bearer " + first.Token}})
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "billing", w.Body.String(), "middleware should expose the key")
+
+	w = call(r, "POST", "/admin/keys/"+first.Key.ID+"/rotate", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+
+	var rotated issued
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated), "rotate response should be valid JSON")
+	assert.Equal(t, first.Key.ID, rotated.Key.ID, "rotation should keep the id")
+	assert.NotNil(t, rotated.Key.RotatedAt, "rotation should be timestamped")
+
+	w = call(r, "GET", "/whoami", "", http.Header{"X-Api-Key": {first.Token}})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "old token should be rejected after rotation")
+
+	w = call(r, "GET", "/whoami", "", http.Header{"X-Api-Key": {rotated.Token}})
+	assert.Equal(t, http.StatusOK, w.Code, "rotated token should be accepted")
+
+	w = call(r, "DELETE", "/admin/keys/"+first.Key.ID, "", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code, "status code should match expected")
+
+	w = call(r, "GET", "/whoami", "", http.Header{"X-Api-Key": {rotated.Token}})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "revoked token should be rejected")
+
+	w = call(r, "GET", "/admin/keys", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+
+	var keys []apikeys.Key
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &keys), "list response should be valid JSON")
+	require.Len(t, keys, 1, "list should contain the issued key")
+	assert.NotNil(t, keys[0].RevokedAt, "listed key should be revoked")
+}
+
+func TestManager_Errors(t *testing.T) {
+	_, r := newApp(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		header   http.Header
+		expected int
+	}{
+		{name: "missing key", method: "GET", path: "/whoami", expected: http.StatusUnauthorized},
+		{name: "malformed key", method: "GET", path: "/whoami", header: http.Header{"X-Api-Key": {"nope"}}, expected: http.StatusUnauthorized},
+		{name: "unknown key", method: "GET", path: "/whoami", header: http.Header{"X-Api-Key": {"sk_abc_def"}}, expected: http.StatusUnauthorized},
+		{name: "missing name", method: "POST", path: "/admin/keys", body: `{}`, expected: http.StatusBadRequest},
+		{name: "invalid body", method: "POST", path: "/admin/keys", body: `{`, expected: http.StatusBadRequest},
+		{name: "rotate unknown", method: "POST", path: "/admin/keys/missing/rotate", expected: http.StatusNotFound},
+		{name: "revoke unknown", method: "DELETE", path: "/admin/keys/missing", expected: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := call(r, tt.method, tt.path, tt.body, tt.header)
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+		})
+	}
+}
+
+func TestManager_RotateRevoked(t *testing.T) {
+	m := apikeys.New(apikeys.NewMemoryStore(), apikeys.WithPrefix("pk"))
+
+	key, token, err := m.Issue(context.Background(), "svc")
+	require.NoError(t, err, "issue should succeed")
+	assert.True(t, strings.HasPrefix(token, "pk_"), "token should use configured prefix")
+
+	require.NoError(t, m.Revoke(context.Background(), key.ID), "revoke should succeed")
+
+	_, _, err = m.Rotate(context.Background(), key.ID)
+	assert.ErrorIs(t, err, apikeys.ErrRevoked, "revoked keys should not rotate")
+}
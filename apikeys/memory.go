@@ -0,0 +1,63 @@
+package apikeys
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[string]Key
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]Key)}
+}
+
+func (s *MemoryStore) Create(_ context.Context, key *Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = *key
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &key, nil
+}
+
+func (s *MemoryStore) Update(_ context.Context, key *Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key.ID]; !ok {
+		return ErrNotFound
+	}
+
+	s.keys[key.ID] = *key
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context) ([]*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		key := key
+		keys = append(keys, &key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+
+	return keys, nil
+}
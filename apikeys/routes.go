@@ -0,0 +1,89 @@
+package apikeys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/josearomeroj/chu"
+)
+
+type issueRequest struct {
+	Name string `json:"name"`
+}
+
+type issueResponse struct {
+	Key   *Key   `json:"key"`
+	Token string `json:"token"`
+}
+
+func (m *Manager) Routes(r *chu.Router) {
+	r.Get("/", m.list)
+	r.Post("/", m.issue)
+	r.Post("/{id}/rotate", m.rotate)
+	r.Delete("/{id}", m.revoke)
+}
+
+func (m *Manager) list(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	keys, err := m.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, keys)
+}
+
+func (m *Manager) issue(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var req issueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return chu.Errorf(http.StatusBadRequest, "apikeys: invalid request body: %w", err)
+	}
+
+	if req.Name == "" {
+		return chu.Errorf(http.StatusBadRequest, "apikeys: name is required")
+	}
+
+	key, token, err := m.Issue(ctx, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusCreated, issueResponse{Key: key, Token: token})
+}
+
+func (m *Manager) rotate(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	key, token, err := m.Rotate(ctx, chu.URLParam(r, "id"))
+	if err != nil {
+		return statusError(err)
+	}
+
+	return writeJSON(w, http.StatusOK, issueResponse{Key: key, Token: token})
+}
+
+func (m *Manager) revoke(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if err := m.Revoke(ctx, chu.URLParam(r, "id")); err != nil {
+		return statusError(err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return chu.NewError(http.StatusNotFound, err)
+	case errors.Is(err, ErrRevoked):
+		return chu.NewError(http.StatusConflict, err)
+	default:
+		return err
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(v)
+}
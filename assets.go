@@ -0,0 +1,101 @@
+package chu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"maps"
+	"path"
+	"strings"
+)
+
+// Assets maps the files of a file system to fingerprinted names that change
+// whenever their content does, so they can be cached forever.
+type Assets struct {
+	prefix   string
+	fsys     fs.FS
+	hashed   map[string]string
+	original map[string]string
+}
+
+// NewAssets fingerprints every file of fsys, turning "app.js" into
+// "app.3f9c1a2b.js". prefix is the path Static serves fsys under. Compressed
+// sidecars are not fingerprinted themselves; Static finds them through the
+// original name.
+func NewAssets(prefix string, fsys fs.FS) (*Assets, error) {
+	a := &Assets{
+		prefix:   strings.TrimSuffix(prefix, "/"),
+		fsys:     fsys,
+		hashed:   make(map[string]string),
+		original: make(map[string]string),
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || a.isSidecar(name) {
+			return err
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(content)
+		ext := path.Ext(name)
+		hashed := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:4]) + ext
+
+		a.hashed[name] = hashed
+		a.original[hashed] = name
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Path returns the URL path of the fingerprinted asset. Unknown names are
+// returned unfingerprinted.
+func (a *Assets) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if hashed, ok := a.hashed[name]; ok {
+		name = hashed
+	}
+
+	return a.prefix + "/" + name
+}
+
+// FuncMap returns the "asset" template function, so templates can write
+// {{asset "app.js"}}.
+func (a *Assets) FuncMap() template.FuncMap {
+	return template.FuncMap{"asset": a.Path}
+}
+
+// Manifest returns the original to fingerprinted name mapping.
+func (a *Assets) Manifest() map[string]string {
+	return maps.Clone(a.hashed)
+}
+
+func (a *Assets) isSidecar(name string) bool {
+	for _, ext := range encodingExts {
+		if base, ok := strings.CutSuffix(name, ext); ok {
+			if _, err := fs.Stat(a.fsys, base); err == nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// WithAssets makes Static serve the fingerprinted names of a, with headers
+// that let clients cache them indefinitely. Original names are still served,
+// without those headers.
+func WithAssets(a *Assets) StaticOption {
+	return func(c *staticConfig) {
+		c.assets = a
+	}
+}
@@ -0,0 +1,58 @@
+package chu_test
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssets(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":       {Data: []byte("console.log(1)")},
+		"app.js.gz":    {Data: []byte("gzip-bytes")},
+		"css/site.css": {Data: []byte("body{}")},
+	}
+
+	assets, err := chu.NewAssets("/static/", fsys)
+	require.NoError(t, err, "fingerprinting should succeed")
+
+	path := assets.Path("app.js")
+	assert.Regexp(t, regexp.MustCompile(`^/static/app\.[0-9a-f]{8}\.js$`), path, "path should be fingerprinted")
+	assert.Regexp(t, regexp.MustCompile(`^/static/css/site\.[0-9a-f]{8}\.css$`), assets.Path("/css/site.css"), "nested path should be fingerprinted")
+	assert.Equal(t, "/static/missing.js", assets.Path("missing.js"), "unknown assets should not be fingerprinted")
+	assert.Len(t, assets.Manifest(), 2, "sidecars should not be fingerprinted")
+
+	tmpl := template.Must(template.New("page").Funcs(assets.FuncMap()).Parse(`<script src="{{asset "app.js"}}"></script>`))
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, nil))
+	assert.Equal(t, `<script src="`+path+`"></script>`, buf.String(), "template helper should render the fingerprinted path")
+
+	r := chu.New()
+	r.Static("/static", fsys, chu.WithAssets(assets))
+
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "gzip-bytes", w.Body.String(), "sidecar should be served for fingerprinted name")
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"), "fingerprinted assets should be immutable")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/static/app.js", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "original names should still be served")
+	assert.Equal(t, "console.log(1)", w.Body.String(), "body should match expected")
+	assert.Empty(t, w.Header().Get("Cache-Control"), "original names should not be immutable")
+}
@@ -0,0 +1,225 @@
+package chu
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/josearomeroj/chu/internal/throttle"
+)
+
+type AttachmentOption func(*attachmentConfig)
+
+type attachmentConfig struct {
+	inline      bool
+	contentType string
+	modTime     time.Time
+	digestName  string
+	newHash     func() hash.Hash
+	bytesPerSec int64
+}
+
+func Inline() AttachmentOption {
+	return func(c *attachmentConfig) {
+		c.inline = true
+	}
+}
+
+func WithContentType(contentType string) AttachmentOption {
+	return func(c *attachmentConfig) {
+		c.contentType = contentType
+	}
+}
+
+func WithModTime(t time.Time) AttachmentOption {
+	return func(c *attachmentConfig) {
+		c.modTime = t
+	}
+}
+
+// WithChecksum sends a Content-Digest trailer computed while streaming full
+// (non-ranged) responses. name is the RFC 9530 algorithm key, e.g. "sha-256".
+func WithChecksum(name string, newHash func() hash.Hash) AttachmentOption {
+	return func(c *attachmentConfig) {
+		c.digestName, c.newHash = name, newHash
+	}
+}
+
+func WithSHA256Checksum() AttachmentOption {
+	return WithChecksum("sha-256", sha256.New)
+}
+
+func WithBandwidthLimit(bytesPerSec int64) AttachmentOption {
+	return func(c *attachmentConfig) {
+		c.bytesPerSec = bytesPerSec
+	}
+}
+
+func Attachment(w http.ResponseWriter, r *http.Request, content io.Reader, filename string, opts ...AttachmentOption) error {
+	cfg := &attachmentConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	disposition := "attachment"
+	if cfg.inline {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Disposition", ContentDisposition(disposition, filename))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	contentType := cfg.contentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	dw := &downloadWriter{ResponseWriter: w, req: r}
+	if cfg.bytesPerSec > 0 {
+		dw.bucket = throttle.New(cfg.bytesPerSec, 0, time.Now)
+		dw.bucket.Drain()
+	}
+	if cfg.newHash != nil {
+		dw.hash = cfg.newHash()
+		DeclareTrailers(w, "Content-Digest")
+	}
+
+	if seeker, ok := content.(io.ReadSeeker); ok {
+		http.ServeContent(dw, r, filename, cfg.modTime, seeker)
+	} else {
+		if contentType == "" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
+
+		dw.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			if _, err := io.Copy(dw, content); err != nil {
+				return err
+			}
+		}
+	}
+
+	if dw.err != nil {
+		return dw.err
+	}
+
+	if dw.hash != nil && dw.status == http.StatusOK && r.Method != http.MethodHead {
+		SetTrailer(w, "Content-Digest", cfg.digestName+"=:"+base64.StdEncoding.EncodeToString(dw.hash.Sum(nil))+":")
+	}
+
+	return nil
+}
+
+func ContentDisposition(disposition, filename string) string {
+	filename = filepath.Base(filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		return disposition
+	}
+
+	var fallback strings.Builder
+	ascii := true
+	for _, c := range filename {
+		switch {
+		case c == '"' || c == '\\':
+			fallback.WriteByte('_')
+		case c < 0x20 || c == 0x7f:
+			fallback.WriteByte('_')
+			ascii = false
+		case c >= utf8.RuneSelf:
+			fallback.WriteByte('_')
+			ascii = false
+		default:
+			fallback.WriteRune(c)
+		}
+	}
+
+	value := disposition + `; filename="` + fallback.String() + `"`
+	if !ascii || fallback.String() != filename {
+		value += "; filename*=UTF-8''" + rfc5987Encode(filename)
+	}
+
+	return value
+}
+
+func rfc5987Encode(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&15])
+	}
+
+	return b.String()
+}
+
+type downloadWriter struct {
+	http.ResponseWriter
+	req    *http.Request
+	status int
+	hash   hash.Hash
+	bucket *throttle.Bucket
+	err    error
+}
+
+func (w *downloadWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+
+	w.status = status
+	if w.hash != nil && status == http.StatusOK {
+		w.Header().Del("Content-Length")
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.bucket == nil {
+		return w.write(p)
+	}
+
+	n, err := w.bucket.Write(w.req.Context(), p, w.write)
+	if err != nil && w.req.Context().Err() != nil {
+		w.err = err
+	}
+
+	return n, err
+}
+
+func (w *downloadWriter) write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	if w.hash != nil {
+		w.hash.Write(p[:n])
+	}
+
+	return n, err
+}
+
+func (w *downloadWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
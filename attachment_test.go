@@ -0,0 +1,110 @@
+package chu_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		expected string
+	}{
+		{name: "plain", filename: "report.pdf", expected: `attachment; filename="report.pdf"`},
+		{name: "path is stripped", filename: "/tmp/../report.pdf", expected: `attachment; filename="report.pdf"`},
+		{name: "quotes", filename: `my "best" file.txt`, expected: `attachment; filename="my _best_ file.txt"; filename*=UTF-8''my%20%22best%22%20file.txt`},
+		{name: "unicode", filename: "résumé €.pdf", expected: `attachment; filename="r_sum_ _.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9%20%E2%82%AC.pdf`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, chu.ContentDisposition("attachment", tt.filename), "disposition should match expected")
+		})
+	}
+}
+
+func TestAttachment(t *testing.T) {
+	const body = "hello, attachment"
+	sum := sha256.Sum256([]byte(body))
+	digest := "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+
+	tests := []struct {
+		name         string
+		seekable     bool
+		rangeHeader  string
+		opts         []chu.AttachmentOption
+		expected     int
+		body         string
+		contentType  string
+		digest       string
+		acceptRanges bool
+	}{
+		{name: "seekable", seekable: true, expected: http.StatusOK, body: body, contentType: "text/plain; charset=utf-8", acceptRanges: true},
+		{name: "resume with range", seekable: true, rangeHeader: "bytes=7-", expected: http.StatusPartialContent, body: "attachment", acceptRanges: true},
+		{name: "stream", expected: http.StatusOK, body: body, contentType: "text/plain; charset=utf-8"},
+		{name: "checksum trailer", seekable: true, opts: []chu.AttachmentOption{chu.WithSHA256Checksum()}, expected: http.StatusOK, body: body, contentType: "text/plain; charset=utf-8", digest: digest, acceptRanges: true},
+		{name: "checksum trailer on stream", opts: []chu.AttachmentOption{chu.WithSHA256Checksum()}, expected: http.StatusOK, body: body, contentType: "text/plain; charset=utf-8", digest: digest},
+		{name: "no checksum for ranges", seekable: true, rangeHeader: "bytes=0-4", opts: []chu.AttachmentOption{chu.WithSHA256Checksum()}, expected: http.StatusPartialContent, body: "hello", acceptRanges: true},
+		{name: "explicit content type", opts: []chu.AttachmentOption{chu.WithContentType("application/x-custom")}, expected: http.StatusOK, body: body, contentType: "application/x-custom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chu.New()
+			r.Get("/download", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				var content io.Reader = strings.NewReader(body)
+				if !tt.seekable {
+					content = io.MultiReader(content)
+				}
+
+				return chu.Attachment(w, r, content, "notes.txt", tt.opts...)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/download", nil)
+			if tt.rangeHeader != "" {
+				req.Header.Set("Range", tt.rangeHeader)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			res := rec.Result()
+
+			assert.Equal(t, tt.expected, res.StatusCode, "status code should match expected")
+			assert.Equal(t, tt.body, rec.Body.String(), "body should match expected")
+			assert.Equal(t, `attachment; filename="notes.txt"`, res.Header.Get("Content-Disposition"), "disposition should be set")
+			if tt.contentType != "" {
+				assert.Equal(t, tt.contentType, res.Header.Get("Content-Type"), "content type should match expected")
+			}
+
+			assert.Equal(t, tt.digest, res.Trailer.Get("Content-Digest"), "digest trailer should match expected")
+			assert.Equal(t, tt.acceptRanges, res.Header.Get("Accept-Ranges") == "bytes", "range support should match expected")
+		})
+	}
+}
+
+func TestAttachment_BandwidthLimit(t *testing.T) {
+	r := chu.New()
+	r.Get("/download", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.Attachment(w, r, strings.NewReader(strings.Repeat("x", 300)), "data.bin", chu.WithBandwidthLimit(1000))
+	})
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/download", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+	assert.Equal(t, 300, rec.Body.Len(), "body should be fully delivered")
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond, "download should be paced to the limit")
+}
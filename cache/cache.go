@@ -0,0 +1,314 @@
+// Package cache provides a concurrency safe in-memory LRU cache with optional
+// expiry and hit/miss metrics.
+package cache
+
+import (
+	"container/list"
+	"iter"
+	"sync"
+	"time"
+)
+
+type Options[K comparable, V any] struct {
+	// MaxEntries bounds the cache; the least recently used entries are evicted
+	// beyond it. Zero means no limit.
+	MaxEntries int
+	// TTL is the default lifetime of entries. Zero means they do not expire.
+	TTL time.Duration
+	Now func() time.Time
+
+	OnHit   func(key K)
+	OnMiss  func(key K)
+	OnEvict func(key K, value V, reason EvictReason)
+}
+
+type EvictReason int
+
+const (
+	// EvictCapacity means the entry was the least recently used one when the
+	// cache was full.
+	EvictCapacity EvictReason = iota
+	EvictExpired
+)
+
+func (r EvictReason) String() string {
+	if r == EvictExpired {
+		return "expired"
+	}
+
+	return "capacity"
+}
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// HitRatio returns the fraction of lookups that were hits.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type Cache[K comparable, V any] struct {
+	opts Options[K, V]
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[K]*list.Element
+	stats   Stats
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+func New[K comparable, V any](opts Options[K, V]) *Cache[K, V] {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return &Cache[K, V]{
+		opts:    opts,
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	e, ok, gone := c.lookup(key)
+	if ok {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	c.mu.Unlock()
+
+	c.notify(gone, EvictExpired)
+
+	if !ok {
+		if c.opts.OnMiss != nil {
+			c.opts.OnMiss(key)
+		}
+
+		var zero V
+		return zero, false
+	}
+
+	if c.opts.OnHit != nil {
+		c.opts.OnHit(key)
+	}
+
+	return e.value, true
+}
+
+// Peek returns the entry without updating recency or metrics.
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok || c.expired(el.Value.(*entry[K, V])) {
+		var zero V
+		return zero, false
+	}
+
+	return el.Value.(*entry[K, V]).value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetTTL(key, value, c.opts.TTL)
+}
+
+// SetTTL stores the entry with its own lifetime. Zero means it does not expire.
+func (c *Cache[K, V]) SetTTL(key K, value V, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.opts.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	expired, evicted := c.put(key, value, expires)
+	c.mu.Unlock()
+
+	c.notify(expired, EvictExpired)
+	c.notify(evicted, EvictCapacity)
+}
+
+// Update atomically replaces the entry with fn's result. fn receives the
+// current value and whether it exists; returning false leaves the cache
+// unchanged. New entries get the default TTL, existing ones keep theirs.
+func (c *Cache[K, V]) Update(key K, fn func(value V, ok bool) (V, bool)) (V, bool) {
+	return c.UpdateTTL(key, c.opts.TTL, fn)
+}
+
+// UpdateTTL is like Update but gives new entries the lifetime ttl.
+func (c *Cache[K, V]) UpdateTTL(key K, ttl time.Duration, fn func(value V, ok bool) (V, bool)) (V, bool) {
+	c.mu.Lock()
+
+	e, ok, gone := c.lookup(key)
+
+	var current V
+	if ok {
+		current = e.value
+	}
+
+	value, store := fn(current, ok)
+	if !store {
+		c.mu.Unlock()
+		c.notify(gone, EvictExpired)
+		return current, ok
+	}
+
+	var expired, evicted []*entry[K, V]
+	if ok {
+		e.value = value
+	} else {
+		var expires time.Time
+		if ttl > 0 {
+			expires = c.opts.Now().Add(ttl)
+		}
+		expired, evicted = c.put(key, value, expires)
+	}
+
+	c.mu.Unlock()
+
+	c.notify(append(gone, expired...), EvictExpired)
+	c.notify(evicted, EvictCapacity)
+	return value, true
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+}
+
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.entries)
+}
+
+// All iterates over a snapshot of the live entries, most recently used first,
+// without updating recency or metrics.
+func (c *Cache[K, V]) All() iter.Seq2[K, V] {
+	c.mu.Lock()
+	entries := make([]*entry[K, V], 0, c.order.Len())
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		if e := el.Value.(*entry[K, V]); !c.expired(e) {
+			entries = append(entries, e)
+		}
+	}
+	c.mu.Unlock()
+
+	return func(yield func(K, V) bool) {
+		for _, e := range entries {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+
+	return stats
+}
+
+// lookup returns the live entry for key. An expired entry is dropped and
+// returned as gone, so the caller can report it once the lock is released.
+func (c *Cache[K, V]) lookup(key K) (*entry[K, V], bool, []*entry[K, V]) {
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	e := el.Value.(*entry[K, V])
+	if c.expired(e) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		c.stats.Evictions++
+
+		return nil, false, []*entry[K, V]{e}
+	}
+
+	c.order.MoveToFront(el)
+	return e, true, nil
+}
+
+// put stores the entry and returns the entries it dropped: expired ones from
+// the least recently used end, so entries that are never read again do not
+// pile up, then the ones beyond MaxEntries.
+func (c *Cache[K, V]) put(key K, value V, expires time.Time) ([]*entry[K, V], []*entry[K, V]) {
+	if el, ok := c.entries[key]; ok {
+		el.Value = &entry[K, V]{key: key, value: value, expires: expires}
+		c.order.MoveToFront(el)
+		return nil, nil
+	}
+
+	var expired []*entry[K, V]
+	for el := c.order.Back(); el != nil && c.expired(el.Value.(*entry[K, V])); el = c.order.Back() {
+		c.order.Remove(el)
+
+		e := el.Value.(*entry[K, V])
+		delete(c.entries, e.key)
+		c.stats.Evictions++
+		expired = append(expired, e)
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+
+	var evicted []*entry[K, V]
+	for c.opts.MaxEntries > 0 && c.order.Len() > c.opts.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+
+		e := oldest.Value.(*entry[K, V])
+		delete(c.entries, e.key)
+		c.stats.Evictions++
+		evicted = append(evicted, e)
+	}
+
+	return expired, evicted
+}
+
+func (c *Cache[K, V]) expired(e *entry[K, V]) bool {
+	return !e.expires.IsZero() && !c.opts.Now().Before(e.expires)
+}
+
+func (c *Cache[K, V]) notify(evicted []*entry[K, V], reason EvictReason) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+
+	for _, e := range evicted {
+		c.opts.OnEvict(e.key, e.value, reason)
+	}
+}
@@ -0,0 +1,134 @@
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/josearomeroj/chu/cache"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	type eviction struct {
+		key    string
+		reason cache.EvictReason
+	}
+
+	var evictions []eviction
+	var hits, misses []string
+
+	c := cache.New(cache.Options[string, int]{
+		MaxEntries: 2,
+		TTL:        time.Minute,
+		Now:        func() time.Time { return now },
+		OnHit:      func(key string) { hits = append(hits, key) },
+		OnMiss:     func(key string) { misses = append(misses, key) },
+		OnEvict: func(key string, value int, reason cache.EvictReason) {
+			evictions = append(evictions, eviction{key: key, reason: reason})
+		},
+	})
+
+	c.Set("a", 1)
+	c.SetTTL("b", 2, 0)
+
+	value, ok := c.Get("a")
+	assert.True(t, ok, "entry should be found")
+	assert.Equal(t, 1, value, "value should match expected")
+
+	c.Set("c", 3)
+
+	_, ok = c.Get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "expired entry should be a miss")
+
+	assert.Equal(t, []string{"a"}, hits, "hits should be reported")
+	assert.Equal(t, []string{"b", "a"}, misses, "misses should be reported")
+	assert.Equal(t, []eviction{{"b", cache.EvictCapacity}, {"a", cache.EvictExpired}}, evictions, "evictions should be reported")
+
+	stats := c.Stats()
+	assert.Equal(t, cache.Stats{Hits: 1, Misses: 2, Evictions: 2, Size: 1}, stats, "stats should match expected")
+	assert.InDelta(t, 1.0/3, stats.HitRatio(), 0.001, "hit ratio should match expected")
+}
+
+func TestCacheUpdate(t *testing.T) {
+	c := cache.New(cache.Options[string, int]{})
+
+	add := func(n int) func(int, bool) (int, bool) {
+		return func(value int, ok bool) (int, bool) { return value + n, true }
+	}
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Update("counter", add(1))
+		}()
+	}
+	wg.Wait()
+
+	value, _ := c.Peek("counter")
+	assert.Equal(t, 100, value, "updates should be atomic")
+
+	value, ok := c.Update("counter", func(int, bool) (int, bool) { return 0, false })
+	assert.True(t, ok, "declined update should report the current entry")
+	assert.Equal(t, 100, value, "declined update should keep the value")
+
+	c.Delete("counter")
+	_, ok = c.Peek("counter")
+	assert.False(t, ok, "deleted entry should be gone")
+
+	c.Set("x", 1)
+	c.Clear()
+	assert.Equal(t, 0, c.Len(), "clear should remove every entry")
+}
+
+func TestCacheAll(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	c := cache.New(cache.Options[string, int]{Now: func() time.Time { return now }})
+	c.Set("a", 1)
+	c.SetTTL("b", 2, time.Second)
+	c.Set("c", 3)
+	c.Get("a")
+
+	now = now.Add(time.Minute)
+
+	var keys []string
+	for key := range c.All() {
+		keys = append(keys, key)
+	}
+
+	assert.Equal(t, []string{"a", "c"}, keys, "live entries should be listed by recency")
+	assert.Equal(t, uint64(1), c.Stats().Hits, "iterating should not count as lookups")
+}
+
+func TestCacheSweepsExpired(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	var expired []string
+	c := cache.New(cache.Options[string, int]{
+		TTL: time.Minute,
+		Now: func() time.Time { return now },
+		OnEvict: func(key string, value int, reason cache.EvictReason) {
+			if reason == cache.EvictExpired {
+				expired = append(expired, key)
+			}
+		},
+	})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	now = now.Add(2 * time.Minute)
+	c.Set("c", 3)
+
+	assert.Equal(t, 1, c.Len(), "expired entries should be dropped on insert")
+	assert.Equal(t, []string{"a", "b"}, expired, "dropped entries should be reported as expired")
+}
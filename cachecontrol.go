@@ -0,0 +1,178 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheDirectives builds a Cache-Control header value. Its methods return
+// modified copies, so a base policy can be shared and refined.
+type CacheDirectives struct {
+	public, private, noCache, noStore  bool
+	mustRevalidate, proxyRevalidate    bool
+	immutable, noTransform             bool
+	maxAge, sMaxAge                    time.Duration
+	staleWhileRevalidate, staleIfError time.Duration
+	hasMaxAge, hasSMaxAge              bool
+}
+
+func CacheControl() CacheDirectives {
+	return CacheDirectives{}
+}
+
+func (c CacheDirectives) Public() CacheDirectives {
+	c.public, c.private = true, false
+	return c
+}
+
+func (c CacheDirectives) Private() CacheDirectives {
+	c.private, c.public = true, false
+	return c
+}
+
+func (c CacheDirectives) NoCache() CacheDirectives {
+	c.noCache = true
+	return c
+}
+
+func (c CacheDirectives) NoStore() CacheDirectives {
+	c.noStore = true
+	return c
+}
+
+func (c CacheDirectives) MustRevalidate() CacheDirectives {
+	c.mustRevalidate = true
+	return c
+}
+
+func (c CacheDirectives) ProxyRevalidate() CacheDirectives {
+	c.proxyRevalidate = true
+	return c
+}
+
+func (c CacheDirectives) Immutable() CacheDirectives {
+	c.immutable = true
+	return c
+}
+
+func (c CacheDirectives) NoTransform() CacheDirectives {
+	c.noTransform = true
+	return c
+}
+
+// MaxAge is rounded down to whole seconds.
+func (c CacheDirectives) MaxAge(d time.Duration) CacheDirectives {
+	c.maxAge, c.hasMaxAge = d, true
+	return c
+}
+
+func (c CacheDirectives) SMaxAge(d time.Duration) CacheDirectives {
+	c.sMaxAge, c.hasSMaxAge = d, true
+	return c
+}
+
+func (c CacheDirectives) StaleWhileRevalidate(d time.Duration) CacheDirectives {
+	c.staleWhileRevalidate = d
+	return c
+}
+
+func (c CacheDirectives) StaleIfError(d time.Duration) CacheDirectives {
+	c.staleIfError = d
+	return c
+}
+
+func (c CacheDirectives) String() string {
+	var directives []string
+
+	flag := func(set bool, name string) {
+		if set {
+			directives = append(directives, name)
+		}
+	}
+
+	seconds := func(set bool, name string, d time.Duration) {
+		if set {
+			directives = append(directives, name+"="+strconv.FormatInt(int64(max(d, 0)/time.Second), 10))
+		}
+	}
+
+	flag(c.public, "public")
+	flag(c.private, "private")
+	flag(c.noCache, "no-cache")
+	flag(c.noStore, "no-store")
+	seconds(c.hasMaxAge, "max-age", c.maxAge)
+	seconds(c.hasSMaxAge, "s-maxage", c.sMaxAge)
+	flag(c.mustRevalidate, "must-revalidate")
+	flag(c.proxyRevalidate, "proxy-revalidate")
+	flag(c.noTransform, "no-transform")
+	flag(c.immutable, "immutable")
+	seconds(c.staleWhileRevalidate > 0, "stale-while-revalidate", c.staleWhileRevalidate)
+	seconds(c.staleIfError > 0, "stale-if-error", c.staleIfError)
+
+	return strings.Join(directives, ", ")
+}
+
+// Apply sets the Cache-Control header of w.
+func (c CacheDirectives) Apply(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", c.String())
+}
+
+// NoStore forbids caching the response anywhere.
+func NoStore(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// DefaultCacheControl applies c to successful and redirect responses whose
+// handler did not set Cache-Control itself. Error responses are left alone.
+func DefaultCacheControl(c CacheDirectives) func(Handler) Handler {
+	value := c.String()
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return next(ctx, &cacheControlWriter{ResponseWriter: w, value: value}, r)
+		}
+	}
+}
+
+type cacheControlWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (w *cacheControlWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+
+		if status < http.StatusBadRequest && w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", w.value)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheControlWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+
+		f.Flush()
+	}
+}
+
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
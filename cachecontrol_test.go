@@ -0,0 +1,77 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControl(t *testing.T) {
+	base := chu.CacheControl().Public().MaxAge(5 * time.Minute)
+
+	tests := []struct {
+		name string
+		cc   chu.CacheDirectives
+		want string
+	}{
+		{name: "empty", cc: chu.CacheControl(), want: ""},
+		{name: "public max age", cc: base, want: "public, max-age=300"},
+		{name: "stale while revalidate", cc: base.StaleWhileRevalidate(time.Minute).StaleIfError(time.Hour), want: "public, max-age=300, stale-while-revalidate=60, stale-if-error=3600"},
+		{name: "private overrides public", cc: base.Private().NoCache(), want: "private, no-cache, max-age=300"},
+		{name: "immutable asset", cc: chu.CacheControl().Public().MaxAge(365 * 24 * time.Hour).Immutable(), want: "public, max-age=31536000, immutable"},
+		{name: "shared caches", cc: chu.CacheControl().MaxAge(0).SMaxAge(90 * time.Second).MustRevalidate().NoTransform(), want: "max-age=0, s-maxage=90, must-revalidate, no-transform"},
+		{name: "no store", cc: chu.CacheControl().NoStore(), want: "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cc.String(), "directives should match expected")
+		})
+	}
+
+	assert.Equal(t, "public, max-age=300", base.String(), "refining should not modify the base policy")
+}
+
+func TestDefaultCacheControl(t *testing.T) {
+	r := chu.New()
+	r.Group(func(r *chu.Router) {
+		r.Use(chu.DefaultCacheControl(chu.CacheControl().Public().MaxAge(time.Minute)))
+
+		r.Get("/default", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte("ok"))
+			return err
+		})
+		r.Get("/private", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			chu.NoStore(w)
+			return nil
+		})
+		r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return chu.Errorf(http.StatusBadGateway, "upstream down")
+		})
+	})
+
+	tests := []struct {
+		path string
+		code int
+		want string
+	}{
+		{path: "/default", code: http.StatusOK, want: "public, max-age=60"},
+		{path: "/private", code: http.StatusOK, want: "no-store"},
+		{path: "/fail", code: http.StatusBadGateway, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.code, w.Code, "status code should match expected")
+			assert.Equal(t, tt.want, w.Header().Get("Cache-Control"), "cache control should match expected")
+		})
+	}
+}
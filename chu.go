@@ -2,7 +2,10 @@ package chu
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -15,91 +18,248 @@ type Router struct {
 	chi chi.Router
 
 	errHandler    ErrorHandler
+	ctxErrHandler ContextErrorHandler
 	routerBuilder func() chi.Router
+	container     *container
+	inflight      *inflight
+	chains        *middlewareChains
+	diagnostics   *stageDiagnostics
+	wideEvents    *slog.Logger
+	sampler       Sampler
+	lifecycle     *lifecycle
+	syncCtx       bool
+	sanitize      func(string) string
+	errMappings   []errorMapping
+	costs         *routeCosts
+	deprecations  *deprecations
+	docs          *routeDocs
+	versions      *versionSet
+	prefix        string
+	options       []Option
+	recipe        []func(c *Router)
+	middlewares   []NamedMiddleware
+	chain         []string
+	hosts         []*hostRoute
+}
+
+type contextKey struct {
+	name string
 }
 
 func New(opts ...Option) *Router {
+	r := newRouter(opts)
+	r.chi = r.routerBuilder()
+
+	return r
+}
+
+// FromChi wraps an already configured chi router so chu handlers can be
+// registered alongside existing chi routes. Routes and middleware added through
+// the returned Router are registered on c.
+func FromChi(c chi.Router, opts ...Option) *Router {
+	r := newRouter(opts)
+	r.chi = c
+
+	return r
+}
+
+// newRouter returns a root Router with opts applied, leaving its chi router
+// to the caller.
+func newRouter(opts []Option) *Router {
 	r := &Router{
 		routerBuilder: defaultRouterBuilder,
 		errHandler:    defaultErrorHandler,
+		container:     newContainer(),
+		inflight:      newInflight(),
+		chains:        newMiddlewareChains(),
+		lifecycle:     newLifecycle(),
+		costs:         newRouteCosts(),
+		deprecations:  newDeprecations(),
+		docs:          newRouteDocs(),
+		versions:      &versionSet{},
+		options:       opts,
 	}
 
 	for _, opt := range opts {
 		opt(r)
 	}
 
-	r.chi = r.routerBuilder()
-
 	return r
 }
 
+// Chi returns the underlying chi router. This is an advanced escape hatch:
+// handlers registered on it bypass chu's error handling, and chi panics if
+// middleware is added after routes.
+func (r *Router) Chi() chi.Router {
+	return r.chi
+}
+
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.inflight.add()
+	defer r.inflight.done()
+
+	req = withRouteInfo(req, r)
+
+	if r.sampler != nil {
+		req = withSampling(req, r.sampler)
+	}
+
+	if r.wideEvents != nil {
+		var done func()
+
+		w, req, done = r.startWideEvent(w, req)
+		defer done()
+	}
+
+	if r.syncCtx {
+		req = withSyncState(req)
+	}
+
+	if !r.container.empty() {
+		req = req.WithContext(r.container.withScope(req.Context()))
+	}
+
+	if r.diagnostics != nil {
+		var done func()
+
+		req, done = r.diagnostics.watch(req)
+		defer done()
+	}
+
+	if host := r.matchHost(req); host != nil {
+		host.chi.ServeHTTP(w, req)
+		return
+	}
+
 	r.chi.ServeHTTP(w, req)
 }
 
 func (r *Router) SetErrorHandler(handler ErrorHandler) {
 	r.errHandler = handler
+	r.ctxErrHandler = nil
+	r.record(func(c *Router) { c.SetErrorHandler(handler) })
 }
 
 func (r *Router) adapt(h Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		if err := h(req.Context(), w, req); err != nil {
-			r.errHandler(w, req, err)
-		}
+		defer Stage(req.Context(), "handler")()
+
+		annotateRoute(req)
+		r.serve(h, w, req)
 	}
 }
 
-func (r *Router) Group(fn func(r *Router)) *Router {
+func (r *Router) subRouter(c chi.Router, prefix string) *Router {
 	subRouter := &Router{
-		chi:        r.routerBuilder(),
-		errHandler: r.errHandler,
+		chi:           c,
+		errHandler:    r.errHandler,
+		ctxErrHandler: r.ctxErrHandler,
+		routerBuilder: r.routerBuilder,
+		container:     r.container,
+		inflight:      newInflight(),
+		chains:        r.chains,
+		chain:         r.chain,
+		lifecycle:     r.lifecycle,
+		syncCtx:       r.syncCtx,
+		sanitize:      r.sanitize,
+		errMappings:   r.errMappings,
+		costs:         r.costs,
+		deprecations:  r.deprecations,
+		docs:          r.docs,
+		versions:      r.versions,
+		prefix:        prefix,
+		options:       r.options,
 	}
 
-	fn(subRouter)
-	r.chi.Mount("/", subRouter.chi)
+	c.Use(subRouter.inflight.middleware)
+
+	return subRouter
+}
+
+// Group registers routes sharing middleware on the same router, like
+// chi.Router.Group: middleware added in fn wraps only the group's routes, and
+// unmatched requests reach the parent's not found handler. Groups can be
+// declared any number of times.
+func (r *Router) Group(fn func(r *Router)) *Router {
+	var subRouter *Router
+
+	r.chi.Group(func(c chi.Router) {
+		subRouter = r.subRouter(c, r.prefix)
+		fn(subRouter)
+	})
+
+	r.record(func(c *Router) { c.Group(subRouter.replay) })
 
 	return subRouter
 }
 
 func (r *Router) Route(pattern string, fn func(r *Router)) {
-	subRouter := &Router{
-		chi:        r.routerBuilder(),
-		errHandler: r.errHandler,
-	}
+	subRouter := r.subRouter(r.routerBuilder(), r.prefix+strings.TrimSuffix(pattern, "/"))
 
 	fn(subRouter)
 	r.chi.Mount(pattern, subRouter.chi)
+
+	r.record(func(c *Router) { c.Route(pattern, subRouter.replay) })
 }
 
 func (r *Router) Mount(pattern string, h http.Handler) {
 	r.chi.Mount(pattern, h)
+	r.chains.set(r.prefix+strings.TrimSuffix(pattern, "/")+"/*", r.chain)
+	r.record(func(c *Router) { c.Mount(pattern, cloneHandler(h)) })
 }
 
 func (r *Router) Use(middlewares ...func(Handler) Handler) {
+	named := make([]NamedMiddleware, len(middlewares))
+	for i, middleware := range middlewares {
+		named[i] = Named(MiddlewareName(middleware), middleware)
+	}
+
+	r.UseNamed(named...)
+}
+
+// UseNamed is like Use, but reports each middleware under its own name in
+// stage timings and MiddlewareChain.
+func (r *Router) UseNamed(middlewares ...NamedMiddleware) {
 	wrappedMiddlewares := make([]func(http.Handler) http.Handler, len(middlewares))
 
-	for i, middleware := range middlewares {
+	for i, nm := range middlewares {
+		stage, middleware := nm.Name, nm.Middleware
+
 		wrappedMiddlewares[i] = func(next http.Handler) http.Handler {
 			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-				wrappedHandler := middleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-					next.ServeHTTP(w, r)
+				defer Stage(req.Context(), stage)()
+
+				wrappedHandler := middleware(func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+					if r.syncCtx {
+						req = syncRequest(ctx, req)
+					}
+
+					next.ServeHTTP(w, req)
 					return nil
 				})
 
-				if err := wrappedHandler(req.Context(), w, req); err != nil {
-					r.errHandler(w, req, err)
+				if r.syncCtx {
+					enterSync(req.Context())
 				}
+
+				r.serve(wrappedHandler, w, req)
 			})
 		}
+
+		r.chain = append(slices.Clip(r.chain), stage)
 	}
 
 	r.chi.Use(wrappedMiddlewares...)
+	r.middlewares = append(r.middlewares, middlewares...)
+	r.record(func(c *Router) { c.UseNamed(middlewares...) })
 }
 
 func (r *Router) NotFound(h Handler) {
 	r.chi.NotFound(r.adapt(h))
+	r.record(func(c *Router) { c.NotFound(h) })
 }
 
 func (r *Router) MethodNotAllowed(h Handler) {
 	r.chi.MethodNotAllowed(r.adapt(h))
+	r.record(func(c *Router) { c.MethodNotAllowed(h) })
 }
@@ -0,0 +1,197 @@
+package chutest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+)
+
+var fuzzMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+var fuzzHeaders = []string{
+	"Content-Type", "Accept", "Authorization", "Content-Encoding",
+	"If-None-Match", "Range", "X-Request-Id", "Cookie",
+}
+
+var patternParam = regexp.MustCompile(`\{[^}]+\}|\*$`)
+
+type FuzzInput struct {
+	Method      uint8
+	Param       string
+	Query       string
+	Header      uint8
+	HeaderValue string
+	Body        []byte
+}
+
+type fuzzConfig struct {
+	seeds      []FuzzInput
+	checkError func(err error) error
+}
+
+type FuzzOption func(*fuzzConfig)
+
+func WithSeeds(seeds ...FuzzInput) FuzzOption {
+	return func(c *fuzzConfig) {
+		c.seeds = append(c.seeds, seeds...)
+	}
+}
+
+func WithErrorCheck(check func(err error) error) FuzzOption {
+	return func(c *fuzzConfig) {
+		c.checkError = check
+	}
+}
+
+var defaultSeeds = []FuzzInput{
+	{Method: 0, Param: "1"},
+	{Method: 2, Param: "abc", Header: 0, HeaderValue: "application/json", Body: []byte(`{}`)},
+	{Method: 3, Param: "", Query: "a=1&a=2", Header: 0, HeaderValue: "application/json", Body: []byte(`{"id":null}`)},
+	{Method: 4, Param: "../..", Query: "%zz", Header: 1, HeaderValue: "*/*;q=x", Body: []byte(`[`)},
+	{Method: 5, Param: "%00", Header: 2, HeaderValue: "Bearer ", Body: []byte{0xff, 0xfe}},
+}
+
+func checkStructuredError(err error) error {
+	var sc interface{ StatusCode() int }
+	if !errors.As(err, &sc) {
+		return fmt.Errorf("handler returned unstructured error %T: %v", err, err)
+	}
+
+	if status := sc.StatusCode(); status < 400 || status > 599 {
+		return fmt.Errorf("handler returned error with non-error status %d: %v", status, err)
+	}
+
+	return nil
+}
+
+func (in FuzzInput) Request(pattern string) *http.Request {
+	path := patternParam.ReplaceAllStringFunc(pattern, func(string) string {
+		return url.PathEscape(in.Param)
+	})
+
+	req := httptest.NewRequest(fuzzMethods[int(in.Method)%len(fuzzMethods)], "/", bytes.NewReader(in.Body))
+	req.URL.Path, _ = url.PathUnescape(path)
+	req.URL.RawPath = path
+	req.URL.RawQuery = in.Query
+	req.RequestURI = req.URL.RequestURI()
+
+	if in.HeaderValue != "" {
+		req.Header.Set(fuzzHeaders[int(in.Header)%len(fuzzHeaders)], in.HeaderValue)
+	}
+
+	return req
+}
+
+func RandomInput(rng *rand.Rand) FuzzInput {
+	return FuzzInput{
+		Method:      uint8(rng.IntN(256)),
+		Param:       randomString(rng, 16),
+		Query:       randomString(rng, 24),
+		Header:      uint8(rng.IntN(256)),
+		HeaderValue: randomString(rng, 32),
+		Body:        []byte(randomString(rng, 128)),
+	}
+}
+
+func randomString(rng *rand.Rand, max int) string {
+	const alphabet = "abcXYZ019-_./%?&=:;{}[]\"', \\\x00\xff"
+
+	var sb strings.Builder
+
+	n := rng.IntN(max + 1)
+	for i := 0; i < n; i++ {
+		sb.WriteByte(alphabet[rng.IntN(len(alphabet))])
+	}
+
+	return sb.String()
+}
+
+func Fuzz(f *testing.F, pattern string, h chu.Handler, opts ...FuzzOption) {
+	cfg := newFuzzConfig(opts)
+
+	for _, seed := range cfg.seeds {
+		f.Add(seed.Method, seed.Param, seed.Query, seed.Header, seed.HeaderValue, seed.Body)
+	}
+
+	f.Fuzz(func(t *testing.T, method uint8, param, query string, header uint8, headerValue string, body []byte) {
+		runFuzzInput(t, pattern, h, cfg, FuzzInput{
+			Method:      method,
+			Param:       param,
+			Query:       query,
+			Header:      header,
+			HeaderValue: headerValue,
+			Body:        body,
+		})
+	})
+}
+
+func Check(t testing.TB, pattern string, h chu.Handler, seed uint64, n int, opts ...FuzzOption) {
+	t.Helper()
+
+	cfg := newFuzzConfig(opts)
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	inputs := append([]FuzzInput{}, cfg.seeds...)
+	for i := 0; i < n; i++ {
+		inputs = append(inputs, RandomInput(rng))
+	}
+
+	for _, in := range inputs {
+		runFuzzInput(t, pattern, h, cfg, in)
+	}
+}
+
+func newFuzzConfig(opts []FuzzOption) *fuzzConfig {
+	cfg := &fuzzConfig{
+		seeds:      append([]FuzzInput{}, defaultSeeds...),
+		checkError: checkStructuredError,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+func runFuzzInput(t testing.TB, pattern string, h chu.Handler, cfg *fuzzConfig, in FuzzInput) {
+	t.Helper()
+
+	var handlerErr error
+
+	r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handlerErr = err
+		w.WriteHeader(chu.StatusCode(err))
+	}))
+	r.Handle(pattern, h)
+
+	req := in.Request(pattern)
+
+	defer func() {
+		if p := recover(); p != nil {
+			t.Fatalf("chutest: handler panicked for %s %s (input %+v): %v", req.Method, req.URL, in, p)
+		}
+	}()
+
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if handlerErr == nil {
+		return
+	}
+
+	if err := cfg.checkError(handlerErr); err != nil {
+		t.Errorf("chutest: %s %s (input %+v): %v", req.Method, req.URL, in, err)
+	}
+}
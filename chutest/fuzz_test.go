@@ -0,0 +1,72 @@
+package chutest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/chutest"
+	"github.com/stretchr/testify/assert"
+)
+
+func showUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(chu.URLParam(r, "id"))
+	if err != nil {
+		return chu.Errorf(http.StatusBadRequest, "invalid id: %v", err)
+	}
+
+	_, _ = w.Write([]byte(strconv.Itoa(id)))
+	return nil
+}
+
+func FuzzShowUser(f *testing.F) {
+	chutest.Fuzz(f, "/users/{id}", showUser)
+}
+
+func TestCheck(t *testing.T) {
+	chutest.Check(t, "/users/{id}", showUser, 1, 200)
+}
+
+func TestCheck_ReportsFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler chu.Handler
+	}{
+		{
+			name: "unstructured error",
+			handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				return errors.New("plain")
+			},
+		},
+		{
+			name: "panic",
+			handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				panic("boom")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+
+			chutest.Check(tb, "/items/{id}", tt.handler, 1, 1)
+
+			assert.NotEmpty(t, tb.errors, "check should report failures")
+		})
+	}
+}
+
+func TestFuzzInput_Request(t *testing.T) {
+	in := chutest.FuzzInput{Method: 2, Param: "a/b", Query: "x=1", Header: 0, HeaderValue: "text/plain"}
+
+	req := in.Request("/users/{id}/files/*")
+
+	assert.Equal(t, http.MethodPost, req.Method, "method should be chosen from the index")
+	assert.Equal(t, "/users/a%2Fb/files/a%2Fb", req.URL.EscapedPath(), "params should be filled and escaped")
+	assert.Equal(t, "x=1", req.URL.RawQuery, "query should be set")
+	assert.Equal(t, "text/plain", req.Header.Get("Content-Type"), "header should be set")
+}
@@ -0,0 +1,154 @@
+package chutest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ignoredValue = "<ignored>"
+
+// updateGolden reports whether golden files should be rewritten, as asked by
+// CHU_UPDATE_GOLDEN=1 or by an -update flag the test binary defines itself.
+// Registering the flag here would clash with test binaries that do.
+func updateGolden() bool {
+	if ok, _ := strconv.ParseBool(os.Getenv("CHU_UPDATE_GOLDEN")); ok {
+		return true
+	}
+
+	f := flag.Lookup("update")
+	return f != nil && f.Value.String() == "true"
+}
+
+type goldenConfig struct {
+	ignoreFields []string
+	normalizers  []func([]byte) []byte
+}
+
+type GoldenOption func(*goldenConfig)
+
+func IgnoreFields(fields ...string) GoldenOption {
+	return func(c *goldenConfig) {
+		c.ignoreFields = append(c.ignoreFields, fields...)
+	}
+}
+
+func ReplacePattern(re *regexp.Regexp, replacement string) GoldenOption {
+	return Normalize(func(data []byte) []byte {
+		return re.ReplaceAll(data, []byte(replacement))
+	})
+}
+
+func Normalize(fn func([]byte) []byte) GoldenOption {
+	return func(c *goldenConfig) {
+		c.normalizers = append(c.normalizers, fn)
+	}
+}
+
+func Golden(t testing.TB, resp *http.Response, path string, opts ...GoldenOption) {
+	t.Helper()
+
+	cfg := &goldenConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("chutest: reading response body: %v", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	actual, err := cfg.normalize(body)
+	if err != nil {
+		t.Fatalf("chutest: normalizing response body: %v", err)
+	}
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("chutest: creating golden directory: %v", err)
+		}
+
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatalf("chutest: writing golden file: %v", err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("chutest: golden file %s does not exist, run with CHU_UPDATE_GOLDEN=1 to create it", path)
+	}
+
+	if err != nil {
+		t.Fatalf("chutest: reading golden file: %v", err)
+	}
+
+	assert.Equal(t, string(expected), string(actual), "response body should match golden file %s", path)
+}
+
+func (c *goldenConfig) normalize(body []byte) ([]byte, error) {
+	var value any
+	if json.Unmarshal(body, &value) == nil {
+		ignoreFields(value, c.ignoreFields)
+
+		var buf bytes.Buffer
+
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(value); err != nil {
+			return nil, err
+		}
+
+		body = buf.Bytes()
+	}
+
+	for _, fn := range c.normalizers {
+		body = fn(body)
+	}
+
+	return body, nil
+}
+
+func ignoreFields(value any, fields []string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if contains(fields, key) {
+				v[key] = ignoredValue
+				continue
+			}
+
+			ignoreFields(child, fields)
+		}
+	case []any:
+		for _, child := range v {
+			ignoreFields(child, fields)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
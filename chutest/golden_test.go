@@ -0,0 +1,65 @@
+package chutest_test
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/chutest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test binaries commonly define their own -update flag; chutest must not
+// clash with it.
+var _ = flag.Bool("update", false, "update golden files")
+
+func TestGolden(t *testing.T) {
+	r := chu.New()
+	r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tags":["admin"],"name":"jose","id":"` + chu.URLParam(r, "id") +
+			`","created_at":"2024-01-01T10:00:00Z","session":"sess-8f3a"}`))
+		return nil
+	})
+
+	for _, id := range []string{"1", "42"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/users/"+id, nil))
+
+		chutest.Golden(t, w.Result(), "testdata/user_show.json",
+			chutest.IgnoreFields("id", "created_at"),
+			chutest.ReplacePattern(regexp.MustCompile(`sess-[0-9a-f]+`), "sess-XXXX"),
+		)
+	}
+}
+
+func TestGolden_Mismatch(t *testing.T) {
+	w := httptest.NewRecorder()
+	_, _ = w.Write([]byte(`{"name":"other"}`))
+
+	tb := &recordingTB{TB: t}
+	chutest.Golden(tb, w.Result(), "testdata/user_show.json")
+
+	assert.NotEmpty(t, tb.errors, "mismatching body should be reported")
+}
+
+func TestGolden_Update(t *testing.T) {
+	t.Setenv("CHU_UPDATE_GOLDEN", "1")
+
+	path := filepath.Join(t.TempDir(), "golden", "body.json")
+
+	w := httptest.NewRecorder()
+	_, _ = w.Write([]byte(`{"name":"jose"}`))
+	chutest.Golden(t, w.Result(), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file should be written")
+	assert.Equal(t, "{\n  \"name\": \"jose\"\n}\n", string(data), "golden file should hold the normalized body")
+}
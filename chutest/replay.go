@@ -0,0 +1,39 @@
+package chutest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/josearomeroj/chu/middleware"
+)
+
+func Replay(t testing.TB, h http.Handler, file string) []*httptest.ResponseRecorder {
+	t.Helper()
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("chutest: opening recording: %v", err)
+	}
+	defer f.Close()
+
+	records, err := middleware.ReadRecords(f)
+	if err != nil {
+		t.Fatalf("chutest: reading recording: %v", err)
+	}
+
+	responses := make([]*httptest.ResponseRecorder, 0, len(records))
+	for _, rec := range records {
+		if rec.Truncated {
+			t.Logf("chutest: replaying %s %s with a truncated body", rec.Method, rec.URL)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, rec.Request())
+
+		responses = append(responses, w)
+	}
+
+	return responses
+}
@@ -0,0 +1,52 @@
+package chutest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/chutest"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "requests.jsonl")
+
+	f, err := os.Create(file)
+	require.NoError(t, err, "should be able to create recording file")
+
+	echo := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		_, _ = w.Write([]byte(r.Method + " " + chu.URLParam(r, "id") + " " + string(body)))
+		return nil
+	}
+
+	production := chu.New()
+	production.Use(middleware.Record(f, middleware.RecordOptions{}))
+	production.Put("/items/{id}", echo)
+
+	production.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/items/1", strings.NewReader("first")))
+	production.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/items/2", strings.NewReader("second")))
+	require.NoError(t, f.Close(), "should be able to close recording file")
+
+	local := chu.New()
+	local.Put("/items/{id}", echo)
+
+	responses := chutest.Replay(t, local, file)
+
+	require.Len(t, responses, 2, "every recorded request should be replayed")
+	assert.Equal(t, "PUT 1 first", responses[0].Body.String(), "first replay should match")
+	assert.Equal(t, "PUT 2 second", responses[1].Body.String(), "second replay should match")
+}
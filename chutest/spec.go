@@ -0,0 +1,189 @@
+package chutest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/openapi"
+)
+
+// ValidateAgainstSpec reports requests and responses that do not match spec.
+// Install it with Router.Use so it observes what the error handler writes for
+// failed requests; when an error is returned through it, as with per-route
+// middleware, only the error's status is checked.
+func ValidateAgainstSpec(t testing.TB, spec *openapi.Document) func(chu.Handler) chu.Handler {
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			t.Helper()
+
+			var reqBody []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				data, err := io.ReadAll(r.Body)
+				if err != nil {
+					return err
+				}
+
+				reqBody = data
+				r.Body = io.NopCloser(bytes.NewReader(data))
+			}
+
+			var respBody bytes.Buffer
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&respBody)
+
+			err := next(ctx, ww, r)
+
+			rctx := chi.RouteContext(ctx)
+			if rctx == nil || rctx.RoutePattern() == "" {
+				return err
+			}
+
+			path := openapi.PathFromPattern(rctx.RoutePattern())
+
+			op, ok := spec.Lookup(r.Method, path)
+			if !ok {
+				t.Errorf("chutest: %s %s is not documented in the spec", r.Method, path)
+				return err
+			}
+
+			validateRequest(t, r, path, op, reqBody)
+
+			status := ww.Status()
+			switch {
+			case status == 0 && err != nil:
+				// The error handler writes the response later, so only its
+				// status is known.
+				validateStatus(t, r, path, op, chu.StatusCode(err))
+				return err
+			case status == 0:
+				status = http.StatusOK
+			}
+
+			validateResponse(t, r, path, op, status, ww.Header().Get("Content-Type"), respBody.Bytes())
+
+			return err
+		}
+	}
+}
+
+func validateRequest(t testing.TB, r *http.Request, path string, op *openapi.Operation, body []byte) {
+	t.Helper()
+
+	for _, p := range op.Parameters {
+		validateParameter(t, r, path, p)
+	}
+
+	if op.RequestBody == nil {
+		return
+	}
+
+	if len(body) == 0 {
+		if op.RequestBody.Required {
+			t.Errorf("chutest: %s %s request body is required", r.Method, path)
+		}
+
+		return
+	}
+
+	validateContent(t, r.Method+" "+path+" request", op.RequestBody.Content, r.Header.Get("Content-Type"), body)
+}
+
+func validateParameter(t testing.TB, r *http.Request, path string, p *openapi.Parameter) {
+	t.Helper()
+
+	var values []string
+	switch p.In {
+	case "path":
+		if value := chu.URLParam(r, p.Name); value != "" {
+			values = []string{value}
+		}
+	case "query":
+		values = r.URL.Query()[p.Name]
+	case "header":
+		values = r.Header.Values(p.Name)
+	case "cookie":
+		if c, err := r.Cookie(p.Name); err == nil {
+			values = []string{c.Value}
+		}
+	}
+
+	if len(values) == 0 {
+		if p.Required || p.In == "path" {
+			t.Errorf("chutest: %s %s %s parameter %q is required", r.Method, path, p.In, p.Name)
+		}
+
+		return
+	}
+
+	for _, value := range values {
+		if err := p.Validate(value); err != nil {
+			t.Errorf("chutest: %s %s %s parameter does not match schema: %v", r.Method, path, p.In, err)
+		}
+	}
+}
+
+func validateStatus(t testing.TB, r *http.Request, path string, op *openapi.Operation, status int) (*openapi.Response, bool) {
+	t.Helper()
+
+	resp, ok := op.ResponseFor(status)
+	if !ok {
+		t.Errorf("chutest: %s %s response status %d is not documented", r.Method, path, status)
+	}
+
+	return resp, ok
+}
+
+func validateResponse(t testing.TB, r *http.Request, path string, op *openapi.Operation, status int, contentType string, body []byte) {
+	t.Helper()
+
+	resp, ok := validateStatus(t, r, path, op, status)
+	if !ok {
+		return
+	}
+
+	if len(resp.Content) == 0 || len(body) == 0 || r.Method == http.MethodHead {
+		return
+	}
+
+	validateContent(t, r.Method+" "+path+" response", resp.Content, contentType, body)
+}
+
+func validateContent(t testing.TB, subject string, content map[string]*openapi.MediaType, contentType string, body []byte) {
+	t.Helper()
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Errorf("chutest: %s has invalid content type %q", subject, contentType)
+		return
+	}
+
+	mt, ok := content[mediaType]
+	if !ok {
+		t.Errorf("chutest: %s content type %q is not documented", subject, mediaType)
+		return
+	}
+
+	if mt.Schema == nil || !(mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		t.Errorf("chutest: %s body is not valid JSON: %v", subject, err)
+		return
+	}
+
+	if err := mt.Schema.Validate(value); err != nil {
+		t.Errorf("chutest: %s does not match schema: %v", subject, err)
+	}
+}
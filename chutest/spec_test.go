@@ -0,0 +1,219 @@
+package chutest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/chutest"
+	"github.com/josearomeroj/chu/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func testSpec() *openapi.Document {
+	doc := openapi.New("test", "1.0.0")
+
+	op := doc.Operation("POST", "/users/{id}")
+	op.Body().Required = true
+	op.Body().Content["application/json"] = &openapi.MediaType{Schema: &openapi.Schema{
+		Type:       "object",
+		Required:   []string{"name"},
+		Properties: map[string]*openapi.Schema{"name": {Type: "string"}},
+	}}
+	op.Response(http.StatusCreated).Content = map[string]*openapi.MediaType{
+		"application/json": {Schema: &openapi.Schema{
+			Type:       "object",
+			Properties: map[string]*openapi.Schema{"id": {Type: "integer"}},
+		}},
+	}
+
+	return doc
+}
+
+func TestValidateAgainstSpec(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		responseStatus int
+		responseBody   string
+		expectedErrors []string
+	}{
+		{
+			name:           "valid exchange",
+			method:         "POST",
+			path:           "/users/1",
+			body:           `{"name":"jose"}`,
+			responseStatus: http.StatusCreated,
+			responseBody:   `{"id":1}`,
+		},
+		{
+			name:           "request missing required field",
+			method:         "POST",
+			path:           "/users/1",
+			body:           `{}`,
+			responseStatus: http.StatusCreated,
+			responseBody:   `{"id":1}`,
+			expectedErrors: []string{"request does not match schema"},
+		},
+		{
+			name:           "response with wrong type",
+			method:         "POST",
+			path:           "/users/1",
+			body:           `{"name":"jose"}`,
+			responseStatus: http.StatusCreated,
+			responseBody:   `{"id":"one"}`,
+			expectedErrors: []string{"response does not match schema"},
+		},
+		{
+			name:           "undocumented status",
+			method:         "POST",
+			path:           "/users/1",
+			body:           `{"name":"jose"}`,
+			responseStatus: http.StatusTeapot,
+			responseBody:   `{}`,
+			expectedErrors: []string{"response status 418 is not documented"},
+		},
+		{
+			name:           "undocumented operation",
+			method:         "GET",
+			path:           "/users/1",
+			responseStatus: http.StatusOK,
+			expectedErrors: []string{"GET /users/{id} is not documented"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+
+			r := chu.New()
+			r.Use(chutest.ValidateAgainstSpec(tb, testSpec()))
+
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.responseStatus)
+				_, _ = w.Write([]byte(tt.responseBody))
+				return nil
+			}
+			r.Post("/users/{id}", handler)
+			r.Get("/users/{id}", handler)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Len(t, tb.errors, len(tt.expectedErrors), "number of reported errors should match: %v", tb.errors)
+			for i, expected := range tt.expectedErrors {
+				if i < len(tb.errors) {
+					assert.Contains(t, tb.errors[i], expected, "reported error should match expected")
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAgainstSpec_Parameters(t *testing.T) {
+	doc := openapi.New("test", "1.0.0")
+
+	op := doc.Operation("GET", "/items/{id}")
+	op.Parameters = []*openapi.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+		{Name: "verbose", In: "query", Schema: &openapi.Schema{Type: "boolean"}},
+		{Name: "X-Tenant", In: "header", Required: true, Schema: &openapi.Schema{Type: "string"}},
+	}
+	op.Response(http.StatusOK)
+
+	tests := []struct {
+		name           string
+		path           string
+		tenant         string
+		expectedErrors []string
+	}{
+		{name: "valid parameters", path: "/items/1?verbose=true", tenant: "acme"},
+		{name: "path parameter of wrong type", path: "/items/one", tenant: "acme", expectedErrors: []string{"path.id: must be integer"}},
+		{name: "query parameter of wrong type", path: "/items/1?verbose=maybe", tenant: "acme", expectedErrors: []string{"query.verbose: must be boolean"}},
+		{name: "missing required header", path: "/items/1", expectedErrors: []string{`header parameter "X-Tenant" is required`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+
+			r := chu.New()
+			r.Use(chutest.ValidateAgainstSpec(tb, doc))
+			r.Get("/items/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.tenant != "" {
+				req.Header.Set("X-Tenant", tt.tenant)
+			}
+
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Len(t, tb.errors, len(tt.expectedErrors), "number of reported errors should match: %v", tb.errors)
+			for i, expected := range tt.expectedErrors {
+				if i < len(tb.errors) {
+					assert.Contains(t, tb.errors[i], expected, "reported error should match expected")
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAgainstSpec_Errors(t *testing.T) {
+	failing := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.Errorf(http.StatusTeapot, "short and stout")
+	}
+
+	tests := []struct {
+		name     string
+		register func(r *chu.Router, validate func(chu.Handler) chu.Handler)
+	}{
+		{name: "router middleware", register: func(r *chu.Router, validate func(chu.Handler) chu.Handler) {
+			r.Use(validate)
+			r.Post("/users/{id}", failing)
+		}},
+		{name: "route middleware", register: func(r *chu.Router, validate func(chu.Handler) chu.Handler) {
+			r.Post("/users/{id}", failing, chu.WithMiddleware(validate))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+
+			r := chu.New()
+			tt.register(r, chutest.ValidateAgainstSpec(tb, testSpec()))
+
+			req := httptest.NewRequest("POST", "/users/1", strings.NewReader(`{"name":"jose"}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if assert.Len(t, tb.errors, 1, "error response should be validated: %v", tb.errors) {
+				assert.Contains(t, tb.errors[0], "response status 418 is not documented", "reported error should match expected")
+			}
+		})
+	}
+}
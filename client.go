@@ -0,0 +1,143 @@
+package chu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var inProcessCtxKey = &contextKey{"in-process"}
+
+func Client(h http.Handler) *http.Client {
+	return &http.Client{Transport: Transport(h)}
+}
+
+func Transport(h http.Handler) http.RoundTripper {
+	return &inProcessTransport{handler: h}
+}
+
+func InProcess(ctx context.Context) bool {
+	inProcess, _ := ctx.Value(inProcessCtxKey).(bool)
+	return inProcess
+}
+
+type inProcessTransport struct {
+	handler http.Handler
+}
+
+func (t *inProcessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	sreq := req.Clone(context.WithValue(ctx, inProcessCtxKey, true))
+	sreq.URL = &url.URL{Path: req.URL.Path, RawPath: req.URL.RawPath, RawQuery: req.URL.RawQuery}
+	sreq.RequestURI = req.URL.RequestURI()
+	sreq.RemoteAddr = "in-process:0"
+	sreq.Proto, sreq.ProtoMajor, sreq.ProtoMinor = "HTTP/1.1", 1, 1
+
+	if sreq.Host == "" {
+		sreq.Host = req.URL.Host
+	}
+
+	if sreq.Body == nil {
+		sreq.Body = http.NoBody
+	}
+
+	pr, pw := io.Pipe()
+	w := &inProcessWriter{
+		header: make(http.Header),
+		body:   pw,
+		head:   req.Method == http.MethodHead,
+		ready:  make(chan struct{}),
+		resp:   &http.Response{Proto: "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1, Request: req, Trailer: make(http.Header)},
+	}
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				pw.CloseWithError(fmt.Errorf("chu: in-process handler panic: %v", p))
+			}
+		}()
+
+		t.handler.ServeHTTP(w, sreq)
+
+		w.WriteHeader(http.StatusOK)
+		w.finish()
+		pw.Close()
+	}()
+
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		pr.CloseWithError(ctx.Err())
+		return nil, ctx.Err()
+	}
+
+	w.resp.Body = pr
+	return w.resp, nil
+}
+
+type inProcessWriter struct {
+	header http.Header
+	body   *io.PipeWriter
+	head   bool
+
+	once  sync.Once
+	ready chan struct{}
+	resp  *http.Response
+}
+
+func (w *inProcessWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *inProcessWriter) WriteHeader(status int) {
+	w.once.Do(func() {
+		w.resp.StatusCode = status
+		w.resp.Status = strconv.Itoa(status) + " " + http.StatusText(status)
+		w.resp.Header = w.header.Clone()
+		w.resp.ContentLength = -1
+
+		if n, err := strconv.ParseInt(w.header.Get("Content-Length"), 10, 64); err == nil {
+			w.resp.ContentLength = n
+		}
+
+		for _, name := range w.resp.Header.Values("Trailer") {
+			w.resp.Trailer[http.CanonicalHeaderKey(name)] = nil
+		}
+		w.resp.Header.Del("Trailer")
+
+		for name := range w.resp.Header {
+			if strings.HasPrefix(name, http.TrailerPrefix) {
+				delete(w.resp.Header, name)
+			}
+		}
+
+		close(w.ready)
+	})
+}
+
+func (w *inProcessWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+
+	if w.head {
+		return len(p), nil
+	}
+
+	return w.body.Write(p)
+}
+
+func (w *inProcessWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (w *inProcessWriter) finish() {
+	for name, values := range trailerValues(w.header) {
+		w.resp.Trailer[name] = values
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("client: circuit open")
+
+type BreakerOptions struct {
+	Failures  int
+	Cooldown  time.Duration
+	IsFailure func(resp *http.Response, err error) bool
+	Key       func(req *http.Request) string
+	Now       func() time.Time
+}
+
+func CircuitBreaker(opts BreakerOptions) Middleware {
+	if opts.Failures <= 0 {
+		opts.Failures = 5
+	}
+
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = 30 * time.Second
+	}
+
+	if opts.IsFailure == nil {
+		opts.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= http.StatusInternalServerError
+		}
+	}
+
+	if opts.Key == nil {
+		opts.Key = func(req *http.Request) string { return req.URL.Host }
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	var (
+		mu       sync.Mutex
+		breakers = make(map[string]*breaker)
+	)
+
+	get := func(key string) *breaker {
+		mu.Lock()
+		defer mu.Unlock()
+
+		b, ok := breakers[key]
+		if !ok {
+			b = &breaker{}
+			breakers[key] = b
+		}
+
+		return b
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			b := get(opts.Key(req))
+			if !b.allow(opts.Now(), opts.Cooldown) {
+				return nil, ErrCircuitOpen
+			}
+
+			resp, err := next.RoundTrip(req)
+			b.record(opts.Now(), opts.IsFailure(resp, err), opts.Failures)
+
+			return resp, err
+		})
+	}
+}
+
+type breaker struct {
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *breaker) allow(now time.Time, cooldown time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return true
+	}
+
+	if b.probing || now.Sub(b.openedAt) < cooldown {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(now time.Time, failed bool, threshold int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures, b.openedAt, b.probing = 0, time.Time{}, false
+		return
+	}
+
+	b.failures++
+	if b.probing || b.failures >= threshold {
+		b.openedAt, b.probing = now, false
+	}
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type Option func(*config)
+
+type config struct {
+	transport   http.RoundTripper
+	timeout     time.Duration
+	middlewares []Middleware
+}
+
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *config) {
+		c.transport = rt
+	}
+}
+
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *config) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+func New(opts ...Option) *http.Client {
+	cfg := &config{transport: http.DefaultTransport}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &http.Client{
+		Transport: Chain(cfg.transport, cfg.middlewares...),
+		Timeout:   cfg.timeout,
+	}
+}
+
+func Chain(rt http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+
+	return rt
+}
+
+func Timeout(d time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithTimeout(req.Context(), d)
+
+			resp, err := next.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		})
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func PropagateHeaders() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			headers := chu.PropagatedHeaders(req.Context())
+			if len(headers) == 0 {
+				return next.RoundTrip(req)
+			}
+
+			req = req.Clone(req.Context())
+			for name, values := range headers {
+				if req.Header.Get(name) == "" {
+					req.Header[name] = values
+				}
+			}
+
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func Logging(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.Redacted()),
+				slog.Duration("elapsed", time.Since(start)),
+			}
+
+			if err != nil {
+				logger.LogAttrs(req.Context(), slog.LevelError, "outbound request failed", append(attrs, slog.Any("error", err))...)
+				return nil, err
+			}
+
+			level := slog.LevelInfo
+			if resp.StatusCode >= http.StatusInternalServerError {
+				level = slog.LevelWarn
+			}
+
+			logger.LogAttrs(req.Context(), level, "outbound request", append(attrs, slog.Int("status", resp.StatusCode))...)
+			return resp, nil
+		})
+	}
+}
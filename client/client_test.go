@@ -0,0 +1,281 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tag(name string, order *[]string) client.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return client.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*order = append(*order, name)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestNew_Chain(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	var order []string
+	c := client.New(client.WithMiddleware(tag("first", &order), tag("second", &order)))
+
+	resp, err := c.Get(upstream.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"first", "second"}, order, "middlewares should run in registration order")
+}
+
+func TestTimeout(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+
+		_, _ = w.Write([]byte("done"))
+	}))
+	defer upstream.Close()
+
+	c := client.New(client.WithMiddleware(client.Timeout(50 * time.Millisecond)))
+
+	_, err := c.Get(upstream.URL + "/slow")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "slow requests should time out")
+
+	resp, err := c.Get(upstream.URL + "/fast")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "done", string(body), "body should remain readable until closed")
+}
+
+func TestPropagateHeaders(t *testing.T) {
+	var seen http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	c := client.New(client.WithMiddleware(client.PropagateHeaders()))
+
+	r := chu.New()
+	r.Use(chu.PropagateHeaders())
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-Id", "req-7")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "req-7", seen.Get("X-Request-Id"), "inbound headers should be propagated upstream")
+}
+
+func TestLogging(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	var buf bytes.Buffer
+	c := client.New(client.WithMiddleware(client.Logging(slog.New(slog.NewTextHandler(&buf, nil)))))
+
+	resp, err := c.Get(upstream.URL + "/items?token=x")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Contains(t, buf.String(), "level=WARN", "5xx responses should log at warn")
+	assert.Contains(t, buf.String(), "status=502", "status should be logged")
+	assert.Contains(t, buf.String(), "method=GET", "method should be logged")
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		header   map[string]string
+		failures int32
+		attempts int32
+		expected int
+	}{
+		{name: "recovers after transient failures", method: http.MethodGet, failures: 2, attempts: 3, expected: http.StatusOK},
+		{name: "gives up after max attempts", method: http.MethodGet, failures: 5, attempts: 3, expected: http.StatusServiceUnavailable},
+		{name: "post is not retried", method: http.MethodPost, body: "payload", failures: 1, attempts: 1, expected: http.StatusServiceUnavailable},
+		{name: "post with idempotency key is retried", method: http.MethodPost, body: "payload", header: map[string]string{"Idempotency-Key": "k1"}, failures: 1, attempts: 2, expected: http.StatusOK},
+		{name: "put body is replayed", method: http.MethodPut, body: "payload", failures: 2, attempts: 3, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, tt.body, string(body), "request body should be intact on every attempt")
+
+				if calls.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+			}))
+			defer upstream.Close()
+
+			c := client.New(client.WithMiddleware(client.Retry(client.RetryOptions{Attempts: 3, BaseDelay: time.Millisecond})))
+
+			req, err := http.NewRequest(tt.method, upstream.URL, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := c.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.expected, resp.StatusCode, "status code should match expected")
+			assert.Equal(t, tt.attempts, calls.Load(), "attempts should match expected")
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	var calls atomic.Int32
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	c := client.New(client.WithMiddleware(client.Retry(client.RetryOptions{Attempts: 3, BaseDelay: time.Millisecond, Budget: 0.5})))
+
+	for range 4 {
+		resp, err := c.Get(upstream.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, int32(7), calls.Load(), "retries should be limited by the budget")
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		after    string
+		attempts int32
+	}{
+		{name: "short wait is honored", after: "0", attempts: 2},
+		{name: "wait beyond max delay is not retried", after: "120", attempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					w.Header().Set("Retry-After", tt.after)
+					w.WriteHeader(http.StatusTooManyRequests)
+				}
+			}))
+			defer upstream.Close()
+
+			c := client.New(client.WithMiddleware(client.Retry(client.RetryOptions{BaseDelay: time.Millisecond, MaxDelay: time.Second})))
+
+			resp, err := c.Get(upstream.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.attempts, calls.Load(), "attempts should match expected")
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		calls   atomic.Int32
+		healthy atomic.Bool
+	)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer upstream.Close()
+
+	now := time.Now()
+	c := client.New(client.WithMiddleware(client.CircuitBreaker(client.BreakerOptions{
+		Failures: 2,
+		Cooldown: time.Minute,
+		Now:      func() time.Time { return now },
+	})))
+
+	get := func() (int, error) {
+		resp, err := c.Get(upstream.URL)
+		if err != nil {
+			return 0, err
+		}
+
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	for range 2 {
+		status, err := get()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, status, "failures should pass through until the threshold")
+	}
+
+	_, err := get()
+	assert.ErrorIs(t, err, client.ErrCircuitOpen, "circuit should open after the threshold")
+	assert.Equal(t, int32(2), calls.Load(), "open circuit should not reach the upstream")
+
+	now = now.Add(2 * time.Minute)
+	_, err = get()
+	require.NoError(t, err, "a probe should be allowed after the cooldown")
+
+	_, err = get()
+	assert.ErrorIs(t, err, client.ErrCircuitOpen, "failed probe should reopen the circuit")
+
+	healthy.Store(true)
+	now = now.Add(2 * time.Minute)
+
+	for range 3 {
+		status, err := get()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status, "successful probe should close the circuit")
+	}
+}
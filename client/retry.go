@@ -0,0 +1,161 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type RetryOptions struct {
+	Attempts  int
+	BaseDelay time.Duration
+	// MaxDelay bounds the wait between attempts. Responses whose Retry-After
+	// asks for a longer wait are returned instead of retried early.
+	MaxDelay time.Duration
+	RetryOn  func(resp *http.Response, err error) bool
+	// Methods lists the methods retried; other requests are only retried with
+	// an Idempotency-Key header.
+	Methods []string
+	// Budget bounds retries to this fraction of the requests, so retries cannot
+	// multiply the load on a struggling upstream. Zero means no limit.
+	Budget float64
+}
+
+func Retry(opts RetryOptions) Middleware {
+	if opts.Attempts <= 0 {
+		opts.Attempts = 3
+	}
+
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = 100 * time.Millisecond
+	}
+
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = 5 * time.Second
+	}
+
+	if opts.RetryOn == nil {
+		opts.RetryOn = DefaultRetryOn
+	}
+
+	if opts.Methods == nil {
+		opts.Methods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete}
+	}
+
+	var requests, retries atomic.Uint64
+
+	// allowed reports whether the budget has room for another retry.
+	allowed := func() bool {
+		return opts.Budget <= 0 || float64(retries.Load()) <= opts.Budget*float64(requests.Load())
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !retryable(req, opts.Methods) {
+				return next.RoundTrip(req)
+			}
+
+			requests.Add(1)
+
+			for attempt := 1; ; attempt++ {
+				attemptReq := req
+				if attempt > 1 && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+
+					attemptReq = req.Clone(req.Context())
+					attemptReq.Body = body
+				}
+
+				resp, err := next.RoundTrip(attemptReq)
+				if attempt >= opts.Attempts || !opts.RetryOn(resp, err) || !allowed() {
+					return resp, err
+				}
+
+				delay := backoff(opts.BaseDelay, opts.MaxDelay, attempt)
+				if resp != nil {
+					after := retryAfter(resp)
+					if after > opts.MaxDelay {
+						return resp, err
+					}
+
+					if after > 0 {
+						delay = after
+					}
+
+					_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+					resp.Body.Close()
+				}
+
+				retries.Add(1)
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				}
+			}
+		})
+	}
+}
+
+func DefaultRetryOn(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrCircuitOpen)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func retryable(req *http.Request, methods []string) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	for _, m := range methods {
+		if m == req.Method {
+			return true
+		}
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	d := base << (attempt - 1)
+	if d <= 0 || d > max {
+		d = max
+	}
+
+	return d/2 + rand.N(d/2+1)
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
@@ -0,0 +1,127 @@
+package chu_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	r := chu.New()
+	r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-In-Process", strconv.FormatBool(chu.InProcess(ctx)))
+		_, err := w.Write([]byte("user " + chu.URLParam(r, "id") + " " + r.URL.Query().Get("expand") + " " + r.Host))
+		return err
+	})
+	r.Post("/echo", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusCreated)
+		_, err := io.Copy(w, r.Body)
+		return err
+	})
+	r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.Errorf(http.StatusConflict, "conflict")
+	})
+
+	client := chu.Client(r)
+
+	resp, err := client.Get("http://users.internal/users/42?expand=roles")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "status code should match expected")
+	assert.Equal(t, "user 42 roles users.internal", string(body), "body should match expected")
+	assert.Equal(t, "true", resp.Header.Get("X-In-Process"), "handler should know the call is in-process")
+
+	resp, err = client.Post("http://users.internal/echo", "text/plain", strings.NewReader("ping"))
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "status code should match expected")
+	assert.Equal(t, "ping", string(body), "request body should reach the handler")
+
+	resp, err = client.Get("http://users.internal/fail")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "errors should go through the error handler")
+	assert.Equal(t, "409 Conflict", resp.Status, "status line should match expected")
+
+	resp, err = client.Head("http://users.internal/users/1")
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, body, "HEAD responses should have no body")
+}
+
+func TestClient_Streaming(t *testing.T) {
+	release := make(chan struct{})
+
+	r := chu.New()
+	r.Get("/events", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Trailer", "X-Count")
+		_, _ = w.Write([]byte("first\n"))
+		w.(http.Flusher).Flush()
+
+		<-release
+
+		_, _ = w.Write([]byte("second\n"))
+		w.Header().Set("X-Count", "2")
+		return nil
+	})
+
+	resp, err := chu.Client(r).Get("http://svc/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", line, "first chunk should arrive before the handler finishes")
+
+	close(release)
+
+	rest, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(rest), "remaining body should arrive")
+	assert.Equal(t, "2", resp.Trailer.Get("X-Count"), "trailers should be available after the body")
+}
+
+func TestClient_Cancel(t *testing.T) {
+	r := chu.New()
+	r.Get("/slow", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://svc/slow", nil)
+	require.NoError(t, err)
+
+	_, err = chu.Client(r).Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "canceled requests should fail")
+}
+
+func TestClient_Panic(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("partial"))
+		panic("boom")
+	})
+
+	resp, err := chu.Client(h).Get("http://svc/")
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(resp.Body)
+	assert.ErrorContains(t, err, "boom", "panics should surface as body read errors")
+}
@@ -0,0 +1,58 @@
+package chu
+
+import "net/http"
+
+// Clone returns an independent copy of the router: routes, middleware, groups,
+// error handlers, options, providers and lifecycle hooks are all copied, so
+// registering on the clone never affects the original and vice versa. This
+// makes it cheap to build a base router once and derive per-test or
+// per-tenant variants.
+//
+// Handlers and middleware functions are shared, and so is any state they
+// capture. Mounted chu routers are cloned too; other mounted handlers are
+// shared. Routes registered directly on the chi router, including those of a
+// router wrapped with FromChi, are not copied.
+func (r *Router) Clone() *Router {
+	c := New(r.options...)
+	c.container = r.container.clone()
+	c.lifecycle = r.lifecycle.clone()
+
+	r.replay(c)
+
+	return c
+}
+
+// record remembers a registration so Clone can replay it on a new router.
+func (r *Router) record(op func(c *Router)) {
+	r.recipe = append(r.recipe, op)
+}
+
+func (r *Router) replay(c *Router) {
+	for _, op := range r.recipe {
+		op(c)
+	}
+}
+
+func cloneHandler(h http.Handler) http.Handler {
+	if router, ok := h.(*Router); ok {
+		return router.Clone()
+	}
+
+	return h
+}
+
+func (c *container) clone() *container {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clone := newContainer()
+	for typ, p := range c.providers {
+		clone.providers[typ] = &provider{scope: p.scope, factory: p.factory}
+	}
+
+	return clone
+}
+
+func (l *lifecycle) clone() *lifecycle {
+	return &lifecycle{hooks: l.snapshot()}
+}
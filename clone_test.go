@@ -0,0 +1,139 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/josearomeroj/chu"
+)
+
+func TestClone(t *testing.T) {
+	text := func(s string) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := io.WriteString(w, s)
+			return err
+		}
+	}
+
+	tag := func(value string) func(chu.Handler) chu.Handler {
+		return func(next chu.Handler) chu.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Add("X-Tag", value)
+				return next(ctx, w, r)
+			}
+		}
+	}
+
+	admin := chu.New()
+	admin.Get("/stats", text("stats"))
+
+	base := chu.New()
+	base.Use(tag("base"))
+	base.Get("/", text("home"))
+	base.Group(func(r *chu.Router) {
+		r.Use(tag("group"))
+		r.Get("/private", text("private"), chu.Cost(3))
+	})
+	base.Route("/api", func(r *chu.Router) {
+		r.Get("/items/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := io.WriteString(w, "item "+chu.URLParam(r, "id")+" cost "+strconv.Itoa(chu.RouteCost(r)))
+			return err
+		}, chu.Cost(5))
+	})
+	base.Mount("/admin", admin)
+	base.NotFound(text("custom not found"))
+	base.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tenant := base.Clone()
+	tenant.Get("/tenant", text("tenant only"))
+	tenant.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	})
+
+	base.Get("/base-only", text("base only"))
+	admin.Get("/late", text("late"))
+
+	tests := []struct {
+		name   string
+		router http.Handler
+		path   string
+		status int
+		body   string
+		tags   []string
+	}{
+		{name: "copied route", router: tenant, path: "/", status: http.StatusOK, body: "home", tags: []string{"base"}},
+		{name: "copied group middleware", router: tenant, path: "/private", status: http.StatusOK, body: "private", tags: []string{"base", "group"}},
+		{name: "copied sub-router with cost", router: tenant, path: "/api/items/7", status: http.StatusOK, body: "item 7 cost 5", tags: []string{"base"}},
+		{name: "cloned mount", router: tenant, path: "/admin/stats", status: http.StatusOK, body: "stats", tags: []string{"base"}},
+		{name: "mount is independent", router: tenant, path: "/admin/late", status: http.StatusNotFound},
+		{name: "copied not found", router: tenant, path: "/missing", status: http.StatusOK, body: "custom not found", tags: []string{"base"}},
+		{name: "copied error handler", router: tenant, path: "/fail", status: http.StatusTeapot, tags: []string{"base"}},
+		{name: "clone route", router: tenant, path: "/tenant", status: http.StatusOK, body: "tenant only", tags: []string{"base"}},
+		{name: "clone route not in base", router: base, path: "/tenant", status: http.StatusOK, body: "custom not found", tags: []string{"base"}},
+		{name: "base route not in clone", router: tenant, path: "/base-only", status: http.StatusOK, body: "custom not found", tags: []string{"base"}},
+		{name: "base keeps working", router: base, path: "/base-only", status: http.StatusOK, body: "base only", tags: []string{"base"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			if tt.body != "" || tt.status != http.StatusOK {
+				assert.Equal(t, tt.status, w.Code, "status code should match expected")
+			}
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+			if tt.tags != nil {
+				assert.Equal(t, tt.tags, w.Header().Values("X-Tag"), "middleware should match expected")
+			}
+		})
+	}
+}
+
+func TestCloneProviders(t *testing.T) {
+	type counter struct{ n int }
+
+	builds := 0
+
+	base := chu.New()
+	chu.ProvideSingleton(base, func(ctx context.Context) (*counter, error) {
+		builds++
+		return &counter{}, nil
+	})
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		c, err := chu.Resolve[*counter](ctx)
+		if err != nil {
+			return err
+		}
+
+		c.n++
+		_, err = io.WriteString(w, strconv.Itoa(c.n))
+		return err
+	}
+	base.Get("/", handler)
+
+	clone := base.Clone()
+
+	call := func(r http.Handler) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "1", call(base), "base should build its singleton")
+	assert.Equal(t, "2", call(base), "base should reuse its singleton")
+	assert.Equal(t, "1", call(clone), "clone should have its own singleton")
+	assert.Equal(t, 2, builds, "each router should build the singleton once")
+}
@@ -0,0 +1,153 @@
+package chu
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var cloudTraceCtxKey = &contextKey{"cloud-trace"}
+
+type CloudTrace struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+func RunCloud(h http.Handler, opts ...ServerOption) error {
+	project := cmp.Or(os.Getenv("GOOGLE_CLOUD_PROJECT"), os.Getenv("GCP_PROJECT"), os.Getenv("GCLOUD_PROJECT"))
+	slog.SetDefault(slog.New(NewCloudLogHandler(os.Stdout, project, nil)))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if r, ok := h.(*Router); ok {
+		opts = append([]ServerOption{withLifecycle(r.lifecycle)}, opts...)
+	}
+
+	return Serve(ctx, ":"+cmp.Or(os.Getenv("PORT"), "8080"), WithCloudTrace(h), opts...)
+}
+
+func WithCloudTrace(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if trace, ok := parseCloudTrace(r.Header); ok {
+			r = r.WithContext(context.WithValue(r.Context(), cloudTraceCtxKey, trace))
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func CloudTraceFromContext(ctx context.Context) (CloudTrace, bool) {
+	trace, ok := ctx.Value(cloudTraceCtxKey).(CloudTrace)
+	return trace, ok
+}
+
+func parseCloudTrace(header http.Header) (CloudTrace, bool) {
+	if parts := strings.Split(header.Get("traceparent"), "-"); len(parts) == 4 && len(parts[1]) == 32 && len(parts[2]) == 16 {
+		flags, err := strconv.ParseUint(parts[3], 16, 8)
+		return CloudTrace{TraceID: parts[1], SpanID: parts[2], Sampled: err == nil && flags&1 == 1}, true
+	}
+
+	value := header.Get("X-Cloud-Trace-Context")
+	if value == "" {
+		return CloudTrace{}, false
+	}
+
+	value, options, _ := strings.Cut(value, ";")
+	traceID, spanID, _ := strings.Cut(value, "/")
+	if traceID == "" {
+		return CloudTrace{}, false
+	}
+
+	trace := CloudTrace{TraceID: traceID, Sampled: options == "o=1"}
+	if span, err := strconv.ParseUint(spanID, 10, 64); err == nil {
+		trace.SpanID = strconv.FormatUint(span, 16)
+		trace.SpanID = strings.Repeat("0", 16-len(trace.SpanID)) + trace.SpanID
+	}
+
+	return trace, true
+}
+
+type cloudLogHandler struct {
+	slog.Handler
+	project string
+}
+
+func NewCloudLogHandler(w io.Writer, project string, opts *slog.HandlerOptions) slog.Handler {
+	var o slog.HandlerOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	replace := o.ReplaceAttr
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.LevelKey:
+				a = slog.String("severity", cloudSeverity(a.Value.Any().(slog.Level)))
+			case slog.MessageKey:
+				a.Key = "message"
+			case slog.SourceKey:
+				a.Key = "logging.googleapis.com/sourceLocation"
+			}
+		}
+
+		if replace != nil {
+			return replace(groups, a)
+		}
+
+		return a
+	}
+
+	return &cloudLogHandler{Handler: slog.NewJSONHandler(w, &o), project: project}
+}
+
+func (h *cloudLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if trace, ok := CloudTraceFromContext(ctx); ok {
+		traceID := trace.TraceID
+		if h.project != "" {
+			traceID = "projects/" + h.project + "/traces/" + traceID
+		}
+
+		record = record.Clone()
+		record.AddAttrs(
+			slog.String("logging.googleapis.com/trace", traceID),
+			slog.Bool("logging.googleapis.com/trace_sampled", trace.Sampled && Sampled(ctx)),
+		)
+
+		if trace.SpanID != "" {
+			record.AddAttrs(slog.String("logging.googleapis.com/spanId", trace.SpanID))
+		}
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *cloudLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &cloudLogHandler{Handler: h.Handler.WithAttrs(attrs), project: h.project}
+}
+
+func (h *cloudLogHandler) WithGroup(name string) slog.Handler {
+	return &cloudLogHandler{Handler: h.Handler.WithGroup(name), project: h.project}
+}
+
+func cloudSeverity(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
@@ -0,0 +1,97 @@
+package chu_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCloudTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   map[string]string
+		expected chu.CloudTrace
+		found    bool
+	}{
+		{name: "no header"},
+		{
+			name:     "cloud trace context",
+			header:   map[string]string{"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000/1;o=1"},
+			expected: chu.CloudTrace{TraceID: "105445aa7843bc8bf206b12000100000", SpanID: "0000000000000001", Sampled: true},
+			found:    true,
+		},
+		{
+			name:     "cloud trace context without span",
+			header:   map[string]string{"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000"},
+			expected: chu.CloudTrace{TraceID: "105445aa7843bc8bf206b12000100000"},
+			found:    true,
+		},
+		{
+			name: "traceparent wins",
+			header: map[string]string{
+				"traceparent":           "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000/1;o=0",
+			},
+			expected: chu.CloudTrace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+			found:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				trace chu.CloudTrace
+				found bool
+			)
+
+			h := chu.WithCloudTrace(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				trace, found = chu.CloudTraceFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.found, found, "trace presence should match expected")
+			assert.Equal(t, tt.expected, trace, "trace should match expected")
+		})
+	}
+}
+
+func TestCloudLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(chu.NewCloudLogHandler(&buf, "my-project", nil)).With("service", "api")
+
+	var ctx context.Context
+	h := chu.WithCloudTrace(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Cloud-Trace-Context", "abc123/255;o=1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	logger.WarnContext(ctx, "slow request", "elapsed_ms", 1200)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	assert.Equal(t, "WARNING", entry["severity"], "level should map to severity")
+	assert.Equal(t, "slow request", entry["message"], "msg should map to message")
+	assert.Equal(t, "projects/my-project/traces/abc123", entry["logging.googleapis.com/trace"], "trace should be correlated")
+	assert.Equal(t, "00000000000000ff", entry["logging.googleapis.com/spanId"], "span should be hex encoded")
+	assert.Equal(t, true, entry["logging.googleapis.com/trace_sampled"], "sampling flag should be forwarded")
+	assert.Equal(t, "api", entry["service"], "handler attributes should be kept")
+	assert.NotContains(t, entry, "level", "level key should be replaced")
+}
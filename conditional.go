@@ -0,0 +1,81 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+func When(pred func(r *http.Request) bool, middlewares ...func(Handler) Handler) func(Handler) Handler {
+	return func(next Handler) Handler {
+		wrapped := next
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			wrapped = middlewares[i](wrapped)
+		}
+
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if pred(r) {
+				return wrapped(ctx, w, r)
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func Unless(pred func(r *http.Request) bool, middlewares ...func(Handler) Handler) func(Handler) Handler {
+	return When(func(r *http.Request) bool { return !pred(r) }, middlewares...)
+}
+
+func PathPrefix(prefixes ...string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func HasHeader(name, value string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		if value == "" {
+			return r.Header.Get(name) != ""
+		}
+
+		return r.Header.Get(name) == value
+	}
+}
+
+func SkipPaths(mw func(Handler) Handler, patterns ...string) func(Handler) Handler {
+	exact := make(map[string]struct{})
+
+	var prefixes []string
+
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			prefixes = append(prefixes, prefix)
+			continue
+		}
+
+		exact[pattern] = struct{}{}
+	}
+
+	skip := func(r *http.Request) bool {
+		if _, ok := exact[r.URL.Path]; ok {
+			return true
+		}
+
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return Unless(skip, mw)
+}
@@ -0,0 +1,103 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func tagMiddleware(tag string) func(chu.Handler) chu.Handler {
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.Header().Add("X-Tags", tag)
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func TestWhenUnless(t *testing.T) {
+	r := chu.New()
+	r.Use(
+		chu.Unless(chu.PathPrefix("/health", "/metrics"), tagMiddleware("auth")),
+		chu.When(chu.HasHeader("Accept-Encoding", ""), tagMiddleware("gzip"), tagMiddleware("vary")),
+	)
+	r.Get("/*", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		header   http.Header
+		expected []string
+	}{
+		{name: "protected path", path: "/api/users", expected: []string{"auth"}},
+		{name: "skipped path", path: "/health/live", expected: nil},
+		{name: "header match", path: "/metrics", header: http.Header{"Accept-Encoding": {"gzip"}}, expected: []string{"gzip", "vary"}},
+		{name: "both", path: "/api", header: http.Header{"Accept-Encoding": {"br"}}, expected: []string{"auth", "gzip", "vary"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header = tt.header
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Header().Values("X-Tags"), "applied middleware should match expected")
+		})
+	}
+}
+
+func TestHasHeader_Value(t *testing.T) {
+	pred := chu.HasHeader("X-Env", "prod")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	assert.False(t, pred(req), "missing header should not match")
+
+	req.Header.Set("X-Env", "dev")
+	assert.False(t, pred(req), "different value should not match")
+
+	req.Header.Set("X-Env", "prod")
+	assert.True(t, pred(req), "matching value should match")
+}
+
+func TestSkipPaths(t *testing.T) {
+	r := chu.New()
+	r.UseNamed(chu.Named("logging", chu.SkipPaths(tagMiddleware("logged"), "/healthz", "/metrics", "/static/*")))
+	r.Get("/*", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	tests := []struct {
+		path    string
+		skipped bool
+	}{
+		{path: "/healthz", skipped: true},
+		{path: "/healthz/deep"},
+		{path: "/metrics", skipped: true},
+		{path: "/static/app.js", skipped: true},
+		{path: "/static/", skipped: true},
+		{path: "/static"},
+		{path: "/api/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, !tt.skipped, w.Header().Get("X-Tags") == "logged", "middleware should run unless path is skipped")
+		})
+	}
+
+	assert.Equal(t, []string{"logging"}, r.MiddlewareChain("/*"), "skipped middleware should keep its name")
+}
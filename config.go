@@ -0,0 +1,76 @@
+package chu
+
+import (
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Config is a data alternative to Option for building a Router. Zero values
+// keep the defaults used by New; the serializable fields can be decoded from
+// configuration files while function fields are filled in code.
+type Config struct {
+	ErrorHandler        ErrorHandler            `json:"-" yaml:"-"`
+	ContextErrorHandler ContextErrorHandler     `json:"-" yaml:"-"`
+	RouterBuilder       func() chi.Router       `json:"-" yaml:"-"`
+	Middlewares         []func(Handler) Handler `json:"-" yaml:"-"`
+	NotFound            Handler                 `json:"-" yaml:"-"`
+	MethodNotAllowed    Handler                 `json:"-" yaml:"-"`
+	StageReport         func(StageReport)       `json:"-" yaml:"-"`
+	ErrorSanitizer      func(string) string     `json:"-" yaml:"-"`
+
+	ServeMux       bool          `json:"serve_mux" yaml:"serve_mux"`
+	SyncContext    bool          `json:"sync_context" yaml:"sync_context"`
+	StageThreshold time.Duration `json:"stage_threshold" yaml:"stage_threshold"`
+}
+
+func (c Config) Options() []Option {
+	var opts []Option
+
+	if c.ErrorHandler != nil {
+		opts = append(opts, WithErrorHandler(c.ErrorHandler))
+	}
+
+	if c.ContextErrorHandler != nil {
+		opts = append(opts, WithContextErrorHandler(c.ContextErrorHandler))
+	}
+
+	switch {
+	case c.RouterBuilder != nil:
+		opts = append(opts, WithRouterBuilder(c.RouterBuilder))
+	case c.ServeMux:
+		opts = append(opts, WithServeMuxBackend())
+	}
+
+	if c.ErrorSanitizer != nil {
+		opts = append(opts, WithErrorSanitizer(c.ErrorSanitizer))
+	}
+
+	if c.SyncContext {
+		opts = append(opts, WithContextSync())
+	}
+
+	if c.StageReport != nil {
+		opts = append(opts, WithStageDiagnostics(c.StageThreshold, c.StageReport))
+	}
+
+	return opts
+}
+
+func NewFromConfig(cfg Config, opts ...Option) *Router {
+	r := New(append(cfg.Options(), opts...)...)
+
+	if len(cfg.Middlewares) > 0 {
+		r.Use(cfg.Middlewares...)
+	}
+
+	if cfg.NotFound != nil {
+		r.NotFound(cfg.NotFound)
+	}
+
+	if cfg.MethodNotAllowed != nil {
+		r.MethodNotAllowed(cfg.MethodNotAllowed)
+	}
+
+	return r
+}
@@ -0,0 +1,79 @@
+package chu_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	cfg := chu.Config{
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			w.WriteHeader(chu.StatusCode(err))
+			_, _ = w.Write([]byte("custom: " + err.Error()))
+		},
+		Middlewares: []func(chu.Handler) chu.Handler{
+			func(next chu.Handler) chu.Handler {
+				return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+					w.Header().Set("X-Config", "yes")
+					return next(ctx, w, r)
+				}
+			},
+		},
+		NotFound: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return chu.NewError(http.StatusNotFound, errors.New("nothing here"))
+		},
+		MethodNotAllowed: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return chu.NewError(http.StatusMethodNotAllowed, errors.New("wrong method"))
+		},
+	}
+
+	for backend, serveMux := range map[string]bool{"chi": false, "servemux": true} {
+		cfg.ServeMux = serveMux
+
+		r := chu.NewFromConfig(cfg)
+		r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return chu.NewError(http.StatusConflict, errors.New("boom"))
+		})
+
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			status int
+			body   string
+		}{
+			{name: "error handler", method: http.MethodGet, path: "/fail", status: http.StatusConflict, body: "custom: boom"},
+			{name: "not found", method: http.MethodGet, path: "/missing", status: http.StatusNotFound, body: "custom: nothing here"},
+			{name: "method not allowed", method: http.MethodPost, path: "/fail", status: http.StatusMethodNotAllowed, body: "custom: wrong method"},
+		}
+
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+				assert.Equal(t, tt.status, rec.Code, "status code should match expected")
+				assert.Equal(t, tt.body, rec.Body.String(), "body should match expected")
+				assert.Equal(t, "yes", rec.Header().Get("X-Config"), "config middleware should run")
+			})
+		}
+	}
+}
+
+func TestConfigDecode(t *testing.T) {
+	var cfg chu.Config
+	require.NoError(t, json.Unmarshal([]byte(`{"serve_mux": true, "stage_threshold": 5000000}`), &cfg))
+
+	assert.True(t, cfg.ServeMux, "serve mux should be decoded")
+	assert.Equal(t, 5*time.Millisecond, cfg.StageThreshold, "stage threshold should be decoded")
+	assert.Len(t, cfg.Options(), 1, "only the backend option should be produced")
+}
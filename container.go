@@ -0,0 +1,159 @@
+package chu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var ErrNotProvided = errors.New("chu: dependency not provided")
+
+type Scope int
+
+const (
+	ScopeRequest Scope = iota
+	ScopeSingleton
+)
+
+var scopeCtxKey = &contextKey{"scope"}
+
+type container struct {
+	mu        sync.RWMutex
+	providers map[reflect.Type]*provider
+}
+
+type provider struct {
+	scope   Scope
+	factory func(ctx context.Context) (any, error)
+
+	mu    sync.Mutex
+	built bool
+	value any
+}
+
+type requestScope struct {
+	container *container
+
+	mu     sync.Mutex
+	values map[reflect.Type]any
+}
+
+func newContainer() *container {
+	return &container{providers: make(map[reflect.Type]*provider)}
+}
+
+func (c *container) empty() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.providers) == 0
+}
+
+func (c *container) register(typ reflect.Type, scope Scope, factory func(ctx context.Context) (any, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.providers[typ] = &provider{scope: scope, factory: factory}
+}
+
+func (c *container) lookup(typ reflect.Type) (*provider, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	p, ok := c.providers[typ]
+	return p, ok
+}
+
+func (c *container) withScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeCtxKey, &requestScope{
+		container: c,
+		values:    make(map[reflect.Type]any),
+	})
+}
+
+func (p *provider) singleton(ctx context.Context) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.built {
+		return p.value, nil
+	}
+
+	// Singletons outlive the request that happens to build them first, so
+	// they must not inherit its cancellation.
+	v, err := p.factory(context.WithoutCancel(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	p.value, p.built = v, true
+	return v, nil
+}
+
+func (s *requestScope) resolve(ctx context.Context, typ reflect.Type) (any, error) {
+	p, ok := s.container.lookup(typ)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotProvided, typ)
+	}
+
+	if p.scope == ScopeSingleton {
+		return p.singleton(ctx)
+	}
+
+	s.mu.Lock()
+	v, ok := s.values[typ]
+	s.mu.Unlock()
+
+	if ok {
+		return v, nil
+	}
+
+	v, err := p.factory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.values[typ]; ok {
+		return existing, nil
+	}
+
+	s.values[typ] = v
+	return v, nil
+}
+
+func Provide[T any](r *Router, factory func(ctx context.Context) (T, error)) {
+	provide(r, ScopeRequest, factory)
+}
+
+func ProvideSingleton[T any](r *Router, factory func(ctx context.Context) (T, error)) {
+	provide(r, ScopeSingleton, factory)
+}
+
+func provide[T any](r *Router, scope Scope, factory func(ctx context.Context) (T, error)) {
+	r.container.register(reflect.TypeFor[T](), scope, func(ctx context.Context) (any, error) {
+		return factory(ctx)
+	})
+}
+
+func Resolve[T any](ctx context.Context) (T, error) {
+	var zero T
+
+	typ := reflect.TypeFor[T]()
+
+	s, ok := ctx.Value(scopeCtxKey).(*requestScope)
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrNotProvided, typ)
+	}
+
+	v, err := s.resolve(ctx, typ)
+	if err != nil {
+		return zero, err
+	}
+
+	return v.(T), nil
+}
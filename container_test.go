@@ -0,0 +1,205 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testService struct {
+	id int
+}
+
+func TestProvideResolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		singleton     bool
+		requests      int
+		expectedCalls int
+	}{
+		{
+			name:          "request scope builds once per request",
+			singleton:     false,
+			requests:      3,
+			expectedCalls: 3,
+		},
+		{
+			name:          "singleton scope builds once",
+			singleton:     true,
+			requests:      3,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chu.New()
+
+			calls := 0
+			factory := func(ctx context.Context) (*testService, error) {
+				calls++
+				return &testService{id: calls}, nil
+			}
+
+			if tt.singleton {
+				chu.ProvideSingleton(r, factory)
+			} else {
+				chu.Provide(r, factory)
+			}
+
+			r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				first, err := chu.Resolve[*testService](ctx)
+				if err != nil {
+					return err
+				}
+
+				second, err := chu.Resolve[*testService](ctx)
+				if err != nil {
+					return err
+				}
+
+				if first != second {
+					return errors.New("resolved different instances in the same request")
+				}
+
+				_, _ = w.Write([]byte(fmt.Sprint(first.id)))
+				return nil
+			})
+
+			for i := 0; i < tt.requests; i++ {
+				req := httptest.NewRequest("GET", "/", nil)
+				w := httptest.NewRecorder()
+
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusOK, w.Code, "status code should be OK")
+			}
+
+			assert.Equal(t, tt.expectedCalls, calls, "factory call count should match expected")
+		})
+	}
+}
+
+func TestResolve_Dependencies(t *testing.T) {
+	type config struct {
+		name string
+	}
+
+	r := chu.New()
+
+	chu.ProvideSingleton(r, func(ctx context.Context) (*config, error) {
+		return &config{name: "db"}, nil
+	})
+	chu.Provide(r, func(ctx context.Context) (string, error) {
+		cfg, err := chu.Resolve[*config](ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return "conn:" + cfg.name, nil
+	})
+
+	r.Route("/api", func(api *chu.Router) {
+		api.Get("/conn", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			conn, err := chu.Resolve[string](ctx)
+			if err != nil {
+				return err
+			}
+
+			_, _ = w.Write([]byte(conn))
+			return nil
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/api/conn", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	body, err := io.ReadAll(w.Result().Body)
+	require.NoError(t, err, "should be able to read response body")
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should be OK")
+	assert.Equal(t, "conn:db", string(body), "response body should match expected")
+}
+
+func TestResolve_Errors(t *testing.T) {
+	factoryErr := errors.New("factory failed")
+
+	tests := []struct {
+		name    string
+		setup   func(r *chu.Router)
+		wantErr error
+	}{
+		{
+			name:    "not provided",
+			setup:   func(r *chu.Router) {},
+			wantErr: chu.ErrNotProvided,
+		},
+		{
+			name: "factory error",
+			setup: func(r *chu.Router) {
+				chu.Provide(r, func(ctx context.Context) (*testService, error) {
+					return nil, factoryErr
+				})
+			},
+			wantErr: factoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got error
+
+			r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+				got = err
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			tt.setup(r)
+
+			r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				_, err := chu.Resolve[*testService](ctx)
+				return err
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.ErrorIs(t, got, tt.wantErr, "error should match expected")
+		})
+	}
+}
+
+func TestProvideSingleton_OutlivesRequest(t *testing.T) {
+	r := chu.New()
+
+	var built context.Context
+	chu.ProvideSingleton(r, func(ctx context.Context) (*testService, error) {
+		built = ctx
+		return &testService{}, nil
+	})
+
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := chu.Resolve[*testService](ctx)
+		return err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	cancel()
+
+	require.Equal(t, http.StatusOK, w.Code, "status code should be OK")
+	require.NotNil(t, built, "singleton should be built")
+	assert.NoError(t, built.Err(), "singleton context should not end with the request")
+}
@@ -0,0 +1,156 @@
+package chu
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidCookie = errors.New("chu: invalid cookie")
+	ErrNoCookieKeys  = errors.New("chu: no cookie keys")
+)
+
+// checkCookieKeys rejects a missing or empty key, which would make cookies
+// forgeable.
+func checkCookieKeys(keys [][]byte) error {
+	if len(keys) == 0 || slices.ContainsFunc(keys, func(key []byte) bool { return len(key) == 0 }) {
+		return ErrNoCookieKeys
+	}
+
+	return nil
+}
+
+func SetSignedCookie(w http.ResponseWriter, c *http.Cookie, keys ...[]byte) error {
+	if err := checkCookieKeys(keys); err != nil {
+		return err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(c.Value))
+
+	signed := *c
+	signed.Value = payload + "." + signCookie(keys[0], c.Name, payload)
+	http.SetCookie(w, &signed)
+
+	return nil
+}
+
+func GetSignedCookie(r *http.Request, name string, keys ...[]byte) (string, error) {
+	if err := checkCookieKeys(keys); err != nil {
+		return "", err
+	}
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	return VerifyCookieValue(name, c.Value, keys...)
+}
+
+func VerifyCookieValue(name, value string, keys ...[]byte) (string, error) {
+	if err := checkCookieKeys(keys); err != nil {
+		return "", err
+	}
+
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+
+	for _, key := range keys {
+		if hmac.Equal([]byte(sig), []byte(signCookie(key, name, payload))) {
+			raw, err := base64.RawURLEncoding.DecodeString(payload)
+			if err != nil {
+				return "", ErrInvalidCookie
+			}
+
+			return string(raw), nil
+		}
+	}
+
+	return "", ErrInvalidCookie
+}
+
+func SetEncryptedCookie(w http.ResponseWriter, c *http.Cookie, keys ...[]byte) error {
+	if err := checkCookieKeys(keys); err != nil {
+		return err
+	}
+
+	aead, err := cookieAEAD(keys[0])
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(c.Value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	encrypted := *c
+	encrypted.Value = base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(c.Value), []byte(c.Name)))
+	http.SetCookie(w, &encrypted)
+
+	return nil
+}
+
+func GetEncryptedCookie(r *http.Request, name string, keys ...[]byte) (string, error) {
+	if err := checkCookieKeys(keys); err != nil {
+		return "", err
+	}
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(c.Value)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	for _, key := range keys {
+		aead, err := cookieAEAD(key)
+		if err != nil {
+			return "", err
+		}
+
+		if len(raw) < aead.NonceSize() {
+			return "", ErrInvalidCookie
+		}
+
+		nonce, ciphertext := raw[:aead.NonceSize()], raw[aead.NonceSize():]
+		if plain, err := aead.Open(nil, nonce, ciphertext, []byte(name)); err == nil {
+			return string(plain), nil
+		}
+	}
+
+	return "", ErrInvalidCookie
+}
+
+func signCookie(key []byte, name, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func cookieAEAD(key []byte) (cipher.AEAD, error) {
+	derived := sha256.Sum256(key)
+
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
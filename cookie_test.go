@@ -0,0 +1,96 @@
+package chu_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func roundTripCookie(t *testing.T, set func(w http.ResponseWriter) error) *http.Request {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	require.NoError(t, set(w), "setting cookie should succeed")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+
+	return req
+}
+
+func TestSignedCookie(t *testing.T) {
+	oldKey, newKey := []byte("old"), []byte("new")
+
+	req := roundTripCookie(t, func(w http.ResponseWriter) error {
+		return chu.SetSignedCookie(w, &http.Cookie{Name: "user", Value: "jose; admin=1"}, oldKey)
+	})
+
+	value, err := chu.GetSignedCookie(req, "user", newKey, oldKey)
+	require.NoError(t, err, "old key should still verify during rotation")
+	assert.Equal(t, "jose; admin=1", value, "value should round-trip")
+
+	_, err = chu.GetSignedCookie(req, "user", newKey)
+	assert.ErrorIs(t, err, chu.ErrInvalidCookie, "retired key should not verify")
+
+	c, _ := req.Cookie("user")
+	_, err = chu.VerifyCookieValue("other", c.Value, oldKey)
+	assert.ErrorIs(t, err, chu.ErrInvalidCookie, "signature should be bound to the cookie name")
+
+	_, err = chu.VerifyCookieValue("user", strings.Replace(c.Value, ".", "x.", 1), oldKey)
+	assert.ErrorIs(t, err, chu.ErrInvalidCookie, "tampered payload should not verify")
+
+	_, err = chu.GetSignedCookie(req, "missing", oldKey)
+	assert.ErrorIs(t, err, http.ErrNoCookie, "missing cookie should be reported")
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	oldKey, newKey := []byte("old"), []byte("new")
+
+	req := roundTripCookie(t, func(w http.ResponseWriter) error {
+		return chu.SetEncryptedCookie(w, &http.Cookie{Name: "session", Value: "secret-data"}, oldKey)
+	})
+
+	c, _ := req.Cookie("session")
+	assert.NotContains(t, c.Value, "secret-data", "value should be encrypted")
+
+	value, err := chu.GetEncryptedCookie(req, "session", newKey, oldKey)
+	require.NoError(t, err, "old key should still decrypt during rotation")
+	assert.Equal(t, "secret-data", value, "value should round-trip")
+
+	_, err = chu.GetEncryptedCookie(req, "session", newKey)
+	assert.ErrorIs(t, err, chu.ErrInvalidCookie, "retired key should not decrypt")
+
+	renamed := httptest.NewRequest("GET", "/", nil)
+	renamed.AddCookie(&http.Cookie{Name: "other", Value: c.Value})
+
+	_, err = chu.GetEncryptedCookie(renamed, "other", oldKey)
+	assert.ErrorIs(t, err, chu.ErrInvalidCookie, "ciphertext should be bound to the cookie name")
+}
+
+func TestCookie_NoKeys(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	assert.ErrorIs(t, chu.SetSignedCookie(w, &http.Cookie{Name: "a"}), chu.ErrNoCookieKeys, "signing without keys should fail")
+	assert.ErrorIs(t, chu.SetEncryptedCookie(w, &http.Cookie{Name: "a"}), chu.ErrNoCookieKeys, "encrypting without keys should fail")
+	assert.ErrorIs(t, chu.SetSignedCookie(w, &http.Cookie{Name: "a"}, []byte{}), chu.ErrNoCookieKeys, "signing with an empty key should fail")
+	assert.ErrorIs(t, chu.SetEncryptedCookie(w, &http.Cookie{Name: "a"}, nil), chu.ErrNoCookieKeys, "encrypting with an empty key should fail")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "a", Value: "x"})
+
+	_, err := chu.GetSignedCookie(req, "a")
+	assert.ErrorIs(t, err, chu.ErrNoCookieKeys, "verifying without keys should fail")
+
+	_, err = chu.GetEncryptedCookie(req, "a", []byte("key"), nil)
+	assert.ErrorIs(t, err, chu.ErrNoCookieKeys, "decrypting with an empty key should fail")
+
+	_, err = chu.VerifyCookieValue("a", "x.y", []byte{})
+	assert.ErrorIs(t, err, chu.ErrNoCookieKeys, "verifying with an empty key should fail")
+}
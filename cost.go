@@ -0,0 +1,74 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// Cost declares how many units of a shared rate limit budget a request to the
+// route consumes. Routes without a declared cost consume one unit.
+func Cost(n int) RouteOption {
+	if n < 0 {
+		panic("chu: route cost must not be negative")
+	}
+
+	return func(rt *route) {
+		rt.cost = &n
+	}
+}
+
+// RouteCost returns the cost declared with Cost for the route matching r. It
+// resolves the route itself, so rate limiters added with Use can call it
+// before routing completes.
+func RouteCost(r *http.Request) int {
+	return RouteCostFromCtx(r.Context())
+}
+
+func RouteCostFromCtx(ctx context.Context) int {
+	info, ok := ctx.Value(routeInfoCtxKey).(*routeInfo)
+	if !ok || info.costs == nil || info.costs.empty() {
+		return 1
+	}
+
+	if cost, ok := info.costs.get(info.method, RoutePatternFromCtx(ctx)); ok {
+		return cost
+	}
+
+	return 1
+}
+
+type routeCosts struct {
+	mu    sync.RWMutex
+	costs map[string]int
+}
+
+func newRouteCosts() *routeCosts {
+	return &routeCosts{costs: make(map[string]int)}
+}
+
+func (c *routeCosts) set(method, pattern string, cost int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.costs[method+" "+pattern] = cost
+}
+
+func (c *routeCosts) get(method, pattern string) (int, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if cost, ok := c.costs[method+" "+pattern]; ok {
+		return cost, true
+	}
+
+	cost, ok := c.costs[" "+pattern]
+	return cost, ok
+}
+
+func (c *routeCosts) empty() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.costs) == 0
+}
@@ -0,0 +1,72 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteCost(t *testing.T) {
+	var inMiddleware, inHandler int
+
+	r := chu.New()
+	r.Use(func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			inMiddleware = chu.RouteCost(r)
+			return next(ctx, w, r)
+		}
+	})
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		inHandler = chu.RouteCostFromCtx(ctx)
+		return nil
+	}
+
+	r.Get("/cheap", h)
+	r.Get("/search", h, chu.Cost(5))
+	r.Post("/search", h)
+	r.Handle("/export", h, chu.Cost(20))
+	r.Get("/free", h, chu.Cost(0))
+
+	r.Group(func(r *chu.Router) {
+		r.Get("/grouped/{id}", h, chu.Cost(3))
+	})
+
+	r.Route("/api/", func(r *chu.Router) {
+		r.Route("/v1", func(r *chu.Router) {
+			r.Get("/reports/{id}", h, chu.Cost(8))
+		})
+	})
+
+	tests := []struct {
+		method   string
+		path     string
+		expected int
+	}{
+		{method: "GET", path: "/cheap", expected: 1},
+		{method: "GET", path: "/search", expected: 5},
+		{method: "POST", path: "/search", expected: 1},
+		{method: "DELETE", path: "/export", expected: 20},
+		{method: "GET", path: "/free", expected: 0},
+		{method: "GET", path: "/grouped/7", expected: 3},
+		{method: "GET", path: "/api/v1/reports/7", expected: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expected, inMiddleware, "global middleware should see the route cost")
+			assert.Equal(t, tt.expected, inHandler, "handler should see the route cost")
+		})
+	}
+}
+
+func TestRouteCostWithoutRouter(t *testing.T) {
+	assert.Equal(t, 1, chu.RouteCost(httptest.NewRequest("GET", "/", nil)), "cost should default to one")
+	assert.Panics(t, func() { chu.Cost(-1) }, "negative cost should panic")
+}
@@ -0,0 +1,32 @@
+package chu
+
+import (
+	"context"
+	"html/template"
+)
+
+var cspNonceCtxKey = &contextKey{"csp-nonce"}
+
+func WithCSPNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, cspNonceCtxKey, nonce)
+}
+
+func CSPNonce(ctx context.Context) string {
+	nonce, _ := ctx.Value(cspNonceCtxKey).(string)
+	return nonce
+}
+
+func CSPFuncs(ctx context.Context) template.FuncMap {
+	nonce := CSPNonce(ctx)
+
+	return template.FuncMap{
+		"cspNonce": func() string { return nonce },
+		"nonceAttr": func() template.HTMLAttr {
+			if nonce == "" {
+				return ""
+			}
+
+			return template.HTMLAttr(`nonce="` + template.HTMLEscapeString(nonce) + `"`)
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type deprecationPolicy struct {
+	sunset time.Time
+	link   string
+}
+
+// Deprecated marks the route as deprecated. Responses carry a Deprecation
+// header, a Sunset header when sunset is set and a Link to link, the
+// documentation of the deprecation, when it is set. Requests to deprecated
+// routes are counted, see DeprecatedRoutes.
+func Deprecated(sunset time.Time, link string) RouteOption {
+	return func(rt *route) {
+		rt.deprecation = &deprecationPolicy{sunset: sunset, link: link}
+	}
+}
+
+// DeprecatedRoute reports the usage of a route marked with Deprecated.
+type DeprecatedRoute struct {
+	// Method is empty for routes registered with Handle.
+	Method  string
+	Pattern string
+	Sunset  time.Time
+	Link    string
+	Calls   uint64
+}
+
+type deprecatedRoute struct {
+	method  string
+	pattern string
+	policy  deprecationPolicy
+	calls   atomic.Uint64
+}
+
+type deprecations struct {
+	mu     sync.Mutex
+	routes []*deprecatedRoute
+}
+
+func newDeprecations() *deprecations {
+	return &deprecations{}
+}
+
+func (d *deprecations) add(method, pattern string, policy deprecationPolicy) *deprecatedRoute {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	dr := &deprecatedRoute{method: method, pattern: pattern, policy: policy}
+	d.routes = append(d.routes, dr)
+
+	return dr
+}
+
+// DeprecatedRoutes returns the routes marked with Deprecated and how many
+// requests each has served.
+func (r *Router) DeprecatedRoutes() []DeprecatedRoute {
+	r.deprecations.mu.Lock()
+	routes := slices.Clone(r.deprecations.routes)
+	r.deprecations.mu.Unlock()
+
+	usage := make([]DeprecatedRoute, len(routes))
+	for i, dr := range routes {
+		usage[i] = DeprecatedRoute{
+			Method:  dr.method,
+			Pattern: dr.pattern,
+			Sunset:  dr.policy.sunset,
+			Link:    dr.policy.link,
+			Calls:   dr.calls.Load(),
+		}
+	}
+
+	return usage
+}
+
+func (dr *deprecatedRoute) middleware(next Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		dr.calls.Add(1)
+
+		h := w.Header()
+		h.Set("Deprecation", "true")
+
+		if !dr.policy.sunset.IsZero() {
+			h.Set("Sunset", dr.policy.sunset.UTC().Format(http.TimeFormat))
+		}
+
+		if dr.policy.link != "" {
+			h.Add("Link", "<"+dr.policy.link+`>; rel="deprecation"`)
+		}
+
+		return next(ctx, w, r)
+	}
+}
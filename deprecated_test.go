@@ -0,0 +1,57 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecated(t *testing.T) {
+	sunset := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	ok := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	r := chu.New()
+	r.Route("/v1", func(r *chu.Router) {
+		r.Get("/users", ok, chu.Deprecated(sunset, "https://example.com/docs/v2-migration"))
+		r.Get("/items", ok, chu.Deprecated(time.Time{}, ""))
+	})
+	r.Get("/v2/users", ok)
+
+	tests := []struct {
+		path        string
+		deprecation string
+		sunset      string
+		link        string
+	}{
+		{path: "/v1/users", deprecation: "true", sunset: "Mon, 30 Jun 2025 00:00:00 GMT", link: `<https://example.com/docs/v2-migration>; rel="deprecation"`},
+		{path: "/v1/items", deprecation: "true"},
+		{path: "/v2/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+			assert.Equal(t, tt.deprecation, w.Header().Get("Deprecation"), "deprecation header should match expected")
+			assert.Equal(t, tt.sunset, w.Header().Get("Sunset"), "sunset header should match expected")
+			assert.Equal(t, tt.link, w.Header().Get("Link"), "link header should match expected")
+		})
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/users", nil))
+
+	usage := r.DeprecatedRoutes()
+	require.Len(t, usage, 2, "deprecated routes should be reported")
+	assert.Equal(t, chu.DeprecatedRoute{Method: "GET", Pattern: "/v1/users", Sunset: sunset, Link: "https://example.com/docs/v2-migration", Calls: 2}, usage[0], "usage should be counted")
+	assert.Equal(t, uint64(1), usage[1].Calls, "usage should be counted per route")
+
+	assert.Equal(t, uint64(0), r.Clone().DeprecatedRoutes()[0].Calls, "clones should count separately")
+}
@@ -0,0 +1,205 @@
+package chu
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Model describes a request or response body by an example value of its
+// type.
+type Model struct {
+	Type    reflect.Type `json:"-"`
+	Name    string       `json:"type"`
+	Example any          `json:"example,omitempty"`
+}
+
+func newModel(example any) *Model {
+	t := reflect.TypeOf(example)
+	if t == nil {
+		return nil
+	}
+
+	return &Model{Type: t, Name: t.String(), Example: example}
+}
+
+type ResponseDoc struct {
+	Status int    `json:"status"`
+	Model  *Model `json:"model,omitempty"`
+}
+
+// RouteDoc describes a registered route with the metadata declared by Doc,
+// Accepts and Returns.
+type RouteDoc struct {
+	Method    string        `json:"method"`
+	Pattern   string        `json:"pattern"`
+	Doc       string        `json:"doc,omitempty"`
+	Params    []string      `json:"params,omitempty"`
+	Request   *Model        `json:"request,omitempty"`
+	Responses []ResponseDoc `json:"responses,omitempty"`
+}
+
+type routeDoc struct {
+	doc       string
+	request   *Model
+	responses []ResponseDoc
+}
+
+// Doc documents what the route does.
+func Doc(text string) RouteOption {
+	return func(rt *route) {
+		rt.docs().doc = text
+	}
+}
+
+// Accepts documents the request body of the route with an example value of
+// its type.
+func Accepts(example any) RouteOption {
+	return func(rt *route) {
+		rt.docs().request = newModel(example)
+	}
+}
+
+// Returns documents a response of the route. example is a value of the body
+// type, or nil for responses without a body.
+func Returns(status int, example any) RouteOption {
+	return func(rt *route) {
+		rt.docs().responses = append(rt.docs().responses, ResponseDoc{Status: status, Model: newModel(example)})
+	}
+}
+
+func (rt *route) docs() *routeDoc {
+	if rt.doc == nil {
+		rt.doc = &routeDoc{}
+	}
+
+	return rt.doc
+}
+
+type routeDocs struct {
+	mu   sync.RWMutex
+	docs map[string]*routeDoc
+}
+
+func newRouteDocs() *routeDocs {
+	return &routeDocs{docs: make(map[string]*routeDoc)}
+}
+
+func (d *routeDocs) set(method, pattern string, doc *routeDoc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.docs[method+" "+pattern] = doc
+}
+
+func (d *routeDocs) get(method, pattern string) (*routeDoc, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if doc, ok := d.docs[method+" "+pattern]; ok {
+		return doc, true
+	}
+
+	doc, ok := d.docs[" "+pattern]
+	return doc, ok
+}
+
+// Routes lists the routes registered on r and the routers below it, sorted by
+// pattern and method, with their documentation. Routes of Host and Version
+// routers are not included.
+func (r *Router) Routes() []RouteDoc {
+	var routes []RouteDoc
+
+	_ = chi.Walk(r.chi, func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		rd := RouteDoc{Method: method, Pattern: pattern, Params: patternParams(pattern)}
+		if doc, ok := r.docs.get(method, pattern); ok {
+			rd.Doc, rd.Request, rd.Responses = doc.doc, doc.request, doc.responses
+		}
+
+		routes = append(routes, rd)
+		return nil
+	})
+
+	slices.SortFunc(routes, func(a, b RouteDoc) int {
+		return cmp.Or(cmp.Compare(a.Pattern, b.Pattern), cmp.Compare(a.Method, b.Method))
+	})
+
+	return routes
+}
+
+// patternParams returns the names of the URL parameters of a chi pattern,
+// with "*" for a trailing wildcard.
+func patternParams(pattern string) []string {
+	var params []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			break
+		}
+
+		name, _, _ := strings.Cut(pattern[start+1:start+end], ":")
+		params = append(params, name)
+		pattern = pattern[start+end+1:]
+	}
+
+	if strings.HasSuffix(pattern, "*") {
+		params = append(params, "*")
+	}
+
+	return params
+}
+
+var docsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"json": func(v any) string {
+		b, _ := json.MarshalIndent(v, "", "  ")
+		return string(b)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>API routes</title></head>
+<body>
+<h1>API routes</h1>
+{{range .}}<section>
+<h2><code>{{.Method}} {{.Pattern}}</code></h2>
+{{with .Doc}}<p>{{.}}</p>{{end}}
+{{with .Params}}<p>Parameters: {{range $i, $p := .}}{{if $i}}, {{end}}<code>{{$p}}</code>{{end}}</p>{{end}}
+{{with .Request}}<h3>Request <code>{{.Name}}</code></h3>{{with .Example}}<pre>{{json .}}</pre>{{end}}{{end}}
+{{range .Responses}}<h3>{{.Status}}{{with .Model}} <code>{{.Name}}</code>{{end}}</h3>{{with .Model}}{{with .Example}}<pre>{{json .}}</pre>{{end}}{{end}}
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
+
+// Docs serves the documentation of the routes of r at pattern, as HTML or as
+// JSON for clients that prefer it. It is a lightweight alternative to an
+// OpenAPI document and is meant for the top-level router.
+func (r *Router) Docs(pattern string) {
+	h := func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+		routes := r.Routes()
+		AddVary(w, "Accept")
+
+		if NegotiateContentType(req, "text/html", "application/json") == "application/json" {
+			w.Header().Set("Content-Type", "application/json")
+			return json.NewEncoder(w).Encode(routes)
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		return docsTemplate.Execute(w, routes)
+	}
+
+	r.chi.Method(http.MethodGet, pattern, r.adapt(r.route(http.MethodGet, pattern, nil).wrap(h)))
+	r.record(func(c *Router) { c.Docs(pattern) })
+}
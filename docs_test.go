@@ -0,0 +1,90 @@
+package chu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type docUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func docsRouter() *chu.Router {
+	noop := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	r := chu.New()
+	r.Docs("/docs")
+	r.Route("/users", func(r *chu.Router) {
+		r.Get("/{id:[0-9]+}", noop,
+			chu.Doc("Returns the user by id"),
+			chu.Returns(http.StatusOK, docUser{ID: 1, Name: "Ada"}),
+			chu.Returns(http.StatusNotFound, nil))
+		r.Post("/", noop,
+			chu.Doc("Creates a user"),
+			chu.Accepts(docUser{Name: "Ada"}),
+			chu.Returns(http.StatusCreated, docUser{ID: 1, Name: "Ada"}))
+	})
+	r.Get("/files/*", noop)
+
+	return r
+}
+
+func TestRoutes(t *testing.T) {
+	routes := docsRouter().Routes()
+
+	require.Len(t, routes, 4, "every route should be listed")
+
+	assert.Equal(t, "/docs", routes[0].Pattern, "routes should be sorted by pattern")
+	assert.Equal(t, []string{"*"}, routes[1].Params, "wildcard should be listed as a parameter")
+
+	create := routes[2]
+	assert.Equal(t, http.MethodPost, create.Method, "method should match expected")
+	assert.Equal(t, "/users/", create.Pattern, "pattern should include the mount prefix")
+	assert.Equal(t, "Creates a user", create.Doc, "doc should match expected")
+	require.NotNil(t, create.Request, "request model should be documented")
+	assert.Equal(t, "chu_test.docUser", create.Request.Name, "request model name should match expected")
+
+	get := routes[3]
+	assert.Equal(t, "/users/{id:[0-9]+}", get.Pattern, "pattern should match expected")
+	assert.Equal(t, []string{"id"}, get.Params, "params should match expected")
+	require.Len(t, get.Responses, 2, "responses should be documented")
+	assert.Equal(t, docUser{ID: 1, Name: "Ada"}, get.Responses[0].Model.Example, "response example should match expected")
+	assert.Nil(t, get.Responses[1].Model, "bodyless responses should have no model")
+}
+
+func TestDocs(t *testing.T) {
+	r := docsRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "content type should match expected")
+
+	var routes []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &routes))
+	require.Len(t, routes, 4, "every route should be listed")
+	assert.Equal(t, "Returns the user by id", routes[3]["doc"], "doc should be served")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"), "content type should match expected")
+	assert.Contains(t, rec.Body.String(), "<code>POST /users/</code>", "route should be listed")
+	assert.Contains(t, rec.Body.String(), "Returns the user by id", "doc should be rendered")
+	assert.Contains(t, rec.Body.String(), "&#34;name&#34;: &#34;Ada&#34;", "example should be rendered")
+
+	clone := r.Clone()
+	clone.Get("/extra", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+	assert.Len(t, clone.Routes(), 5, "clone should list its own routes")
+}
@@ -0,0 +1,121 @@
+package chu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type Code struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	DocsURL     string `json:"docs_url,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type CodeOption func(*Code)
+
+func WithDocsURL(url string) CodeOption {
+	return func(c *Code) {
+		c.DocsURL = url
+	}
+}
+
+func WithDescription(description string) CodeOption {
+	return func(c *Code) {
+		c.Description = description
+	}
+}
+
+var codeRegistry = struct {
+	sync.RWMutex
+	codes map[string]*Code
+}{codes: make(map[string]*Code)}
+
+func ErrorCode(code string, status int, opts ...CodeOption) *Code {
+	c := &Code{Code: code, Status: status}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	codeRegistry.Lock()
+	defer codeRegistry.Unlock()
+
+	if existing, ok := codeRegistry.codes[code]; ok {
+		if existing.Status != status {
+			panic(fmt.Sprintf("chu: error code %q already registered with status %d", code, existing.Status))
+		}
+
+		return existing
+	}
+
+	codeRegistry.codes[code] = c
+
+	return c
+}
+
+func LookupErrorCode(code string) (*Code, bool) {
+	codeRegistry.RLock()
+	defer codeRegistry.RUnlock()
+
+	c, ok := codeRegistry.codes[code]
+	return c, ok
+}
+
+func Catalog() []Code {
+	codeRegistry.RLock()
+	defer codeRegistry.RUnlock()
+
+	codes := make([]Code, 0, len(codeRegistry.codes))
+	for _, c := range codeRegistry.codes {
+		codes = append(codes, *c)
+	}
+
+	slices.SortFunc(codes, func(a, b Code) int { return strings.Compare(a.Code, b.Code) })
+
+	return codes
+}
+
+func CatalogHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(Catalog())
+}
+
+func (c *Code) Error() string {
+	return c.Code
+}
+
+func (c *Code) StatusCode() int {
+	return c.Status
+}
+
+func (c *Code) New(message string) *Error {
+	return &Error{Status: c.Status, Code: c, Err: errors.New(message)}
+}
+
+func (c *Code) Errorf(format string, args ...any) *Error {
+	return &Error{Status: c.Status, Code: c, Err: fmt.Errorf(format, args...)}
+}
+
+func (c *Code) Wrap(err error) *Error {
+	return &Error{Status: c.Status, Code: c, Err: err}
+}
+
+func ErrorCodeOf(err error) (*Code, bool) {
+	var e *Error
+	if errors.As(err, &e) && e.Code != nil {
+		return e.Code, true
+	}
+
+	var c *Code
+	if errors.As(err, &c) {
+		return c, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,123 @@
+package chu_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errUserNotFound = chu.ErrorCode("TEST_USER_NOT_FOUND", http.StatusNotFound,
+		chu.WithDocsURL("https://docs.example.com/errors/user-not-found"),
+		chu.WithDescription("The requested user does not exist."))
+	errQuotaExceeded = chu.ErrorCode("TEST_QUOTA_EXCEEDED", http.StatusTooManyRequests)
+)
+
+func TestErrorCode_Registry(t *testing.T) {
+	assert.Same(t, errUserNotFound, chu.ErrorCode("TEST_USER_NOT_FOUND", http.StatusNotFound),
+		"re-registering a code should return the existing code")
+	assert.Panics(t, func() { chu.ErrorCode("TEST_USER_NOT_FOUND", http.StatusBadRequest) },
+		"conflicting status should panic")
+
+	c, ok := chu.LookupErrorCode("TEST_QUOTA_EXCEEDED")
+	require.True(t, ok, "code should be registered")
+	assert.Equal(t, http.StatusTooManyRequests, c.Status, "status should match expected")
+
+	var codes []string
+	for _, c := range chu.Catalog() {
+		codes = append(codes, c.Code)
+	}
+	assert.Subset(t, codes, []string{"TEST_QUOTA_EXCEEDED", "TEST_USER_NOT_FOUND"}, "catalog should contain registered codes")
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	wrapped := fmt.Errorf("loading profile: %w", errUserNotFound.Errorf("user %d not found", 42))
+
+	c, ok := chu.ErrorCodeOf(wrapped)
+	require.True(t, ok, "code should be found through wrapping")
+	assert.Same(t, errUserNotFound, c, "code should match expected")
+	assert.Equal(t, http.StatusNotFound, chu.StatusCode(wrapped), "status should come from the code")
+
+	c, ok = chu.ErrorCodeOf(errQuotaExceeded)
+	require.True(t, ok, "bare codes should be errors")
+	assert.Same(t, errQuotaExceeded, c, "code should match expected")
+
+	_, ok = chu.ErrorCodeOf(errors.New("plain"))
+	assert.False(t, ok, "plain errors should have no code")
+}
+
+func TestJSONErrorHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected map[string]any
+	}{
+		{
+			name: "coded error with message",
+			err:  errUserNotFound.Errorf("user %d not found", 42),
+			expected: map[string]any{
+				"code": "TEST_USER_NOT_FOUND", "message": "user 42 not found", "status": float64(404),
+				"docs_url": "https://docs.example.com/errors/user-not-found",
+			},
+		},
+		{
+			name:     "bare code with description",
+			err:      errUserNotFound,
+			expected: map[string]any{"code": "TEST_USER_NOT_FOUND", "message": "The requested user does not exist.", "status": float64(404), "docs_url": "https://docs.example.com/errors/user-not-found"},
+		},
+		{
+			name:     "bare code without description",
+			err:      errQuotaExceeded,
+			expected: map[string]any{"code": "TEST_QUOTA_EXCEEDED", "message": "Too Many Requests", "status": float64(429)},
+		},
+		{
+			name:     "status error",
+			err:      chu.Errorf(http.StatusBadRequest, "invalid id"),
+			expected: map[string]any{"code": "BAD_REQUEST", "message": "invalid id", "status": float64(400)},
+		},
+		{
+			name:     "internal error is not leaked",
+			err:      errors.New("db password wrong"),
+			expected: map[string]any{"code": "INTERNAL_SERVER_ERROR", "message": "Internal Server Error", "status": float64(500)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chu.New(chu.WithErrorHandler(chu.JSONErrorHandler))
+			r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				return tt.err
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, int(tt.expected["status"].(float64)), w.Code, "status code should match expected")
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "content type should be JSON")
+
+			var body map[string]map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), "body should be valid JSON")
+			assert.Equal(t, tt.expected, body["error"], "error body should match expected")
+		})
+	}
+}
+
+func TestCatalogHandler(t *testing.T) {
+	r := chu.New()
+	r.Get("/errors", chu.CatalogHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/errors", nil))
+
+	var codes []chu.Code
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &codes), "catalog should be valid JSON")
+	assert.Contains(t, codes, *errUserNotFound, "catalog should include registered codes")
+}
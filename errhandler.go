@@ -0,0 +1,83 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type ErrorInfo struct {
+	RoutePattern   string
+	HeadersWritten bool
+}
+
+// ContextErrorHandler is the context-aware form of ErrorHandler. It receives
+// the request context along with the matched route pattern and whether the
+// handler had already written the response headers.
+type ContextErrorHandler func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error, info ErrorInfo)
+
+func WithContextErrorHandler(handler ContextErrorHandler) Option {
+	return func(r *Router) {
+		r.ctxErrHandler = handler
+	}
+}
+
+func (r *Router) SetContextErrorHandler(handler ContextErrorHandler) {
+	r.ctxErrHandler = handler
+	r.record(func(c *Router) { c.SetContextErrorHandler(handler) })
+}
+
+func AdaptErrorHandler(h ErrorHandler) ContextErrorHandler {
+	return func(_ context.Context, w http.ResponseWriter, r *http.Request, err error, _ ErrorInfo) {
+		h(w, r, err)
+	}
+}
+
+// ErrorHandler adapts h to the plain signature. Since the response is not
+// observed, ErrorInfo.HeadersWritten is always false.
+func (h ContextErrorHandler) ErrorHandler() ErrorHandler {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		h(r.Context(), w, r, err, ErrorInfo{RoutePattern: RoutePattern(r)})
+	}
+}
+
+func (r *Router) errorHandler() ErrorHandler {
+	handler := r.errHandler
+	if r.ctxErrHandler != nil {
+		handler = r.ctxErrHandler.ErrorHandler()
+	}
+
+	if r.sanitize == nil && len(r.errMappings) == 0 {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, req *http.Request, err error) {
+		handler(w, req, r.handlerError(err))
+	}
+}
+
+// handlerError prepares err for the router's error handler.
+func (r *Router) handlerError(err error) error {
+	return SanitizeError(withErrorMappings(err, r.errMappings), r.sanitize)
+}
+
+func (r *Router) serve(h Handler, w http.ResponseWriter, req *http.Request) {
+	if r.ctxErrHandler == nil {
+		if err := h(req.Context(), w, req); err != nil {
+			annotateError(req.Context(), err)
+			r.errHandler(w, req, r.handlerError(err))
+		}
+
+		return
+	}
+
+	ww := middleware.NewWrapResponseWriter(w, req.ProtoMajor)
+	if err := h(req.Context(), ww, req); err != nil {
+		annotateError(req.Context(), err)
+		r.ctxErrHandler(req.Context(), w, req, r.handlerError(err), ErrorInfo{
+			RoutePattern:   RoutePattern(req),
+			HeadersWritten: ww.Status() != 0,
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+func TestContextErrorHandler(t *testing.T) {
+	var got []chu.ErrorInfo
+	var tenants []string
+
+	r := chu.New(chu.WithContextErrorHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error, info chu.ErrorInfo) {
+		got = append(got, info)
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		tenants = append(tenants, tenant)
+
+		if !info.HeadersWritten {
+			w.WriteHeader(chu.StatusCode(err))
+		}
+	}))
+
+	r.Use(func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.Header.Get("X-Deny") != "" {
+				return chu.NewError(http.StatusForbidden, errors.New("denied"))
+			}
+
+			ctx = context.WithValue(ctx, tenantKey{}, "acme")
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	})
+
+	r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.NewError(http.StatusNotFound, errors.New("no user"))
+	})
+	r.Get("/stream", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("partial"))
+		return errors.New("stream broke")
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		deny   bool
+		status int
+		info   chu.ErrorInfo
+		tenant string
+	}{
+		{name: "handler error", path: "/users/1", status: http.StatusNotFound, info: chu.ErrorInfo{RoutePattern: "/users/{id}"}, tenant: "acme"},
+		{name: "after write", path: "/stream", status: http.StatusOK, info: chu.ErrorInfo{RoutePattern: "/stream", HeadersWritten: true}, tenant: "acme"},
+		{name: "middleware error", path: "/users/1", deny: true, status: http.StatusForbidden, info: chu.ErrorInfo{RoutePattern: "/users/{id}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, tenants = nil, nil
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.deny {
+				req.Header.Set("X-Deny", "1")
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code, "status code should match expected")
+			assert.Equal(t, []chu.ErrorInfo{tt.info}, got, "error info should match expected")
+			assert.Equal(t, []string{tt.tenant}, tenants, "handler should see the request context")
+		})
+	}
+}
+
+func TestErrorHandlerAdapters(t *testing.T) {
+	var calls int
+	plain := chu.ErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		calls++
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	r := chu.New(chu.WithContextErrorHandler(chu.AdaptErrorHandler(plain)))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("fail")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code, "adapted plain handler should be used")
+
+	var info chu.ErrorInfo
+	ctxHandler := chu.ContextErrorHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error, i chu.ErrorInfo) {
+		info = i
+		w.WriteHeader(http.StatusConflict)
+	})
+
+	h := chu.AdaptHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("fail")
+	}, ctxHandler.ErrorHandler())
+
+	rec = httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code, "context handler should be usable as a plain handler")
+	assert.False(t, info.HeadersWritten, "headers written should be unknown through the plain adapter")
+
+	r.SetErrorHandler(plain)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, 2, calls, "plain handler should replace the context handler")
+}
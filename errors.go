@@ -0,0 +1,269 @@
+package chu
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type Error struct {
+	Status int
+	Code   *Code
+	Err    error
+}
+
+func NewError(status int, err error) *Error {
+	return &Error{Status: status, Err: err}
+}
+
+func Errorf(status int, format string, args ...any) *Error {
+	return &Error{Status: status, Err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil && e.Code != nil {
+		return e.Code.Code
+	}
+
+	if e.Err == nil {
+		return http.StatusText(e.Status)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) StatusCode() int {
+	return e.Status
+}
+
+type errorMapping struct {
+	target error
+	status int
+}
+
+// WithErrorMapping makes errors matching target, as reported by errors.Is,
+// carry status when they reach the router's error handler without an explicit
+// one. A later mapping for the same target replaces the earlier one.
+func WithErrorMapping(target error, status int) Option {
+	return func(r *Router) {
+		r.errMappings = slices.DeleteFunc(slices.Clone(r.errMappings), func(m errorMapping) bool {
+			return m.target == target
+		})
+		r.errMappings = append(r.errMappings, errorMapping{target: target, status: status})
+	}
+}
+
+// mappedError carries a router's error mappings to StatusCode.
+type mappedError struct {
+	err      error
+	mappings []errorMapping
+}
+
+func withErrorMappings(err error, mappings []errorMapping) error {
+	if err == nil || len(mappings) == 0 {
+		return err
+	}
+
+	return &mappedError{err: err, mappings: mappings}
+}
+
+func (e *mappedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *mappedError) Unwrap() error {
+	return e.err
+}
+
+func mappingsOf(err error) []errorMapping {
+	var m *mappedError
+	if errors.As(err, &m) {
+		return m.mappings
+	}
+
+	return nil
+}
+
+// StatusCode returns the HTTP status carried by err, or 500 when it carries
+// none or one outside 100-599, such as NewError(0, err).
+func StatusCode(err error) int {
+	if status, ok := statusOf(err); ok && status >= 100 && status <= 599 {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+func statusOf(err error) (int, bool) {
+	return mappedStatusOf(err, nil)
+}
+
+func mappedStatusOf(err error, mappings []errorMapping) (int, bool) {
+	for err != nil {
+		if m, ok := err.(*mappedError); ok {
+			err, mappings = m.err, m.mappings
+			continue
+		}
+
+		if sc, ok := err.(interface{ StatusCode() int }); ok {
+			return sc.StatusCode(), true
+		}
+
+		if status, ok := mappedStatus(err, mappings); ok {
+			return status, true
+		}
+
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			return joinedStatus(e.Unwrap(), mappings)
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			return 0, false
+		}
+	}
+
+	return 0, false
+}
+
+func mappedStatus(err error, mappings []errorMapping) (int, bool) {
+	for _, m := range mappings {
+		if err == m.target {
+			return m.status, true
+		}
+
+		if is, ok := err.(interface{ Is(error) bool }); ok && is.Is(m.target) {
+			return m.status, true
+		}
+	}
+
+	return 0, false
+}
+
+// joinedStatus prefers specific statuses (e.g. 404, 422) over the generic
+// 400 and 500 class defaults, falling back to the first mapped status.
+func joinedStatus(errs []error, mappings []errorMapping) (int, bool) {
+	var statuses []int
+
+	for _, err := range errs {
+		if status, ok := mappedStatusOf(err, mappings); ok {
+			statuses = append(statuses, status)
+		}
+	}
+
+	for _, status := range statuses {
+		if status != http.StatusBadRequest && status != http.StatusInternalServerError {
+			return status, true
+		}
+	}
+
+	if len(statuses) > 0 {
+		return statuses[0], true
+	}
+
+	return 0, false
+}
+
+func joinedErrors(err error) []error {
+	for err != nil {
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			return e.Unwrap()
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func codeBeforeJoin(err error) (*Code, bool) {
+	for err != nil {
+		switch e := err.(type) {
+		case *Code:
+			return e, true
+		case *Error:
+			if e.Code != nil {
+				return e.Code, true
+			}
+		}
+
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil, false
+		}
+
+		err = u.Unwrap()
+	}
+
+	return nil, false
+}
+
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Status  int           `json:"status"`
+	DocsURL string        `json:"docs_url,omitempty"`
+	Errors  []errorDetail `json:"errors,omitempty"`
+}
+
+func JSONErrorHandler(w http.ResponseWriter, _ *http.Request, err error) {
+	status := StatusCode(err)
+	detail := newErrorDetail(err, status)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(errorBody{Error: detail})
+}
+
+func newErrorDetail(err error, status int) errorDetail {
+	detail := errorDetail{
+		Code:    strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_")),
+		Message: err.Error(),
+		Status:  status,
+	}
+
+	joined := joinedErrors(err)
+
+	if code, ok := codeBeforeJoin(err); ok {
+		detail.Code, detail.DocsURL = code.Code, code.DocsURL
+
+		var e *Error
+		if !errors.As(err, &e) || e.Err == nil || len(joined) > 0 {
+			detail.Message = cmp.Or(code.Description, http.StatusText(status))
+		}
+	} else if len(joined) > 0 || status >= http.StatusInternalServerError {
+		detail.Message = http.StatusText(status)
+	}
+
+	sanitize := sanitizerOf(err)
+	mappings := mappingsOf(err)
+
+	for _, inner := range joined {
+		innerStatus, ok := mappedStatusOf(inner, mappings)
+		if !ok {
+			innerStatus = http.StatusInternalServerError
+		}
+
+		inner = SanitizeError(withErrorMappings(inner, mappings), sanitize)
+		detail.Errors = append(detail.Errors, newErrorDetail(inner, innerStatus))
+	}
+
+	return detail
+}
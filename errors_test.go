@@ -0,0 +1,147 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusCode(t *testing.T) {
+	sentinel := errors.New("sentinel")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "plain error", err: errors.New("boom"), expected: http.StatusInternalServerError},
+		{name: "chu error", err: chu.Errorf(http.StatusNotFound, "user %d not found", 1), expected: http.StatusNotFound},
+		{name: "wrapped chu error", err: fmt.Errorf("lookup: %w", chu.NewError(http.StatusConflict, sentinel)), expected: http.StatusConflict},
+		{name: "zero status", err: chu.NewError(0, sentinel), expected: http.StatusInternalServerError},
+		{name: "status out of range", err: chu.NewError(1000, sentinel), expected: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, chu.StatusCode(tt.err), "status code should match expected")
+		})
+	}
+
+	assert.ErrorIs(t, chu.NewError(http.StatusConflict, sentinel), sentinel, "chu error should unwrap to the cause")
+}
+
+func TestDefaultErrorHandler_Status(t *testing.T) {
+	r := chu.New()
+	r.Get("/missing", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.Errorf(http.StatusNotFound, "item %s not found", "abc")
+	})
+
+	req := httptest.NewRequest("GET", "/missing", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	body, err := io.ReadAll(w.Result().Body)
+	require.NoError(t, err, "should be able to read response body")
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "status code should come from the error")
+	assert.Equal(t, "item abc not found\n", string(body), "response body should match expected")
+}
+
+var (
+	errMappedNotFound = errors.New("record not found")
+	errMappedInvalid  = errors.New("invalid input")
+)
+
+var errorMappings = []chu.Option{
+	chu.WithErrorMapping(errMappedNotFound, http.StatusNotFound),
+	chu.WithErrorMapping(errMappedInvalid, http.StatusBadRequest),
+}
+
+// mappedStatusCode returns the status the error handler of a router with
+// opts sees for err.
+func mappedStatusCode(err error, opts ...chu.Option) int {
+	var status int
+
+	r := chu.New(append(opts, chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		status = chu.StatusCode(err)
+	}))...)
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return err
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	return status
+}
+
+func TestStatusCode_Mapping(t *testing.T) {
+	validation := chu.Errorf(http.StatusUnprocessableEntity, "email is invalid")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "mapped sentinel", err: errMappedNotFound, expected: http.StatusNotFound},
+		{name: "wrapped mapped sentinel", err: fmt.Errorf("loading user: %w", errMappedNotFound), expected: http.StatusNotFound},
+		{name: "explicit status wins over mapping", err: chu.NewError(http.StatusGone, errMappedNotFound), expected: http.StatusGone},
+		{name: "joined prefers specific status", err: errors.Join(errMappedInvalid, validation), expected: http.StatusUnprocessableEntity},
+		{name: "joined with unmapped error", err: errors.Join(errors.New("boom"), errMappedNotFound), expected: http.StatusNotFound},
+		{name: "joined generic statuses", err: errors.Join(errMappedInvalid, chu.Errorf(http.StatusInternalServerError, "x")), expected: http.StatusBadRequest},
+		{name: "joined unmapped errors", err: errors.Join(errors.New("a"), errors.New("b")), expected: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mappedStatusCode(tt.err, errorMappings...), "status code should match expected")
+		})
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, mappedStatusCode(errMappedNotFound), "mappings should not leak across routers")
+	assert.Equal(t, http.StatusInternalServerError, chu.StatusCode(errMappedNotFound), "mappings should only apply in the router's error handler")
+	assert.Equal(t, http.StatusConflict, mappedStatusCode(errMappedNotFound, append(errorMappings, chu.WithErrorMapping(errMappedNotFound, http.StatusConflict))...), "later mapping should replace earlier one")
+}
+
+func TestJSONErrorHandler_Joined(t *testing.T) {
+	r := chu.New(append(errorMappings, chu.WithErrorHandler(chu.JSONErrorHandler))...)
+	r.Post("/users", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.Join(
+			chu.Errorf(http.StatusUnprocessableEntity, "email is invalid"),
+			errMappedInvalid,
+			errors.New("db password wrong"),
+		)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "status code should match expected")
+	assert.JSONEq(t, `{"error": {
+		"code": "UNPROCESSABLE_ENTITY", "message": "Unprocessable Entity", "status": 422,
+		"errors": [
+			{"code": "UNPROCESSABLE_ENTITY", "message": "email is invalid", "status": 422},
+			{"code": "BAD_REQUEST", "message": "invalid input", "status": 400},
+			{"code": "INTERNAL_SERVER_ERROR", "message": "Internal Server Error", "status": 500}
+		]
+	}}`, w.Body.String(), "joined errors should render as an array")
+}
+
+func TestDefaultErrorHandler_InvalidStatus(t *testing.T) {
+	r := chu.New()
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.NewError(0, errors.New("boom"))
+	})
+
+	w := httptest.NewRecorder()
+	require.NotPanics(t, func() { r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil)) }, "invalid status should not panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "invalid status should be answered as 500")
+}
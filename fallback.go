@@ -0,0 +1,65 @@
+package chu
+
+import (
+	"context"
+	"maps"
+	"net/http"
+)
+
+func Fallback(handlers ...Handler) Handler {
+	return FallbackWhen(func(error) bool { return true }, handlers...)
+}
+
+func FallbackWhen(retryable func(err error) bool, handlers ...Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if len(handlers) == 0 {
+			return Errorf(http.StatusNotFound, "chu: no fallback handlers")
+		}
+
+		var err error
+
+		for _, h := range handlers {
+			header := maps.Clone(w.Header())
+			fw := &fallbackWriter{ResponseWriter: w}
+
+			if err = h(ctx, fw, r); err == nil {
+				return nil
+			}
+
+			if fw.written || !retryable(err) {
+				return err
+			}
+
+			clear(w.Header())
+			maps.Copy(w.Header(), header)
+		}
+
+		return err
+	}
+}
+
+type fallbackWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *fallbackWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *fallbackWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *fallbackWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.written = true
+		f.Flush()
+	}
+}
+
+func (w *fallbackWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,108 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+var errCacheMiss = errors.New("cache miss")
+
+func TestFallback(t *testing.T) {
+	var calls []string
+
+	handler := func(name string, err error) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			calls = append(calls, name)
+			w.Header().Set("X-Source", name)
+
+			if err != nil {
+				return err
+			}
+
+			_, _ = w.Write([]byte(name))
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name     string
+		handler  chu.Handler
+		expected int
+		body     string
+		calls    []string
+	}{
+		{
+			name:     "first succeeds",
+			handler:  chu.Fallback(handler("cache", nil), handler("origin", nil)),
+			expected: http.StatusOK, body: "cache", calls: []string{"cache"},
+		},
+		{
+			name:     "falls back on error",
+			handler:  chu.Fallback(handler("cache", errCacheMiss), handler("origin", nil)),
+			expected: http.StatusOK, body: "origin", calls: []string{"cache", "origin"},
+		},
+		{
+			name:     "all fail",
+			handler:  chu.Fallback(handler("cache", errCacheMiss), handler("origin", chu.Errorf(http.StatusBadGateway, "down"))),
+			expected: http.StatusBadGateway, body: "down\n", calls: []string{"cache", "origin"},
+		},
+		{
+			name: "non-retryable error stops",
+			handler: chu.FallbackWhen(func(err error) bool { return errors.Is(err, errCacheMiss) },
+				handler("cache", chu.Errorf(http.StatusForbidden, "forbidden")), handler("origin", nil)),
+			expected: http.StatusForbidden, body: "forbidden\n", calls: []string{"cache"},
+		},
+		{
+			name: "written response is not retried",
+			handler: chu.Fallback(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				calls = append(calls, "partial")
+				w.WriteHeader(http.StatusAccepted)
+				return errCacheMiss
+			}, handler("origin", nil)),
+			expected: http.StatusAccepted, calls: []string{"partial"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+
+			r := chu.New()
+			r.Get("/", tt.handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+			assert.Equal(t, tt.calls, calls, "handlers should be called in order")
+
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+		})
+	}
+}
+
+func TestFallback_RestoresHeaders(t *testing.T) {
+	r := chu.New()
+	r.Get("/", chu.Fallback(
+		func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Cache-Control", "max-age=3600")
+			return errCacheMiss
+		},
+		func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return nil
+		},
+	))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Empty(t, w.Header().Get("Cache-Control"), "headers from failed handlers should be discarded")
+}
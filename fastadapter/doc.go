@@ -0,0 +1,5 @@
+// Package fastadapter serves a chu Router with fasthttp. It is built only with
+// the fasthttp build tag:
+//
+//	go build -tags fasthttp
+package fastadapter
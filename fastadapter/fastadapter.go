@@ -0,0 +1,94 @@
+//go:build fasthttp
+
+package fastadapter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/josearomeroj/chu"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+type requestCtxKey struct{}
+
+func RequestCtx(ctx context.Context) (*fasthttp.RequestCtx, bool) {
+	fctx, ok := ctx.Value(requestCtxKey{}).(*fasthttp.RequestCtx)
+	return fctx, ok
+}
+
+func Handler(router *chu.Router) fasthttp.RequestHandler {
+	return func(fctx *fasthttp.RequestCtx) {
+		var req http.Request
+		if err := fasthttpadaptor.ConvertRequest(fctx, &req, true); err != nil {
+			fctx.Error(err.Error(), fasthttp.StatusInternalServerError)
+			return
+		}
+
+		rctx := chi.NewRouteContext()
+		rctx.Routes = router.Chi()
+
+		ctx := context.WithValue(fctx, requestCtxKey{}, fctx)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+
+		w := &responseWriter{fctx: fctx, header: make(http.Header)}
+		router.ServeHTTP(w, req.WithContext(ctx))
+		w.WriteHeader(http.StatusOK)
+
+		for i, key := range rctx.URLParams.Keys {
+			fctx.SetUserValue(key, rctx.URLParams.Values[i])
+		}
+	}
+}
+
+func NewServer(router *chu.Router) *fasthttp.Server {
+	return &fasthttp.Server{Handler: Handler(router)}
+}
+
+func ListenAndServe(addr string, router *chu.Router) error {
+	return NewServer(router).ListenAndServe(addr)
+}
+
+type responseWriter struct {
+	fctx        *fasthttp.RequestCtx
+	header      http.Header
+	wroteHeader bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.wroteHeader = true
+
+	for key, values := range w.header {
+		for i, value := range values {
+			if i == 0 {
+				w.fctx.Response.Header.Set(key, value)
+			} else {
+				w.fctx.Response.Header.Add(key, value)
+			}
+		}
+	}
+
+	w.fctx.SetStatusCode(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.header.Get("Content-Type") == "" {
+			w.header.Set("Content-Type", http.DetectContentType(p))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.fctx.Write(p)
+}
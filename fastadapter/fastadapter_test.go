@@ -0,0 +1,100 @@
+//go:build fasthttp
+
+package fastadapter_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/fastadapter"
+)
+
+func newRouter() *chu.Router {
+	r := chu.New()
+	r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if _, ok := fastadapter.RequestCtx(ctx); ok {
+			w.Header().Set("X-Fasthttp", "yes")
+		}
+		w.Header().Add("X-Multi", "a")
+		w.Header().Add("X-Multi", "b")
+		_, err := io.WriteString(w, "user "+chu.URLParam(r, "id"))
+
+		return err
+	})
+	r.Post("/echo", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		_, err = w.Write(body)
+
+		return err
+	})
+	r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.Errorf(http.StatusTeapot, "short and stout")
+	})
+
+	return r
+}
+
+func TestHandler(t *testing.T) {
+	h := fastadapter.Handler(newRouter())
+
+	tests := []struct {
+		name           string
+		method         string
+		uri            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"path params", http.MethodGet, "/users/42", "", http.StatusOK, "user 42"},
+		{"request body", http.MethodPost, "/echo", "payload", http.StatusCreated, "payload"},
+		{"handler error", http.MethodGet, "/fail", "", http.StatusTeapot, "short and stout\n"},
+		{"not found", http.MethodGet, "/missing", "", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fctx fasthttp.RequestCtx
+			fctx.Request.Header.SetMethod(tt.method)
+			fctx.Request.SetRequestURI(tt.uri)
+			fctx.Request.SetBodyString(tt.body)
+
+			h(&fctx)
+
+			assert.Equal(t, tt.expectedStatus, fctx.Response.StatusCode(), "status code should match expected")
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, string(fctx.Response.Body()), "body should match expected")
+			}
+		})
+	}
+}
+
+func TestHandlerHeaders(t *testing.T) {
+	var fctx fasthttp.RequestCtx
+	fctx.Request.Header.SetMethod(http.MethodGet)
+	fctx.Request.SetRequestURI("/users/7")
+
+	fastadapter.Handler(newRouter())(&fctx)
+
+	require.Equal(t, http.StatusOK, fctx.Response.StatusCode(), "status code should match expected")
+	assert.Equal(t, "yes", string(fctx.Response.Header.Peek("X-Fasthttp")), "handler should see the fasthttp request")
+	assert.Equal(t, "7", fctx.UserValue("id"), "route params should be exposed as user values")
+
+	var multi []string
+	for _, value := range fctx.Response.Header.PeekAll("X-Multi") {
+		multi = append(multi, string(value))
+	}
+	assert.Equal(t, []string{"a", "b"}, multi, "repeated headers should be kept")
+}
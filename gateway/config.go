@@ -0,0 +1,214 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidConfig = errors.New("invalid gateway config")
+
+type Config struct {
+	Middlewares []MiddlewareConfig `json:"middlewares" yaml:"middlewares"`
+	Routes      []RouteConfig      `json:"routes" yaml:"routes"`
+}
+
+type RouteConfig struct {
+	Path        string             `json:"path" yaml:"path"`
+	Methods     []string           `json:"methods" yaml:"methods"`
+	Upstream    string             `json:"upstream" yaml:"upstream"`
+	StripPrefix bool               `json:"strip_prefix" yaml:"strip_prefix"`
+	Middlewares []MiddlewareConfig `json:"middlewares" yaml:"middlewares"`
+	Transform   *Transform         `json:"transform" yaml:"transform"`
+	Cost        *int               `json:"cost" yaml:"cost"`
+}
+
+type MiddlewareConfig struct {
+	Name   string `json:"name" yaml:"name"`
+	Params Params `json:"params" yaml:"params"`
+}
+
+type Params map[string]any
+
+func (p Params) String(key, fallback string) string {
+	if v, ok := p[key].(string); ok {
+		return v
+	}
+
+	return fallback
+}
+
+func (p Params) Strings(key string) []string {
+	switch v := p[key].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+
+	return nil
+}
+
+func (p Params) Int(key string, fallback int) (int, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return fallback, nil
+	case int:
+		return v, nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("param %q: %v is not an integer", key, v)
+		}
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("param %q: expected integer, got %T", key, v)
+	}
+}
+
+func (p Params) Duration(key string, fallback time.Duration) (time.Duration, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return fallback, nil
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("param %q: %w", key, err)
+		}
+		return d, nil
+	default:
+		return 0, fmt.Errorf("param %q: expected duration string, got %T", key, v)
+	}
+}
+
+func ParseYAML(data []byte) (*Config, error) {
+	var cfg Config
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	return &cfg, nil
+}
+
+func ParseJSON(data []byte) (*Config, error) {
+	var cfg Config
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	return &cfg, nil
+}
+
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseJSON(data)
+	}
+
+	return ParseYAML(data)
+}
+
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+func (c *Config) validate(registry map[string]Factory) error {
+	var errs []error
+
+	check := func(where string, mws []MiddlewareConfig) {
+		for j, mw := range mws {
+			if _, ok := registry[mw.Name]; !ok {
+				errs = append(errs, fmt.Errorf("%s.middlewares[%d]: unknown middleware %q", where, j, mw.Name))
+			}
+		}
+	}
+
+	check("config", c.Middlewares)
+
+	seen := make(map[string]bool)
+	for i, route := range c.Routes {
+		where := fmt.Sprintf("routes[%d]", i)
+
+		if !strings.HasPrefix(route.Path, "/") {
+			errs = append(errs, fmt.Errorf("%s: path %q must start with /", where, route.Path))
+		}
+
+		if route.StripPrefix && !strings.HasSuffix(route.Path, "/*") {
+			errs = append(errs, fmt.Errorf("%s: strip_prefix requires a path ending in /*", where))
+		}
+
+		target, err := url.Parse(route.Upstream)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			errs = append(errs, fmt.Errorf("%s: upstream %q must be an absolute http(s) URL", where, route.Upstream))
+		}
+
+		if route.Cost != nil && *route.Cost < 0 {
+			errs = append(errs, fmt.Errorf("%s: cost must not be negative", where))
+		}
+
+		methods := route.Methods
+		if len(methods) == 0 {
+			methods = []string{"*"}
+		}
+
+		for _, method := range methods {
+			method = strings.ToUpper(method)
+			if method != "*" && !knownMethods[method] {
+				errs = append(errs, fmt.Errorf("%s: unknown method %q", where, method))
+			}
+
+			key := method + " " + route.Path
+			if seen[key] {
+				errs = append(errs, fmt.Errorf("%s: duplicate route %s", where, key))
+			}
+			seen[key] = true
+		}
+
+		check(where, route.Middlewares)
+
+		if route.Transform != nil {
+			if _, err := route.Transform.Middleware(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", where, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/proxy"
+)
+
+type Option func(*Gateway)
+
+func WithMiddlewareFactory(name string, factory Factory) Option {
+	return func(g *Gateway) {
+		g.factories[name] = factory
+	}
+}
+
+func WithRouterOptions(opts ...chu.Option) Option {
+	return func(g *Gateway) {
+		g.routerOpts = append(g.routerOpts, opts...)
+	}
+}
+
+func WithProxyOptions(opts ...proxy.Option) Option {
+	return func(g *Gateway) {
+		g.proxyOpts = append(g.proxyOpts, opts...)
+	}
+}
+
+func WithOnReload(fn func(cfg *Config, err error)) Option {
+	return func(g *Gateway) {
+		g.onReload = fn
+	}
+}
+
+type Gateway struct {
+	factories  map[string]Factory
+	routerOpts []chu.Option
+	proxyOpts  []proxy.Option
+	onReload   func(cfg *Config, err error)
+
+	mu      sync.Mutex
+	handler atomic.Pointer[http.Handler]
+	config  atomic.Pointer[Config]
+}
+
+func New(opts ...Option) *Gateway {
+	g := &Gateway{factories: builtinMiddlewares()}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	var notFound http.Handler = http.NotFoundHandler()
+	g.handler.Store(&notFound)
+
+	return g
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*g.handler.Load()).ServeHTTP(w, r)
+}
+
+func (g *Gateway) Config() *Config {
+	return g.config.Load()
+}
+
+func (g *Gateway) Validate(cfg *Config) error {
+	return cfg.validate(g.factories)
+}
+
+func (g *Gateway) Apply(cfg *Config) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	router, err := g.build(cfg)
+	if err != nil {
+		return err
+	}
+
+	var h http.Handler = router
+	g.handler.Store(&h)
+	g.config.Store(cfg)
+
+	return nil
+}
+
+func (g *Gateway) LoadFile(path string) error {
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return g.Apply(cfg)
+}
+
+func (g *Gateway) Watch(ctx context.Context, path string, interval time.Duration) error {
+	stamp, err := fileStamp(path)
+	if err != nil {
+		return err
+	}
+
+	if err := g.LoadFile(path); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := fileStamp(path)
+			if err != nil || current == stamp {
+				continue
+			}
+			stamp = current
+
+			err = g.LoadFile(path)
+			if g.onReload != nil {
+				g.onReload(g.Config(), err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (g *Gateway) build(cfg *Config) (*chu.Router, error) {
+	if err := g.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	router := chu.New(g.routerOpts...)
+
+	global, err := g.middlewares("config", cfg.Middlewares)
+	if err != nil {
+		return nil, err
+	}
+	router.Use(global...)
+
+	for i, route := range cfg.Routes {
+		where := fmt.Sprintf("routes[%d]", i)
+
+		mws, err := g.middlewares(where, route.Middlewares)
+		if err != nil {
+			return nil, err
+		}
+
+		target, _ := url.Parse(route.Upstream)
+		p := proxy.New(target, g.proxyOpts...)
+
+		h := chu.Handler(p.Handle)
+		if route.Transform != nil {
+			transform, err := route.Transform.Middleware()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, where, err)
+			}
+			h = transform(h)
+		}
+
+		if route.StripPrefix {
+			h = stripPrefix(h)
+		}
+
+		opts := []chu.RouteOption{chu.WithMiddleware(mws...)}
+		if route.Cost != nil {
+			opts = append(opts, chu.Cost(*route.Cost))
+		}
+		err = register(where, func() {
+			if len(route.Methods) == 0 {
+				router.Handle(route.Path, h, opts...)
+				return
+			}
+
+			for _, method := range route.Methods {
+				router.Method(strings.ToUpper(method), route.Path, h, opts...)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return router, nil
+}
+
+// register runs fn, turning the panic chi raises for a malformed pattern into
+// a config error, so a bad reload leaves the previous handler serving.
+func register(where string, fn func()) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%w: %s: %v", ErrInvalidConfig, where, v)
+		}
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (g *Gateway) middlewares(where string, configs []MiddlewareConfig) ([]func(chu.Handler) chu.Handler, error) {
+	mws := make([]func(chu.Handler) chu.Handler, 0, len(configs))
+	for j, mc := range configs {
+		mw, err := g.factories[mc.Name](mc.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s.middlewares[%d]: %w", ErrInvalidConfig, where, j, err)
+		}
+		mws = append(mws, mw)
+	}
+
+	return mws, nil
+}
+
+func stripPrefix(next chu.Handler) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		rest := "/" + chu.Wildcard(r)
+
+		r2 := r.Clone(ctx)
+		if r.URL.RawPath != "" {
+			path, err := url.PathUnescape(rest)
+			if err != nil {
+				return chu.NewError(http.StatusBadRequest, err)
+			}
+
+			r2.URL.Path, r2.URL.RawPath = path, rest
+		} else {
+			r2.URL.Path = rest
+		}
+
+		return next(ctx, w, r2)
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func fileStamp(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, err
+	}
+
+	return fileState{modTime: info.ModTime(), size: info.Size()}, nil
+}
@@ -0,0 +1,233 @@
+package gateway_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/gateway"
+)
+
+func newUpstream(t *testing.T, name string) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, name+" "+r.Method+" "+r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+func serve(g http.Handler, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestGatewayRoutes(t *testing.T) {
+	users := newUpstream(t, "users")
+	orders := newUpstream(t, "orders")
+
+	cfg, err := gateway.ParseYAML([]byte(`
+routes:
+  - path: /users/*
+    methods: [GET]
+    upstream: ` + users + `
+    strip_prefix: true
+  - path: /orders/{id}
+    upstream: ` + orders + `
+`))
+	require.NoError(t, err)
+
+	g := gateway.New()
+	require.NoError(t, g.Apply(cfg))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{name: "stripped prefix", method: http.MethodGet, path: "/users/42", status: http.StatusOK, body: "users GET /42"},
+		{name: "method not allowed", method: http.MethodPost, path: "/users/42", status: http.StatusMethodNotAllowed},
+		{name: "any method", method: http.MethodDelete, path: "/orders/7", status: http.StatusOK, body: "orders DELETE /orders/7"},
+		{name: "unknown path", method: http.MethodGet, path: "/missing", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(g, tt.method, tt.path, nil)
+
+			assert.Equal(t, tt.status, rec.Code, "status code should match expected")
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rec.Body.String(), "body should match expected")
+			}
+		})
+	}
+}
+
+func TestGatewayMiddlewares(t *testing.T) {
+	upstream := newUpstream(t, "api")
+
+	cfg, err := gateway.ParseJSON([]byte(`{
+		"routes": [{
+			"path": "/api/*",
+			"upstream": "` + upstream + `",
+			"middlewares": [
+				{"name": "auth", "params": {"scheme": "Bearer", "tokens": ["secret"]}},
+				{"name": "ratelimit", "params": {"requests": 2, "window": "1h", "key": "global"}}
+			]
+		}]
+	}`))
+	require.NoError(t, err)
+
+	g := gateway.New()
+	require.NoError(t, g.Apply(cfg))
+
+	authorized := http.Header{"Authorization": {"Bearer secret"}}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(g, http.MethodGet, "/api/a", nil).Code, "missing token should be rejected")
+	assert.Equal(t, http.StatusUnauthorized, serve(g, http.MethodGet, "/api/a", http.Header{"Authorization": {"Bearer wrong"}}).Code, "wrong token should be rejected")
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/api/a", authorized).Code, "first request should pass")
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/api/a", authorized).Code, "second request should pass")
+
+	rec := serve(g, http.MethodGet, "/api/a", authorized)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "third request should be rate limited")
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"), "retry after should be set")
+}
+
+func TestGatewayRouteCost(t *testing.T) {
+	upstream := newUpstream(t, "api")
+
+	cfg, err := gateway.ParseYAML([]byte(`
+middlewares:
+  - name: ratelimit
+    params: {requests: 5, window: 1h, key: global}
+routes:
+  - path: /search
+    upstream: ` + upstream + `
+    cost: 3
+  - path: /items
+    upstream: ` + upstream + `
+`))
+	require.NoError(t, err)
+
+	g := gateway.New()
+	require.NoError(t, g.Apply(cfg))
+
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/search", nil).Code, "expensive request should pass")
+	assert.Equal(t, http.StatusTooManyRequests, serve(g, http.MethodGet, "/search", nil).Code, "second expensive request should exceed the budget")
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/items", nil).Code, "cheap request should use the remaining budget")
+	assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/items", nil).Code, "cheap request should use the remaining budget")
+	assert.Equal(t, http.StatusTooManyRequests, serve(g, http.MethodGet, "/items", nil).Code, "budget should be exhausted")
+}
+
+func TestGatewayCustomMiddleware(t *testing.T) {
+	upstream := newUpstream(t, "api")
+
+	tag := func(params gateway.Params) (func(chu.Handler) chu.Handler, error) {
+		value := params.String("value", "")
+		return func(next chu.Handler) chu.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Tag", value)
+				return next(ctx, w, r)
+			}
+		}, nil
+	}
+
+	cfg, err := gateway.ParseYAML([]byte(`
+middlewares:
+  - name: tag
+    params: {value: edge}
+routes:
+  - path: /
+    upstream: ` + upstream + `
+`))
+	require.NoError(t, err)
+
+	g := gateway.New(gateway.WithMiddlewareFactory("tag", tag))
+	require.NoError(t, g.Apply(cfg))
+
+	rec := serve(g, http.MethodGet, "/", nil)
+	assert.Equal(t, "edge", rec.Header().Get("X-Tag"), "global middleware should run")
+}
+
+func TestGatewayValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		errMsg string
+	}{
+		{name: "relative path", config: "routes: [{path: users, upstream: http://u}]", errMsg: "must start with /"},
+		{name: "bad upstream", config: "routes: [{path: /, upstream: 'ftp://u'}]", errMsg: "absolute http(s) URL"},
+		{name: "bad method", config: "routes: [{path: /, methods: [FETCH], upstream: http://u}]", errMsg: `unknown method "FETCH"`},
+		{name: "duplicate", config: "routes: [{path: /, upstream: http://u}, {path: /, upstream: http://v}]", errMsg: "duplicate route"},
+		{name: "unknown middleware", config: "routes: [{path: /, upstream: http://u, middlewares: [{name: magic}]}]", errMsg: `unknown middleware "magic"`},
+		{name: "strip without wildcard", config: "routes: [{path: /a, upstream: http://u, strip_prefix: true}]", errMsg: "strip_prefix requires"},
+		{name: "bad params", config: "routes: [{path: /, upstream: http://u, middlewares: [{name: ratelimit, params: {requests: 0}}]}]", errMsg: "requests must be positive"},
+		{name: "negative cost", config: "routes: [{path: /, upstream: http://u, cost: -1}]", errMsg: "cost must not be negative"},
+		{name: "malformed pattern", config: "routes: [{path: '/a/{id', upstream: http://u}]", errMsg: "closing delimiter"},
+		{name: "unknown field", config: "routes: [{path: /, upstream: http://u, upstreams: x}]", errMsg: "upstreams"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gateway.New()
+
+			cfg, err := gateway.ParseYAML([]byte(tt.config))
+			if err == nil {
+				err = g.Apply(cfg)
+			}
+
+			require.ErrorIs(t, err, gateway.ErrInvalidConfig, "error should be a config error")
+			assert.Contains(t, err.Error(), tt.errMsg, "error message should match expected")
+		})
+	}
+}
+
+func TestGatewayWatch(t *testing.T) {
+	first := newUpstream(t, "first")
+	second := newUpstream(t, "second")
+
+	path := filepath.Join(t.TempDir(), "gateway.yaml")
+	write := func(upstream string) {
+		require.NoError(t, os.WriteFile(path, []byte("routes: [{path: /, upstream: "+upstream+"}]\n"), 0o600))
+	}
+
+	write(first)
+
+	reloaded := make(chan error, 4)
+	g := gateway.New(gateway.WithOnReload(func(_ *gateway.Config, err error) { reloaded <- err }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, g.Watch(ctx, path, 10*time.Millisecond))
+
+	assert.True(t, strings.HasPrefix(serve(g, http.MethodGet, "/", nil).Body.String(), "first"), "initial config should be served")
+
+	require.NoError(t, os.WriteFile(path, []byte("routes: [{path: nope}]\n"), 0o600))
+	require.Error(t, <-reloaded, "invalid config should fail to reload")
+	assert.True(t, strings.HasPrefix(serve(g, http.MethodGet, "/", nil).Body.String(), "first"), "previous config should be kept")
+
+	write(second + " ")
+	require.NoError(t, <-reloaded, "valid config should reload")
+	assert.True(t, strings.HasPrefix(serve(g, http.MethodGet, "/", nil).Body.String(), "second"), "new config should be served")
+}
@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type jsonPath []any
+
+func parseJSONPath(expr string) (jsonPath, error) {
+	rest, ok := strings.CutPrefix(expr, "$")
+	if !ok {
+		return nil, fmt.Errorf("json path %q must start with $", expr)
+	}
+
+	var path jsonPath
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("json path %q has an empty key", expr)
+			}
+
+			path = append(path, key)
+			rest = rest[end+1:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("json path %q has an unclosed index", expr)
+			}
+
+			inner := rest[1:end]
+			if quoted, err := strconv.Unquote(strings.ReplaceAll(inner, "'", `"`)); err == nil {
+				path = append(path, quoted)
+			} else if index, err := strconv.Atoi(inner); err == nil && index >= 0 {
+				path = append(path, index)
+			} else {
+				return nil, fmt.Errorf("json path %q has an invalid index %q", expr, inner)
+			}
+
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("json path %q is malformed", expr)
+		}
+	}
+
+	if len(path) == 0 {
+		return nil, fmt.Errorf("json path %q selects the whole document", expr)
+	}
+
+	return path, nil
+}
+
+func (p jsonPath) get(doc any) (any, bool) {
+	for _, seg := range p {
+		switch seg := seg.(type) {
+		case string:
+			obj, ok := doc.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if doc, ok = obj[seg]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := doc.([]any)
+			if !ok || seg >= len(arr) {
+				return nil, false
+			}
+			doc = arr[seg]
+		}
+	}
+
+	return doc, true
+}
+
+func (p jsonPath) set(doc, value any) any {
+	if len(p) == 0 {
+		return value
+	}
+
+	switch seg := p[0].(type) {
+	case string:
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			obj = make(map[string]any)
+		}
+		obj[seg] = p[1:].set(obj[seg], value)
+		return obj
+	default:
+		index := seg.(int)
+		arr, _ := doc.([]any)
+		for len(arr) <= index {
+			arr = append(arr, nil)
+		}
+		arr[index] = p[1:].set(arr[index], value)
+		return arr
+	}
+}
+
+func (p jsonPath) delete(doc any) {
+	parent, ok := p[:len(p)-1].get(doc)
+	if !ok {
+		return
+	}
+
+	switch seg := p[len(p)-1].(type) {
+	case string:
+		if obj, ok := parent.(map[string]any); ok {
+			delete(obj, seg)
+		}
+	case int:
+		if arr, ok := parent.([]any); ok && seg < len(arr) {
+			arr[seg] = nil
+		}
+	}
+}
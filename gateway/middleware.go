@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limit exceeded")
+)
+
+type Factory func(params Params) (func(chu.Handler) chu.Handler, error)
+
+func builtinMiddlewares() map[string]Factory {
+	return map[string]Factory{
+		"auth":      authMiddleware,
+		"ratelimit": rateLimitMiddleware,
+		"timeout":   timeoutMiddleware,
+	}
+}
+
+func authMiddleware(params Params) (func(chu.Handler) chu.Handler, error) {
+	header := params.String("header", "Authorization")
+	scheme := params.String("scheme", "")
+	tokens := params.Strings("tokens")
+	if len(tokens) == 0 {
+		return nil, errors.New("auth: tokens are required")
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			got := r.Header.Get(header)
+			if scheme != "" {
+				prefix, rest, ok := strings.Cut(got, " ")
+				if !ok || !strings.EqualFold(prefix, scheme) {
+					return chu.NewError(http.StatusUnauthorized, ErrUnauthorized)
+				}
+				got = rest
+			}
+
+			for _, token := range tokens {
+				if subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+					return next(ctx, w, r)
+				}
+			}
+
+			return chu.NewError(http.StatusUnauthorized, ErrUnauthorized)
+		}
+	}, nil
+}
+
+func rateLimitMiddleware(params Params) (func(chu.Handler) chu.Handler, error) {
+	limit, err := params.Int("requests", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		return nil, errors.New("ratelimit: requests must be positive")
+	}
+
+	window, err := params.Duration("window", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	if window <= 0 {
+		return nil, errors.New("ratelimit: window must be positive")
+	}
+
+	keyFn, err := rateLimitKey(params.String("key", "ip"))
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := &windowLimiter{limit: limit, window: window, counts: make(map[string]int)}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if retry, ok := limiter.allow(keyFn(r), chu.RouteCost(r), time.Now()); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds()+0.5)))
+				return chu.NewError(http.StatusTooManyRequests, ErrRateLimited)
+			}
+
+			return next(ctx, w, r)
+		}
+	}, nil
+}
+
+func rateLimitKey(key string) (func(*http.Request) string, error) {
+	switch {
+	case key == "ip":
+		return func(r *http.Request) string {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				return r.RemoteAddr
+			}
+			return host
+		}, nil
+	case key == "global":
+		return func(*http.Request) string { return "" }, nil
+	case strings.HasPrefix(key, "header:"):
+		name := strings.TrimPrefix(key, "header:")
+		return func(r *http.Request) string { return r.Header.Get(name) }, nil
+	default:
+		return nil, fmt.Errorf("ratelimit: unknown key %q", key)
+	}
+}
+
+type windowLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	start  time.Time
+	counts map[string]int
+}
+
+func (l *windowLimiter) allow(key string, cost int, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.start) >= l.window {
+		l.start = now.Truncate(l.window)
+		clear(l.counts)
+	}
+
+	if l.counts[key]+cost > l.limit {
+		return l.start.Add(l.window).Sub(now), false
+	}
+
+	l.counts[key] += cost
+	return 0, true
+}
+
+func timeoutMiddleware(params Params) (func(chu.Handler) chu.Handler, error) {
+	d, err := params.Duration("duration", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if d <= 0 {
+		return nil, errors.New("timeout: duration must be positive")
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}, nil
+}
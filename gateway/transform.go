@@ -0,0 +1,234 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/josearomeroj/chu"
+)
+
+const maxTransformBody = 10 << 20
+
+type Transform struct {
+	Request  RequestTransform  `json:"request" yaml:"request"`
+	Response ResponseTransform `json:"response" yaml:"response"`
+}
+
+type RequestTransform struct {
+	Headers HeaderTransform `json:"headers" yaml:"headers"`
+	Rewrite *PathRewrite    `json:"rewrite" yaml:"rewrite"`
+	Body    []FieldMapping  `json:"body" yaml:"body"`
+}
+
+type ResponseTransform struct {
+	Headers HeaderTransform `json:"headers" yaml:"headers"`
+	Body    []FieldMapping  `json:"body" yaml:"body"`
+}
+
+type HeaderTransform struct {
+	Set    map[string]string `json:"set" yaml:"set"`
+	Add    map[string]string `json:"add" yaml:"add"`
+	Remove []string          `json:"remove" yaml:"remove"`
+}
+
+type PathRewrite struct {
+	Match   string `json:"match" yaml:"match"`
+	Replace string `json:"replace" yaml:"replace"`
+}
+
+// FieldMapping moves the JSON value at From to To; an empty To drops the field.
+type FieldMapping struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+type compiledMapping struct {
+	from, to jsonPath
+}
+
+func (t Transform) Middleware() (func(chu.Handler) chu.Handler, error) {
+	var rewrite *regexp.Regexp
+	if t.Request.Rewrite != nil {
+		re, err := regexp.Compile(t.Request.Rewrite.Match)
+		if err != nil {
+			return nil, fmt.Errorf("transform rewrite: %w", err)
+		}
+		rewrite = re
+	}
+
+	reqBody, err := compileMappings("request", t.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := compileMappings("response", t.Response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			r = r.Clone(ctx)
+			t.Request.Headers.apply(r.Header)
+
+			if rewrite != nil {
+				r.URL.Path = rewrite.ReplaceAllString(r.URL.Path, t.Request.Rewrite.Replace)
+				r.URL.RawPath = ""
+			}
+
+			if len(reqBody) > 0 && isJSON(r.Header) && r.Body != nil {
+				data, err := io.ReadAll(io.LimitReader(r.Body, maxTransformBody))
+				if err != nil {
+					return chu.NewError(http.StatusBadRequest, err)
+				}
+
+				data, err = mapJSON(data, reqBody)
+				if err != nil {
+					return chu.NewError(http.StatusBadRequest, err)
+				}
+
+				r.Body = io.NopCloser(bytes.NewReader(data))
+				r.ContentLength = int64(len(data))
+				r.Header.Del("Content-Length")
+			}
+
+			tw := &transformWriter{ResponseWriter: w, headers: t.Response.Headers, mappings: respBody}
+			err := next(ctx, tw, r)
+
+			if ferr := tw.finish(); err == nil {
+				err = ferr
+			}
+
+			return err
+		}
+	}, nil
+}
+
+func compileMappings(where string, mappings []FieldMapping) ([]compiledMapping, error) {
+	compiled := make([]compiledMapping, 0, len(mappings))
+	for i, m := range mappings {
+		from, err := parseJSONPath(m.From)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s body[%d]: %w", where, i, err)
+		}
+
+		var to jsonPath
+		if m.To != "" {
+			if to, err = parseJSONPath(m.To); err != nil {
+				return nil, fmt.Errorf("transform %s body[%d]: %w", where, i, err)
+			}
+		}
+
+		compiled = append(compiled, compiledMapping{from: from, to: to})
+	}
+
+	return compiled, nil
+}
+
+func mapJSON(data []byte, mappings []compiledMapping) ([]byte, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	for _, m := range mappings {
+		value, ok := m.from.get(doc)
+		if !ok {
+			continue
+		}
+
+		m.from.delete(doc)
+		if m.to != nil {
+			doc = m.to.set(doc, value)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+func (h HeaderTransform) apply(header http.Header) {
+	for _, name := range h.Remove {
+		header.Del(name)
+	}
+
+	for name, value := range h.Set {
+		header.Set(name, value)
+	}
+
+	for name, value := range h.Add {
+		header.Add(name, value)
+	}
+}
+
+func isJSON(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+type transformWriter struct {
+	http.ResponseWriter
+	headers     HeaderTransform
+	mappings    []compiledMapping
+	status      int
+	wroteHeader bool
+	buffering   bool
+	buf         bytes.Buffer
+}
+
+func (w *transformWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+
+	w.headers.apply(w.Header())
+
+	if len(w.mappings) > 0 && isJSON(w.Header()) && status != http.StatusNoContent && status != http.StatusNotModified {
+		w.buffering = true
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *transformWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.buffering {
+		return w.buf.Write(p)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *transformWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *transformWriter) finish() error {
+	if !w.buffering {
+		return nil
+	}
+
+	data := w.buf.Bytes()
+	if mapped, err := mapJSON(data, w.mappings); err == nil {
+		data = mapped
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.ResponseWriter.WriteHeader(w.status)
+
+	_, err := w.ResponseWriter.Write(data)
+	return err
+}
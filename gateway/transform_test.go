@@ -0,0 +1,137 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/gateway"
+)
+
+func TestTransformMiddleware(t *testing.T) {
+	mw, err := gateway.Transform{
+		Request: gateway.RequestTransform{
+			Headers: gateway.HeaderTransform{Set: map[string]string{"X-Tenant": "acme"}, Remove: []string{"Cookie"}},
+			Rewrite: &gateway.PathRewrite{Match: `^/v1/(.*)$`, Replace: "/api/$1"},
+			Body:    []gateway.FieldMapping{{From: "$.user.name", To: "$.username"}, {From: "$.debug"}},
+		},
+		Response: gateway.ResponseTransform{
+			Headers: gateway.HeaderTransform{Remove: []string{"Server"}, Add: map[string]string{"X-Gateway": "chu"}},
+			Body:    []gateway.FieldMapping{{From: "$.items[0].id", To: "$.first"}},
+		},
+	}.Middleware()
+	require.NoError(t, err)
+
+	r := chu.New()
+	r.Post("/v1/*", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		assert.Equal(t, "/api/users", r.URL.Path, "path should be rewritten")
+		assert.Equal(t, "acme", r.Header.Get("X-Tenant"), "request header should be set")
+		assert.Empty(t, r.Header.Get("Cookie"), "request header should be removed")
+		assert.JSONEq(t, `{"user":{},"username":"ana"}`, string(body), "request body should be mapped")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Server", "upstream")
+		_, err = io.WriteString(w, `{"items":[{"id":1},{"id":2}]}`)
+		return err
+	}, chu.WithMiddleware(mw))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(`{"user":{"name":"ana"},"debug":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", "session=1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+	assert.Empty(t, rec.Header().Get("Server"), "response header should be removed")
+	assert.Equal(t, "chu", rec.Header().Get("X-Gateway"), "response header should be added")
+	assert.JSONEq(t, `{"items":[{},{"id":2}],"first":1}`, rec.Body.String(), "response body should be mapped")
+	assert.Equal(t, len(rec.Body.String()), int(rec.Result().ContentLength), "content length should match mapped body")
+}
+
+func TestTransformSkipsNonJSON(t *testing.T) {
+	mw, err := gateway.Transform{
+		Response: gateway.ResponseTransform{Body: []gateway.FieldMapping{{From: "$.a", To: "$.b"}}},
+	}.Middleware()
+	require.NoError(t, err)
+
+	r := chu.New()
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/plain")
+		_, err := io.WriteString(w, `{"a":1}`)
+		return err
+	}, chu.WithMiddleware(mw))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, `{"a":1}`, rec.Body.String(), "non JSON body should pass through")
+}
+
+func TestTransformInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform gateway.Transform
+	}{
+		{name: "bad regexp", transform: gateway.Transform{Request: gateway.RequestTransform{Rewrite: &gateway.PathRewrite{Match: "("}}}},
+		{name: "missing root", transform: gateway.Transform{Request: gateway.RequestTransform{Body: []gateway.FieldMapping{{From: "a.b"}}}}},
+		{name: "bad index", transform: gateway.Transform{Response: gateway.ResponseTransform{Body: []gateway.FieldMapping{{From: "$.a[x]"}}}}},
+		{name: "whole document", transform: gateway.Transform{Response: gateway.ResponseTransform{Body: []gateway.FieldMapping{{From: "$.a", To: "$"}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.transform.Middleware()
+			assert.Error(t, err, "transform should fail to compile")
+		})
+	}
+}
+
+func TestGatewayTransformConfig(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"path": r.URL.Path, "secret": "x"})
+	}))
+	t.Cleanup(upstream.Close)
+
+	cfg, err := gateway.ParseYAML([]byte(`
+routes:
+  - path: /svc/*
+    upstream: ` + upstream.URL + `
+    strip_prefix: true
+    transform:
+      request:
+        rewrite: {match: "^/old/", replace: "/new/"}
+      response:
+        headers:
+          set: {X-Route: svc}
+        body:
+          - from: $.secret
+`))
+	require.NoError(t, err)
+
+	g := gateway.New()
+	require.NoError(t, g.Apply(cfg))
+
+	rec := serve(g, http.MethodGet, "/svc/old/thing", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+	assert.Equal(t, "svc", rec.Header().Get("X-Route"), "response header should be set")
+	assert.JSONEq(t, `{"path":"/new/thing"}`, rec.Body.String(), "body should be rewritten and mapped")
+
+	bad, err := gateway.ParseYAML([]byte("routes: [{path: /, upstream: http://u, transform: {request: {rewrite: {match: '('}}}}]"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, g.Validate(bad), gateway.ErrInvalidConfig, "invalid transform should fail validation")
+}
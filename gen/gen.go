@@ -0,0 +1,144 @@
+// Package gen generates clients and mock servers from the routes of a chu
+// router, as listed by Router.Routes with the models declared by the Accepts
+// and Returns route options.
+package gen
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/josearomeroj/chu"
+)
+
+// operation is a route as a client call.
+type operation struct {
+	route  chu.RouteDoc
+	name   string
+	params []param
+	// result is the model of the first successful response, if any.
+	result *chu.Model
+}
+
+type param struct {
+	name     string
+	ident    string
+	wildcard bool
+}
+
+// segment is a literal part of a path or a parameter.
+type segment struct {
+	literal string
+	param   *param
+}
+
+func operations(routes []chu.RouteDoc) []operation {
+	ops := make([]operation, 0, len(routes))
+	used := make(map[string]int)
+
+	for _, rt := range routes {
+		op := operation{route: rt, name: operationName(rt)}
+		if n := used[op.name]; n > 0 {
+			op.name += strconv.Itoa(n + 1)
+		}
+		used[op.name]++
+
+		for _, s := range segments(rt.Pattern) {
+			if s.param != nil {
+				op.params = append(op.params, *s.param)
+			}
+		}
+
+		for _, resp := range rt.Responses {
+			if resp.Status >= http.StatusOK && resp.Status < http.StatusMultipleChoices && resp.Model != nil {
+				op.result = resp.Model
+				break
+			}
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops
+}
+
+// operationName derives a name such as GetUsersByID from the method and the
+// pattern of a route.
+func operationName(rt chu.RouteDoc) string {
+	name := exported(strings.ToLower(rt.Method))
+	for _, s := range segments(rt.Pattern) {
+		switch {
+		case s.param == nil:
+			name += exported(s.literal)
+		case !s.param.wildcard:
+			name += "By" + exported(s.param.name)
+		}
+	}
+
+	return name
+}
+
+func segments(pattern string) []segment {
+	var out []segment
+	for _, part := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		switch {
+		case part == "":
+		case part == "*":
+			out = append(out, segment{param: &param{name: "path", ident: "path", wildcard: true}})
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			name, _, _ := strings.Cut(part[1:len(part)-1], ":")
+			out = append(out, segment{param: &param{name: name, ident: identifier(name)}})
+		default:
+			out = append(out, segment{literal: part})
+		}
+	}
+
+	return out
+}
+
+// words splits s on any character that is not a letter or a digit.
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+var initialisms = map[string]string{"id": "ID", "url": "URL", "uri": "URI", "api": "API", "http": "HTTP", "json": "JSON", "uuid": "UUID"}
+
+func exported(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		if upper, ok := initialisms[strings.ToLower(w)]; ok {
+			b.WriteString(upper)
+			continue
+		}
+
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+
+	return b.String()
+}
+
+var reserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+	// Names used by the generated code.
+	"c": true, "ctx": true, "body": true, "out": true, "err": true, "path": true, "url": true,
+}
+
+// identifier turns a parameter name into an unexported identifier that does
+// not clash with keywords or the generated code.
+func identifier(name string) string {
+	ws := words(name)
+	if len(ws) == 0 {
+		return "param"
+	}
+
+	id := strings.ToLower(ws[0][:1]) + ws[0][1:] + exported(strings.Join(ws[1:], " "))
+	if reserved[id] || unicode.IsDigit(rune(id[0])) {
+		id += "Param"
+	}
+
+	return id
+}
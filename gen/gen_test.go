@@ -0,0 +1,165 @@
+package gen_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/gen"
+	"github.com/josearomeroj/chu/openapi"
+)
+
+type Item struct {
+	ID      int            `json:"id"`
+	Name    string         `json:"name"`
+	Tags    []string       `json:"tags,omitempty"`
+	Parent  *Item          `json:"parent"`
+	Info    openapi.Info   `json:"info"`
+	Labels  map[string]int `json:"labels"`
+	Secret  string         `json:"-"`
+	private int
+}
+
+func itemRoutes() []chu.RouteDoc {
+	noop := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	r := chu.New()
+	r.Get("/items/{id}", noop, chu.Doc("Returns the item by id"), chu.Returns(http.StatusOK, Item{}))
+	r.Post("/items", noop, chu.Accepts(Item{}), chu.Returns(http.StatusCreated, Item{}))
+	r.Delete("/items/{id}", noop, chu.Returns(http.StatusNoContent, nil))
+	r.Get("/files/*", noop)
+
+	return r.Routes()
+}
+
+func TestGo(t *testing.T) {
+	src, err := gen.Go(itemRoutes(), gen.GoOptions{Package: "items"})
+	require.NoError(t, err)
+
+	code := string(src)
+	assert.Contains(t, code, "// Code generated by chu/gen. DO NOT EDIT.", "generated code should be marked")
+	assert.Contains(t, code, "package items", "package name should match expected")
+	assert.Contains(t, code, "\t\"net/url\"\n\n\t\"github.com/josearomeroj/chu/gen_test\"\n)", "model package should be imported after the standard library")
+	assert.Contains(t, code, "// GetItemsByID calls GET /items/{id}.\n// Returns the item by id", "doc should be included")
+	assert.Contains(t, code, "func (c *Client) GetItemsByID(ctx context.Context, id string) (gen_test.Item, error)", "get signature should match expected")
+	assert.Contains(t, code, `c.do(ctx, "GET", "/items/"+url.PathEscape(id), nil, &out)`, "path should be built from params")
+	assert.Contains(t, code, "func (c *Client) PostItems(ctx context.Context, body gen_test.Item) (gen_test.Item, error)", "post signature should match expected")
+	assert.Contains(t, code, "func (c *Client) DeleteItemsByID(ctx context.Context, id string) error", "delete without body should only return an error")
+	assert.Contains(t, code, `c.do(ctx, "GET", "/files/"+path, nil, nil)`, "wildcard should be appended unescaped")
+}
+
+const generatedClientTest = `package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/openapi"
+)
+
+func TestClient(t *testing.T) {
+	r := chu.New()
+	r.Get("/infos/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if chu.URLParam(r, "id") != "a b" {
+			return chu.Errorf(http.StatusNotFound, "not found")
+		}
+
+		return json.NewEncoder(w).Encode(openapi.Info{Title: "found"})
+	})
+	r.Post("/infos", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		var in openapi.Info
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			return err
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		return json.NewEncoder(w).Encode([]openapi.Info{in, in})
+	})
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	c := New(srv.URL, nil)
+
+	info, err := c.GetInfosByID(context.Background(), "a b")
+	if err != nil || info.Title != "found" {
+		t.Fatalf("get: %v %v", info, err)
+	}
+
+	var apiErr *Error
+	if _, err := c.GetInfosByID(context.Background(), "other"); !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Fatalf("get missing: %v", err)
+	}
+
+	infos, err := c.PostInfos(context.Background(), openapi.Info{Title: "new"})
+	if err != nil || len(infos) != 2 || infos[1].Title != "new" {
+		t.Fatalf("post: %v %v", infos, err)
+	}
+}
+`
+
+func TestGoCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated client")
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+
+	noop := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	r := chu.New()
+	r.Get("/infos/{id}", noop, chu.Returns(http.StatusOK, openapi.Info{}))
+	r.Post("/infos", noop, chu.Accepts(openapi.Info{}), chu.Returns(http.StatusCreated, []openapi.Info{}))
+
+	src, err := gen.Go(r.Routes(), gen.GoOptions{})
+	require.NoError(t, err)
+
+	// The client must live inside the module to resolve its imports.
+	dir, err := os.MkdirTemp(".", "client")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.go"), src, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client_test.go"), []byte(generatedClientTest), 0o644))
+
+	out, err := exec.Command(goBin, "test", "./"+filepath.Base(dir)).CombinedOutput()
+	assert.NoError(t, err, "generated client should build and work: %s", out)
+}
+
+func TestGoUnexportedModel(t *testing.T) {
+	type hidden struct{}
+
+	r := chu.New()
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }, chu.Returns(http.StatusOK, hidden{}))
+
+	_, err := gen.Go(r.Routes(), gen.GoOptions{})
+	assert.ErrorContains(t, err, "not exported", "unexported models should be rejected")
+}
+
+func TestTypeScript(t *testing.T) {
+	src, err := gen.TypeScript(itemRoutes())
+	require.NoError(t, err)
+
+	code := string(src)
+	assert.Contains(t, code, "export interface Info {\n  title: string;\n  description?: string;\n  version: string;\n}", "nested models should be declared")
+	assert.Contains(t, code, "export interface Item {\n  id: number;\n  name: string;\n  tags?: string[];\n  parent: Item | null;\n  info: Info;\n  labels: Record<string, number>;\n}", "model should be declared")
+	assert.Contains(t, code, "/** GET /items/{id}: Returns the item by id */\n  getItemsByID(id: string): Promise<Item> {", "get method should match expected")
+	assert.Contains(t, code, `return this.request<Item>("GET", "/items/" + encodeURIComponent(id));`, "path should be built from params")
+	assert.Contains(t, code, `postItems(body: Item): Promise<Item> {`, "post method should match expected")
+	assert.Contains(t, code, `deleteItemsByID(id: string): Promise<void> {`, "delete method should match expected")
+}
@@ -0,0 +1,290 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/josearomeroj/chu"
+)
+
+type GoOptions struct {
+	// Package is the package name of the generated code. Defaults to "client".
+	Package string
+}
+
+// Go generates the source of a typed Go client with one method per route.
+// Request and response bodies use the model types declared with Accepts and
+// Returns, imported from their packages, so server and client share them.
+// Responses outside the 2xx range are returned as *Error.
+func Go(routes []chu.RouteDoc, opts GoOptions) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "client"
+	}
+
+	imports := newImportSet("bytes", "context", "encoding/json", "fmt", "io", "net/http")
+	ops := operations(routes)
+
+	var methods bytes.Buffer
+	for _, op := range ops {
+		writeGoMethod(&methods, op, imports)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by chu/gen. DO NOT EDIT.\n\npackage %s\n\n", opts.Package)
+
+	if imports.err != nil {
+		return nil, imports.err
+	}
+
+	b.WriteString("import (\n")
+	for i, imp := range imports.sorted() {
+		if i > 0 && !imp.std() && imports.sorted()[i-1].std() {
+			b.WriteString("\n")
+		}
+
+		if imp.alias == path.Base(imp.path) {
+			fmt.Fprintf(&b, "\t%q\n", imp.path)
+		} else {
+			fmt.Fprintf(&b, "\t%s %q\n", imp.alias, imp.path)
+		}
+	}
+	b.WriteString(")\n")
+
+	b.WriteString(goClientRuntime)
+	b.Write(methods.Bytes())
+
+	return format.Source(b.Bytes())
+}
+
+const goClientRuntime = `
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// New returns a client for the API at baseURL. hc defaults to
+// http.DefaultClient.
+func New(baseURL string, hc *http.Client) *Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	return &Client{BaseURL: baseURL, HTTP: hc}
+}
+
+// Error is returned for responses outside the 2xx range.
+type Error struct {
+	Status int
+	Body   []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), bytes.TrimSpace(e.Body))
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return &Error{Status: resp.StatusCode, Body: data}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+`
+
+func writeGoMethod(b *bytes.Buffer, op operation, imports *importSet) {
+	fmt.Fprintf(b, "\n// %s calls %s %s.", op.name, op.route.Method, op.route.Pattern)
+	if op.route.Doc != "" {
+		fmt.Fprintf(b, "\n// %s", strings.ReplaceAll(op.route.Doc, "\n", "\n// "))
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range op.params {
+		args = append(args, p.ident+" string")
+	}
+
+	in := "nil"
+	if op.route.Request != nil {
+		args = append(args, "body "+goType(op.route.Request.Type, imports))
+		in = "body"
+	}
+
+	if op.result == nil {
+		fmt.Fprintf(b, "\nfunc (c *Client) %s(%s) error {\n", op.name, strings.Join(args, ", "))
+		fmt.Fprintf(b, "\treturn c.do(ctx, %q, %s, %s, nil)\n}\n", op.route.Method, goPath(op.route.Pattern, imports), in)
+		return
+	}
+
+	result := goType(op.result.Type, imports)
+	fmt.Fprintf(b, "\nfunc (c *Client) %s(%s) (%s, error) {\n", op.name, strings.Join(args, ", "), result)
+	fmt.Fprintf(b, "\tvar out %s\n", result)
+	fmt.Fprintf(b, "\terr := c.do(ctx, %q, %s, %s, &out)\n", op.route.Method, goPath(op.route.Pattern, imports), in)
+	b.WriteString("\treturn out, err\n}\n")
+}
+
+// goPath returns the expression building the request path of pattern.
+func goPath(pattern string, imports *importSet) string {
+	var parts []string
+	var literal strings.Builder
+
+	for _, s := range segments(pattern) {
+		literal.WriteString("/")
+
+		if s.param == nil {
+			literal.WriteString(s.literal)
+			continue
+		}
+
+		parts = append(parts, strconv.Quote(literal.String()))
+		literal.Reset()
+
+		if s.param.wildcard {
+			parts = append(parts, s.param.ident)
+		} else {
+			parts = append(parts, imports.add("net/url", "url")+".PathEscape("+s.param.ident+")")
+		}
+	}
+
+	if strings.HasSuffix(pattern, "/") || len(parts) == 0 && literal.Len() == 0 {
+		literal.WriteString("/")
+	}
+
+	if literal.Len() > 0 {
+		parts = append(parts, strconv.Quote(literal.String()))
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+func goType(t reflect.Type, imports *importSet) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+
+		if !token.IsExported(t.Name()) {
+			imports.err = fmt.Errorf("gen: model type %s is not exported", t)
+		}
+
+		// The string form of a named type is qualified by its package name,
+		// which may differ from the last element of its path.
+		name, _, _ := strings.Cut(t.String(), ".")
+		return imports.add(t.PkgPath(), name) + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + goType(t.Elem(), imports)
+	case reflect.Slice:
+		return "[]" + goType(t.Elem(), imports)
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + goType(t.Elem(), imports)
+	case reflect.Map:
+		return "map[" + goType(t.Key(), imports) + "]" + goType(t.Elem(), imports)
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	}
+
+	return t.String()
+}
+
+type goImport struct {
+	path  string
+	alias string
+}
+
+// std reports whether the import is from the standard library.
+func (imp goImport) std() bool {
+	first, _, _ := strings.Cut(imp.path, "/")
+	return !strings.Contains(first, ".")
+}
+
+type importSet struct {
+	imports []goImport
+	err     error
+}
+
+func newImportSet(paths ...string) *importSet {
+	s := &importSet{}
+	for _, p := range paths {
+		s.add(p, path.Base(p))
+	}
+
+	return s
+}
+
+// add imports the package name at p and returns the name to refer to it by,
+// aliasing packages whose name is already taken.
+func (s *importSet) add(p, name string) string {
+	for _, imp := range s.imports {
+		if imp.path == p {
+			return imp.alias
+		}
+	}
+
+	base := name
+	alias := base
+	for i := 2; slices.ContainsFunc(s.imports, func(imp goImport) bool { return imp.alias == alias }); i++ {
+		alias = base + strconv.Itoa(i)
+	}
+
+	s.imports = append(s.imports, goImport{path: p, alias: alias})
+
+	return alias
+}
+
+func (s *importSet) sorted() []goImport {
+	imports := slices.Clone(s.imports)
+	slices.SortFunc(imports, func(a, b goImport) int {
+		if a.std() != b.std() {
+			if a.std() {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a.path, b.path)
+	})
+
+	return imports
+}
@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/josearomeroj/chu"
+)
+
+// MockServer returns a router serving the declared responses of routes, so
+// clients can be developed before the handlers exist. Each route answers
+// with its first successful response, or its first response when none is
+// successful, writing the example as JSON. A "Prefer: code=404" request
+// header picks another declared response. Routes without responses answer
+// 501 Not Implemented.
+func MockServer(routes []chu.RouteDoc, opts ...chu.Option) *chu.Router {
+	r := chu.New(opts...)
+	for _, rt := range routes {
+		r.Method(rt.Method, rt.Pattern, mockHandler(rt.Responses))
+	}
+
+	return r
+}
+
+func mockHandler(responses []chu.ResponseDoc) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		resp, ok := mockResponse(responses, r.Header.Values("Prefer"))
+		if !ok {
+			return chu.NewError(http.StatusNotImplemented, nil)
+		}
+
+		if resp.Model == nil || resp.Model.Example == nil {
+			w.WriteHeader(resp.Status)
+			return nil
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.Status)
+
+		return json.NewEncoder(w).Encode(resp.Model.Example)
+	}
+}
+
+func mockResponse(responses []chu.ResponseDoc, prefer []string) (chu.ResponseDoc, bool) {
+	if len(responses) == 0 {
+		return chu.ResponseDoc{}, false
+	}
+
+	if status, ok := preferredStatus(prefer); ok {
+		for _, resp := range responses {
+			if resp.Status == status {
+				return resp, true
+			}
+		}
+	}
+
+	for _, resp := range responses {
+		if resp.Status >= http.StatusOK && resp.Status < http.StatusMultipleChoices {
+			return resp, true
+		}
+	}
+
+	return responses[0], true
+}
+
+// preferredStatus reads the code preference of Prefer headers such as
+// "code=404".
+func preferredStatus(prefer []string) (int, bool) {
+	for _, header := range prefer {
+		for _, pref := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+			name, value, ok := strings.Cut(strings.TrimSpace(pref), "=")
+			if !ok || !strings.EqualFold(name, "code") {
+				continue
+			}
+
+			if status, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				return status, true
+			}
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,63 @@
+package gen_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/gen"
+)
+
+func TestMockServer(t *testing.T) {
+	noop := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	r := chu.New()
+	r.Get("/items/{id}", noop,
+		chu.Returns(http.StatusNotFound, map[string]string{"error": "not found"}),
+		chu.Returns(http.StatusOK, Item{ID: 1, Name: "first"}),
+	)
+	r.Delete("/items/{id}", noop, chu.Returns(http.StatusNoContent, nil))
+	r.Post("/items", noop, chu.Returns(http.StatusBadRequest, nil))
+	r.Get("/files/*", noop)
+
+	mock := gen.MockServer(r.Routes())
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		prefer         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"successful example", http.MethodGet, "/items/7", "", http.StatusOK, `{"id":1,"name":"first","parent":null,"info":{"title":"","version":""},"labels":null}` + "\n"},
+		{"preferred status", http.MethodGet, "/items/7", "code=404", http.StatusNotFound, `{"error":"not found"}` + "\n"},
+		{"undeclared preference", http.MethodGet, "/items/7", "code=500", http.StatusOK, ""},
+		{"no body", http.MethodDelete, "/items/7", "", http.StatusNoContent, ""},
+		{"only failure declared", http.MethodPost, "/items", "", http.StatusBadRequest, ""},
+		{"no responses", http.MethodGet, "/files/a/b", "", http.StatusNotImplemented, ""},
+		{"unknown route", http.MethodGet, "/users", "", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.prefer != "" {
+				req.Header.Set("Prefer", tt.prefer)
+			}
+
+			rec := httptest.NewRecorder()
+			mock.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, "status code should match expected")
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rec.Body.String(), "body should match the declared example")
+				assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "content type should be JSON")
+			}
+		})
+	}
+}
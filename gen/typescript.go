@@ -0,0 +1,268 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+// TypeScript generates a TypeScript client using fetch, with an interface for
+// every struct model declared with Accepts and Returns. Responses outside the
+// 2xx range reject with an APIError.
+func TypeScript(routes []chu.RouteDoc) ([]byte, error) {
+	ts := &tsTypes{names: make(map[reflect.Type]string), taken: make(map[string]bool)}
+
+	var methods bytes.Buffer
+	for _, op := range operations(routes) {
+		writeTSMethod(&methods, op, ts)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by chu/gen. DO NOT EDIT.\n")
+	for _, decl := range ts.decls {
+		b.WriteString("\n" + decl)
+	}
+	b.WriteString(tsClientRuntime)
+	b.Write(methods.Bytes())
+	b.WriteString("}\n")
+
+	return b.Bytes(), nil
+}
+
+const tsClientRuntime = `
+export class APIError extends Error {
+  constructor(public status: number, public body: string) {
+    super(status + ": " + body);
+  }
+}
+
+export class Client {
+  constructor(private baseURL: string, private fetchFn: typeof fetch = fetch) {}
+
+  private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+
+    const resp = await this.fetchFn(this.baseURL + path, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+
+    const text = await resp.text();
+    if (!resp.ok) {
+      throw new APIError(resp.status, text);
+    }
+
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+`
+
+func writeTSMethod(b *bytes.Buffer, op operation, ts *tsTypes) {
+	fmt.Fprintf(b, "\n  /** %s %s", op.route.Method, op.route.Pattern)
+	if op.route.Doc != "" {
+		fmt.Fprintf(b, ": %s", strings.ReplaceAll(op.route.Doc, "*/", "* /"))
+	}
+	b.WriteString(" */\n")
+
+	var args []string
+	for _, p := range op.params {
+		args = append(args, p.ident+": string")
+	}
+
+	call := []string{strconv.Quote(op.route.Method), tsPath(op.route.Pattern)}
+	if op.route.Request != nil {
+		args = append(args, "body: "+ts.typeOf(op.route.Request.Type))
+		call = append(call, "body")
+	}
+
+	result := "void"
+	if op.result != nil {
+		result = ts.typeOf(op.result.Type)
+	}
+
+	name := strings.ToLower(op.name[:1]) + op.name[1:]
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", name, strings.Join(args, ", "), result)
+	fmt.Fprintf(b, "    return this.request<%s>(%s);\n  }\n", result, strings.Join(call, ", "))
+}
+
+func tsPath(pattern string) string {
+	var parts []string
+	var literal strings.Builder
+
+	for _, s := range segments(pattern) {
+		literal.WriteString("/")
+
+		if s.param == nil {
+			literal.WriteString(s.literal)
+			continue
+		}
+
+		parts = append(parts, strconv.Quote(literal.String()))
+		literal.Reset()
+
+		if s.param.wildcard {
+			parts = append(parts, s.param.ident)
+		} else {
+			parts = append(parts, "encodeURIComponent("+s.param.ident+")")
+		}
+	}
+
+	if strings.HasSuffix(pattern, "/") || len(parts) == 0 && literal.Len() == 0 {
+		literal.WriteString("/")
+	}
+
+	if literal.Len() > 0 {
+		parts = append(parts, strconv.Quote(literal.String()))
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// tsTypes maps Go types to TypeScript, declaring an interface per named
+// struct.
+type tsTypes struct {
+	names map[reflect.Type]string
+	taken map[string]bool
+	decls []string
+}
+
+func (ts *tsTypes) typeOf(t reflect.Type) string {
+	switch t {
+	case timeType:
+		return "string"
+	case rawMessageType:
+		return "unknown"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Pointer:
+		return ts.typeOf(t.Elem()) + " | null"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+
+		elem := ts.typeOf(t.Elem())
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+
+		return elem + "[]"
+	case reflect.Map:
+		return "Record<string, " + ts.typeOf(t.Elem()) + ">"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "{ " + strings.Join(ts.fields(t), " ") + " }"
+		}
+
+		return ts.declare(t)
+	default:
+		return "unknown"
+	}
+}
+
+func (ts *tsTypes) declare(t reflect.Type) string {
+	if name, ok := ts.names[t]; ok {
+		return name
+	}
+
+	name := exported(t.Name())
+	if ts.taken[name] {
+		pkg, _, _ := strings.Cut(t.String(), ".")
+		name = exported(pkg) + name
+	}
+
+	ts.names[t] = name
+	ts.taken[name] = true
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "export interface %s {\n", name)
+	for _, field := range ts.fields(t) {
+		b.WriteString("  " + field + "\n")
+	}
+	b.WriteString("}\n")
+
+	ts.decls = append(ts.decls, b.String())
+
+	return name
+}
+
+// fields returns the members of a struct as encoding/json sees them.
+func (ts *tsTypes) fields(t reflect.Type) []string {
+	var fields []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, ts.fields(ft)...)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		typ := ts.typeOf(f.Type)
+		if strings.Contains(","+opts+",", ",string,") {
+			typ = "string"
+		}
+
+		optional := ""
+		if strings.Contains(","+opts+",", ",omitempty,") || strings.Contains(","+opts+",", ",omitzero,") {
+			optional = "?"
+		}
+
+		fields = append(fields, tsKey(name)+optional+": "+typ+";")
+	}
+
+	return fields
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func tsKey(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+
+	return strconv.Quote(name)
+}
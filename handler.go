@@ -1,41 +1,70 @@
 package chu
 
-func (r *Router) Method(method, pattern string, h Handler) {
-	r.chi.Method(method, pattern, r.adapt(h))
+import "net/http"
+
+func (r *Router) Handle(pattern string, h Handler, opts ...RouteOption) {
+	r.chi.Handle(pattern, r.adapt(r.route("", pattern, opts).wrap(h)))
+	r.record(func(c *Router) { c.Handle(pattern, h, opts...) })
+}
+
+func (r *Router) Method(method, pattern string, h Handler, opts ...RouteOption) {
+	r.chi.Method(method, pattern, r.adapt(r.route(method, pattern, opts).wrap(h)))
+	r.record(func(c *Router) { c.Method(method, pattern, h, opts...) })
+}
+
+func (r *Router) route(method, pattern string, opts []RouteOption) *route {
+	rt := newRoute(method, pattern, opts)
+	rt.syncCtx = r.syncCtx
+	r.chains.set(r.prefix+pattern, r.chain)
+
+	if rt.cost != nil {
+		r.costs.set(method, r.prefix+pattern, *rt.cost)
+	}
+
+	if rt.doc != nil {
+		r.docs.set(method, r.prefix+pattern, rt.doc)
+	}
+
+	if rt.deprecation != nil {
+		dr := r.deprecations.add(method, r.prefix+pattern, *rt.deprecation)
+		rt.middlewares = append([]func(Handler) Handler{dr.middleware}, rt.middlewares...)
+	}
+
+	return rt
 }
 
-func (r *Router) Get(pattern string, h Handler) {
-	r.chi.Get(pattern, r.adapt(h))
+func (r *Router) Get(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodGet, pattern, h, opts...)
 }
 
-func (r *Router) Post(pattern string, h Handler) {
-	r.chi.Post(pattern, r.adapt(h))
+func (r *Router) Post(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodPost, pattern, h, opts...)
 }
 
-func (r *Router) Put(pattern string, h Handler) {
-	r.chi.Put(pattern, r.adapt(h))
+func (r *Router) Put(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodPut, pattern, h, opts...)
 }
 
-func (r *Router) Delete(pattern string, h Handler) {
-	r.chi.Delete(pattern, r.adapt(h))
+func (r *Router) Delete(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodDelete, pattern, h, opts...)
 }
 
-func (r *Router) Patch(pattern string, h Handler) {
-	r.chi.Patch(pattern, r.adapt(h))
+func (r *Router) Patch(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodPatch, pattern, h, opts...)
 }
 
-func (r *Router) Head(pattern string, h Handler) {
-	r.chi.Head(pattern, r.adapt(h))
+func (r *Router) Head(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodHead, pattern, h, opts...)
 }
 
-func (r *Router) Options(pattern string, h Handler) {
-	r.chi.Options(pattern, r.adapt(h))
+func (r *Router) Options(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodOptions, pattern, h, opts...)
 }
 
-func (r *Router) Connect(pattern string, h Handler) {
-	r.chi.Connect(pattern, r.adapt(h))
+func (r *Router) Connect(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodConnect, pattern, h, opts...)
 }
 
-func (r *Router) Trace(pattern string, h Handler) {
-	r.chi.Trace(pattern, r.adapt(h))
+func (r *Router) Trace(pattern string, h Handler, opts ...RouteOption) {
+	r.Method(http.MethodTrace, pattern, h, opts...)
 }
@@ -0,0 +1,224 @@
+package chu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Check func(ctx context.Context) error
+
+type HealthOption func(*health)
+
+func WithLivenessCheck(name string, check Check) HealthOption {
+	return func(h *health) {
+		h.liveness[name] = check
+	}
+}
+
+func WithReadinessCheck(name string, check Check) HealthOption {
+	return func(h *health) {
+		h.readiness[name] = check
+	}
+}
+
+func WithCheckTimeout(d time.Duration) HealthOption {
+	return func(h *health) {
+		h.timeout = d
+	}
+}
+
+// WithVersion sets the version metadata reported by /version, usually injected
+// at build time with -ldflags "-X main.version=...". Empty values fall back to
+// the module and VCS information embedded by the Go toolchain.
+func WithVersion(version, commit, buildTime string) HealthOption {
+	return func(h *health) {
+		h.info.Version, h.info.Commit, h.info.BuildTime = version, commit, buildTime
+	}
+}
+
+func WithHealthPrefix(prefix string) HealthOption {
+	return func(h *health) {
+		h.prefix = prefix
+	}
+}
+
+type VersionInfo struct {
+	Version   string `json:"version,omitempty"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+type health struct {
+	prefix    string
+	timeout   time.Duration
+	liveness  map[string]Check
+	readiness map[string]Check
+	info      VersionInfo
+}
+
+type healthBody struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// MountHealth registers /livez, /readyz, /version and /buildinfo on the router.
+func (r *Router) MountHealth(opts ...HealthOption) {
+	h := &health{
+		timeout:   5 * time.Second,
+		liveness:  make(map[string]Check),
+		readiness: make(map[string]Check),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.info = buildVersion(h.info)
+
+	r.Get(h.prefix+"/livez", h.probe(h.liveness))
+	r.Get(h.prefix+"/readyz", h.probe(h.readiness))
+	r.Get(h.prefix+"/version", h.version)
+	r.Get(h.prefix+"/buildinfo", buildInfoHandler)
+}
+
+func (h *health) probe(checks map[string]Check) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+
+		var (
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+			body = healthBody{Status: "ok", Checks: make(map[string]string, len(checks))}
+		)
+
+		for name, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				result := "ok"
+				if err := check(ctx); err != nil {
+					result = err.Error()
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				body.Checks[name] = result
+				if result != "ok" {
+					body.Status = "fail"
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		status := http.StatusOK
+		if body.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+
+		return json.NewEncoder(w).Encode(body)
+	}
+}
+
+func (h *health) version(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(h.info)
+}
+
+func buildVersion(info VersionInfo) VersionInfo {
+	info.GoVersion = runtime.Version()
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if info.Version == "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	return info
+}
+
+type buildInfoModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+	Sum     string `json:"sum,omitempty"`
+	Replace string `json:"replace,omitempty"`
+}
+
+type buildInfoBody struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path"`
+	Main      buildInfoModule   `json:"main"`
+	Deps      []buildInfoModule `json:"deps"`
+	Settings  map[string]string `json:"settings"`
+}
+
+func buildInfoHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Errorf(http.StatusNotFound, "build info unavailable")
+	}
+
+	body := buildInfoBody{
+		GoVersion: bi.GoVersion,
+		Path:      bi.Path,
+		Main:      newBuildInfoModule(&bi.Main),
+		Deps:      make([]buildInfoModule, 0, len(bi.Deps)),
+		Settings:  make(map[string]string, len(bi.Settings)),
+	}
+
+	for _, dep := range bi.Deps {
+		body.Deps = append(body.Deps, newBuildInfoModule(dep))
+	}
+
+	slices.SortFunc(body.Deps, func(a, b buildInfoModule) int { return strings.Compare(a.Path, b.Path) })
+
+	for _, s := range bi.Settings {
+		body.Settings[s.Key] = s.Value
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(body)
+}
+
+func newBuildInfoModule(m *debug.Module) buildInfoModule {
+	mod := buildInfoModule{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		mod.Replace = m.Replace.Path + "@" + m.Replace.Version
+	}
+
+	return mod
+}
@@ -0,0 +1,102 @@
+package chu_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+)
+
+func TestMountHealth(t *testing.T) {
+	ready := errors.New("database unavailable")
+
+	r := chu.New()
+	r.MountHealth(
+		chu.WithLivenessCheck("loop", func(ctx context.Context) error { return nil }),
+		chu.WithReadinessCheck("db", func(ctx context.Context) error { return ready }),
+		chu.WithReadinessCheck("cache", func(ctx context.Context) error { return nil }),
+	)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   map[string]any
+	}{
+		{name: "live", path: "/livez", status: http.StatusOK, body: map[string]any{"status": "ok", "checks": map[string]any{"loop": "ok"}}},
+		{name: "not ready", path: "/readyz", status: http.StatusServiceUnavailable, body: map[string]any{"status": "fail", "checks": map[string]any{"db": "database unavailable", "cache": "ok"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.status, rec.Code, "status code should match expected")
+			assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"), "probes should not be cached")
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.body, body, "body should match expected")
+		})
+	}
+}
+
+func TestMountHealthCheckTimeout(t *testing.T) {
+	r := chu.New()
+	r.MountHealth(
+		chu.WithCheckTimeout(10*time.Millisecond),
+		chu.WithReadinessCheck("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "status code should match expected")
+	assert.Contains(t, rec.Body.String(), "deadline exceeded", "timeout should be reported")
+}
+
+func TestMountHealthVersion(t *testing.T) {
+	r := chu.New()
+	r.MountHealth(chu.WithHealthPrefix("/_"), chu.WithVersion("1.4.2", "abc123", "2024-01-02T03:04:05Z"))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_/version", nil))
+	require.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+
+	var info chu.VersionInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, chu.VersionInfo{
+		Version:   "1.4.2",
+		Commit:    "abc123",
+		BuildTime: "2024-01-02T03:04:05Z",
+		Modified:  info.Modified,
+		GoVersion: runtime.Version(),
+	}, info, "version info should match expected")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_/buildinfo", nil))
+	require.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+
+	var bi struct {
+		GoVersion string `json:"go_version"`
+		Deps      []struct {
+			Path string `json:"path"`
+		} `json:"deps"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &bi))
+	assert.Equal(t, runtime.Version(), bi.GoVersion, "go version should match runtime")
+	assert.NotEmpty(t, bi.Deps, "dependencies should be listed")
+}
@@ -0,0 +1,113 @@
+package chu
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"time"
+)
+
+type HoneypotHit struct {
+	Time      time.Time
+	Method    string
+	Path      string
+	Pattern   string
+	RemoteIP  netip.Addr
+	UserAgent string
+}
+
+type HoneypotOption func(*honeypot)
+
+// WithHoneypotReport replaces the default report, a warning logged with slog.
+func WithHoneypotReport(fn func(ctx context.Context, hit HoneypotHit)) HoneypotOption {
+	return func(h *honeypot) {
+		h.report = fn
+	}
+}
+
+// WithHoneypotBlock blocks callers in f for d. Put f.Middleware in front of the
+// routes that should reject flagged clients.
+func WithHoneypotBlock(f *IPFilter, d time.Duration) HoneypotOption {
+	return func(h *honeypot) {
+		h.filter, h.blockFor = f, d
+	}
+}
+
+// WithTarpit delays the decoy response by d to slow down scanners. The delay
+// ends early when the client goes away.
+func WithTarpit(d time.Duration) HoneypotOption {
+	return func(h *honeypot) {
+		h.delay = d
+	}
+}
+
+// WithHoneypotStatus sets the decoy response status. Defaults to 404, so the
+// endpoint looks like any other missing page.
+func WithHoneypotStatus(code int) HoneypotOption {
+	return func(h *honeypot) {
+		h.status = code
+	}
+}
+
+type honeypot struct {
+	report   func(ctx context.Context, hit HoneypotHit)
+	filter   *IPFilter
+	blockFor time.Duration
+	delay    time.Duration
+	status   int
+}
+
+// Honeypot registers a decoy endpoint for every method on pattern. Legitimate
+// clients never call it, so every hit is reported and optionally blocked and
+// tarpitted, e.g. r.Honeypot("/wp-login.php").
+func (r *Router) Honeypot(pattern string, opts ...HoneypotOption) {
+	h := &honeypot{
+		status: http.StatusNotFound,
+		report: func(ctx context.Context, hit HoneypotHit) {
+			slog.WarnContext(ctx, "honeypot hit",
+				"method", hit.Method,
+				"path", hit.Path,
+				"remote_ip", hit.RemoteIP,
+				"user_agent", hit.UserAgent,
+			)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	r.Handle(pattern, h.serve)
+}
+
+func (h *honeypot) serve(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ip, _ := remoteIP(r)
+
+	h.report(ctx, HoneypotHit{
+		Time:      time.Now(),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Pattern:   RoutePatternFromCtx(ctx),
+		RemoteIP:  ip,
+		UserAgent: r.UserAgent(),
+	})
+
+	if h.filter != nil && ip.IsValid() {
+		h.filter.Block(ip, h.blockFor)
+	}
+
+	if h.delay > 0 {
+		timer := time.NewTimer(h.delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	http.Error(w, http.StatusText(h.status), h.status)
+	return nil
+}
@@ -0,0 +1,100 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+)
+
+func TestHoneypot(t *testing.T) {
+	var hits []chu.HoneypotHit
+
+	filter := chu.NewIPFilter()
+
+	r := chu.New()
+	r.Use(filter.Middleware)
+	r.Honeypot("/wp-login.php",
+		chu.WithHoneypotReport(func(ctx context.Context, hit chu.HoneypotHit) {
+			hits = append(hits, hit)
+		}),
+		chu.WithHoneypotBlock(filter, time.Hour),
+	)
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	call := func(method, path, remoteAddr string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("User-Agent", "scanner/1.0")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, call("GET", "/", "203.0.113.7:1000"), "client should pass before tripping the honeypot")
+	assert.Equal(t, http.StatusNotFound, call("POST", "/wp-login.php", "203.0.113.7:1000"), "decoy should look like a missing page")
+	assert.Equal(t, http.StatusForbidden, call("GET", "/", "203.0.113.7:2000"), "flagged client should be blocked")
+	assert.Equal(t, http.StatusOK, call("GET", "/", "203.0.113.8:1000"), "other clients should pass")
+
+	require.Len(t, hits, 1, "hit should be reported")
+	assert.Equal(t, chu.HoneypotHit{
+		Time:      hits[0].Time,
+		Method:    "POST",
+		Path:      "/wp-login.php",
+		Pattern:   "/wp-login.php",
+		RemoteIP:  netip.MustParseAddr("203.0.113.7"),
+		UserAgent: "scanner/1.0",
+	}, hits[0], "hit should describe the caller")
+}
+
+func TestHoneypotTarpit(t *testing.T) {
+	r := chu.New()
+	r.Honeypot("/.env",
+		chu.WithHoneypotReport(func(ctx context.Context, hit chu.HoneypotHit) {}),
+		chu.WithTarpit(50*time.Millisecond),
+		chu.WithHoneypotStatus(http.StatusForbidden),
+	)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/.env", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code, "status code should match expected")
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "response should be delayed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start = time.Now()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/.env", nil).WithContext(ctx))
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "tarpit should end when the client goes away")
+}
+
+func TestIPFilter(t *testing.T) {
+	f := chu.NewIPFilter()
+	ip := netip.MustParseAddr("198.51.100.1")
+
+	f.Block(ip, 20*time.Millisecond)
+	assert.True(t, f.Blocked(ip), "address should be blocked")
+	assert.True(t, f.Blocked(netip.MustParseAddr("::ffff:198.51.100.1")), "mapped address should be blocked")
+
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, f.Blocked(ip), "block should expire")
+
+	f.Block(ip, 0)
+	assert.True(t, f.Blocked(ip), "block without duration should not expire")
+
+	f.Unblock(ip)
+	assert.False(t, f.Blocked(ip), "address should be unblocked")
+}
@@ -0,0 +1,108 @@
+package chu
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type hostRoute struct {
+	segments []string
+	router   *Router
+}
+
+// Host routes requests whose host matches pattern to the routes registered in
+// fn. Pattern segments in braces, as in "{tenant}.example.com", match a single
+// label and are available through URLParam like path parameters. Matching
+// ignores case and the port. Middleware added to r with Use before Host runs
+// for the host routes too; requests for other hosts use r's own routes. Host
+// is only honored on the router that serves requests, not on groups or
+// sub-routers.
+func (r *Router) Host(pattern string, fn func(r *Router)) {
+	hr := &hostRoute{segments: strings.Split(stripPort(pattern), ".")}
+	for i, segment := range hr.segments {
+		if !isHostParam(segment) {
+			hr.segments[i] = strings.ToLower(segment)
+		}
+	}
+
+	sub := r.subRouter(r.routerBuilder(), "")
+	sub.chi.Use(hr.params)
+	sub.chain = nil
+	sub.UseNamed(r.middlewares...)
+	sub.recipe = nil
+
+	fn(sub)
+
+	hr.router = sub
+	r.hosts = append(r.hosts, hr)
+
+	r.record(func(c *Router) { c.Host(pattern, sub.replay) })
+}
+
+func (r *Router) matchHost(req *http.Request) *Router {
+	if len(r.hosts) == 0 {
+		return nil
+	}
+
+	labels := strings.Split(strings.ToLower(stripPort(req.Host)), ".")
+	for _, hr := range r.hosts {
+		if hr.match(labels) {
+			return hr.router
+		}
+	}
+
+	return nil
+}
+
+func (hr *hostRoute) match(labels []string) bool {
+	if len(labels) != len(hr.segments) {
+		return false
+	}
+
+	for i, segment := range hr.segments {
+		if isHostParam(segment) {
+			if labels[i] == "" {
+				return false
+			}
+			continue
+		}
+
+		if segment != labels[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// params adds the captured host labels to the route context before routing,
+// so path parameters are appended after them.
+func (hr *hostRoute) params(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if rctx := chi.RouteContext(req.Context()); rctx != nil {
+			labels := strings.Split(strings.ToLower(stripPort(req.Host)), ".")
+			for i, segment := range hr.segments {
+				if isHostParam(segment) && i < len(labels) {
+					rctx.URLParams.Add(segment[1:len(segment)-1], labels[i])
+				}
+			}
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+func isHostParam(segment string) bool {
+	return len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}'
+}
+
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+
+	return host
+}
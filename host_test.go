@@ -0,0 +1,121 @@
+package chu_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/josearomeroj/chu"
+)
+
+func TestHost(t *testing.T) {
+	for _, backend := range []struct {
+		name string
+		opts []chu.Option
+	}{
+		{name: "chi"},
+		{name: "servemux", opts: []chu.Option{chu.WithServeMuxBackend()}},
+	} {
+		t.Run(backend.name, func(t *testing.T) {
+			r := chu.New(backend.opts...)
+			r.Use(func(next chu.Handler) chu.Handler {
+				return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+					w.Header().Set("X-Tenant", chu.URLParam(r, "tenant"))
+					return next(ctx, w, r)
+				}
+			})
+
+			r.Host("{tenant}.example.com", func(r *chu.Router) {
+				r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+					_, err := io.WriteString(w, chu.URLParam(r, "tenant")+" "+chu.URLParamFromCtx(ctx, "id"))
+					return err
+				})
+			})
+
+			r.Host("{region}.{tenant}.example.com", func(r *chu.Router) {
+				r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+					_, err := io.WriteString(w, chu.URLParam(r, "tenant")+"@"+chu.URLParam(r, "region"))
+					return err
+				})
+			})
+
+			r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				_, err := io.WriteString(w, "apex "+chu.URLParam(r, "id"))
+				return err
+			})
+
+			tests := []struct {
+				name   string
+				host   string
+				path   string
+				status int
+				body   string
+				tenant string
+			}{
+				{name: "tenant host", host: "acme.example.com", path: "/users/7", status: http.StatusOK, body: "acme 7", tenant: "acme"},
+				{name: "port and case", host: "Globex.Example.com:8443", path: "/users/9", status: http.StatusOK, body: "globex 9", tenant: "globex"},
+				{name: "nested labels", host: "eu.acme.example.com", path: "/", status: http.StatusOK, body: "acme@eu", tenant: "acme"},
+				{name: "apex host", host: "example.com", path: "/users/7", status: http.StatusOK, body: "apex 7"},
+				{name: "other domain", host: "acme.example.org", path: "/users/7", status: http.StatusOK, body: "apex 7"},
+				{name: "host route miss", host: "acme.example.com", path: "/missing", status: http.StatusNotFound, tenant: "acme"},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					req := httptest.NewRequest("GET", tt.path, nil)
+					req.Host = tt.host
+
+					w := httptest.NewRecorder()
+					r.ServeHTTP(w, req)
+
+					assert.Equal(t, tt.status, w.Code, "status code should match expected")
+					if tt.body != "" {
+						assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+					}
+					assert.Equal(t, tt.tenant, w.Header().Get("X-Tenant"), "middleware should see host params")
+				})
+			}
+		})
+	}
+}
+
+func TestHostClone(t *testing.T) {
+	r := chu.New()
+	r.Host("{tenant}.example.com", func(r *chu.Router) {
+		r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := io.WriteString(w, chu.URLParam(r, "tenant"))
+			return err
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Host = "acme.example.com"
+
+	w := httptest.NewRecorder()
+	r.Clone().ServeHTTP(w, req)
+
+	assert.Equal(t, "acme", w.Body.String(), "clone should keep host routes")
+}
+
+func TestHostMixedCase(t *testing.T) {
+	r := chu.New()
+	r.Host("{tenantID}.API.Example.com", func(r *chu.Router) {
+		r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := io.WriteString(w, chu.URLParam(r, "tenantID"))
+			return err
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Host = "Acme.api.example.COM"
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "acme", w.Body.String(), "param name should keep its case while labels ignore it")
+}
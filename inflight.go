@@ -0,0 +1,92 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// inflight counts requests with an atomic, taking the lock only when the
+// count moves between zero and one to open or close the idle channel.
+type inflight struct {
+	n atomic.Int64
+
+	mu   sync.Mutex
+	idle chan struct{}
+}
+
+func newInflight() *inflight {
+	idle := make(chan struct{})
+	close(idle)
+
+	return &inflight{idle: idle}
+}
+
+func (f *inflight) add() {
+	if f.n.Add(1) == 1 {
+		f.transition()
+	}
+}
+
+func (f *inflight) done() {
+	if f.n.Add(-1) == 0 {
+		f.transition()
+	}
+}
+
+// transition matches the idle channel to the current count. Every change
+// between zero and one is followed by a transition, so the last one to run
+// sees the final count.
+func (f *inflight) transition() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	select {
+	case <-f.idle:
+		if f.n.Load() > 0 {
+			f.idle = make(chan struct{})
+		}
+	default:
+		if f.n.Load() == 0 {
+			close(f.idle)
+		}
+	}
+}
+
+func (f *inflight) count() int64 {
+	return f.n.Load()
+}
+
+func (f *inflight) wait(ctx context.Context) error {
+	for f.n.Load() > 0 {
+		f.mu.Lock()
+		idle := f.idle
+		f.mu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (f *inflight) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.add()
+		defer f.done()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (r *Router) InFlight() int64 {
+	return r.inflight.count()
+}
+
+func (r *Router) Drain(ctx context.Context) error {
+	return r.inflight.wait(ctx)
+}
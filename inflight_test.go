@@ -0,0 +1,63 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_InFlightAndDrain(t *testing.T) {
+	r := chu.New()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var uploads *chu.Router
+	r.Group(func(g *chu.Router) {
+		uploads = g
+		g.Post("/upload", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			close(started)
+			<-release
+			return nil
+		})
+	})
+
+	var api *chu.Router
+	r.Group(func(g *chu.Router) {
+		api = g
+		g.Get("/ping", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return nil
+		})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/upload", nil))
+	}()
+	<-started
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ping", nil))
+
+	assert.Equal(t, int64(1), r.InFlight(), "root router should track the upload")
+	assert.Equal(t, int64(1), uploads.InFlight(), "upload group should track the upload")
+	assert.Equal(t, int64(0), api.InFlight(), "api group should be idle")
+
+	require.NoError(t, api.Drain(context.Background()), "draining an idle group should return immediately")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, uploads.Drain(ctx), context.DeadlineExceeded, "draining a busy group should respect the context")
+
+	close(release)
+	require.NoError(t, uploads.Drain(context.Background()), "draining should finish once the upload completes")
+	<-done
+
+	assert.Equal(t, int64(0), r.InFlight(), "root router should be idle")
+}
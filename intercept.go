@@ -0,0 +1,108 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// Response is a buffered response captured by Intercept.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type Interceptor func(ctx context.Context, r *http.Request, res *Response) error
+
+// Intercept buffers the response written by the next handler so fn can inspect
+// and modify status, headers and body before anything reaches the client. If
+// the handler fails, the buffered output is discarded and the error returned.
+// Buffering defeats streaming, so only use it on routes with bounded bodies.
+// Trailers are known once the body is buffered, so they are sent as headers.
+func Intercept(fn Interceptor) func(Handler) Handler {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			bw := &bufferedWriter{w: w, res: Response{Header: w.Header().Clone()}}
+
+			if err := next(ctx, bw, r); err != nil {
+				return err
+			}
+
+			if bw.res.Status == 0 {
+				bw.res.Status = http.StatusOK
+			}
+
+			promoteTrailers(bw.res.Header)
+
+			if err := fn(ctx, r, &bw.res); err != nil {
+				return err
+			}
+
+			return bw.flush(r.Method == http.MethodHead)
+		}
+	}
+}
+
+type bufferedWriter struct {
+	w   http.ResponseWriter
+	res Response
+}
+
+func (b *bufferedWriter) Header() http.Header {
+	return b.res.Header
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		copyHeader(b.w.Header(), b.res.Header)
+		b.w.WriteHeader(status)
+		return
+	}
+
+	if b.res.Status == 0 {
+		b.res.Status = status
+	}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	if b.res.Status == 0 {
+		b.res.Status = http.StatusOK
+	}
+
+	b.res.Body = append(b.res.Body, p...)
+	return len(p), nil
+}
+
+func (b *bufferedWriter) flush(head bool) error {
+	copyHeader(b.w.Header(), b.res.Header)
+
+	if bodyAllowed(b.res.Status) && !(head && len(b.res.Body) == 0) {
+		b.w.Header().Set("Content-Length", strconv.Itoa(len(b.res.Body)))
+	}
+
+	b.w.WriteHeader(b.res.Status)
+
+	if len(b.res.Body) == 0 {
+		return nil
+	}
+
+	_, err := b.w.Write(b.res.Body)
+	return err
+}
+
+func copyHeader(dst, src http.Header) {
+	for name := range dst {
+		if _, ok := src[name]; !ok {
+			delete(dst, name)
+		}
+	}
+
+	for name, values := range src {
+		dst[name] = values
+	}
+}
+
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified && status >= 200
+}
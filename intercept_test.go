@@ -0,0 +1,101 @@
+package chu_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntercept(t *testing.T) {
+	upper := chu.Intercept(func(ctx context.Context, r *http.Request, res *chu.Response) error {
+		if res.Status == http.StatusCreated {
+			res.Header.Set("Location", "/items/1")
+		}
+
+		res.Header.Del("X-Internal")
+		res.Header.Set("X-Body-Length", "changed")
+		res.Body = bytes.ToUpper(res.Body)
+
+		if r.URL.Query().Has("reject") {
+			return chu.Errorf(http.StatusForbidden, "rejected by interceptor")
+		}
+
+		return nil
+	})
+
+	r := chu.New()
+	r.Post("/items", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Internal", "secret")
+		w.Header().Set("Content-Length", "5")
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte("hello world"))
+		return err
+	}, chu.WithMiddleware(upper))
+	r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("partial output"))
+		return chu.Errorf(http.StatusConflict, "conflict")
+	}, chu.WithMiddleware(upper))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+		header map[string]string
+	}{
+		{
+			name: "modifies response", method: http.MethodPost, path: "/items", status: http.StatusCreated, body: "HELLO WORLD",
+			header: map[string]string{"Location": "/items/1", "X-Internal": "", "Content-Length": "11", "X-Body-Length": "changed"},
+		},
+		{name: "handler error discards buffer", method: http.MethodGet, path: "/fail", status: http.StatusConflict, body: "conflict\n"},
+		{name: "interceptor error", method: http.MethodPost, path: "/items?reject", status: http.StatusForbidden, body: "rejected by interceptor\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.status, rec.Code, "status code should match expected")
+			assert.Equal(t, tt.body, rec.Body.String(), "body should match expected")
+			for name, value := range tt.header {
+				assert.Equal(t, value, rec.Header().Get(name), "header %s should match expected", name)
+			}
+		})
+	}
+}
+
+func TestInterceptUse(t *testing.T) {
+	var seen []int
+
+	r := chu.New()
+	r.Use(chu.Intercept(func(ctx context.Context, r *http.Request, res *chu.Response) error {
+		seen = append(seen, res.Status)
+		res.Header.Set("X-Seen", "yes")
+		return nil
+	}))
+	r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	})
+	r.Head("/head", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Length", "42")
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code, "status code should match expected")
+	assert.Equal(t, "yes", rec.Header().Get("X-Seen"), "error responses should be intercepted under Use")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/head", nil))
+	assert.Equal(t, "42", rec.Header().Get("Content-Length"), "head content length should be preserved")
+	assert.Equal(t, []int{http.StatusInternalServerError, http.StatusOK}, seen, "interceptor should see final statuses")
+}
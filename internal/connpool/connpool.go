@@ -0,0 +1,124 @@
+// Package connpool is the small connection pool shared by the network store
+// adapters.
+package connpool
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+var ErrClosed = errors.New("connpool: pool closed")
+
+type Conn struct {
+	net.Conn
+	R *bufio.Reader
+	W *bufio.Writer
+}
+
+type Pool struct {
+	dial    func(ctx context.Context) (net.Conn, error)
+	init    func(c *Conn) error
+	timeout time.Duration
+	idle    chan *Conn
+	sem     chan struct{}
+	done    chan struct{}
+}
+
+// New returns a pool of at most size connections. init runs on every new
+// connection, e.g. to authenticate. timeout bounds each use when the context
+// has no earlier deadline.
+func New(size int, timeout time.Duration, dial func(ctx context.Context) (net.Conn, error), init func(c *Conn) error) *Pool {
+	return &Pool{
+		dial:    dial,
+		init:    init,
+		timeout: timeout,
+		idle:    make(chan *Conn, size),
+		sem:     make(chan struct{}, size),
+		done:    make(chan struct{}),
+	}
+}
+
+// Do runs fn on a pooled connection. The connection is discarded when fn
+// returns an error, since the protocol state is then unknown.
+func (p *Pool) Do(ctx context.Context, fn func(c *Conn) error) error {
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.done:
+		return ErrClosed
+	}
+	defer func() { <-p.sem }()
+
+	c, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = c.SetDeadline(deadline)
+
+	if err := fn(c); err != nil {
+		c.Close()
+		return err
+	}
+
+	select {
+	case p.idle <- c:
+	default:
+		c.Close()
+	}
+
+	return nil
+}
+
+func (p *Pool) get(ctx context.Context) (*Conn, error) {
+	select {
+	case c := <-p.idle:
+		return c, nil
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	nc, err := p.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{Conn: nc, R: bufio.NewReader(nc), W: bufio.NewWriter(nc)}
+	if p.init != nil {
+		_ = c.SetDeadline(time.Now().Add(p.timeout))
+		if err := p.init(c); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (p *Pool) Close() error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+		close(p.done)
+	}
+
+	for {
+		select {
+		case c := <-p.idle:
+			c.Close()
+		default:
+			return nil
+		}
+	}
+}
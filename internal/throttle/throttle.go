@@ -0,0 +1,116 @@
+// Package throttle is the token bucket shared by the bandwidth limits of
+// chu.Attachment and the Bandwidth middleware.
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bucket limits a transfer to rate bytes per second, allowing bursts of up
+// to burst bytes. It is safe for concurrent use, so transfers sharing a
+// bucket share its rate.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// New returns a full bucket. A non-positive burst defaults to a tenth of
+// rate, and a nil now to time.Now.
+func New(rate, burst int64, now func() time.Time) *Bucket {
+	if burst <= 0 {
+		burst = max(rate/10, 1)
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	return &Bucket{rate: float64(rate), burst: burst, tokens: float64(burst), last: now(), now: now}
+}
+
+// Burst returns the largest number of bytes the bucket releases at once.
+func (b *Bucket) Burst() int64 {
+	return b.burst
+}
+
+// Drain empties the bucket, so a transfer is paced from its first byte.
+func (b *Bucket) Drain() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = 0
+	b.last = b.now()
+}
+
+func (b *Bucket) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, float64(b.burst))
+	b.last = now
+	b.tokens -= float64(n)
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// Wait takes n bytes from the bucket, blocking until they are available or
+// ctx is done.
+func (b *Bucket) Wait(ctx context.Context, n int) error {
+	delay := b.reserve(n)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Idle reports whether the bucket has refilled by now, so it can be dropped
+// and recreated without changing the rate seen by its transfers.
+func (b *Bucket) Idle(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= float64(b.burst)
+}
+
+// Write passes p to write in chunks of at most the burst, waiting for each
+// chunk's tokens first.
+func (b *Bucket) Write(ctx context.Context, p []byte, write func(p []byte) (int, error)) (int, error) {
+	var total int
+	for len(p) > 0 {
+		chunk := p[:min(int64(len(p)), b.burst)]
+
+		if err := b.Wait(ctx, len(chunk)); err != nil {
+			return total, err
+		}
+
+		n, err := write(chunk)
+		total += n
+		if err != nil {
+			return total, err
+		}
+
+		p = p[len(chunk):]
+	}
+
+	return total, nil
+}
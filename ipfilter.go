@@ -0,0 +1,89 @@
+package chu
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+var ErrIPBlocked = errors.New("chu: client address blocked")
+
+// IPFilter is a concurrency safe set of blocked client addresses. Entries can
+// expire, which makes it suitable as a target for automated detection such as
+// Honeypot.
+type IPFilter struct {
+	mu      sync.RWMutex
+	blocked map[netip.Addr]time.Time
+	now     func() time.Time
+}
+
+func NewIPFilter() *IPFilter {
+	return &IPFilter{blocked: make(map[netip.Addr]time.Time), now: time.Now}
+}
+
+// Block blocks ip for d. A non-positive d blocks it until Unblock is called.
+func (f *IPFilter) Block(ip netip.Addr, d time.Duration) {
+	var until time.Time
+	if d > 0 {
+		until = f.now().Add(d)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.blocked[ip.Unmap()] = until
+}
+
+func (f *IPFilter) Unblock(ip netip.Addr) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.blocked, ip.Unmap())
+}
+
+func (f *IPFilter) Blocked(ip netip.Addr) bool {
+	f.mu.RLock()
+	until, ok := f.blocked[ip.Unmap()]
+	f.mu.RUnlock()
+
+	if !ok {
+		return false
+	}
+
+	if !until.IsZero() && !f.now().Before(until) {
+		f.Unblock(ip)
+		return false
+	}
+
+	return true
+}
+
+// Middleware rejects requests from blocked addresses with 403 Forbidden. The
+// address is taken from r.RemoteAddr.
+func (f *IPFilter) Middleware(next Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if ip, ok := remoteIP(r); ok && f.Blocked(ip) {
+			return NewError(http.StatusForbidden, ErrIPBlocked)
+		}
+
+		return next(ctx, w, r)
+	}
+}
+
+func remoteIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return ip.Unmap(), true
+}
@@ -0,0 +1,86 @@
+package chu
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type lifecycleHook struct {
+	start bool
+	fn    func(ctx context.Context) error
+}
+
+type lifecycle struct {
+	mu    sync.Mutex
+	hooks []lifecycleHook
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{}
+}
+
+// OnStart registers fn to run, in registration order, before Serve starts
+// accepting connections. The first failing hook aborts startup.
+func (r *Router) OnStart(fn func(ctx context.Context) error) {
+	r.lifecycle.add(lifecycleHook{start: true, fn: fn})
+}
+
+// OnStop registers fn to run after the server has shut down. Stop hooks run in
+// reverse registration order and all of them run even if some fail.
+func (r *Router) OnStop(fn func(ctx context.Context) error) {
+	r.lifecycle.add(lifecycleHook{fn: fn})
+}
+
+func (l *lifecycle) add(h lifecycleHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, h)
+}
+
+func (l *lifecycle) snapshot() []lifecycleHook {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]lifecycleHook(nil), l.hooks...)
+}
+
+// start runs the start hooks and returns a function that runs the stop hooks
+// registered before the point startup reached.
+func (l *lifecycle) start(ctx context.Context) (func(ctx context.Context) error, error) {
+	hooks := l.snapshot()
+
+	for i, h := range hooks {
+		if !h.start {
+			continue
+		}
+
+		if err := h.fn(ctx); err != nil {
+			return nil, errors.Join(err, runStopHooks(context.WithoutCancel(ctx), hooks[:i]))
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return runStopHooks(ctx, hooks)
+	}, nil
+}
+
+func runStopHooks(ctx context.Context, hooks []lifecycleHook) error {
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].start {
+			continue
+		}
+
+		if err := hooks[i].fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
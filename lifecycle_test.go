@@ -0,0 +1,96 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *hookLog) hook(name string, err error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.calls = append(l.calls, name)
+		return err
+	}
+}
+
+func (l *hookLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.calls...)
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	var log hookLog
+	errClose := errors.New("cache close failed")
+
+	r := chu.New()
+	r.OnStart(log.hook("start db", nil))
+	r.OnStop(log.hook("stop db", nil))
+	r.Route("/api", func(r *chu.Router) {
+		r.OnStart(log.hook("start cache", nil))
+		r.OnStop(log.hook("stop cache", errClose))
+	})
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	addr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- chu.Serve(ctx, addr, r)
+	}()
+
+	waitForServer(t, addr)
+	assert.Equal(t, []string{"start db", "start cache"}, log.get(), "start hooks should run in order before serving")
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, errClose, "stop hook errors should be returned")
+	case <-time.After(2 * time.Second):
+		t.Fatal("server should shut down")
+	}
+
+	assert.Equal(t, []string{"start db", "start cache", "stop cache", "stop db"}, log.get(), "stop hooks should run in reverse order")
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	var log hookLog
+	errStart := errors.New("queue unreachable")
+
+	r := chu.New()
+	r.OnStart(log.hook("start db", nil))
+	r.OnStop(log.hook("stop db", nil))
+	r.OnStart(log.hook("start queue", errStart))
+	r.OnStop(log.hook("stop queue", nil))
+
+	addr := freeAddr(t)
+	err := chu.Serve(context.Background(), addr, r)
+
+	require.ErrorIs(t, err, errStart, "start error should be returned")
+	assert.Equal(t, []string{"start db", "start queue", "stop db"}, log.get(), "only components started before the failure should stop")
+
+	ln, err := net.Listen("tcp", addr)
+	require.NoError(t, err, "listener should be released after a failed start")
+	_ = ln.Close()
+}
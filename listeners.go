@@ -0,0 +1,126 @@
+package chu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const systemdListenFdsStart = 3
+
+var listenerCtxKey = &contextKey{"listener"}
+
+type Listener struct {
+	Name        string
+	Listener    net.Listener
+	Middlewares []func(Handler) Handler
+}
+
+func ServeListeners(ctx context.Context, h http.Handler, listeners []Listener, opts ...ServerOption) error {
+	if len(listeners) == 0 {
+		return errors.New("chu: no listeners to serve")
+	}
+
+	cfg := newServerConfig(opts)
+
+	units := make([]serveUnit, len(listeners))
+	for i, l := range listeners {
+		l := l
+
+		srv := cfg.newServer(l.Listener.Addr().String(), l.handler(h))
+		srv.BaseContext = func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), listenerCtxKey, l.Name)
+		}
+
+		units[i] = serveUnit{srv: srv, serve: func() error { return srv.Serve(l.Listener) }}
+	}
+
+	return cfg.run(ctx, h, units...)
+}
+
+func (l Listener) handler(h http.Handler) http.Handler {
+	if len(l.Middlewares) == 0 {
+		return h
+	}
+
+	errHandler := ErrorHandler(defaultErrorHandler)
+	if r, ok := h.(*Router); ok {
+		errHandler = r.errorHandler()
+	}
+
+	chained := StandardHandler(h.ServeHTTP)
+	for i := len(l.Middlewares) - 1; i >= 0; i-- {
+		chained = l.Middlewares[i](chained)
+	}
+
+	return AdaptHandler(chained, errHandler)
+}
+
+func ListenerName(ctx context.Context) string {
+	name, _ := ctx.Value(listenerCtxKey).(string)
+	return name
+}
+
+func ListenTCP(name, addr string, middlewares ...func(Handler) Handler) (Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return Listener{}, err
+	}
+
+	return Listener{Name: name, Listener: ln, Middlewares: middlewares}, nil
+}
+
+func ListenUnix(name, path string, middlewares ...func(Handler) Handler) (Listener, error) {
+	if info, err := os.Stat(path); err == nil && info.Mode()&fs.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return Listener{}, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return Listener{}, err
+	}
+
+	return Listener{Name: name, Listener: ln, Middlewares: middlewares}, nil
+}
+
+func SystemdListeners() ([]Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return nil, fmt.Errorf("chu: invalid LISTEN_FDS: %w", err)
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := make([]Listener, 0, count)
+	for i := 0; i < count; i++ {
+		name := "systemd-" + strconv.Itoa(i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		f := os.NewFile(uintptr(systemdListenFdsStart+i), name)
+
+		ln, err := net.FileListener(f)
+		_ = f.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("chu: systemd listener %s: %w", name, err)
+		}
+
+		listeners = append(listeners, Listener{Name: name, Listener: ln})
+	}
+
+	return listeners, nil
+}
@@ -0,0 +1,93 @@
+package chu_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeListeners(t *testing.T) {
+	requireToken := func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				return chu.Errorf(http.StatusUnauthorized, "unauthorized")
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+
+	r := chu.New()
+	r.Get("/whoami", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte(chu.ListenerName(ctx)))
+		return nil
+	})
+
+	public, err := chu.ListenTCP("public", "127.0.0.1:0", requireToken)
+	require.NoError(t, err, "should listen on TCP")
+
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	admin, err := chu.ListenUnix("admin", socket)
+	require.NoError(t, err, "should listen on unix socket")
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- chu.ServeListeners(ctx, r, []chu.Listener{public, admin}) }()
+
+	get := func(client *http.Client, url string, header string) (int, string) {
+		req, err := http.NewRequest("GET", url, nil)
+		require.NoError(t, err, "should build request")
+
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err, "request should succeed")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "should read body")
+
+		return resp.StatusCode, string(body)
+	}
+
+	publicURL := "http://" + public.Listener.Addr().String() + "/whoami"
+
+	status, _ := get(http.DefaultClient, publicURL, "")
+	assert.Equal(t, http.StatusUnauthorized, status, "public listener should require a token")
+
+	status, body := get(http.DefaultClient, publicURL, "Bearer token")
+	assert.Equal(t, http.StatusOK, status, "public listener should accept a token")
+	assert.Equal(t, "public", body, "handler should see the public listener name")
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	status, body = get(unixClient, "http://admin/whoami", "")
+	assert.Equal(t, http.StatusOK, status, "admin listener should skip auth")
+	assert.Equal(t, "admin", body, "handler should see the admin listener name")
+
+	cancel()
+	assert.NoError(t, <-errCh, "server should shut down cleanly")
+}
+
+func TestSystemdListeners_NotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+
+	listeners, err := chu.SystemdListeners()
+
+	assert.NoError(t, err, "missing activation should not be an error")
+	assert.Empty(t, listeners, "no listeners should be returned")
+}
@@ -0,0 +1,288 @@
+// Package memcachestore implements chu.Store on top of memcached using the
+// text protocol directly, without a client library dependency.
+package memcachestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/internal/connpool"
+)
+
+// Expirations longer than this are sent as absolute Unix times, as the
+// protocol requires.
+const maxRelativeExpiry = 30 * 24 * time.Hour
+
+type Options struct {
+	Addr string
+	// Prefix is prepended to every key. Keys that are too long or contain
+	// characters memcached rejects are hashed.
+	Prefix   string
+	PoolSize int
+	Timeout  time.Duration
+	Dial     func(ctx context.Context, network, addr string) (net.Conn, error)
+	Now      func() time.Time
+}
+
+type Store struct {
+	prefix string
+	now    func() time.Time
+	pool   *connpool.Pool
+}
+
+var _ chu.Store = (*Store)(nil)
+
+// Error is an error reply from the server.
+type Error string
+
+func (e Error) Error() string {
+	return "memcached: " + string(e)
+}
+
+var errNotFound = errors.New("memcached: not found")
+
+func New(opts Options) *Store {
+	if opts.Addr == "" {
+		opts.Addr = "localhost:11211"
+	}
+
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	if opts.Dial == nil {
+		opts.Dial = (&net.Dialer{}).DialContext
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return opts.Dial(ctx, "tcp", opts.Addr)
+	}
+
+	return &Store{prefix: opts.Prefix, now: opts.Now, pool: connpool.New(opts.PoolSize, opts.Timeout, dial, nil)}
+}
+
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+
+	err := s.pool.Do(ctx, func(c *connpool.Conn) error {
+		fmt.Fprintf(c.W, "get %s\r\n", s.key(key))
+		if err := c.W.Flush(); err != nil {
+			return err
+		}
+
+		line, err := readLine(c)
+		if err != nil {
+			return err
+		}
+
+		if line == "END" {
+			return nil
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != "VALUE" {
+			return fmt.Errorf("memcached: unexpected reply %q", line)
+		}
+
+		size, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return fmt.Errorf("memcached: unexpected reply %q", line)
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.R, buf); err != nil {
+			return err
+		}
+		value = buf[:size]
+
+		if line, err = readLine(c); err != nil {
+			return err
+		}
+
+		if line != "END" {
+			return fmt.Errorf("memcached: unexpected reply %q", line)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, chu.ErrStoreMiss
+	}
+
+	return value, nil
+}
+
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	reply, err := s.store(ctx, "set", s.key(key), value, ttl)
+	if err != nil {
+		return err
+	}
+
+	if reply != "STORED" {
+		return Error(reply)
+	}
+
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	reply, err := s.command(ctx, "delete "+s.key(key))
+	if err != nil {
+		return err
+	}
+
+	if reply != "DELETED" && reply != "NOT_FOUND" {
+		return Error(reply)
+	}
+
+	return nil
+}
+
+// Increment adds n to the counter. memcached clamps decrements at zero, so
+// counters cannot go negative.
+func (s *Store) Increment(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	key = s.key(key)
+
+	for {
+		value, err := s.incr(ctx, key, n)
+		if !errors.Is(err, errNotFound) {
+			return value, err
+		}
+
+		// The counter does not exist yet: create it, and retry the increment if
+		// another client created it first.
+		reply, err := s.store(ctx, "add", key, []byte(strconv.FormatInt(max(n, 0), 10)), ttl)
+		if err != nil {
+			return 0, err
+		}
+
+		switch reply {
+		case "STORED":
+			return max(n, 0), nil
+		case "NOT_STORED":
+			continue
+		default:
+			return 0, Error(reply)
+		}
+	}
+}
+
+func (s *Store) Close() error {
+	return s.pool.Close()
+}
+
+func (s *Store) incr(ctx context.Context, key string, n int64) (int64, error) {
+	cmd := "incr"
+	if n < 0 {
+		cmd, n = "decr", -n
+	}
+
+	reply, err := s.command(ctx, cmd+" "+key+" "+strconv.FormatInt(n, 10))
+	if err != nil {
+		return 0, err
+	}
+
+	if reply == "NOT_FOUND" {
+		return 0, errNotFound
+	}
+
+	value, err := strconv.ParseInt(reply, 10, 64)
+	if err != nil {
+		return 0, Error(reply)
+	}
+
+	return value, nil
+}
+
+func (s *Store) store(ctx context.Context, cmd, key string, value []byte, ttl time.Duration) (string, error) {
+	var reply string
+
+	err := s.pool.Do(ctx, func(c *connpool.Conn) error {
+		fmt.Fprintf(c.W, "%s %s 0 %d %d\r\n", cmd, key, s.expiry(ttl), len(value))
+		c.W.Write(value)
+		c.W.WriteString("\r\n")
+		if err := c.W.Flush(); err != nil {
+			return err
+		}
+
+		var err error
+		reply, err = readLine(c)
+		return err
+	})
+
+	return reply, err
+}
+
+func (s *Store) command(ctx context.Context, line string) (string, error) {
+	var reply string
+
+	err := s.pool.Do(ctx, func(c *connpool.Conn) error {
+		c.W.WriteString(line + "\r\n")
+		if err := c.W.Flush(); err != nil {
+			return err
+		}
+
+		var err error
+		reply, err = readLine(c)
+		return err
+	})
+
+	return reply, err
+}
+
+func (s *Store) expiry(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+
+	if ttl > maxRelativeExpiry {
+		return s.now().Add(ttl).Unix()
+	}
+
+	return int64(max(time.Duration(1), (ttl+time.Second-1)/time.Second))
+}
+
+func (s *Store) key(key string) string {
+	key = s.prefix + key
+	if len(key) <= 250 && !strings.ContainsFunc(key, func(r rune) bool { return r <= ' ' || r == 0x7f }) {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return s.prefix + hex.EncodeToString(sum[:])
+}
+
+func readLine(c *connpool.Conn) (string, error) {
+	line, err := c.R.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "ERROR" || strings.HasPrefix(line, "CLIENT_ERROR ") || strings.HasPrefix(line, "SERVER_ERROR ") {
+		return "", Error(line)
+	}
+
+	return line, nil
+}
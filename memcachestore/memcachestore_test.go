@@ -0,0 +1,171 @@
+package memcachestore_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/memcachestore"
+)
+
+type fakeMemcached struct {
+	mu     sync.Mutex
+	data   map[string]string
+	expiry map[string]int64
+}
+
+func newFakeMemcached(t *testing.T) (string, *fakeMemcached) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeMemcached{data: make(map[string]string), expiry: make(map[string]int64)}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return ln.Addr().String(), f
+}
+
+func (f *fakeMemcached) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+
+		var value string
+		if fields[0] == "set" || fields[0] == "add" {
+			size, _ := strconv.Atoi(fields[4])
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			value = string(buf[:size])
+		}
+
+		f.mu.Lock()
+		reply := f.handle(fields, value)
+		f.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeMemcached) handle(fields []string, value string) string {
+	key := fields[1]
+	current, exists := f.data[key]
+
+	switch fields[0] {
+	case "get":
+		if !exists {
+			return "END\r\n"
+		}
+		return "VALUE " + key + " 0 " + strconv.Itoa(len(current)) + "\r\n" + current + "\r\nEND\r\n"
+	case "add":
+		if exists {
+			return "NOT_STORED\r\n"
+		}
+		fallthrough
+	case "set":
+		f.data[key] = value
+		f.expiry[key], _ = strconv.ParseInt(fields[3], 10, 64)
+		return "STORED\r\n"
+	case "delete":
+		if !exists {
+			return "NOT_FOUND\r\n"
+		}
+		delete(f.data, key)
+		return "DELETED\r\n"
+	case "incr", "decr":
+		if !exists {
+			return "NOT_FOUND\r\n"
+		}
+		n, err := strconv.ParseInt(current, 10, 64)
+		if err != nil {
+			return "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n"
+		}
+		delta, _ := strconv.ParseInt(fields[2], 10, 64)
+		if fields[0] == "decr" {
+			n = max(n-delta, 0)
+		} else {
+			n += delta
+		}
+		f.data[key] = strconv.FormatInt(n, 10)
+		return f.data[key] + "\r\n"
+	default:
+		return "ERROR\r\n"
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	addr, fake := newFakeMemcached(t)
+	s := memcachestore.New(memcachestore.Options{Addr: addr, Prefix: "app:", Now: func() time.Time { return now }})
+	t.Cleanup(func() { s.Close() })
+
+	_, err := s.Get(ctx, "missing")
+	assert.ErrorIs(t, err, chu.ErrStoreMiss, "missing key should be a miss")
+
+	require.NoError(t, s.Set(ctx, "greeting", []byte("hello\r\nworld"), 1500*time.Millisecond))
+
+	value, err := s.Get(ctx, "greeting")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\r\nworld", string(value), "value should round trip")
+	assert.Equal(t, int64(2), fake.expiry["app:greeting"], "ttl should be rounded up to seconds")
+
+	require.NoError(t, s.Set(ctx, "long", []byte("x"), 60*24*time.Hour))
+	assert.Equal(t, now.Add(60*24*time.Hour).Unix(), fake.expiry["app:long"], "long ttl should be absolute")
+
+	require.NoError(t, s.Delete(ctx, "greeting"))
+	_, err = s.Get(ctx, "greeting")
+	assert.ErrorIs(t, err, chu.ErrStoreMiss, "deleted key should be a miss")
+	require.NoError(t, s.Delete(ctx, "greeting"), "deleting a missing key should succeed")
+
+	n, err := s.Increment(ctx, "hits", 3, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n, "new counter should start at the increment")
+
+	n, err = s.Increment(ctx, "hits", -1, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n, "negative increments should decrement")
+
+	require.NoError(t, s.Set(ctx, "name", []byte("chu"), 0))
+	_, err = s.Increment(ctx, "name", 1, 0)
+	var replyErr memcachestore.Error
+	assert.ErrorAs(t, err, &replyErr, "server errors should be returned")
+
+	long := strings.Repeat("k", 300)
+	require.NoError(t, s.Set(ctx, long, []byte("v"), 0))
+	value, err = s.Get(ctx, long)
+	require.NoError(t, err)
+	assert.Equal(t, "v", string(value), "long keys should be hashed transparently")
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/josearomeroj/chu"
+)
+
+var ErrConcurrencyLimit = errors.New("concurrency limit exceeded")
+
+type AdaptiveStats struct {
+	Limit       int
+	InFlight    int
+	Accepted    uint64
+	Rejected    uint64
+	Drops       uint64
+	LastLatency time.Duration
+}
+
+type AdaptiveOptions struct {
+	InitialLimit     int
+	MinLimit         int
+	MaxLimit         int
+	LatencyThreshold time.Duration
+	BackoffRatio     float64
+	OnChange         func(stats AdaptiveStats)
+	Now              func() time.Time
+}
+
+type AdaptiveLimiter struct {
+	opts AdaptiveOptions
+
+	mu    sync.Mutex
+	limit float64
+	stats AdaptiveStats
+}
+
+func NewAdaptiveLimiter(opts AdaptiveOptions) *AdaptiveLimiter {
+	if opts.MinLimit <= 0 {
+		opts.MinLimit = 1
+	}
+
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = 1000
+	}
+
+	if opts.InitialLimit <= 0 {
+		opts.InitialLimit = 20
+	}
+
+	if opts.LatencyThreshold <= 0 {
+		opts.LatencyThreshold = time.Second
+	}
+
+	if opts.BackoffRatio <= 0 || opts.BackoffRatio >= 1 {
+		opts.BackoffRatio = 0.9
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	l := &AdaptiveLimiter{opts: opts, limit: float64(opts.InitialLimit)}
+	l.stats.Limit = opts.InitialLimit
+
+	return l
+}
+
+func (l *AdaptiveLimiter) Stats() AdaptiveStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+func (l *AdaptiveLimiter) Middleware(next chu.Handler) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) (err error) {
+		if !l.acquire() {
+			return chu.NewError(http.StatusServiceUnavailable, ErrConcurrencyLimit)
+		}
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := l.opts.Now()
+
+		// A panicking handler still releases its slot, and counts as failed.
+		panicked := true
+		defer func() {
+			failed := panicked || ww.Status() >= 500 || (err != nil && chu.StatusCode(err) >= 500)
+			l.release(l.opts.Now().Sub(start), failed)
+		}()
+
+		err = next(ctx, ww, r)
+		panicked = false
+
+		return err
+	}
+}
+
+func (l *AdaptiveLimiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stats.InFlight >= l.stats.Limit {
+		l.stats.Rejected++
+		return false
+	}
+
+	l.stats.InFlight++
+	l.stats.Accepted++
+
+	return true
+}
+
+func (l *AdaptiveLimiter) release(latency time.Duration, failed bool) {
+	l.mu.Lock()
+
+	inflight := l.stats.InFlight
+	l.stats.InFlight--
+	l.stats.LastLatency = latency
+
+	switch {
+	case failed || latency > l.opts.LatencyThreshold:
+		l.stats.Drops++
+		l.limit *= l.opts.BackoffRatio
+	case inflight*2 >= l.stats.Limit:
+		l.limit++
+	}
+
+	l.limit = math.Max(float64(l.opts.MinLimit), math.Min(float64(l.opts.MaxLimit), l.limit))
+
+	changed := int(l.limit) != l.stats.Limit
+	l.stats.Limit = int(l.limit)
+	stats := l.stats
+
+	l.mu.Unlock()
+
+	if changed && l.opts.OnChange != nil {
+		l.opts.OnChange(stats)
+	}
+}
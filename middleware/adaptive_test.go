@@ -0,0 +1,123 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	latency := 10 * time.Millisecond
+
+	var changes []int
+
+	limiter := middleware.NewAdaptiveLimiter(middleware.AdaptiveOptions{
+		InitialLimit:     2,
+		MinLimit:         1,
+		MaxLimit:         3,
+		LatencyThreshold: 100 * time.Millisecond,
+		BackoffRatio:     0.5,
+		OnChange:         func(stats middleware.AdaptiveStats) { changes = append(changes, stats.Limit) },
+		Now:              func() time.Time { return now },
+	})
+
+	r := chu.New()
+	r.Use(limiter.Middleware)
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		now = now.Add(latency)
+		return nil
+	})
+	r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.Errorf(http.StatusBadGateway, "upstream failed")
+	})
+
+	call := func(path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		return w.Code
+	}
+
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusOK, call("/"), "status code should match expected")
+	}
+
+	assert.Equal(t, 3, limiter.Stats().Limit, "limit should grow additively while utilisation is high")
+
+	latency = time.Second
+	call("/")
+	assert.Equal(t, 1, limiter.Stats().Limit, "slow responses should back off multiplicatively")
+
+	latency = 10 * time.Millisecond
+	call("/")
+	assert.Equal(t, 2, limiter.Stats().Limit, "limit should recover once latency is healthy")
+
+	assert.Equal(t, http.StatusBadGateway, call("/fail"), "status code should match expected")
+	assert.Equal(t, 1, limiter.Stats().Limit, "server errors should back off")
+
+	call("/fail")
+	assert.Equal(t, 1, limiter.Stats().Limit, "limit should not drop below the min")
+
+	stats := limiter.Stats()
+	assert.Equal(t, uint64(3), stats.Drops, "drops should be counted")
+	assert.Equal(t, uint64(9), stats.Accepted, "accepted requests should be counted")
+	assert.Equal(t, 0, stats.InFlight, "no requests should be in flight")
+	assert.Equal(t, []int{3, 1, 2, 1}, changes, "limit changes should be reported")
+}
+
+func TestAdaptiveLimiter_Rejects(t *testing.T) {
+	limiter := middleware.NewAdaptiveLimiter(middleware.AdaptiveOptions{InitialLimit: 1, MaxLimit: 1})
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	r := chu.New()
+	r.Use(limiter.Middleware)
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		close(done)
+	}()
+
+	<-started
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "status code should match expected")
+	assert.Equal(t, uint64(1), limiter.Stats().Rejected, "rejections should be counted")
+
+	close(release)
+	<-done
+}
+
+func TestAdaptiveLimiter_Panic(t *testing.T) {
+	limiter := middleware.NewAdaptiveLimiter(middleware.AdaptiveOptions{InitialLimit: 1, MinLimit: 1})
+
+	h := limiter.Middleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	})
+
+	for range 3 {
+		assert.Panics(t, func() {
+			_ = h(context.Background(), httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}, "panic should propagate")
+	}
+
+	stats := limiter.Stats()
+	assert.Equal(t, 0, stats.InFlight, "panicking requests should release their slot")
+	assert.Equal(t, uint64(3), stats.Accepted, "requests should not be rejected after panics")
+	assert.Equal(t, uint64(3), stats.Drops, "panics should count as failures")
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/josearomeroj/chu"
+)
+
+const (
+	AuditCacheableAuthenticated = "cacheable-authenticated"
+	AuditMissingContentType     = "missing-content-type"
+	AuditMixedContent           = "mixed-content"
+	AuditInsecureCookie         = "insecure-cookie"
+)
+
+type AuditFinding struct {
+	Rule    string
+	Message string
+	Method  string
+	Route   string
+}
+
+type SecurityAuditOptions struct {
+	Logger        *slog.Logger
+	OnFinding     func(r *http.Request, f AuditFinding)
+	Authenticated func(r *http.Request) bool
+	ReportOnce    bool
+}
+
+var insecureSubresource = regexp.MustCompile(`(?i)\b(?:src|href|action)\s*=\s*["']?http://`)
+
+// SecurityAudit inspects outgoing responses and reports common header mistakes.
+// It only observes and never alters the response, so it is meant for
+// development and staging rather than production traffic. Requests left out by
+// chu.WithSampling are not inspected.
+func SecurityAudit(opts SecurityAuditOptions) func(chu.Handler) chu.Handler {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	if opts.Authenticated == nil {
+		opts.Authenticated = func(r *http.Request) bool {
+			return r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+		}
+	}
+
+	var seen sync.Map
+
+	report := func(r *http.Request, f AuditFinding) {
+		f.Method, f.Route = r.Method, chu.RoutePattern(r)
+
+		if opts.ReportOnce {
+			if _, loaded := seen.LoadOrStore(f.Method+" "+f.Route+" "+f.Rule, true); loaded {
+				return
+			}
+		}
+
+		if opts.OnFinding != nil {
+			opts.OnFinding(r, f)
+			return
+		}
+
+		opts.Logger.LogAttrs(context.WithoutCancel(r.Context()), slog.LevelWarn, "security audit",
+			slog.String("rule", f.Rule),
+			slog.String("message", f.Message),
+			slog.String("method", f.Method),
+			slog.String("route", f.Route),
+		)
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if !chu.Sampled(ctx) {
+				return next(ctx, w, r)
+			}
+
+			aw := &auditWriter{ResponseWriter: w, r: r, opts: &opts, report: report}
+			return next(ctx, aw, r)
+		}
+	}
+}
+
+type auditWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	opts        *SecurityAuditOptions
+	report      func(r *http.Request, f AuditFinding)
+	wroteHeader bool
+	scanned     bool
+}
+
+func (w *auditWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status >= http.StatusOK {
+		w.wroteHeader = true
+		w.checkHeaders(status)
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if len(p) > 0 && w.Header().Get("Content-Type") == "" {
+			w.report(w.r, AuditFinding{Rule: AuditMissingContentType, Message: "response body written without a Content-Type header; clients will sniff it"})
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.scanned && len(p) > 0 {
+		w.scanned = true
+		w.checkBody(p)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *auditWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *auditWriter) checkHeaders(status int) {
+	header := w.Header()
+
+	if status < http.StatusMultipleChoices && w.opts.Authenticated(w.r) && cacheable(header) {
+		w.report(w.r, AuditFinding{Rule: AuditCacheableAuthenticated, Message: "authenticated response may be stored by shared caches; add Cache-Control: private or no-store"})
+	}
+
+	if !secureRequest(w.r) {
+		return
+	}
+
+	if location := header.Get("Location"); strings.HasPrefix(strings.ToLower(location), "http://") {
+		w.report(w.r, AuditFinding{Rule: AuditMixedContent, Message: "HTTPS response redirects to insecure " + location})
+	}
+
+	for _, cookie := range header.Values("Set-Cookie") {
+		if !hasCookieAttr(cookie, "secure") {
+			name, _, _ := strings.Cut(cookie, "=")
+			w.report(w.r, AuditFinding{Rule: AuditInsecureCookie, Message: "cookie " + name + " set over HTTPS without the Secure attribute"})
+		}
+	}
+}
+
+func (w *auditWriter) checkBody(p []byte) {
+	if !secureRequest(w.r) || w.Header().Get("Location") != "" || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		return
+	}
+
+	if insecureSubresource.Match(bytes.ToLower(p)) {
+		w.report(w.r, AuditFinding{Rule: AuditMixedContent, Message: "HTTPS page references http:// resources"})
+	}
+}
+
+func cacheable(header http.Header) bool {
+	cc := strings.ToLower(strings.Join(header.Values("Cache-Control"), ","))
+	if strings.Contains(cc, "private") || strings.Contains(cc, "no-store") {
+		return false
+	}
+
+	if strings.Contains(cc, "public") || strings.Contains(cc, "max-age") || strings.Contains(cc, "s-maxage") {
+		return true
+	}
+
+	return header.Get("Expires") != "" || header.Get("Last-Modified") != ""
+}
+
+func secureRequest(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+func hasCookieAttr(cookie, attr string) bool {
+	for _, part := range strings.Split(cookie, ";")[1:] {
+		name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.EqualFold(name, attr) {
+			return true
+		}
+	}
+
+	return false
+}
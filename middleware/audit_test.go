@@ -0,0 +1,144 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityAudit(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  http.Header
+		handler chu.Handler
+		rules   []string
+	}{
+		{
+			name:   "cacheable authenticated",
+			header: http.Header{"Authorization": {"Bearer x"}},
+			handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Cache-Control", "public, max-age=60")
+				_, err := w.Write([]byte(`{}`))
+				return err
+			},
+			rules: []string{middleware.AuditCacheableAuthenticated},
+		},
+		{
+			name:   "private authenticated",
+			header: http.Header{"Authorization": {"Bearer x"}},
+			handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Cache-Control", "private, max-age=60")
+				_, err := w.Write([]byte(`{}`))
+				return err
+			},
+		},
+		{
+			name: "missing content type",
+			handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				_, err := w.Write([]byte("hello"))
+				return err
+			},
+			rules: []string{middleware.AuditMissingContentType},
+		},
+		{
+			name:   "mixed content",
+			header: http.Header{"X-Forwarded-Proto": {"https"}},
+			handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+				_, err := w.Write([]byte(`<script src="http://cdn.example.com/app.js"></script>`))
+				return err
+			},
+			rules: []string{middleware.AuditInsecureCookie, middleware.AuditMixedContent},
+		},
+		{
+			name:   "insecure redirect",
+			header: http.Header{"X-Forwarded-Proto": {"https"}},
+			handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				http.Redirect(w, r, "http://example.com/login", http.StatusFound)
+				return nil
+			},
+			rules: []string{middleware.AuditMixedContent},
+		},
+		{
+			name:   "plain http page",
+			header: http.Header{},
+			handler: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "text/html")
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+				_, err := w.Write([]byte(`<img src="http://example.com/a.png">`))
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+
+			r := chu.New()
+			r.Use(middleware.SecurityAudit(middleware.SecurityAuditOptions{
+				OnFinding: func(r *http.Request, f middleware.AuditFinding) {
+					assert.Equal(t, "/page", f.Route, "route should be reported")
+					rules = append(rules, f.Rule)
+				},
+			}))
+			r.Get("/page", tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/page", nil)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.rules, rules, "findings should match expected")
+		})
+	}
+}
+
+func TestSecurityAuditLogsOnce(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := chu.New()
+	r.Use(middleware.SecurityAudit(middleware.SecurityAuditOptions{
+		Logger:     slog.New(slog.NewTextHandler(&buf, nil)),
+		ReportOnce: true,
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte("x"))
+		return err
+	})
+
+	for range 3 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("rule=missing-content-type")), "finding should be logged once")
+	assert.Contains(t, buf.String(), "level=WARN", "finding should be a warning")
+}
+
+func TestSecurityAuditSampling(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := chu.New(chu.WithSampling(chu.SampleRatio(0)))
+	r.Use(middleware.SecurityAudit(middleware.SecurityAuditOptions{
+		Logger: slog.New(slog.NewTextHandler(&buf, nil)),
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte("x"))
+		return err
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Empty(t, buf.String(), "requests left out by sampling should not be audited")
+}
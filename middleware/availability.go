@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrUnavailable = errors.New("route unavailable")
+
+// Schedule is a parsed standard five field cron expression
+// (minute hour day-of-month month day-of-week).
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	domAny, dowAny bool
+}
+
+var scheduleDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression. Fields accept *, single values,
+// ranges (1-5), steps (*/15, 8-18/2) and comma separated lists. Day of week
+// runs from 0 (Sunday) to 7 (Sunday). The @yearly, @monthly, @weekly, @daily
+// and @hourly descriptors are also supported.
+func ParseSchedule(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if d, ok := scheduleDescriptors[spec]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{}
+
+	var err error
+	bounds := []struct {
+		dst      *uint64
+		min, max int
+	}{
+		{&s.minute, 0, 59},
+		{&s.hour, 0, 23},
+		{&s.dom, 1, 31},
+		{&s.month, 1, 12},
+		{&s.dow, 0, 7},
+	}
+
+	for i, b := range bounds {
+		if *b.dst, err = parseCronField(fields[i], b.min, b.max); err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", expr, err)
+		}
+	}
+
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+
+	return s, nil
+}
+
+func MustParseSchedule(expr string) *Schedule {
+	s, err := ParseSchedule(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step = n
+		}
+
+		start, end := lo, hi
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+
+			var err error
+			if start, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				end = hi
+			}
+		}
+
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, lo, hi)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+// Matches reports whether the schedule fires at the minute containing t.
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.minute&(1<<t.Minute()) != 0 &&
+		s.hour&(1<<t.Hour()) != 0 &&
+		s.month&(1<<int(t.Month())) != 0 &&
+		s.dayMatches(t)
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+
+	// Like cron, a restricted day of month and day of week match either.
+	if !s.domAny && !s.dowAny {
+		return dom || dow
+	}
+
+	return dom && dow
+}
+
+// Next returns the first time the schedule fires strictly after t, or the zero
+// time if it does not fire within the next five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// Window is a period that opens each time Schedule fires and stays open for
+// Duration.
+type Window struct {
+	Schedule *Schedule
+	Duration time.Duration
+}
+
+func (w Window) activeUntil(now time.Time) (time.Time, bool) {
+	start := w.Schedule.Next(now.Add(-w.Duration))
+	if start.IsZero() || start.After(now) {
+		return time.Time{}, false
+	}
+
+	end := start.Add(w.Duration)
+	for range 1000 {
+		next := w.Schedule.Next(start)
+		if next.IsZero() || next.After(end) {
+			break
+		}
+
+		start, end = next, next.Add(w.Duration)
+	}
+
+	return end, true
+}
+
+type AvailabilityOptions struct {
+	// Open restricts the routes to the given windows. Routes are always open
+	// when it is empty.
+	Open []Window
+	// Maintenance closes the routes during the given windows, even inside an
+	// open window.
+	Maintenance []Window
+	// Location is the time zone schedules are evaluated in. Defaults to
+	// time.Local.
+	Location *time.Location
+	Now      func() time.Time
+}
+
+// Availability rejects requests with 503 Service Unavailable outside the open
+// windows or during a maintenance window. Retry-After is set to the time the
+// routes become available again when it is known.
+func Availability(opts AvailabilityOptions) func(chu.Handler) chu.Handler {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	for _, window := range slices.Concat(opts.Open, opts.Maintenance) {
+		if window.Schedule == nil || window.Duration <= 0 {
+			panic("middleware: availability windows need a schedule and a positive duration")
+		}
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			now := opts.Now().In(opts.Location)
+
+			for _, window := range opts.Maintenance {
+				if end, ok := window.activeUntil(now); ok {
+					setRetryAfter(w, end.Sub(now))
+					return chu.Errorf(http.StatusServiceUnavailable, "%w: maintenance window", ErrUnavailable)
+				}
+			}
+
+			if len(opts.Open) == 0 {
+				return next(ctx, w, r)
+			}
+
+			var opens time.Time
+			for _, window := range opts.Open {
+				if _, ok := window.activeUntil(now); ok {
+					return next(ctx, w, r)
+				}
+
+				if start := window.Schedule.Next(now); !start.IsZero() && (opens.IsZero() || start.Before(opens)) {
+					opens = start
+				}
+			}
+
+			if !opens.IsZero() {
+				setRetryAfter(w, opens.Sub(now))
+			}
+
+			return chu.Errorf(http.StatusServiceUnavailable, "%w: outside availability schedule", ErrUnavailable)
+		}
+	}
+}
+
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10))
+}
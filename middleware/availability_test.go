@@ -0,0 +1,102 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	from := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC) // Friday
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{expr: "* * * * *", expected: time.Date(2024, 3, 15, 10, 31, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", expected: time.Date(2024, 3, 15, 10, 45, 0, 0, time.UTC)},
+		{expr: "0 22 * * *", expected: time.Date(2024, 3, 15, 22, 0, 0, 0, time.UTC)},
+		{expr: "0 9-17/4 * * 1-5", expected: time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{expr: "30 2 * * 7", expected: time.Date(2024, 3, 17, 2, 30, 0, 0, time.UTC)},
+		{expr: "0 0 1,15 * *", expected: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 13 * 5", expected: time.Date(2024, 3, 22, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", expected: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "@monthly", expected: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := middleware.ParseSchedule(tt.expr)
+			require.NoError(t, err)
+
+			next := s.Next(from)
+			assert.Equal(t, tt.expected, next, "next run should match expected")
+			assert.True(t, s.Matches(next), "schedule should match its next run")
+		})
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := middleware.ParseSchedule(expr)
+		assert.Error(t, err, "invalid expression %q should be rejected", expr)
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	var now time.Time
+	var handled error
+
+	r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(chu.StatusCode(err))
+	}))
+	r.Group(func(r *chu.Router) {
+		r.Use(middleware.Availability(middleware.AvailabilityOptions{
+			Open: []middleware.Window{
+				{Schedule: middleware.MustParseSchedule("0 22 * * *"), Duration: 4 * time.Hour},
+			},
+			Maintenance: []middleware.Window{
+				{Schedule: middleware.MustParseSchedule("0 0 * * 0"), Duration: 30 * time.Minute},
+			},
+			Location: time.UTC,
+			Now:      func() time.Time { return now },
+		}))
+		r.Post("/batch", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return nil
+		})
+	})
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		status     int
+		retryAfter string
+	}{
+		{name: "inside window", now: time.Date(2024, 3, 15, 23, 0, 0, 0, time.UTC), status: http.StatusOK},
+		{name: "window past midnight", now: time.Date(2024, 3, 16, 1, 59, 0, 0, time.UTC), status: http.StatusOK},
+		{name: "before window", now: time.Date(2024, 3, 15, 21, 0, 0, 0, time.UTC), status: http.StatusServiceUnavailable, retryAfter: "3600"},
+		{name: "window closed", now: time.Date(2024, 3, 16, 2, 0, 0, 0, time.UTC), status: http.StatusServiceUnavailable, retryAfter: "72000"},
+		{name: "maintenance", now: time.Date(2024, 3, 17, 0, 10, 0, 0, time.UTC), status: http.StatusServiceUnavailable, retryAfter: "1200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, handled = tt.now, nil
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("POST", "/batch", nil))
+
+			assert.Equal(t, tt.status, w.Code, "status code should match expected")
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"), "retry after should match expected")
+			if tt.status != http.StatusOK {
+				assert.ErrorIs(t, handled, middleware.ErrUnavailable, "error should wrap ErrUnavailable")
+			}
+		})
+	}
+}
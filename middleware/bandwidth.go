@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/internal/throttle"
+)
+
+type BandwidthOptions struct {
+	ReadRate  int64
+	WriteRate int64
+	Burst     int64
+	Key       func(r *http.Request) string
+	Now       func() time.Time
+}
+
+func Bandwidth(opts BandwidthOptions) func(chu.Handler) chu.Handler {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	reads := newBucketSet(opts.ReadRate, opts.Burst, opts.Now)
+	writes := newBucketSet(opts.WriteRate, opts.Burst, opts.Now)
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key := ""
+			if opts.Key != nil {
+				key = opts.Key(r)
+			}
+
+			if opts.ReadRate > 0 && r.Body != nil && r.Body != http.NoBody {
+				r.Body = &throttledReader{ReadCloser: r.Body, ctx: ctx, bucket: reads.get(key)}
+			}
+
+			if opts.WriteRate > 0 {
+				w = &throttledWriter{ResponseWriter: w, ctx: ctx, bucket: writes.get(key)}
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+type bucketSet struct {
+	rate      int64
+	burst     int64
+	now       func() time.Time
+	mu        sync.Mutex
+	buckets   map[string]*throttle.Bucket
+	lastSweep time.Time
+}
+
+func newBucketSet(rate, burst int64, now func() time.Time) *bucketSet {
+	return &bucketSet{rate: rate, burst: burst, now: now, buckets: make(map[string]*throttle.Bucket)}
+}
+
+func (s *bucketSet) get(key string) *throttle.Bucket {
+	if key == "" {
+		return throttle.New(s.rate, s.burst, s.now)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, b := range s.buckets {
+			if b.Idle(now) {
+				delete(s.buckets, k)
+			}
+		}
+
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = throttle.New(s.rate, s.burst, s.now)
+		s.buckets[key] = b
+	}
+
+	return b
+}
+
+type throttledReader struct {
+	io.ReadCloser
+	ctx    context.Context
+	bucket *throttle.Bucket
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.bucket.Burst() {
+		p = p[:r.bucket.Burst()]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := r.bucket.Wait(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+
+	return n, err
+}
+
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx    context.Context
+	bucket *throttle.Bucket
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	return w.bucket.Write(w.ctx, p, w.ResponseWriter.Write)
+}
+
+func (w *throttledWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
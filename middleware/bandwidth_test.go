@@ -0,0 +1,126 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBandwidth(t *testing.T) {
+	newRouter := func(opts middleware.BandwidthOptions) *chu.Router {
+		r := chu.New()
+		r.Use(middleware.Bandwidth(opts))
+		r.Get("/download", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte(strings.Repeat("x", 150)))
+			return err
+		})
+		r.Post("/upload", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				return err
+			}
+
+			_, err = w.Write([]byte{byte(len(data))})
+			return err
+		})
+
+		return r
+	}
+
+	byUser := func(r *http.Request) string { return r.Header.Get("X-User") }
+
+	tests := []struct {
+		name    string
+		opts    middleware.BandwidthOptions
+		method  string
+		path    string
+		users   []string
+		minTime time.Duration
+		maxTime time.Duration
+	}{
+		{
+			name:    "write rate per request",
+			opts:    middleware.BandwidthOptions{WriteRate: 1000, Burst: 50},
+			method:  http.MethodGet,
+			path:    "/download",
+			users:   []string{"a", "a"},
+			minTime: 150 * time.Millisecond,
+			maxTime: 600 * time.Millisecond,
+		},
+		{
+			name:    "write rate shared by principal",
+			opts:    middleware.BandwidthOptions{WriteRate: 1000, Burst: 150, Key: byUser},
+			method:  http.MethodGet,
+			path:    "/download",
+			users:   []string{"a", "a"},
+			minTime: 130 * time.Millisecond,
+		},
+		{
+			name:    "principals have separate buckets",
+			opts:    middleware.BandwidthOptions{WriteRate: 1000, Burst: 150, Key: byUser},
+			method:  http.MethodGet,
+			path:    "/download",
+			users:   []string{"a", "b"},
+			maxTime: 100 * time.Millisecond,
+		},
+		{
+			name:    "read rate",
+			opts:    middleware.BandwidthOptions{ReadRate: 1000, Burst: 50},
+			method:  http.MethodPost,
+			path:    "/upload",
+			users:   []string{"a"},
+			minTime: 90 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRouter(tt.opts)
+
+			start := time.Now()
+			for _, user := range tt.users {
+				req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(strings.Repeat("y", 150)))
+				req.Header.Set("X-User", user)
+
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+				require.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+			}
+			elapsed := time.Since(start)
+
+			assert.GreaterOrEqual(t, elapsed, tt.minTime, "transfer should be throttled")
+			if tt.maxTime > 0 {
+				assert.Less(t, elapsed, tt.maxTime, "transfer should not be over-throttled")
+			}
+		})
+	}
+}
+
+func TestBandwidth_Canceled(t *testing.T) {
+	r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {}))
+	r.Use(middleware.Bandwidth(middleware.BandwidthOptions{WriteRate: 10, Burst: 1}))
+
+	var writeErr error
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, writeErr = w.Write([]byte(strings.Repeat("x", 100)))
+		return writeErr
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	assert.ErrorIs(t, writeErr, context.DeadlineExceeded, "write should stop when the request is canceled")
+	assert.Less(t, time.Since(start), time.Second, "canceled transfer should return promptly")
+}
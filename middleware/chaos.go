@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrChaos = errors.New("chaos: injected fault")
+
+type ChaosOptions struct {
+	Enabled bool
+	EnvVar  string
+
+	LatencyRate float64
+	MinLatency  time.Duration
+	MaxLatency  time.Duration
+
+	ErrorRate   float64
+	ErrorStatus int
+
+	ResetRate float64
+
+	Random func() float64
+}
+
+func Chaos(opts ChaosOptions) func(chu.Handler) chu.Handler {
+	if !opts.Enabled && opts.EnvVar != "" {
+		opts.Enabled, _ = strconv.ParseBool(os.Getenv(opts.EnvVar))
+	}
+
+	if opts.ErrorStatus == 0 {
+		opts.ErrorStatus = http.StatusInternalServerError
+	}
+
+	if opts.MaxLatency < opts.MinLatency {
+		opts.MaxLatency = opts.MinLatency
+	}
+
+	if opts.Random == nil {
+		opts.Random = rand.Float64
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		if !opts.Enabled {
+			return next
+		}
+
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if opts.Random() < opts.LatencyRate {
+				delay := opts.MinLatency + time.Duration(opts.Random()*float64(opts.MaxLatency-opts.MinLatency))
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				}
+			}
+
+			if opts.Random() < opts.ResetRate {
+				panic(http.ErrAbortHandler)
+			}
+
+			if opts.Random() < opts.ErrorRate {
+				return chu.NewError(opts.ErrorStatus, ErrChaos)
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
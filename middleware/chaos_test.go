@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChaos(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           middleware.ChaosOptions
+		env            string
+		expectedStatus int
+		expectedPanic  bool
+		minDuration    time.Duration
+	}{
+		{
+			name:           "disabled by default",
+			opts:           middleware.ChaosOptions{ErrorRate: 1},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "enabled through env var",
+			opts:           middleware.ChaosOptions{EnvVar: "CHU_TEST_CHAOS", ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+			env:            "true",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "env var not truthy",
+			opts:           middleware.ChaosOptions{EnvVar: "CHU_TEST_CHAOS", ErrorRate: 1},
+			env:            "no",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "injected error",
+			opts:           middleware.ChaosOptions{Enabled: true, ErrorRate: 1},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "injected latency",
+			opts:           middleware.ChaosOptions{Enabled: true, LatencyRate: 1, MinLatency: 20 * time.Millisecond},
+			expectedStatus: http.StatusOK,
+			minDuration:    20 * time.Millisecond,
+		},
+		{
+			name:          "injected reset",
+			opts:          middleware.ChaosOptions{Enabled: true, ResetRate: 1},
+			expectedPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CHU_TEST_CHAOS", tt.env)
+
+			r := chu.New()
+			r.Use(middleware.Chaos(tt.opts))
+			r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				return nil
+			})
+
+			w := httptest.NewRecorder()
+			serve := func() { r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil)) }
+
+			if tt.expectedPanic {
+				assert.PanicsWithValue(t, http.ErrAbortHandler, serve, "reset should abort the connection")
+				return
+			}
+
+			start := time.Now()
+			serve()
+
+			assert.Equal(t, tt.expectedStatus, w.Code, "status code should match expected")
+			assert.GreaterOrEqual(t, time.Since(start), tt.minDuration, "latency should be injected")
+		})
+	}
+}
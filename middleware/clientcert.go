@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var (
+	ErrNoClientCert      = errors.New("client certificate required")
+	ErrClientCertInvalid = errors.New("client certificate invalid")
+	ErrClientNotAllowed  = errors.New("client certificate not allowed")
+	ErrInvalidSPIFFEID   = errors.New("invalid SPIFFE ID")
+)
+
+type clientIdentityCtxKey struct{}
+
+type SPIFFEID struct {
+	TrustDomain string
+	Path        string
+}
+
+func (id SPIFFEID) String() string {
+	return "spiffe://" + id.TrustDomain + id.Path
+}
+
+func ParseSPIFFEID(raw string) (SPIFFEID, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return SPIFFEID{}, fmt.Errorf("%w: %v", ErrInvalidSPIFFEID, err)
+	}
+
+	if u.Scheme != "spiffe" || u.Host == "" || u.User != nil || u.Port() != "" ||
+		u.RawQuery != "" || u.Fragment != "" || u.Host != strings.ToLower(u.Host) {
+		return SPIFFEID{}, fmt.Errorf("%w: %q", ErrInvalidSPIFFEID, raw)
+	}
+
+	if u.Path != "" && (path.Clean(u.Path) != u.Path || strings.HasSuffix(u.Path, "/")) {
+		return SPIFFEID{}, fmt.Errorf("%w: %q", ErrInvalidSPIFFEID, raw)
+	}
+
+	return SPIFFEID{TrustDomain: u.Host, Path: u.Path}, nil
+}
+
+type ClientIdentity struct {
+	Certificate *x509.Certificate
+	CommonName  string
+	DNSNames    []string
+	URIs        []string
+	Emails      []string
+	SPIFFEID    *SPIFFEID
+}
+
+type ClientCertOptions struct {
+	// Roots verifies the peer certificates. Without it, only chains verified
+	// by the TLS handshake are trusted, so the server must use
+	// VerifyClientCertIfGiven or RequireAndVerifyClientCert.
+	Roots               *x509.CertPool
+	AllowedDNSNames     []string
+	AllowedURIs         []string
+	AllowedEmails       []string
+	AllowedSPIFFEIDs    []string
+	AllowedTrustDomains []string
+	Authorize           func(ctx context.Context, id *ClientIdentity) error
+	Optional            bool
+	Now                 func() time.Time
+}
+
+func ClientCert(opts ClientCertOptions) func(chu.Handler) chu.Handler {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+				if opts.Optional {
+					return next(ctx, w, r)
+				}
+
+				return chu.NewError(http.StatusUnauthorized, ErrNoClientCert)
+			}
+
+			id, err := opts.identify(r.TLS)
+			if err != nil {
+				return chu.NewError(http.StatusUnauthorized, err)
+			}
+
+			if !opts.allowed(id) {
+				return chu.NewError(http.StatusUnauthorized, ErrClientNotAllowed)
+			}
+
+			if opts.Authorize != nil {
+				if err := opts.Authorize(ctx, id); err != nil {
+					return chu.NewError(http.StatusUnauthorized, fmt.Errorf("%w: %v", ErrClientNotAllowed, err))
+				}
+			}
+
+			ctx = context.WithValue(ctx, clientIdentityCtxKey{}, id)
+
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+func ClientIdentityFromContext(ctx context.Context) (*ClientIdentity, bool) {
+	id, ok := ctx.Value(clientIdentityCtxKey{}).(*ClientIdentity)
+	return id, ok
+}
+
+func (opts ClientCertOptions) identify(state *tls.ConnectionState) (*ClientIdentity, error) {
+	chain := state.PeerCertificates
+	leaf := chain[0]
+
+	switch {
+	case opts.Roots != nil:
+		intermediates := x509.NewCertPool()
+		for _, cert := range chain[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         opts.Roots,
+			Intermediates: intermediates,
+			CurrentTime:   opts.Now(),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrClientCertInvalid, err)
+		}
+	case len(state.VerifiedChains) == 0:
+		return nil, fmt.Errorf("%w: certificate not verified", ErrClientCertInvalid)
+	default:
+		leaf = state.VerifiedChains[0][0]
+
+		if now := opts.Now(); now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+			return nil, fmt.Errorf("%w: certificate expired or not yet valid", ErrClientCertInvalid)
+		}
+	}
+
+	id := &ClientIdentity{
+		Certificate: leaf,
+		CommonName:  leaf.Subject.CommonName,
+		DNSNames:    leaf.DNSNames,
+		Emails:      leaf.EmailAddresses,
+	}
+
+	for _, u := range leaf.URIs {
+		id.URIs = append(id.URIs, u.String())
+
+		if u.Scheme != "spiffe" {
+			continue
+		}
+
+		if id.SPIFFEID != nil {
+			return nil, fmt.Errorf("%w: certificate has multiple SPIFFE IDs", ErrInvalidSPIFFEID)
+		}
+
+		spiffe, err := ParseSPIFFEID(u.String())
+		if err != nil {
+			return nil, err
+		}
+
+		id.SPIFFEID = &spiffe
+	}
+
+	return id, nil
+}
+
+func (opts ClientCertOptions) allowed(id *ClientIdentity) bool {
+	if len(opts.AllowedDNSNames) == 0 && len(opts.AllowedURIs) == 0 && len(opts.AllowedEmails) == 0 &&
+		len(opts.AllowedSPIFFEIDs) == 0 && len(opts.AllowedTrustDomains) == 0 {
+		return true
+	}
+
+	for _, name := range id.DNSNames {
+		if slices.ContainsFunc(opts.AllowedDNSNames, func(pattern string) bool { return matchDNSName(pattern, name) }) {
+			return true
+		}
+	}
+
+	for _, uri := range id.URIs {
+		if slices.Contains(opts.AllowedURIs, uri) {
+			return true
+		}
+	}
+
+	for _, email := range id.Emails {
+		if slices.Contains(opts.AllowedEmails, email) {
+			return true
+		}
+	}
+
+	if id.SPIFFEID != nil {
+		if slices.Contains(opts.AllowedSPIFFEIDs, id.SPIFFEID.String()) ||
+			slices.Contains(opts.AllowedTrustDomains, id.SPIFFEID.TrustDomain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchDNSName(pattern, name string) bool {
+	pattern, name = strings.ToLower(pattern), strings.ToLower(name)
+
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		label, rest, found := strings.Cut(name, ".")
+		return found && label != "" && rest == suffix
+	}
+
+	return pattern == name
+}
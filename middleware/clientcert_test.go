@@ -0,0 +1,220 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "key generation should succeed")
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err, "CA creation should succeed")
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, "CA should parse")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+func (ca *testCA) issue(t *testing.T, dnsNames []string, uris ...string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "key generation should succeed")
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     dnsNames,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	for _, raw := range uris {
+		u, err := url.Parse(raw)
+		require.NoError(t, err, "URI should parse")
+		tmpl.URIs = append(tmpl.URIs, u)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err, "certificate creation should succeed")
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, "certificate should parse")
+
+	return cert
+}
+
+func TestParseSPIFFEID(t *testing.T) {
+	tests := []struct {
+		raw    string
+		valid  bool
+		domain string
+		idPath string
+	}{
+		{raw: "spiffe://example.org/ns/prod/sa/api", valid: true, domain: "example.org", idPath: "/ns/prod/sa/api"},
+		{raw: "spiffe://example.org", valid: true, domain: "example.org"},
+		{raw: "https://example.org/api"},
+		{raw: "spiffe:///api"},
+		{raw: "spiffe://Example.org/api"},
+		{raw: "spiffe://example.org/api/"},
+		{raw: "spiffe://example.org/a/../b"},
+		{raw: "spiffe://example.org/api?x=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			id, err := middleware.ParseSPIFFEID(tt.raw)
+			if !tt.valid {
+				assert.ErrorIs(t, err, middleware.ErrInvalidSPIFFEID, "invalid IDs should be rejected")
+				return
+			}
+
+			require.NoError(t, err, "valid IDs should parse")
+			assert.Equal(t, tt.domain, id.TrustDomain, "trust domain should match expected")
+			assert.Equal(t, tt.idPath, id.Path, "path should match expected")
+		})
+	}
+}
+
+func TestClientCert(t *testing.T) {
+	ca := newTestCA(t)
+	other := newTestCA(t)
+
+	spiffe := ca.issue(t, nil, "spiffe://example.org/ns/prod/sa/api")
+	dns := ca.issue(t, []string{"billing.internal.test"})
+	foreign := other.issue(t, []string{"billing.internal.test"})
+	selfSigned := other.cert
+
+	opts := middleware.ClientCertOptions{
+		Roots:               ca.pool,
+		AllowedDNSNames:     []string{"*.internal.test"},
+		AllowedTrustDomains: []string{"example.org"},
+	}
+
+	tests := []struct {
+		name     string
+		cert     *x509.Certificate
+		verified bool
+		opts     middleware.ClientCertOptions
+		expected int
+		err      error
+		body     string
+	}{
+		{name: "spiffe", cert: spiffe, opts: opts, expected: http.StatusOK, body: "spiffe://example.org/ns/prod/sa/api"},
+		{name: "dns wildcard", cert: dns, opts: opts, expected: http.StatusOK, body: "client"},
+		{name: "no certificate", opts: opts, expected: http.StatusUnauthorized, err: middleware.ErrNoClientCert},
+		{name: "untrusted issuer", cert: foreign, opts: opts, expected: http.StatusUnauthorized, err: middleware.ErrClientCertInvalid},
+		{
+			name:     "not allowlisted",
+			cert:     dns,
+			opts:     middleware.ClientCertOptions{Roots: ca.pool, AllowedSPIFFEIDs: []string{"spiffe://example.org/other"}},
+			expected: http.StatusUnauthorized,
+			err:      middleware.ErrClientNotAllowed,
+		},
+		{
+			name: "authorize hook",
+			cert: spiffe,
+			opts: middleware.ClientCertOptions{Roots: ca.pool, Authorize: func(ctx context.Context, id *middleware.ClientIdentity) error {
+				return errors.New("denied")
+			}},
+			expected: http.StatusUnauthorized,
+			err:      middleware.ErrClientNotAllowed,
+		},
+		{
+			name:     "self-signed without roots",
+			cert:     selfSigned,
+			opts:     middleware.ClientCertOptions{},
+			expected: http.StatusUnauthorized,
+			err:      middleware.ErrClientCertInvalid,
+		},
+		{name: "verified by handshake", cert: dns, verified: true, opts: middleware.ClientCertOptions{}, expected: http.StatusOK, body: "client"},
+		{name: "optional without certificate", opts: middleware.ClientCertOptions{Optional: true}, expected: http.StatusOK, body: "anonymous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handlerErr error
+
+			r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+				handlerErr = err
+				w.WriteHeader(chu.StatusCode(err))
+			}))
+			r.Use(middleware.ClientCert(tt.opts))
+			r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				id, ok := middleware.ClientIdentityFromContext(ctx)
+
+				switch {
+				case !ok:
+					_, _ = w.Write([]byte("anonymous"))
+				case id.SPIFFEID != nil:
+					_, _ = w.Write([]byte(id.SPIFFEID.String()))
+				default:
+					_, _ = w.Write([]byte(id.CommonName))
+				}
+
+				return nil
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.cert != nil {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{tt.cert}}
+				if tt.verified {
+					req.TLS.VerifiedChains = [][]*x509.Certificate{{tt.cert, ca.cert}}
+				}
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+
+			if tt.err != nil {
+				assert.ErrorIs(t, handlerErr, tt.err, "error should match expected")
+			} else {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// ContentTypeError is returned, with status 415, for requests whose
+// Content-Type is not allowed. It matches ErrUnsupportedMediaType.
+type ContentTypeError struct {
+	// ContentType is the request's Content-Type, empty when missing.
+	ContentType string
+	Allowed     []string
+}
+
+func (e *ContentTypeError) Error() string {
+	if e.ContentType == "" {
+		return "missing content type, expected " + strings.Join(e.Allowed, " or ")
+	}
+
+	return "unsupported content type " + e.ContentType + ", expected " + strings.Join(e.Allowed, " or ")
+}
+
+func (e *ContentTypeError) Unwrap() error {
+	return ErrUnsupportedMediaType
+}
+
+type mediaRange struct {
+	typ     string
+	charset string
+}
+
+// RequireContentType rejects requests with a body whose Content-Type is not one
+// of allowed. Entries may use a subtype wildcard, as in "image/*". An entry with
+// a charset parameter, as in "text/plain; charset=utf-8", rejects other
+// charsets but accepts a missing one; entries without it accept any charset.
+// Requests without a body, such as most GET and DELETE requests, pass.
+func RequireContentType(allowed ...string) func(chu.Handler) chu.Handler {
+	ranges := make([]mediaRange, 0, len(allowed))
+	for _, a := range allowed {
+		typ, params, err := mime.ParseMediaType(a)
+		if err != nil {
+			panic("middleware: invalid content type " + a)
+		}
+
+		ranges = append(ranges, mediaRange{typ: typ, charset: strings.ToLower(params["charset"])})
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+				return next(ctx, w, r)
+			}
+
+			header := r.Header.Get("Content-Type")
+
+			typ, params, err := mime.ParseMediaType(header)
+			if err == nil {
+				charset := strings.ToLower(params["charset"])
+				for _, mr := range ranges {
+					if mr.matches(typ, charset) {
+						return next(ctx, w, r)
+					}
+				}
+			}
+
+			return chu.NewError(http.StatusUnsupportedMediaType, &ContentTypeError{ContentType: header, Allowed: allowed})
+		}
+	}
+}
+
+func (mr mediaRange) matches(typ, charset string) bool {
+	if mr.charset != "" && charset != "" && charset != mr.charset {
+		return false
+	}
+
+	if prefix, ok := strings.CutSuffix(mr.typ, "/*"); ok {
+		return strings.HasPrefix(typ, prefix+"/")
+	}
+
+	return typ == mr.typ
+}
@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireContentType(t *testing.T) {
+	var got error
+
+	r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		got = err
+		w.WriteHeader(chu.StatusCode(err))
+	}))
+	r.Group(func(r *chu.Router) {
+		r.Use(middleware.RequireContentType("application/json", "text/plain; charset=utf-8", "image/*"))
+
+		r.Post("/items", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusCreated)
+			return nil
+		})
+	})
+
+	tests := []struct {
+		name  string
+		ctype string
+		body  string
+		code  int
+	}{
+		{name: "json", ctype: "application/json", body: "{}", code: http.StatusCreated},
+		{name: "json with charset", ctype: "Application/JSON; charset=UTF-8", body: "{}", code: http.StatusCreated},
+		{name: "text missing charset", ctype: "text/plain", body: "hi", code: http.StatusCreated},
+		{name: "text wrong charset", ctype: "text/plain; charset=latin1", body: "hi", code: http.StatusUnsupportedMediaType},
+		{name: "wildcard", ctype: "image/png", body: "png", code: http.StatusCreated},
+		{name: "mismatch", ctype: "application/xml", body: "<a/>", code: http.StatusUnsupportedMediaType},
+		{name: "missing", body: "{}", code: http.StatusUnsupportedMediaType},
+		{name: "malformed", ctype: "application/", body: "{}", code: http.StatusUnsupportedMediaType},
+		{name: "no body", code: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+
+			var body *strings.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+
+			req := httptest.NewRequest("POST", "/items", nil)
+			if body != nil {
+				req = httptest.NewRequest("POST", "/items", body)
+			}
+			if tt.ctype != "" {
+				req.Header.Set("Content-Type", tt.ctype)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code, "status code should match expected")
+			if tt.code == http.StatusUnsupportedMediaType {
+				assert.ErrorIs(t, got, middleware.ErrUnsupportedMediaType, "error should match sentinel")
+
+				var cte *middleware.ContentTypeError
+				if assert.True(t, errors.As(got, &cte), "error should be typed") {
+					assert.Equal(t, tt.ctype, cte.ContentType, "content type should match request")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/cache"
+)
+
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to make cross-origin requests.
+	// "*" allows any origin and a single wildcard, as in
+	// "https://*.example.com", matches any subdomain.
+	AllowedOrigins []string
+	// AllowOrigin, when set, is consulted for origins not in AllowedOrigins.
+	AllowOrigin func(r *http.Request, origin string) bool
+	// AllowedMethods bounds the methods advertised in preflight responses. When
+	// empty every method registered for the path is advertised.
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed in preflight responses.
+	// When empty the headers requested by the client are echoed.
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+	// CacheSize bounds the number of route patterns whose preflight methods are
+	// cached. Defaults to 1024.
+	CacheSize int
+}
+
+// CORS handles cross-origin requests. Preflight requests are answered directly
+// and advertise only the methods registered for the matched route, computed
+// once per route pattern. The middleware must be added with Use so it runs for
+// OPTIONS requests that no route handles.
+func CORS(opts CORSOptions) func(chu.Handler) chu.Handler {
+	if opts.CacheSize <= 0 {
+		opts.CacheSize = 1024
+	}
+
+	allowedHeaders := strings.Join(opts.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(opts.ExposedHeaders, ", ")
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+
+	methods := cache.New(cache.Options[string, string]{MaxEntries: opts.CacheSize})
+
+	allowed := func(r *http.Request, origin string) bool {
+		if anyOrigin || slices.ContainsFunc(opts.AllowedOrigins, func(o string) bool { return matchOrigin(o, origin) }) {
+			return true
+		}
+
+		return opts.AllowOrigin != nil && opts.AllowOrigin(r, origin)
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			h := w.Header()
+			chu.AddVary(w, "Origin")
+			if preflight {
+				chu.AddVary(w, "Access-Control-Request-Method", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" || !allowed(r, origin) {
+				return next(ctx, w, r)
+			}
+
+			if anyOrigin && !opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposedHeaders != "" {
+					h.Set("Access-Control-Expose-Headers", exposedHeaders)
+				}
+
+				return next(ctx, w, r)
+			}
+
+			allow, ok := preflightMethods(r, methods, opts.AllowedMethods)
+			if !ok {
+				return next(ctx, w, r)
+			}
+
+			if allow != "" {
+				h.Set("Access-Control-Allow-Methods", allow)
+			}
+
+			if allowedHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", allowedHeaders)
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+
+			if opts.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge/time.Second)))
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+	}
+}
+
+// preflightMethods returns the methods to advertise for the request path. They
+// are cached by the pattern of the route serving the requested method; a
+// requested method with no route is answered from the route tree directly.
+func preflightMethods(r *http.Request, methods *cache.Cache[string, string], bound []string) (string, bool) {
+	pattern := chu.RoutePatternFor(r, r.Header.Get("Access-Control-Request-Method"))
+
+	// Host routes may register different methods for the same pattern.
+	key := strings.ToLower(r.Host) + " " + pattern
+	if pattern != "" {
+		if allow, ok := methods.Get(key); ok {
+			return allow, true
+		}
+	}
+
+	registered := chu.AllowedMethods(r)
+	if len(registered) == 0 {
+		return "", false
+	}
+
+	if len(bound) > 0 {
+		registered = slices.DeleteFunc(registered, func(m string) bool { return !slices.Contains(bound, m) })
+	}
+
+	allow := strings.Join(registered, ", ")
+	if pattern != "" {
+		methods.Set(key, allow)
+	}
+
+	return allow, true
+}
+
+func matchOrigin(allowed, origin string) bool {
+	prefix, suffix, ok := strings.Cut(allowed, "*")
+	if !ok {
+		return strings.EqualFold(allowed, origin)
+	}
+
+	origin = strings.ToLower(origin)
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, strings.ToLower(prefix)) &&
+		strings.HasSuffix(origin, strings.ToLower(suffix))
+}
@@ -0,0 +1,125 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	ok := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	r := chu.New()
+	r.Use(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+		ExposedHeaders: []string{"X-Request-Id"},
+		MaxAge:         10 * time.Minute,
+	}))
+	r.Get("/users/{id}", ok)
+	r.Put("/users/{id}", ok)
+	r.Delete("/users/{id}", ok)
+	r.Post("/users", ok)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		origin  string
+		code    int
+		allow   string
+		methods string
+	}{
+		{name: "preflight", method: "OPTIONS", path: "/users/7", origin: "https://app.example.com", code: http.StatusNoContent, allow: "https://app.example.com", methods: "GET, PUT, DELETE"},
+		{name: "preflight other route", method: "OPTIONS", path: "/users", origin: "https://app.example.com", code: http.StatusNoContent, allow: "https://app.example.com", methods: "POST"},
+		{name: "wildcard origin", method: "OPTIONS", path: "/users/7", origin: "https://eu.example.org", code: http.StatusNoContent, allow: "https://eu.example.org", methods: "GET, PUT, DELETE"},
+		{name: "disallowed origin", method: "OPTIONS", path: "/users/7", origin: "https://evil.test", code: http.StatusMethodNotAllowed},
+		{name: "unknown route", method: "OPTIONS", path: "/missing", origin: "https://app.example.com", code: http.StatusNotFound, allow: "https://app.example.com"},
+		{name: "actual request", method: "GET", path: "/users/7", origin: "https://app.example.com", code: http.StatusOK, allow: "https://app.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.method == "OPTIONS" {
+				req.Header.Set("Access-Control-Request-Method", "PUT")
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code, "status code should match expected")
+			assert.Equal(t, tt.allow, w.Header().Get("Access-Control-Allow-Origin"), "allowed origin should match expected")
+			assert.Equal(t, tt.methods, w.Header().Get("Access-Control-Allow-Methods"), "allowed methods should match expected")
+			assert.True(t, chu.Varies(w.Header(), "Origin"), "response should vary by origin")
+		})
+	}
+
+	req := httptest.NewRequest("OPTIONS", "/users/7", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"), "requested headers should be echoed")
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"), "max age should be in seconds")
+
+	req = httptest.NewRequest("GET", "/users/7", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "X-Request-Id", w.Header().Get("Access-Control-Expose-Headers"), "exposed headers should be set on actual requests")
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	r := chu.New()
+	r.Use(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST"},
+	}))
+	r.Handle("/any", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+
+	req := httptest.NewRequest("OPTIONS", "/any", nil)
+	req.Header.Set("Origin", "https://client.test")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "status code should match expected")
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"), "any origin should be allowed")
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"), "methods should be bounded by options")
+}
+
+func TestAllowedMethods(t *testing.T) {
+	ok := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	var got []string
+	r := chu.New()
+	r.Use(func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			got = chu.AllowedMethods(r)
+			return next(ctx, w, r)
+		}
+	})
+	r.Route("/items", func(r *chu.Router) {
+		r.Get("/{id}", ok)
+		r.Patch("/{id}", ok)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/items/1", nil))
+	assert.Equal(t, []string{"GET", "PATCH"}, got, "methods should match registered routes")
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nothing", nil))
+	assert.Nil(t, got, "unknown paths should have no methods")
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/josearomeroj/chu"
+)
+
+type CSPOptions struct {
+	Policy          string
+	NonceDirectives []string
+	ReportOnly      bool
+}
+
+func CSP(opts CSPOptions) func(chu.Handler) chu.Handler {
+	if opts.Policy == "" {
+		opts.Policy = "default-src 'self'; script-src 'strict-dynamic'; object-src 'none'; base-uri 'none'"
+	}
+
+	if len(opts.NonceDirectives) == 0 {
+		opts.NonceDirectives = []string{"script-src", "style-src"}
+	}
+
+	header := "Content-Security-Policy"
+	if opts.ReportOnly {
+		header = "Content-Security-Policy-Report-Only"
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			buf := make([]byte, 16)
+			if _, err := rand.Read(buf); err != nil {
+				return err
+			}
+
+			nonce := base64.RawURLEncoding.EncodeToString(buf)
+			w.Header().Set(header, withNonce(opts.Policy, opts.NonceDirectives, nonce))
+
+			ctx = chu.WithCSPNonce(ctx, nonce)
+
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+func withNonce(policy string, directives []string, nonce string) string {
+	source := "'nonce-" + nonce + "'"
+
+	var parts []string
+
+	for _, part := range strings.Split(policy, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(part, " ")
+		name = strings.ToLower(name)
+
+		if slices.Contains(directives, name) {
+			part += " " + source
+		}
+
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, "; ")
+}
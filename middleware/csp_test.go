@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cspPage = template.Must(template.New("page").Funcs(chu.CSPFuncs(context.Background())).Parse(
+	`<script {{nonceAttr}}>run()</script><style nonce="{{cspNonce}}"></style>`))
+
+func TestCSP(t *testing.T) {
+	r := chu.New()
+	r.Use(middleware.CSP(middleware.CSPOptions{
+		Policy: "default-src 'self'; script-src 'self'; style-src 'self'",
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		tmpl, err := cspPage.Clone()
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Funcs(chu.CSPFuncs(ctx)).Execute(&buf, nil); err != nil {
+			return err
+		}
+
+		_, err = w.Write(buf.Bytes())
+		return err
+	})
+
+	nonces := map[string]bool{}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		require.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+
+		policy := w.Header().Get("Content-Security-Policy")
+		_, rest, ok := strings.Cut(policy, "'nonce-")
+		require.True(t, ok, "policy should contain nonce")
+
+		nonce, _, _ := strings.Cut(rest, "'")
+		nonces[nonce] = true
+
+		assert.Equal(t,
+			"default-src 'self'; script-src 'self' 'nonce-"+nonce+"'; style-src 'self' 'nonce-"+nonce+"'",
+			policy, "nonce should be appended to script-src and style-src")
+		assert.Equal(t,
+			`<script nonce="`+nonce+`">run()</script><style nonce="`+nonce+`"></style>`,
+			w.Body.String(), "template should render the request nonce")
+	}
+
+	assert.Len(t, nonces, 2, "each request should get a fresh nonce")
+}
+
+func TestCSP_ReportOnly(t *testing.T) {
+	r := chu.New()
+	r.Use(middleware.CSP(middleware.CSPOptions{ReportOnly: true}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		assert.NotEmpty(t, chu.CSPNonce(ctx), "nonce should be available in context")
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"), "enforcing header should not be set")
+	assert.Contains(t, w.Header().Get("Content-Security-Policy-Report-Only"), "script-src 'strict-dynamic' 'nonce-",
+		"report-only header should contain nonce")
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/josearomeroj/chu"
+)
+
+var (
+	ErrDecompressedTooLarge = errors.New("decompressed request body too large")
+	ErrUnsupportedEncoding  = errors.New("unsupported content encoding")
+)
+
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+type DecompressOptions struct {
+	// MaxSize caps the decompressed body in bytes. Reading past it fails with
+	// ErrDecompressedTooLarge carrying 413 Request Entity Too Large. Defaults
+	// to 10 MiB.
+	MaxSize int64
+	// Decoders adds or replaces decoders by encoding name. gzip, deflate and
+	// br are built in; other encodings answer 415 Unsupported Media Type
+	// unless a decoder is registered here.
+	Decoders map[string]Decoder
+}
+
+// Decompress decodes request bodies sent with a Content-Encoding header,
+// applying stacked encodings in reverse order.
+func Decompress(opts DecompressOptions) func(chu.Handler) chu.Handler {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 10 << 20
+	}
+
+	decoders := map[string]Decoder{
+		"gzip":   func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		"x-gzip": func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.ReadCloser, error) {
+			return zlib.NewReader(r)
+		},
+		"br": func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(brotli.NewReader(r)), nil
+		},
+	}
+
+	for name, d := range opts.Decoders {
+		decoders[strings.ToLower(name)] = d
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			header := r.Header.Get("Content-Encoding")
+			if header == "" || r.Body == nil || r.Body == http.NoBody {
+				return next(ctx, w, r)
+			}
+
+			encodings := strings.Split(header, ",")
+
+			var body io.ReadCloser = r.Body
+			closers := []io.Closer{r.Body}
+
+			for i := len(encodings) - 1; i >= 0; i-- {
+				name := strings.ToLower(strings.TrimSpace(encodings[i]))
+				if name == "identity" {
+					continue
+				}
+
+				decode, ok := decoders[name]
+				if !ok {
+					return chu.NewError(http.StatusUnsupportedMediaType, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, name))
+				}
+
+				decoded, err := decode(body)
+				if err != nil {
+					return chu.Errorf(http.StatusBadRequest, "invalid %s request body: %w", name, err)
+				}
+
+				body = decoded
+				closers = append(closers, decoded)
+			}
+
+			r2 := r.Clone(ctx)
+			r2.Body = &decompressedBody{r: body, remaining: opts.MaxSize, closers: closers}
+			r2.ContentLength = -1
+			r2.Header.Del("Content-Encoding")
+			r2.Header.Del("Content-Length")
+
+			return next(ctx, w, r2)
+		}
+	}
+}
+
+type decompressedBody struct {
+	r         io.Reader
+	remaining int64
+	closers   []io.Closer
+}
+
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		var probe [1]byte
+		if n, _ := b.r.Read(probe[:]); n > 0 {
+			return 0, chu.NewError(http.StatusRequestEntityTooLarge, ErrDecompressedTooLarge)
+		}
+
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.r.Read(p)
+	b.remaining -= int64(n)
+
+	if err != nil && err != io.EOF {
+		// Decoders such as brotli only detect corrupt input while reading.
+		err = chu.Errorf(http.StatusBadRequest, "invalid request body: %w", err)
+	}
+
+	return n, err
+}
+
+func (b *decompressedBody) Close() error {
+	var errs []error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		errs = append(errs, b.closers[i].Close())
+	}
+
+	return errors.Join(errs...)
+}
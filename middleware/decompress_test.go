@@ -0,0 +1,155 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func deflateBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func brotliBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	bw := brotli.NewWriter(&buf)
+	_, err := bw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, bw.Close())
+
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	payload := []byte(`{"name":"chu"}`)
+
+	newRouter := func(opts middleware.DecompressOptions) *chu.Router {
+		r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			w.WriteHeader(chu.StatusCode(err))
+		}))
+		r.Use(middleware.Decompress(opts))
+		r.Post("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return err
+			}
+
+			_, err = w.Write([]byte(r.Header.Get("Content-Encoding") + "|" + string(body)))
+			return err
+		})
+
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		opts     middleware.DecompressOptions
+		expected int
+		response string
+	}{
+		{name: "plain body", body: payload, expected: http.StatusOK, response: "|" + string(payload)},
+		{name: "gzip", encoding: "gzip", body: gzipBytes(t, payload), expected: http.StatusOK, response: "|" + string(payload)},
+		{name: "deflate", encoding: "deflate", body: deflateBytes(t, payload), expected: http.StatusOK, response: "|" + string(payload)},
+		{name: "stacked encodings", encoding: "deflate, gzip", body: gzipBytes(t, deflateBytes(t, payload)), expected: http.StatusOK, response: "|" + string(payload)},
+		{name: "identity", encoding: "identity", body: payload, expected: http.StatusOK, response: "|" + string(payload)},
+		{name: "brotli", encoding: "br", body: brotliBytes(t, payload), expected: http.StatusOK, response: "|" + string(payload)},
+		{name: "unsupported encoding", encoding: "zstd", body: payload, expected: http.StatusUnsupportedMediaType},
+		{name: "corrupt brotli", encoding: "br", body: []byte("not brotli"), expected: http.StatusBadRequest},
+		{name: "corrupt gzip", encoding: "gzip", body: []byte("not gzip"), expected: http.StatusBadRequest},
+		{
+			name:     "exceeds limit",
+			encoding: "gzip",
+			body:     gzipBytes(t, bytes.Repeat([]byte("a"), 1<<20)),
+			opts:     middleware.DecompressOptions{MaxSize: 1024},
+			expected: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "brotli exceeds limit",
+			encoding: "br",
+			body:     brotliBytes(t, bytes.Repeat([]byte("a"), 1<<20)),
+			opts:     middleware.DecompressOptions{MaxSize: 1024},
+			expected: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "custom decoder",
+			encoding: "br",
+			body:     []byte(strings.ToUpper(string(payload))),
+			opts: middleware.DecompressOptions{Decoders: map[string]middleware.Decoder{
+				"br": func(r io.Reader) (io.ReadCloser, error) {
+					data, err := io.ReadAll(r)
+					return io.NopCloser(strings.NewReader(strings.ToLower(string(data)))), err
+				},
+			}},
+			expected: http.StatusOK,
+			response: "|" + string(payload),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+
+			rec := httptest.NewRecorder()
+			newRouter(tt.opts).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code, "status code should match expected")
+			if tt.response != "" {
+				assert.Equal(t, tt.response, rec.Body.String(), "decompressed body should match expected")
+			}
+		})
+	}
+}
+
+func TestDecompress_TooLargeError(t *testing.T) {
+	var readErr error
+
+	r := chu.New()
+	r.Use(middleware.Decompress(middleware.DecompressOptions{MaxSize: 16}))
+	r.Post("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, readErr = io.ReadAll(r.Body)
+		return readErr
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(gzipBytes(t, bytes.Repeat([]byte("a"), 1024))))
+	req.Header.Set("Content-Encoding", "gzip")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.ErrorIs(t, readErr, middleware.ErrDecompressedTooLarge, "read error should match the sentinel")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, chu.StatusCode(readErr), "read error should carry its status")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, "default error handler should answer 413")
+}
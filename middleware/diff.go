@@ -0,0 +1,332 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/josearomeroj/chu"
+)
+
+// Discrepancy describes how the responses of the old and new handlers of a
+// migration differ.
+type Discrepancy struct {
+	Request   *http.Request
+	OldStatus int
+	NewStatus int
+	// Diffs lists the differences, as JSON paths such as "$.items[2].price",
+	// "status" or "body" for responses that are not JSON.
+	Diffs []string
+	// NewError is the error returned by the new handler, if any.
+	NewError error
+}
+
+func (d Discrepancy) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("method", d.Request.Method),
+		slog.String("path", d.Request.URL.Path),
+		slog.Int("old_status", d.OldStatus),
+		slog.Int("new_status", d.NewStatus),
+		slog.Any("diffs", d.Diffs),
+	}
+
+	if d.NewError != nil {
+		attrs = append(attrs, slog.String("new_error", d.NewError.Error()))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+type DiffOptions struct {
+	// New is the rewritten handler compared against the wrapped one. Its
+	// response is discarded; clients always get the old response.
+	New chu.Handler
+	// SampleRate is the fraction of requests compared.
+	SampleRate float64
+	// Methods lists the methods compared, since both handlers run. Defaults to
+	// GET and HEAD.
+	Methods []string
+	// Ignore lists JSON paths, such as "$.generated_at", left out of the
+	// comparison.
+	Ignore      []string
+	MaxBodySize int64
+	// MaxDiffs bounds the differences reported per request. Defaults to 20.
+	MaxDiffs      int
+	OnDiscrepancy func(ctx context.Context, d Discrepancy)
+	Random        func() float64
+}
+
+// Diff runs the new handler after the wrapped one for sampled requests and
+// reports where their responses differ. The new handler runs once the old
+// response is complete, so sampled requests take longer.
+func Diff(opts DiffOptions) func(chu.Handler) chu.Handler {
+	if len(opts.Methods) == 0 {
+		opts.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+
+	if opts.MaxDiffs <= 0 {
+		opts.MaxDiffs = 20
+	}
+
+	if opts.Random == nil {
+		opts.Random = rand.Float64
+	}
+
+	if opts.OnDiscrepancy == nil {
+		opts.OnDiscrepancy = func(ctx context.Context, d Discrepancy) {
+			slog.WarnContext(ctx, "migration response mismatch", slog.Any("discrepancy", d))
+		}
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if opts.New == nil || !slices.Contains(opts.Methods, r.Method) || opts.Random() >= opts.SampleRate {
+				return next(ctx, w, r)
+			}
+
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				data, err := io.ReadAll(io.LimitReader(r.Body, opts.MaxBodySize+1))
+				if err != nil {
+					return err
+				}
+
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+
+				if int64(len(data)) > opts.MaxBodySize {
+					return next(ctx, w, r)
+				}
+
+				body = data
+			}
+
+			old := &diffCaptureWriter{ResponseWriter: w, limit: opts.MaxBodySize}
+			oldErr := next(ctx, old, r)
+
+			candidate := r.Clone(ctx)
+			candidate.Body = io.NopCloser(bytes.NewReader(body))
+
+			buffered := &diffBufferWriter{header: make(http.Header), limit: opts.MaxBodySize}
+			newErr := opts.New(ctx, buffered, candidate)
+
+			d := Discrepancy{Request: r, OldStatus: old.status(oldErr), NewStatus: buffered.status(newErr), NewError: newErr}
+			if d.OldStatus != d.NewStatus {
+				d.Diffs = append(d.Diffs, "status")
+			}
+
+			if oldErr == nil && newErr == nil && !old.truncated && !buffered.truncated {
+				d.Diffs = append(d.Diffs, diffBodies(old.body.Bytes(), buffered.body.Bytes(), opts.Ignore, opts.MaxDiffs)...)
+			}
+
+			if len(d.Diffs) > 0 {
+				opts.OnDiscrepancy(ctx, d)
+			}
+
+			return oldErr
+		}
+	}
+}
+
+func diffBodies(old, new []byte, ignore []string, limit int) []string {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+
+	var a, b any
+	if decodeJSON(old, &a) != nil || decodeJSON(new, &b) != nil {
+		return []string{"body"}
+	}
+
+	var diffs []string
+	diffJSON("$", a, b, ignore, limit, &diffs)
+
+	return diffs
+}
+
+func decodeJSON(data []byte, v *any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
+
+func diffJSON(path string, a, b any, ignore []string, limit int, diffs *[]string) {
+	if len(*diffs) >= limit || slices.Contains(ignore, path) {
+		return
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			diffJSON(path+"."+k, av[k], bv[k], ignore, limit, diffs)
+		}
+
+		return
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+
+		for i := range max(len(av), len(bv)) {
+			var ai, bi any
+			if i < len(av) {
+				ai = av[i]
+			}
+			if i < len(bv) {
+				bi = bv[i]
+			}
+
+			diffJSON(path+"["+strconv.Itoa(i)+"]", ai, bi, ignore, limit, diffs)
+		}
+
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s != %s", path, jsonText(a), jsonText(b)))
+	}
+}
+
+func jsonText(v any) string {
+	if v == nil {
+		return "null"
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
+// diffCaptureWriter passes the old response through while keeping a copy.
+type diffCaptureWriter struct {
+	http.ResponseWriter
+	code      int
+	body      bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (w *diffCaptureWriter) WriteHeader(status int) {
+	if w.code == 0 {
+		w.code = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *diffCaptureWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	if int64(w.body.Len()+len(b)) > w.limit {
+		w.truncated = true
+	} else {
+		w.body.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *diffCaptureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *diffCaptureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *diffCaptureWriter) status(err error) int {
+	if err != nil {
+		return chu.StatusCode(err)
+	}
+
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
+
+// diffBufferWriter collects the new response without sending it.
+type diffBufferWriter struct {
+	header    http.Header
+	code      int
+	body      bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (w *diffBufferWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *diffBufferWriter) WriteHeader(status int) {
+	if w.code == 0 {
+		w.code = status
+	}
+}
+
+func (w *diffBufferWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	if int64(w.body.Len()+len(b)) > w.limit {
+		w.truncated = true
+	} else {
+		w.body.Write(b)
+	}
+
+	return len(b), nil
+}
+
+func (w *diffBufferWriter) status(err error) int {
+	if err != nil {
+		return chu.StatusCode(err)
+	}
+
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	write := func(body string) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write([]byte(body))
+			return err
+		}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		old    string
+		new    chu.Handler
+		diffs  []string
+		status int
+	}{
+		{name: "equal", method: "GET", old: `{"a":1,"b":[1,2]}`, new: write(`{"b":[1,2],"a":1}`)},
+		{name: "field changed", method: "GET", old: `{"user":{"name":"ana","age":30}}`, new: write(`{"user":{"name":"Ana","age":30}}`),
+			diffs: []string{`$.user.name: "ana" != "Ana"`}},
+		{name: "array and missing", method: "GET", old: `{"items":[1,2,3],"total":3}`, new: write(`{"items":[1,2]}`),
+			diffs: []string{`$.items[2]: 3 != null`, `$.total: 3 != null`}},
+		{name: "ignored path", method: "GET", old: `{"id":1,"generated_at":"x"}`, new: write(`{"id":1,"generated_at":"y"}`)},
+		{name: "not json", method: "GET", old: `hello`, new: write(`bye`), diffs: []string{"body"}},
+		{name: "new fails", method: "GET", old: `{}`, new: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return chu.Errorf(http.StatusBadGateway, "boom")
+		}, diffs: []string{"status"}, status: http.StatusBadGateway},
+		{name: "unsafe method skipped", method: "POST", old: `{"a":1}`, new: write(`{"a":2}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []middleware.Discrepancy
+
+			r := chu.New()
+			r.Handle("/", write(tt.old), chu.WithMiddleware(middleware.Diff(middleware.DiffOptions{
+				New:        tt.new,
+				SampleRate: 1,
+				Ignore:     []string{"$.generated_at"},
+				OnDiscrepancy: func(ctx context.Context, d middleware.Discrepancy) {
+					got = append(got, d)
+				},
+			})))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, "/", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+			assert.Equal(t, tt.old, w.Body.String(), "old response should be served")
+
+			if tt.diffs == nil {
+				assert.Empty(t, got, "no discrepancy should be reported")
+				return
+			}
+
+			require.Len(t, got, 1, "discrepancy should be reported")
+			assert.Equal(t, tt.diffs, got[0].Diffs, "diffs should match expected")
+			if tt.status != 0 {
+				assert.Equal(t, tt.status, got[0].NewStatus, "new status should match expected")
+				assert.Error(t, got[0].NewError, "new error should be reported")
+			}
+		})
+	}
+}
+
+func TestDiffSampling(t *testing.T) {
+	calls := 0
+
+	r := chu.New()
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("old fails")
+	}, chu.WithMiddleware(middleware.Diff(middleware.DiffOptions{
+		New: func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			calls++
+			return nil
+		},
+		SampleRate: 0.5,
+		Random:     func() float64 { return 0.7 },
+	})))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "old errors should be returned")
+	assert.Zero(t, calls, "unsampled requests should not run the new handler")
+}
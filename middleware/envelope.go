@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/josearomeroj/chu"
+)
+
+type EnvelopeOptions struct {
+	RequestID    func(r *http.Request) string
+	Meta         func(r *http.Request, res *chu.Response) map[string]any
+	ErrorHandler chu.ErrorHandler
+}
+
+type envelopeBody struct {
+	Data      json.RawMessage `json:"data,omitempty"`
+	Error     json.RawMessage `json:"error,omitempty"`
+	Meta      map[string]any  `json:"meta,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+}
+
+type envelopeError struct {
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+// Envelope wraps JSON responses in {data, meta, request_id} and error
+// responses in {error, meta, request_id}. Errors returned by the handler are
+// rendered with ErrorHandler (chu.JSONErrorHandler by default) first.
+func Envelope(opts EnvelopeOptions) func(chu.Handler) chu.Handler {
+	if opts.RequestID == nil {
+		opts.RequestID = func(r *http.Request) string {
+			return cmp.Or(chimiddleware.GetReqID(r.Context()), r.Header.Get("X-Request-Id"))
+		}
+	}
+
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = chu.JSONErrorHandler
+	}
+
+	wrap := chu.Intercept(func(ctx context.Context, r *http.Request, res *chu.Response) error {
+		opts.envelope(r, res)
+		return nil
+	})
+
+	return func(next chu.Handler) chu.Handler {
+		intercepted := wrap(next)
+
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			err := intercepted(ctx, w, r)
+			if err == nil {
+				return nil
+			}
+
+			return wrap(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				opts.ErrorHandler(w, r, err)
+				return nil
+			})(ctx, w, r)
+		}
+	}
+}
+
+func (opts EnvelopeOptions) envelope(r *http.Request, res *chu.Response) {
+	failed := res.Status >= http.StatusBadRequest
+	isJSON := jsonContent(res.Header)
+
+	if !failed && (!isJSON || len(bytes.TrimSpace(res.Body)) == 0) {
+		return
+	}
+
+	body := envelopeBody{RequestID: opts.RequestID(r)}
+	if opts.Meta != nil {
+		body.Meta = opts.Meta(r, res)
+	}
+
+	switch {
+	case !failed:
+		body.Data = res.Body
+	case isJSON:
+		var wrapped struct {
+			Error json.RawMessage `json:"error"`
+		}
+
+		if json.Unmarshal(res.Body, &wrapped) == nil && len(wrapped.Error) > 0 {
+			body.Error = wrapped.Error
+		} else {
+			body.Error = res.Body
+		}
+	default:
+		message := strings.TrimSpace(string(res.Body))
+		if message == "" {
+			message = http.StatusText(res.Status)
+		}
+
+		body.Error, _ = json.Marshal(envelopeError{Message: message, Status: res.Status})
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+
+	res.Header.Set("Content-Type", "application/json")
+	res.Header.Set("X-Content-Type-Options", "nosniff")
+	res.Body = append(data, '\n')
+}
+
+func jsonContent(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	envelope := middleware.Envelope(middleware.EnvelopeOptions{
+		Meta: func(r *http.Request, res *chu.Response) map[string]any {
+			return map[string]any{"version": "v1"}
+		},
+	})
+
+	r := chu.New()
+	r.Group(func(r *chu.Router) {
+		r.Use(envelope)
+
+		r.Get("/users", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write([]byte(`[{"id":1}]`))
+			return err
+		})
+		r.Get("/text", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte("plain"))
+			return err
+		})
+		r.Get("/missing", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return chu.Errorf(http.StatusNotFound, "user not found")
+		})
+		r.Get("/raw", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			http.Error(w, "teapot", http.StatusTeapot)
+			return nil
+		})
+	})
+	r.Get("/route", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("database down")
+	}, chu.WithMiddleware(envelope))
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+	}{
+		{name: "json data", path: "/users", status: http.StatusOK, body: `{"data":[{"id":1}],"meta":{"version":"v1"},"request_id":"req-1"}`},
+		{name: "non json passes through", path: "/text", status: http.StatusOK, body: "plain"},
+		{name: "use level error", path: "/missing", status: http.StatusNotFound, body: `{"error":{"message":"user not found","status":404},"meta":{"version":"v1"},"request_id":"req-1"}`},
+		{name: "text error", path: "/raw", status: http.StatusTeapot, body: `{"error":{"message":"teapot","status":418},"meta":{"version":"v1"},"request_id":"req-1"}`},
+		{name: "route level error", path: "/route", status: http.StatusInternalServerError, body: `{"error":{"code":"INTERNAL_SERVER_ERROR","message":"Internal Server Error","status":500},"meta":{"version":"v1"},"request_id":"req-1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("X-Request-Id", "req-1")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code, "status code should match expected")
+			if tt.body == "plain" {
+				assert.Equal(t, tt.body, rec.Body.String(), "body should match expected")
+				return
+			}
+
+			assert.JSONEq(t, tt.body, rec.Body.String(), "body should match expected")
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "content type should be json")
+		})
+	}
+}
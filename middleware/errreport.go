@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"cmp"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/cache"
+)
+
+type ErrorStats struct {
+	Fingerprint string
+	Route       string
+	Chain       string
+	Message     string
+	Count       uint64
+	Suppressed  uint64
+	FirstSeen   time.Time
+	LastSeen    time.Time
+
+	lastLogged time.Time
+}
+
+type ErrorReporterOptions struct {
+	Interval  time.Duration
+	MinStatus int
+	// MaxEntries bounds the fingerprints tracked; the least recently seen are
+	// forgotten beyond it. Defaults to 1000.
+	MaxEntries int
+	Logger     *slog.Logger
+	Now        func() time.Time
+}
+
+type ErrorReporter struct {
+	opts ErrorReporterOptions
+
+	mu    sync.Mutex
+	stats *cache.Cache[string, *ErrorStats]
+}
+
+func NewErrorReporter(opts ErrorReporterOptions) *ErrorReporter {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+
+	if opts.MinStatus == 0 {
+		opts.MinStatus = http.StatusInternalServerError
+	}
+
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1000
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return &ErrorReporter{
+		opts:  opts,
+		stats: cache.New(cache.Options[string, *ErrorStats]{MaxEntries: opts.MaxEntries}),
+	}
+}
+
+func (rep *ErrorReporter) Wrap(next chu.ErrorHandler) chu.ErrorHandler {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		rep.Report(r, err)
+		next(w, r, err)
+	}
+}
+
+func (rep *ErrorReporter) Report(r *http.Request, err error) {
+	status := chu.StatusCode(err)
+	if status < rep.opts.MinStatus {
+		return
+	}
+
+	// Unmatched paths share one key, so probing random URLs cannot grow the
+	// stats.
+	route := r.Method + " " + cmp.Or(chu.RoutePattern(r), "unmatched")
+	chain := errorChain(err)
+	fingerprint := Fingerprint(route, chain)
+	now := rep.opts.Now()
+
+	rep.mu.Lock()
+
+	s, ok := rep.stats.Get(fingerprint)
+	if !ok {
+		s = &ErrorStats{Fingerprint: fingerprint, Route: route, Chain: chain, FirstSeen: now}
+		rep.stats.Set(fingerprint, s)
+	}
+
+	s.Count++
+	s.LastSeen, s.Message = now, err.Error()
+
+	if !s.lastLogged.IsZero() && now.Sub(s.lastLogged) < rep.opts.Interval {
+		s.Suppressed++
+		rep.mu.Unlock()
+
+		return
+	}
+
+	suppressed := s.Suppressed
+	s.Suppressed = 0
+	s.lastLogged = now
+	count := s.Count
+
+	rep.mu.Unlock()
+
+	rep.opts.Logger.ErrorContext(r.Context(), "handler error",
+		"fingerprint", fingerprint,
+		"route", route,
+		"status", status,
+		"error", err.Error(),
+		"chain", chain,
+		"count", count,
+		"suppressed", suppressed,
+	)
+}
+
+func (rep *ErrorReporter) Stats() []ErrorStats {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	stats := make([]ErrorStats, 0, rep.stats.Len())
+	for _, s := range rep.stats.All() {
+		stats = append(stats, *s)
+	}
+
+	return stats
+}
+
+func Fingerprint(route, chain string) string {
+	h := fnv.New64a()
+	h.Write([]byte(route))
+	h.Write([]byte{0})
+	h.Write([]byte(chain))
+
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func errorChain(err error) string {
+	var parts []string
+
+	for err != nil {
+		part := reflect.TypeOf(err).String()
+
+		switch e := err.(type) {
+		case *chu.Code:
+			part += "(" + e.Code + ")"
+		case *chu.Error:
+			if e.Code != nil {
+				part += "(" + e.Code.Code + ")"
+			}
+		}
+
+		parts = append(parts, part)
+
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			var joined []string
+			for _, inner := range e.Unwrap() {
+				joined = append(joined, errorChain(inner))
+			}
+
+			parts = append(parts, "["+strings.Join(joined, ", ")+"]")
+			err = nil
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			err = nil
+		}
+	}
+
+	return strings.Join(parts, " > ")
+}
@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errDatabase = errors.New("database unavailable")
+
+func TestErrorReporter(t *testing.T) {
+	var buf syncBuffer
+	now := time.Unix(0, 0)
+
+	rep := middleware.NewErrorReporter(middleware.ErrorReporterOptions{
+		Interval: time.Minute,
+		Logger:   slog.New(slog.NewTextHandler(&buf, nil)),
+		Now:      func() time.Time { return now },
+	})
+
+	r := chu.New(chu.WithErrorHandler(rep.Wrap(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(chu.StatusCode(err))
+	})))
+	r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("loading user %s: %w", chu.URLParam(r, "id"), errDatabase)
+	})
+	r.Get("/orders", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errDatabase
+	})
+	r.Get("/bad", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.Errorf(http.StatusBadRequest, "bad input")
+	})
+
+	call := func(path string) {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	for i := 0; i < 5; i++ {
+		call(fmt.Sprintf("/users/%d", i))
+	}
+
+	call("/orders")
+	call("/bad")
+
+	assert.Equal(t, 2, strings.Count(buf.String(), "handler error"), "repeated errors should be logged once per interval")
+
+	now = now.Add(2 * time.Minute)
+	call("/users/9")
+
+	logs := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, logs, 3, "error should be logged again after the interval")
+	assert.Contains(t, logs[2], "suppressed=4", "log should report suppressed occurrences")
+	assert.Contains(t, logs[2], "count=6", "log should report total occurrences")
+
+	stats := rep.Stats()
+	require.Len(t, stats, 2, "errors should be grouped by route and chain")
+
+	counts := map[string]uint64{}
+	for _, s := range stats {
+		counts[s.Route] = s.Count
+	}
+
+	assert.Equal(t, map[string]uint64{"GET /users/{id}": 6, "GET /orders": 1}, counts, "counters should match expected")
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, middleware.Fingerprint("GET /a", "x"), middleware.Fingerprint("GET /a", "x"), "fingerprint should be stable")
+	assert.NotEqual(t, middleware.Fingerprint("GET /a", "x"), middleware.Fingerprint("GET /b", "x"), "fingerprint should depend on route")
+}
+
+func TestErrorReporterBounded(t *testing.T) {
+	rep := middleware.NewErrorReporter(middleware.ErrorReporterOptions{
+		MaxEntries: 2,
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	for i := range 10 {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/missing/%d", i), nil)
+		rep.Report(req, chu.NewError(http.StatusInternalServerError, fmt.Errorf("order %d: %w", i, errors.New("lookup failed"))))
+	}
+
+	stats := rep.Stats()
+	require.Len(t, stats, 1, "unmatched paths and varying messages should share a fingerprint")
+	assert.Equal(t, "GET unmatched", stats[0].Route, "route should match expected")
+	assert.Equal(t, uint64(10), stats[0].Count, "count should match expected")
+
+	for _, method := range []string{"POST", "PUT", "DELETE"} {
+		rep.Report(httptest.NewRequest(method, "/", nil), errDatabase)
+	}
+
+	assert.Len(t, rep.Stats(), 2, "tracked fingerprints should be bounded")
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"hash/fnv"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+type experimentsCtxKey struct{}
+
+type Variant struct {
+	Name   string
+	Weight int
+}
+
+type ExperimentOptions struct {
+	Name         string
+	Variants     []Variant
+	Key          func(r *http.Request) string
+	Cookie       string
+	CookieMaxAge time.Duration
+	Header       string
+	Random       func(n int) int
+}
+
+// Experiment assigns requests to a weighted variant, sticky through a cookie
+// or a hash of Key, and exposes it through ExperimentVariant, a response
+// header and an "experiment.<name>" field on the request's wide event, so
+// logs and metrics derived from it can be split by variant.
+func Experiment(opts ExperimentOptions) func(chu.Handler) chu.Handler {
+	if opts.Cookie == "" {
+		opts.Cookie = "exp_" + opts.Name
+	}
+
+	if opts.CookieMaxAge == 0 {
+		opts.CookieMaxAge = 30 * 24 * time.Hour
+	}
+
+	if opts.Header == "" {
+		opts.Header = "X-Experiment-" + opts.Name
+	}
+
+	if opts.Random == nil {
+		opts.Random = rand.IntN
+	}
+
+	total := 0
+	for _, v := range opts.Variants {
+		total += max(v.Weight, 0)
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if total == 0 {
+				return next(ctx, w, r)
+			}
+
+			variant, fromCookie := opts.assign(r, total)
+
+			if !fromCookie {
+				http.SetCookie(w, &http.Cookie{
+					Name:     opts.Cookie,
+					Value:    variant,
+					Path:     "/",
+					MaxAge:   int(opts.CookieMaxAge.Seconds()),
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+
+			w.Header().Set(opts.Header, variant)
+			chu.Annotate(ctx, "experiment."+opts.Name, variant)
+
+			assignments := maps.Clone(Experiments(ctx))
+			if assignments == nil {
+				assignments = make(map[string]string)
+			}
+
+			assignments[opts.Name] = variant
+
+			ctx = context.WithValue(ctx, experimentsCtxKey{}, assignments)
+
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+func (opts ExperimentOptions) assign(r *http.Request, total int) (string, bool) {
+	if c, err := r.Cookie(opts.Cookie); err == nil {
+		for _, v := range opts.Variants {
+			if v.Name == c.Value && v.Weight > 0 {
+				return v.Name, true
+			}
+		}
+	}
+
+	var point int
+	if key := keyOf(opts.Key, r); key != "" {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(opts.Name + ":" + key))
+		point = int(h.Sum64() % uint64(total))
+	} else {
+		point = opts.Random(total)
+	}
+
+	for _, v := range opts.Variants {
+		if v.Weight <= 0 {
+			continue
+		}
+
+		if point < v.Weight {
+			return v.Name, false
+		}
+
+		point -= v.Weight
+	}
+
+	return opts.Variants[len(opts.Variants)-1].Name, false
+}
+
+func ExperimentVariant(ctx context.Context, name string) string {
+	return Experiments(ctx)[name]
+}
+
+func Experiments(ctx context.Context) map[string]string {
+	assignments, _ := ctx.Value(experimentsCtxKey{}).(map[string]string)
+	return assignments
+}
+
+func keyOf(keyer func(r *http.Request) string, r *http.Request) string {
+	if keyer == nil {
+		return ""
+	}
+
+	return keyer(r)
+}
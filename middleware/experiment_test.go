@@ -0,0 +1,114 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperiment(t *testing.T) {
+	newRouter := func(random int) *chu.Router {
+		r := chu.New()
+		r.Use(middleware.Experiment(middleware.ExperimentOptions{
+			Name:     "checkout",
+			Variants: []middleware.Variant{{Name: "control", Weight: 1}, {Name: "new-flow", Weight: 1}},
+			Key:      func(r *http.Request) string { return r.Header.Get("X-User-Id") },
+			Random:   func(int) int { return random },
+		}))
+		r.Use(middleware.Experiment(middleware.ExperimentOptions{
+			Name:     "banner",
+			Variants: []middleware.Variant{{Name: "blue", Weight: 1}},
+		}))
+		r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			assert.Equal(t, "blue", middleware.ExperimentVariant(ctx, "banner"), "every experiment should be visible")
+			_, _ = w.Write([]byte(middleware.ExperimentVariant(ctx, "checkout")))
+			return nil
+		})
+
+		return r
+	}
+
+	tests := []struct {
+		name            string
+		random          int
+		userID          string
+		cookie          string
+		expectedVariant string
+		expectCookie    bool
+	}{
+		{name: "random assignment", random: 1, expectedVariant: "new-flow", expectCookie: true},
+		{name: "sticky cookie", random: 1, cookie: "control", expectedVariant: "control"},
+		{name: "unknown cookie is reassigned", random: 0, cookie: "removed", expectedVariant: "control", expectCookie: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "exp_checkout", Value: tt.cookie})
+			}
+
+			w := httptest.NewRecorder()
+			newRouter(tt.random).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedVariant, w.Body.String(), "variant should match expected")
+			assert.Equal(t, tt.expectedVariant, w.Header().Get("X-Experiment-checkout"), "variant header should be set")
+
+			var cookie *http.Cookie
+			for _, c := range w.Result().Cookies() {
+				if c.Name == "exp_checkout" {
+					cookie = c
+				}
+			}
+
+			if !tt.expectCookie {
+				assert.Nil(t, cookie, "cookie should not be rewritten")
+				return
+			}
+
+			require.NotNil(t, cookie, "assignment cookie should be set")
+			assert.Equal(t, tt.expectedVariant, cookie.Value, "cookie should store the variant")
+		})
+	}
+
+	t.Run("hash of user id is stable", func(t *testing.T) {
+		variants := map[string]bool{}
+		for _, random := range []int{0, 1} {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-User-Id", "user-42")
+
+			w := httptest.NewRecorder()
+			newRouter(random).ServeHTTP(w, req)
+
+			variants[w.Body.String()] = true
+		}
+
+		assert.Len(t, variants, 1, "user id assignment should not depend on randomness")
+	})
+}
+
+func TestExperimentWideEvent(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := chu.New(chu.WithWideEvents(slog.New(slog.NewTextHandler(&buf, nil))))
+	r.Use(middleware.Experiment(middleware.ExperimentOptions{
+		Name:     "checkout",
+		Variants: []middleware.Variant{{Name: "control", Weight: 1}, {Name: "new-flow", Weight: 1}},
+		Random:   func(n int) int { return 1 },
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	assert.Contains(t, buf.String(), "experiment.checkout=new-flow", "wide event should carry the variant")
+}
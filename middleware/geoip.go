@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrGeoBlocked = errors.New("request blocked by location")
+
+type geoLocationCtxKey struct{}
+
+type GeoLocation struct {
+	IP           netip.Addr
+	Country      string
+	ASN          uint32
+	Organization string
+}
+
+func (l GeoLocation) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("ip", l.IP.String()),
+		slog.String("country", l.Country),
+		slog.Any("asn", l.ASN),
+		slog.String("org", l.Organization),
+	)
+}
+
+// GeoProvider resolves client addresses, typically backed by a MaxMind or
+// similar database. Country codes are ISO 3166-1 alpha-2.
+type GeoProvider interface {
+	Lookup(ctx context.Context, ip netip.Addr) (GeoLocation, error)
+}
+
+type GeoProviderFunc func(ctx context.Context, ip netip.Addr) (GeoLocation, error)
+
+func (f GeoProviderFunc) Lookup(ctx context.Context, ip netip.Addr) (GeoLocation, error) {
+	return f(ctx, ip)
+}
+
+type GeoIPOptions struct {
+	Provider GeoProvider
+	// ClientIP extracts the address to look up. Defaults to r.RemoteAddr, so put
+	// a trusted proxy middleware such as chi's RealIP in front when needed.
+	ClientIP func(r *http.Request) (netip.Addr, bool)
+
+	AllowCountries []string
+	BlockCountries []string
+	BlockASNs      []uint32
+
+	// Reroute returns the handler that should serve the request instead of the
+	// route, or nil to continue normally.
+	Reroute func(loc GeoLocation, r *http.Request) chu.Handler
+
+	// FailClosed blocks requests whose address cannot be resolved instead of
+	// letting them through with an empty location.
+	FailClosed bool
+}
+
+// GeoIP resolves the client location, blocks or reroutes the request by
+// country or ASN, and stores the location in the context for handlers and
+// logging.
+func GeoIP(opts GeoIPOptions) func(chu.Handler) chu.Handler {
+	if opts.Provider == nil {
+		panic("middleware: GeoIP requires a provider")
+	}
+
+	if opts.ClientIP == nil {
+		opts.ClientIP = remoteIP
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ip, ok := opts.ClientIP(r)
+
+			loc := GeoLocation{IP: ip}
+			if ok {
+				found, err := opts.Provider.Lookup(ctx, ip)
+				if err == nil {
+					loc = found
+					loc.IP = ip
+				} else if opts.FailClosed {
+					return chu.NewError(http.StatusForbidden, fmt.Errorf("%w: %v", ErrGeoBlocked, err))
+				}
+			} else if opts.FailClosed {
+				return chu.NewError(http.StatusForbidden, ErrGeoBlocked)
+			}
+
+			if !opts.allowed(loc) {
+				return chu.NewError(http.StatusForbidden, ErrGeoBlocked)
+			}
+
+			ctx = context.WithValue(ctx, geoLocationCtxKey{}, loc)
+			r = r.WithContext(ctx)
+
+			if opts.Reroute != nil {
+				if h := opts.Reroute(loc, r); h != nil {
+					return h(ctx, w, r)
+				}
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func GeoLocationFromContext(ctx context.Context) (GeoLocation, bool) {
+	loc, ok := ctx.Value(geoLocationCtxKey{}).(GeoLocation)
+	return loc, ok
+}
+
+func (opts GeoIPOptions) allowed(loc GeoLocation) bool {
+	if loc.ASN != 0 && slices.Contains(opts.BlockASNs, loc.ASN) {
+		return false
+	}
+
+	country := func(c string) bool { return strings.EqualFold(c, loc.Country) }
+	if slices.ContainsFunc(opts.BlockCountries, country) {
+		return false
+	}
+
+	if len(opts.AllowCountries) > 0 {
+		return slices.ContainsFunc(opts.AllowCountries, country)
+	}
+
+	return true
+}
+
+func remoteIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return ip.Unmap(), true
+}
@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoIP(t *testing.T) {
+	db := map[string]middleware.GeoLocation{
+		"203.0.113.1":  {Country: "ES", ASN: 3352},
+		"203.0.113.2":  {Country: "KP", ASN: 131279},
+		"203.0.113.3":  {Country: "US", ASN: 64500},
+		"203.0.113.4":  {Country: "DE", ASN: 3320},
+		"2001:db8::10": {Country: "es", ASN: 3352},
+	}
+
+	provider := middleware.GeoProviderFunc(func(ctx context.Context, ip netip.Addr) (middleware.GeoLocation, error) {
+		loc, ok := db[ip.String()]
+		if !ok {
+			return middleware.GeoLocation{}, errors.New("not found")
+		}
+		return loc, nil
+	})
+
+	r := chu.New()
+	r.Use(middleware.GeoIP(middleware.GeoIPOptions{
+		Provider:       provider,
+		BlockCountries: []string{"KP"},
+		BlockASNs:      []uint32{64500},
+		Reroute: func(loc middleware.GeoLocation, r *http.Request) chu.Handler {
+			if loc.Country != "DE" {
+				return nil
+			}
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				_, err := w.Write([]byte("eu"))
+				return err
+			}
+		},
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		loc, _ := middleware.GeoLocationFromContext(ctx)
+		_, err := w.Write([]byte(loc.IP.String() + " " + loc.Country))
+		return err
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		status     int
+		body       string
+	}{
+		{name: "allowed", remoteAddr: "203.0.113.1:1234", status: http.StatusOK, body: "203.0.113.1 ES"},
+		{name: "ipv6", remoteAddr: "[2001:db8::10]:1234", status: http.StatusOK, body: "2001:db8::10 es"},
+		{name: "blocked country", remoteAddr: "203.0.113.2:1234", status: http.StatusForbidden},
+		{name: "blocked asn", remoteAddr: "203.0.113.3:1234", status: http.StatusForbidden},
+		{name: "rerouted", remoteAddr: "203.0.113.4:1234", status: http.StatusOK, body: "eu"},
+		{name: "unknown fails open", remoteAddr: "198.51.100.1:1234", status: http.StatusOK, body: "198.51.100.1 "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code, "status code should match expected")
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+		})
+	}
+}
+
+func TestGeoIPAllowList(t *testing.T) {
+	provider := middleware.GeoProviderFunc(func(ctx context.Context, ip netip.Addr) (middleware.GeoLocation, error) {
+		if ip == netip.MustParseAddr("203.0.113.1") {
+			return middleware.GeoLocation{Country: "ES"}, nil
+		}
+		return middleware.GeoLocation{}, errors.New("not found")
+	})
+
+	r := chu.New()
+	r.Use(middleware.GeoIP(middleware.GeoIPOptions{
+		Provider:       provider,
+		AllowCountries: []string{"es", "pt"},
+		FailClosed:     true,
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	call := func(remoteAddr string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, call("203.0.113.1:80"), "allowed country should pass")
+	assert.Equal(t, http.StatusForbidden, call("198.51.100.1:80"), "unresolved address should be blocked when failing closed")
+	assert.Equal(t, http.StatusForbidden, call("not-an-ip"), "unparsable address should be blocked when failing closed")
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+type LatencyHeadersOptions struct {
+	// Header is the request header that asks for the latency headers. Defaults
+	// to "X-Debug-Latency".
+	Header string
+	// Always sends the headers to allowed clients even without the request
+	// header.
+	Always bool
+	// Allow lists the networks that may get the headers, since they reveal
+	// backend details. Requests from other addresses never get them.
+	Allow []netip.Prefix
+	// ClientIP extracts the address matched against Allow. Defaults to
+	// r.RemoteAddr, so put a trusted proxy middleware such as chi's RealIP in
+	// front when needed.
+	ClientIP func(r *http.Request) (netip.Addr, bool)
+	// RequestStartHeader carries the time the front proxy received the
+	// request, as Unix seconds, milliseconds or microseconds with an optional
+	// "t=" prefix. Defaults to "X-Request-Start".
+	RequestStartHeader string
+	Now                func() time.Time
+}
+
+// LatencyHeaders adds X-Handler-Duration, X-Queue-Time and, for requests with
+// a deadline, X-Deadline-Remaining to the responses of allowed clients that
+// ask for them, for triaging slow requests in production without tracing.
+// Durations are taken when the response headers are written.
+func LatencyHeaders(opts LatencyHeadersOptions) func(chu.Handler) chu.Handler {
+	if opts.Header == "" {
+		opts.Header = "X-Debug-Latency"
+	}
+
+	if opts.ClientIP == nil {
+		opts.ClientIP = remoteIP
+	}
+
+	if opts.RequestStartHeader == "" {
+		opts.RequestStartHeader = "X-Request-Start"
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	allowed := func(r *http.Request) bool {
+		if !opts.Always && r.Header.Get(opts.Header) == "" {
+			return false
+		}
+
+		ip, ok := opts.ClientIP(r)
+		return ok && slices.ContainsFunc(opts.Allow, func(p netip.Prefix) bool { return p.Contains(ip) })
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if !allowed(r) {
+				return next(ctx, w, r)
+			}
+
+			lw := &latencyWriter{ResponseWriter: w, ctx: ctx, now: opts.Now, start: opts.Now()}
+			if received, ok := parseRequestStart(r.Header.Get(opts.RequestStartHeader)); ok {
+				lw.queue = max(lw.start.Sub(received), 0)
+				lw.hasQueue = true
+			}
+
+			if err := next(ctx, lw, r); err != nil {
+				return err
+			}
+
+			if !lw.wroteHeader {
+				lw.WriteHeader(http.StatusOK)
+			}
+
+			return nil
+		}
+	}
+}
+
+func parseRequestStart(value string) (time.Time, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "t=")
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+
+	switch {
+	case n > 1e15:
+		return time.UnixMicro(int64(n)), true
+	case n > 1e12:
+		return time.UnixMilli(int64(n)), true
+	default:
+		return time.Unix(0, int64(n*float64(time.Second))), true
+	}
+}
+
+type latencyWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	now         func() time.Time
+	start       time.Time
+	queue       time.Duration
+	hasQueue    bool
+	wroteHeader bool
+}
+
+func (w *latencyWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status >= http.StatusOK {
+		w.wroteHeader = true
+
+		now := w.now()
+		h := w.Header()
+		h.Set("X-Handler-Duration", formatLatency(now.Sub(w.start)))
+
+		if w.hasQueue {
+			h.Set("X-Queue-Time", formatLatency(w.queue))
+		}
+
+		if deadline, ok := w.ctx.Deadline(); ok {
+			h.Set("X-Deadline-Remaining", formatLatency(max(deadline.Sub(now), 0)))
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *latencyWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *latencyWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+
+		f.Flush()
+	}
+}
+
+func (w *latencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func formatLatency(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
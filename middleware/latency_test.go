@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyHeaders(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+
+	r := chu.New()
+	r.Use(middleware.LatencyHeaders(middleware.LatencyHeadersOptions{
+		Allow: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		Now:   func() time.Time { return now },
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		now = now.Add(25 * time.Millisecond)
+		return nil
+	})
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		debug        bool
+		requestStart string
+		deadline     time.Duration
+		handler      string
+		queue        string
+	}{
+		{name: "allowed client", remoteAddr: "10.1.2.3:1234", debug: true, handler: "25ms"},
+		{name: "queue time in seconds", remoteAddr: "10.1.2.3:1234", debug: true, requestStart: "t=" + strconv.FormatFloat(float64(start.Add(-40*time.Millisecond).UnixMilli())/1000, 'f', 3, 64), handler: "25ms", queue: "40ms"},
+		{name: "queue time in milliseconds", remoteAddr: "10.1.2.3:1234", debug: true, requestStart: strconv.FormatInt(start.Add(-7*time.Millisecond).UnixMilli(), 10), handler: "25ms", queue: "7ms"},
+		{name: "queue time in microseconds", remoteAddr: "10.1.2.3:1234", debug: true, requestStart: "t=" + strconv.FormatInt(start.Add(-1500*time.Microsecond).UnixMicro(), 10), handler: "25ms", queue: "1.5ms"},
+		{name: "clock skew", remoteAddr: "10.1.2.3:1234", debug: true, requestStart: strconv.FormatInt(start.Add(time.Second).UnixMilli(), 10), handler: "25ms", queue: "0s"},
+		{name: "deadline", remoteAddr: "10.1.2.3:1234", debug: true, deadline: time.Hour, handler: "25ms"},
+		{name: "not requested", remoteAddr: "10.1.2.3:1234"},
+		{name: "client not allowed", remoteAddr: "203.0.113.1:1234", debug: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = start
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.debug {
+				req.Header.Set("X-Debug-Latency", "1")
+			}
+			if tt.requestStart != "" {
+				req.Header.Set("X-Request-Start", tt.requestStart)
+			}
+			if tt.deadline > 0 {
+				ctx, cancel := context.WithTimeout(req.Context(), tt.deadline)
+				defer cancel()
+				req = req.WithContext(ctx)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+			assert.Equal(t, tt.handler, rec.Header().Get("X-Handler-Duration"), "handler duration should match expected")
+			assert.Equal(t, tt.queue, rec.Header().Get("X-Queue-Time"), "queue time should match expected")
+			assert.Equal(t, tt.deadline > 0, rec.Header().Get("X-Deadline-Remaining") != "", "deadline header presence should match expected")
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+type MirrorOptions struct {
+	Upstream    *url.URL
+	SampleRate  float64
+	Client      *http.Client
+	Timeout     time.Duration
+	MaxBodySize int64
+	// MaxInFlight caps concurrent shadow requests. Sampled requests beyond it
+	// are not mirrored and reported to OnDrop. Defaults to 100.
+	MaxInFlight int
+	OnError     func(r *http.Request, err error)
+	OnDrop      func(r *http.Request)
+	Random      func() float64
+}
+
+func Mirror(opts MirrorOptions) func(chu.Handler) chu.Handler {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = 100
+	}
+
+	if opts.Random == nil {
+		opts.Random = rand.Float64
+	}
+
+	inflight := make(chan struct{}, opts.MaxInFlight)
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if opts.Upstream == nil || opts.Random() >= opts.SampleRate {
+				return next(ctx, w, r)
+			}
+
+			select {
+			case inflight <- struct{}{}:
+			default:
+				if opts.OnDrop != nil {
+					opts.OnDrop(r)
+				}
+
+				return next(ctx, w, r)
+			}
+
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				data, err := io.ReadAll(io.LimitReader(r.Body, opts.MaxBodySize+1))
+				if err != nil {
+					<-inflight
+					return err
+				}
+
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+
+				if int64(len(data)) > opts.MaxBodySize {
+					<-inflight
+					return next(ctx, w, r)
+				}
+
+				body = data
+			}
+
+			shadow := shadowRequest(opts.Upstream, r, body)
+			go func() {
+				defer func() { <-inflight }()
+				sendShadow(opts, shadow, r)
+			}()
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func shadowRequest(upstream *url.URL, r *http.Request, body []byte) *http.Request {
+	target := *upstream
+	target.Path = strings.TrimSuffix(upstream.Path, "/") + r.URL.Path
+	target.RawPath = ""
+	target.RawQuery = r.URL.RawQuery
+
+	shadow, _ := http.NewRequest(r.Method, target.String(), bytes.NewReader(body))
+	shadow.Header = r.Header.Clone()
+	shadow.Header.Set("X-Shadow-Request", "1")
+
+	for _, h := range hopHeaders {
+		shadow.Header.Del(h)
+	}
+
+	return shadow
+}
+
+func sendShadow(opts MirrorOptions, shadow, original *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	resp, err := opts.Client.Do(shadow.WithContext(ctx))
+	if err != nil {
+		if opts.OnError != nil {
+			opts.OnError(original, err)
+		}
+
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,113 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type shadowHit struct {
+	method, uri, body, shadowHeader string
+}
+
+func TestMirror(t *testing.T) {
+	hits := make(chan shadowHit, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hits <- shadowHit{r.Method, r.URL.RequestURI(), string(body), r.Header.Get("X-Shadow-Request")}
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer upstream.Close()
+
+	target, err := url.Parse(upstream.URL + "/v2")
+	require.NoError(t, err, "upstream URL should parse")
+
+	tests := []struct {
+		name       string
+		sampleRate float64
+		expectHit  bool
+	}{
+		{name: "sampled", sampleRate: 1, expectHit: true},
+		{name: "not sampled", sampleRate: 0, expectHit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chu.New()
+			r.Use(middleware.Mirror(middleware.MirrorOptions{Upstream: target, SampleRate: tt.sampleRate}))
+
+			var received string
+			r.Post("/orders", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				body, err := io.ReadAll(r.Body)
+				received = string(body)
+				w.WriteHeader(http.StatusCreated)
+				return err
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("POST", "/orders?dry=1", strings.NewReader("order")))
+
+			assert.Equal(t, http.StatusCreated, w.Code, "primary response should be served")
+			assert.Equal(t, "order", received, "primary handler should receive the body")
+
+			select {
+			case hit := <-hits:
+				require.True(t, tt.expectHit, "unexpected shadow request")
+				assert.Equal(t, shadowHit{"POST", "/v2/orders?dry=1", "order", "1"}, hit, "shadow request should match")
+			case <-time.After(200 * time.Millisecond):
+				assert.False(t, tt.expectHit, "shadow request should be sent")
+			}
+		})
+	}
+}
+
+func TestMirrorSaturated(t *testing.T) {
+	release := make(chan struct{})
+	var shadows atomic.Int32
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shadows.Add(1)
+		<-release
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	target, err := url.Parse(upstream.URL)
+	require.NoError(t, err, "upstream URL should parse")
+
+	var drops atomic.Int32
+
+	r := chu.New()
+	r.Use(middleware.Mirror(middleware.MirrorOptions{
+		Upstream:    target,
+		SampleRate:  1,
+		MaxInFlight: 1,
+		OnDrop:      func(r *http.Request) { drops.Add(1) },
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	require.Eventually(t, func() bool { return shadows.Load() == 1 }, time.Second, 5*time.Millisecond, "first request should be mirrored")
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code, "primary response should be served while saturated")
+	}
+
+	assert.Equal(t, int32(3), drops.Load(), "requests beyond the limit should be dropped")
+	assert.Equal(t, int32(1), shadows.Load(), "dropped requests should not be mirrored")
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/josearomeroj/chu"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrInvalidPathEncoding = errors.New("invalid path encoding")
+	ErrPathTraversal       = errors.New("path traversal rejected")
+	ErrEncodedSlash        = errors.New("encoded slash rejected")
+)
+
+type NormalizeOptions struct {
+	AllowEncodedSlash bool
+	NFC               bool
+	Redirect          bool
+}
+
+func Normalize(opts NormalizeOptions) func(chu.Handler) chu.Handler {
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			path, rawPath, err := normalizePath(r.URL.EscapedPath(), opts)
+			if err != nil {
+				return chu.NewError(http.StatusBadRequest, err)
+			}
+
+			if path == r.URL.Path && rawPath == r.URL.RawPath {
+				return next(ctx, w, r)
+			}
+
+			if opts.Redirect && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+				target := url.URL{Path: path, RawPath: rawPath, RawQuery: r.URL.RawQuery}
+				http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+
+				return nil
+			}
+
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path, r2.URL.RawPath = path, rawPath
+
+			return next(ctx, w, r2)
+		}
+	}
+}
+
+func normalizePath(escaped string, opts NormalizeOptions) (string, string, error) {
+	var segments []string
+	encodedSlash := false
+
+	for _, seg := range strings.Split(escaped, "/") {
+		decoded, err := url.PathUnescape(seg)
+		if err != nil || !utf8.ValidString(decoded) || hasControl(decoded) {
+			return "", "", ErrInvalidPathEncoding
+		}
+
+		if strings.Contains(decoded, "\\") {
+			return "", "", ErrPathTraversal
+		}
+
+		if strings.Contains(decoded, "/") {
+			if !opts.AllowEncodedSlash {
+				return "", "", ErrEncodedSlash
+			}
+
+			encodedSlash = true
+		}
+
+		if opts.NFC {
+			decoded = norm.NFC.String(decoded)
+		}
+
+		switch decoded {
+		case "", ".":
+			continue
+		case "..":
+			return "", "", ErrPathTraversal
+		}
+
+		segments = append(segments, decoded)
+	}
+
+	path := "/" + strings.Join(segments, "/")
+	if len(segments) > 0 && strings.HasSuffix(escaped, "/") {
+		path += "/"
+	}
+
+	if !encodedSlash {
+		return path, "", nil
+	}
+
+	escapedSegments := make([]string, len(segments))
+	for i, seg := range segments {
+		escapedSegments[i] = url.PathEscape(seg)
+	}
+
+	rawPath := "/" + strings.Join(escapedSegments, "/")
+	if strings.HasSuffix(path, "/") && len(segments) > 0 {
+		rawPath += "/"
+	}
+
+	return path, rawPath, nil
+}
+
+func hasControl(s string) bool {
+	for _, c := range s {
+		if c < 0x20 || c == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}
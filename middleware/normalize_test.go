@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	var handlerErr error
+
+	newRouter := func(opts middleware.NormalizeOptions) *chu.Router {
+		r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handlerErr = err
+			w.WriteHeader(chu.StatusCode(err))
+		}))
+		r.Use(middleware.Normalize(opts))
+		r.Get("/*", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte(r.URL.Path + "|" + r.URL.RawPath))
+			return err
+		})
+
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		target   string
+		opts     middleware.NormalizeOptions
+		expected int
+		body     string
+		err      error
+	}{
+		{name: "clean path", target: "/files/a.txt", expected: http.StatusOK, body: "/files/a.txt|"},
+		{name: "double slashes", target: "//files///a.txt", expected: http.StatusOK, body: "/files/a.txt|"},
+		{name: "dot segments", target: "/files/./a/./b/", expected: http.StatusOK, body: "/files/a/b/|"},
+		{name: "encoded unreserved", target: "/fil%65s/%61.txt", expected: http.StatusOK, body: "/files/a.txt|"},
+		{name: "traversal", target: "/files/../etc/passwd", expected: http.StatusBadRequest, err: middleware.ErrPathTraversal},
+		{name: "encoded traversal", target: "/files/%2e%2e/etc/passwd", expected: http.StatusBadRequest, err: middleware.ErrPathTraversal},
+		{name: "backslash traversal", target: "/files/..%5c..%5cwin.ini", expected: http.StatusBadRequest, err: middleware.ErrPathTraversal},
+		{name: "overlong encoding", target: "/files/%c0%ae%c0%ae/x", expected: http.StatusBadRequest, err: middleware.ErrInvalidPathEncoding},
+		{name: "encoded null", target: "/files/a%00.txt", expected: http.StatusBadRequest, err: middleware.ErrInvalidPathEncoding},
+		{name: "encoded slash", target: "/files/a%2Fb", expected: http.StatusBadRequest, err: middleware.ErrEncodedSlash},
+		{
+			name: "allowed encoded slash", target: "/files//a%2Fb", opts: middleware.NormalizeOptions{AllowEncodedSlash: true},
+			expected: http.StatusOK, body: "/files/a/b|/files/a%2Fb",
+		},
+		{
+			name: "unicode NFC", target: "/caf%65%CC%81", opts: middleware.NormalizeOptions{NFC: true},
+			expected: http.StatusOK, body: "/café|",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerErr = nil
+
+			w := httptest.NewRecorder()
+			newRouter(tt.opts).ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+
+			if tt.err != nil {
+				assert.ErrorIs(t, handlerErr, tt.err, "error should match expected")
+			} else {
+				assert.Equal(t, tt.body, w.Body.String(), "normalized path should match expected")
+			}
+		})
+	}
+}
+
+func TestNormalize_Redirect(t *testing.T) {
+	r := chu.New()
+	r.Use(middleware.Normalize(middleware.NormalizeOptions{Redirect: true}))
+	r.Get("/*", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "//a/./b?x=1", nil))
+
+	assert.Equal(t, http.StatusMovedPermanently, w.Code, "status code should match expected")
+	assert.Equal(t, "/a/b?x=1", w.Header().Get("Location"), "redirect should target the canonical path")
+}
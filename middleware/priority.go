@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrShed = errors.New("request shed under load")
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+)
+
+type PriorityOptions struct {
+	Classify      func(r *http.Request) Priority
+	MaxConcurrent int
+	MaxQueue      int
+	QueueTimeout  time.Duration
+	RetryAfter    time.Duration
+}
+
+func Prioritize(opts PriorityOptions) func(chu.Handler) chu.Handler {
+	if opts.Classify == nil {
+		opts.Classify = func(*http.Request) Priority { return PriorityNormal }
+	}
+
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = 100
+	}
+
+	if opts.QueueTimeout <= 0 {
+		opts.QueueTimeout = time.Second
+	}
+
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = time.Second
+	}
+
+	s := &scheduler{limit: opts.MaxConcurrent, maxQueue: opts.MaxQueue}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			p := opts.Classify(r)
+			if p >= PriorityCritical {
+				return next(ctx, w, r)
+			}
+
+			waitCtx, cancel := context.WithTimeout(ctx, opts.QueueTimeout)
+			err := s.acquire(waitCtx, p)
+			cancel()
+
+			if err != nil {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(opts.RetryAfter.Seconds()))))
+				return chu.NewError(http.StatusServiceUnavailable, ErrShed)
+			}
+
+			defer s.release()
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func PriorityByHeader(header string, classes map[string]Priority, fallback Priority) func(r *http.Request) Priority {
+	return func(r *http.Request) Priority {
+		if p, ok := classes[strings.ToLower(r.Header.Get(header))]; ok {
+			return p
+		}
+
+		return fallback
+	}
+}
+
+func PriorityByPath(prefixes map[string]Priority, fallback Priority) func(r *http.Request) Priority {
+	return func(r *http.Request) Priority {
+		best, matched := fallback, -1
+
+		for prefix, p := range prefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) && len(prefix) > matched {
+				best, matched = p, len(prefix)
+			}
+		}
+
+		return best
+	}
+}
+
+type waiter struct {
+	priority Priority
+	seq      uint64
+	ready    chan error
+	index    int
+}
+
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index, q[j].index = i, j
+}
+
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	*q = old[:len(old)-1]
+	w.index = -1
+
+	return w
+}
+
+type scheduler struct {
+	mu       sync.Mutex
+	limit    int
+	maxQueue int
+	active   int
+	seq      uint64
+	queue    waitQueue
+}
+
+func (s *scheduler) acquire(ctx context.Context, p Priority) error {
+	s.mu.Lock()
+
+	if s.active < s.limit && len(s.queue) == 0 {
+		s.active++
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	if s.maxQueue > 0 && len(s.queue) >= s.maxQueue {
+		lowest := s.lowest()
+		if lowest == nil || lowest.priority >= p {
+			s.mu.Unlock()
+			return ErrShed
+		}
+
+		heap.Remove(&s.queue, lowest.index)
+		lowest.ready <- ErrShed
+	}
+
+	s.seq++
+	w := &waiter{priority: p, seq: s.seq, ready: make(chan error, 1)}
+	heap.Push(&s.queue, w)
+	s.mu.Unlock()
+
+	select {
+	case err := <-w.ready:
+		return err
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if w.index >= 0 {
+			heap.Remove(&s.queue, w.index)
+			return ctx.Err()
+		}
+
+		if err := <-w.ready; err != nil {
+			return err
+		}
+
+		s.releaseLocked()
+
+		return ctx.Err()
+	}
+}
+
+func (s *scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.releaseLocked()
+}
+
+func (s *scheduler) releaseLocked() {
+	if len(s.queue) > 0 {
+		w := heap.Pop(&s.queue).(*waiter)
+		w.ready <- nil
+
+		return
+	}
+
+	s.active--
+}
+
+func (s *scheduler) lowest() *waiter {
+	var lowest *waiter
+
+	for _, w := range s.queue {
+		if lowest == nil || w.priority < lowest.priority || (w.priority == lowest.priority && w.seq > lowest.seq) {
+			lowest = w
+		}
+	}
+
+	return lowest
+}
@@ -0,0 +1,153 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type priorityApp struct {
+	router  *chu.Router
+	started chan string
+	release chan struct{}
+
+	mu    sync.Mutex
+	order []string
+}
+
+func newPriorityApp(opts middleware.PriorityOptions) *priorityApp {
+	app := &priorityApp{started: make(chan string, 10), release: make(chan struct{})}
+
+	opts.Classify = middleware.PriorityByHeader("X-Priority", map[string]middleware.Priority{
+		"low":      middleware.PriorityLow,
+		"high":     middleware.PriorityHigh,
+		"critical": middleware.PriorityCritical,
+	}, middleware.PriorityNormal)
+
+	app.router = chu.New()
+	app.router.Use(middleware.Prioritize(opts))
+	app.router.Get("/{name}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		name := chu.URLParam(r, "name")
+
+		app.mu.Lock()
+		app.order = append(app.order, name)
+		app.mu.Unlock()
+
+		app.started <- name
+		if name == "block" {
+			<-app.release
+		}
+
+		return nil
+	})
+
+	return app
+}
+
+func (app *priorityApp) serve(name, priority string) chan int {
+	codes := make(chan int, 1)
+
+	go func() {
+		req := httptest.NewRequest("GET", "/"+name, nil)
+		req.Header.Set("X-Priority", priority)
+
+		w := httptest.NewRecorder()
+		app.router.ServeHTTP(w, req)
+		codes <- w.Code
+	}()
+
+	return codes
+}
+
+func TestPrioritize_Order(t *testing.T) {
+	app := newPriorityApp(middleware.PriorityOptions{MaxConcurrent: 1, QueueTimeout: time.Second})
+
+	blocked := app.serve("block", "normal")
+	require.Equal(t, "block", <-app.started, "first request should run")
+
+	low := app.serve("low", "low")
+	time.Sleep(10 * time.Millisecond)
+	high := app.serve("high", "high")
+	time.Sleep(10 * time.Millisecond)
+
+	critical := app.serve("critical", "critical")
+	assert.Equal(t, "critical", <-app.started, "critical requests should bypass the queue")
+	assert.Equal(t, http.StatusOK, <-critical, "status code should match expected")
+
+	close(app.release)
+
+	for _, codes := range []chan int{blocked, high, low} {
+		assert.Equal(t, http.StatusOK, <-codes, "status code should match expected")
+	}
+
+	assert.Equal(t, []string{"block", "critical", "high", "low"}, app.order, "higher priority should be scheduled first")
+}
+
+func TestPrioritize_Shedding(t *testing.T) {
+	app := newPriorityApp(middleware.PriorityOptions{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: time.Second})
+
+	blocked := app.serve("block", "normal")
+	<-app.started
+
+	low := app.serve("low", "low")
+	time.Sleep(10 * time.Millisecond)
+
+	high := app.serve("high", "high")
+	assert.Equal(t, http.StatusServiceUnavailable, <-low, "queued low priority request should be evicted")
+
+	rejected := app.serve("low2", "low")
+	assert.Equal(t, http.StatusServiceUnavailable, <-rejected, "low priority request should be rejected when queue is full")
+
+	close(app.release)
+
+	assert.Equal(t, http.StatusOK, <-blocked, "status code should match expected")
+	assert.Equal(t, http.StatusOK, <-high, "status code should match expected")
+}
+
+func TestPrioritize_QueueTimeout(t *testing.T) {
+	app := newPriorityApp(middleware.PriorityOptions{MaxConcurrent: 1, QueueTimeout: 20 * time.Millisecond, RetryAfter: 1500 * time.Millisecond})
+
+	blocked := app.serve("block", "normal")
+	<-app.started
+
+	req := httptest.NewRequest("GET", "/late", nil)
+	w := httptest.NewRecorder()
+	app.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "status code should match expected")
+	assert.Equal(t, "2", w.Header().Get("Retry-After"), "retry after should be rounded up")
+
+	close(app.release)
+	assert.Equal(t, http.StatusOK, <-blocked, "status code should match expected")
+
+	w = httptest.NewRecorder()
+	app.router.ServeHTTP(w, httptest.NewRequest("GET", "/after", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "slot should be released after timeout")
+}
+
+func TestPriorityByPath(t *testing.T) {
+	classify := middleware.PriorityByPath(map[string]middleware.Priority{
+		"/health":      middleware.PriorityCritical,
+		"/api":         middleware.PriorityNormal,
+		"/api/premium": middleware.PriorityHigh,
+	}, middleware.PriorityLow)
+
+	tests := map[string]middleware.Priority{
+		"/health/live":      middleware.PriorityCritical,
+		"/api/users":        middleware.PriorityNormal,
+		"/api/premium/data": middleware.PriorityHigh,
+		"/static/app.js":    middleware.PriorityLow,
+	}
+
+	for path, expected := range tests {
+		assert.Equal(t, expected, classify(httptest.NewRequest("GET", path, nil)), "priority should match expected for %s", path)
+	}
+}
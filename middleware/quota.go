@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+type QuotaPeriod int
+
+const (
+	QuotaDaily QuotaPeriod = iota
+	QuotaMonthly
+)
+
+type QuotaStore interface {
+	Increment(ctx context.Context, key string, window time.Time, n int64) (int64, error)
+}
+
+type UsageEvent struct {
+	Key      string
+	Window   time.Time
+	Used     int64
+	Limit    int64
+	Exceeded bool
+	Request  *http.Request
+}
+
+type QuotaOptions struct {
+	Period   QuotaPeriod
+	Limit    int64
+	LimitFor func(key string) int64
+	Key      func(r *http.Request) string
+	Store    QuotaStore
+	OnUsage  func(ctx context.Context, event UsageEvent)
+	Now      func() time.Time
+}
+
+func Quota(opts QuotaOptions) func(chu.Handler) chu.Handler {
+	if opts.Key == nil {
+		opts.Key = func(r *http.Request) string { return r.Header.Get("X-API-Key") }
+	}
+
+	if opts.Store == nil {
+		opts.Store = NewMemoryQuotaStore()
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key := opts.Key(r)
+			if key == "" {
+				return next(ctx, w, r)
+			}
+
+			limit := opts.Limit
+			if opts.LimitFor != nil {
+				limit = opts.LimitFor(key)
+			}
+
+			now := opts.Now().UTC()
+			window, reset := opts.Period.bounds(now)
+
+			cost := int64(chu.RouteCost(r))
+
+			used, err := opts.Store.Increment(ctx, key, window, cost)
+			if err != nil {
+				return err
+			}
+
+			exceeded := limit > 0 && used > limit
+			if exceeded {
+				// Rejected requests do not consume quota, so a client retrying
+				// too early cannot push its usage further past the limit.
+				if used, err = opts.Store.Increment(ctx, key, window, -cost); err != nil {
+					return err
+				}
+			}
+
+			w.Header().Set("X-Quota-Limit", strconv.FormatInt(limit, 10))
+			w.Header().Set("X-Quota-Remaining", strconv.FormatInt(max(limit-used, 0), 10))
+			w.Header().Set("X-Quota-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+			if opts.OnUsage != nil {
+				opts.OnUsage(ctx, UsageEvent{
+					Key:      key,
+					Window:   window,
+					Used:     used,
+					Limit:    limit,
+					Exceeded: exceeded,
+					Request:  r,
+				})
+			}
+
+			if exceeded {
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(reset.Sub(now).Seconds())+1, 10))
+				return chu.NewError(http.StatusTooManyRequests, ErrQuotaExceeded)
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func (p QuotaPeriod) bounds(now time.Time) (time.Time, time.Time) {
+	if p == QuotaMonthly {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+type MemoryQuotaStore struct {
+	mu     sync.Mutex
+	counts map[string]quotaCount
+}
+
+type quotaCount struct {
+	window time.Time
+	used   int64
+}
+
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counts: make(map[string]quotaCount)}
+}
+
+func (s *MemoryQuotaStore) Increment(_ context.Context, key string, window time.Time, n int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.counts[key]
+	if !c.window.Equal(window) {
+		c = quotaCount{window: window}
+	}
+
+	c.used += n
+	s.counts[key] = c
+
+	return c.used, nil
+}
+
+// StoreQuota adapts a chu.Store, such as a Redis backed one, so quotas are
+// shared between instances.
+func StoreQuota(store chu.Store) QuotaStore {
+	return storeQuota{store: store}
+}
+
+type storeQuota struct {
+	store chu.Store
+}
+
+func (s storeQuota) Increment(ctx context.Context, key string, window time.Time, n int64) (int64, error) {
+	// Windows last at most a month; the counter outlives it slightly so late
+	// requests still see it.
+	return s.store.Increment(ctx, "quota:"+key+":"+strconv.FormatInt(window.Unix(), 10), n, 32*24*time.Hour)
+}
@@ -0,0 +1,131 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuota(t *testing.T) {
+	now := time.Date(2024, 3, 15, 23, 59, 0, 0, time.UTC)
+
+	var events []middleware.UsageEvent
+
+	r := chu.New()
+	r.Use(middleware.Quota(middleware.QuotaOptions{
+		Period: middleware.QuotaDaily,
+		Limit:  2,
+		LimitFor: func(key string) int64 {
+			if key == "premium" {
+				return 100
+			}
+
+			return 2
+		},
+		Now: func() time.Time { return now },
+		OnUsage: func(ctx context.Context, event middleware.UsageEvent) {
+			events = append(events, event)
+		},
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	call := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", key)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		w := call("basic")
+		assert.Equal(t, http.StatusOK, w.Code, "requests within quota should succeed")
+		assert.Equal(t, strconv.Itoa(1-i), w.Header().Get("X-Quota-Remaining"), "remaining quota should decrease")
+	}
+
+	w := call("basic")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "request over quota should be rejected")
+	assert.Equal(t, "61", w.Header().Get("Retry-After"), "retry should be at the next window")
+	assert.Equal(t, strconv.FormatInt(time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC).Unix(), 10),
+		w.Header().Get("X-Quota-Reset"), "reset should be the next day")
+
+	assert.Equal(t, http.StatusOK, call("premium").Code, "other keys should have their own quota")
+	assert.Equal(t, http.StatusOK, call("").Code, "anonymous requests should not be counted")
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, http.StatusOK, call("basic").Code, "quota should reset in the next window")
+
+	assert.Len(t, events, 5, "every counted request should emit a usage event")
+	assert.True(t, events[2].Exceeded, "rejected request should be flagged")
+	assert.Equal(t, int64(2), events[2].Used, "usage should not include the rejected request")
+}
+
+func TestQuotaRouteCost(t *testing.T) {
+	r := chu.New()
+	r.Use(middleware.Quota(middleware.QuotaOptions{Limit: 10, Store: middleware.StoreQuota(chu.NewMemoryStore(0))}))
+
+	ok := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+	r.Get("/cheap", ok)
+	r.Get("/report", ok, chu.Cost(4))
+
+	call := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-API-Key", "key")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	w := call("/report")
+	assert.Equal(t, http.StatusOK, w.Code, "expensive request within quota should succeed")
+	assert.Equal(t, "6", w.Header().Get("X-Quota-Remaining"), "expensive request should consume its cost")
+
+	w = call("/cheap")
+	assert.Equal(t, "5", w.Header().Get("X-Quota-Remaining"), "cheap request should consume one unit")
+
+	assert.Equal(t, http.StatusOK, call("/report").Code, "second expensive request should fit the budget")
+	w = call("/report")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "expensive request over budget should be rejected")
+	assert.Equal(t, "1", w.Header().Get("X-Quota-Remaining"), "rejected request should not consume quota")
+
+	assert.Equal(t, http.StatusOK, call("/cheap").Code, "remaining quota should still be usable")
+	assert.Equal(t, http.StatusTooManyRequests, call("/cheap").Code, "exhausted quota should reject")
+}
+
+func TestQuotaPeriods(t *testing.T) {
+	now := time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC)
+
+	r := chu.New()
+	r.Use(middleware.Quota(middleware.QuotaOptions{
+		Period: middleware.QuotaMonthly,
+		Limit:  1,
+		Now:    func() time.Time { return now },
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-API-Key", "k")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, strconv.FormatInt(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), 10),
+		w.Header().Get("X-Quota-Reset"), "monthly quota should reset at the start of next month")
+}
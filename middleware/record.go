@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/redact"
+)
+
+type RecordedRequest struct {
+	Time       time.Time   `json:"time"`
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Host       string      `json:"host"`
+	Proto      string      `json:"proto"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body,omitempty"`
+	Truncated  bool        `json:"truncated,omitempty"`
+	RemoteAddr string      `json:"remote_addr"`
+}
+
+type RecordOptions struct {
+	MaxBodySize   int64
+	RedactHeaders []string
+	Redactor      *redact.Redactor
+	Filter        func(r *http.Request) bool
+	// Logger reports requests that could not be recorded; they are still
+	// served. Defaults to slog.Default.
+	Logger *slog.Logger
+	Now    func() time.Time
+}
+
+func Record(w io.Writer, opts RecordOptions) func(chu.Handler) chu.Handler {
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+
+	if opts.RedactHeaders == nil {
+		opts.RedactHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if opts.Filter != nil && !opts.Filter(r) {
+				return next(ctx, w, r)
+			}
+
+			rec := RecordedRequest{
+				Time:       opts.Now(),
+				Method:     r.Method,
+				URL:        r.URL.RequestURI(),
+				Host:       r.Host,
+				Proto:      r.Proto,
+				Header:     r.Header.Clone(),
+				RemoteAddr: r.RemoteAddr,
+			}
+
+			for _, name := range opts.RedactHeaders {
+				if rec.Header.Get(name) != "" {
+					rec.Header.Set(name, redact.Mask)
+				}
+			}
+
+			if r.Body != nil && r.Body != http.NoBody {
+				body, err := io.ReadAll(io.LimitReader(r.Body, opts.MaxBodySize+1))
+
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+
+				// The handler sees the same failure when it reads the body.
+				if err != nil {
+					opts.Logger.ErrorContext(ctx, "request not recorded", "error", err)
+					return next(ctx, w, r)
+				}
+
+				if int64(len(body)) > opts.MaxBodySize {
+					body, rec.Truncated = body[:opts.MaxBodySize], true
+				}
+
+				rec.Body = body
+
+				if opts.Redactor != nil {
+					if rec.Body, err = opts.Redactor.JSON(body); err != nil {
+						rec.Body = []byte(redact.Mask)
+					}
+				}
+			}
+
+			mu.Lock()
+			err := enc.Encode(rec)
+			mu.Unlock()
+
+			// A full disk must not fail the traffic being recorded.
+			if err != nil {
+				opts.Logger.ErrorContext(ctx, "request not recorded", "error", err)
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func ReadRecords(r io.Reader) ([]RecordedRequest, error) {
+	var records []RecordedRequest
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 64<<20)
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var rec RecordedRequest
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, err
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, scanner.Err()
+}
+
+func (rec RecordedRequest) Request() *http.Request {
+	req := httptest.NewRequest(rec.Method, rec.URL, bytes.NewReader(rec.Body))
+	req.Header = rec.Header.Clone()
+	req.Host = rec.Host
+	req.RemoteAddr = rec.RemoteAddr
+
+	return req
+}
@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/josearomeroj/chu/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := chu.New()
+	r.Use(middleware.Record(&buf, middleware.RecordOptions{
+		MaxBodySize: 4,
+		Filter:      func(r *http.Request) bool { return r.URL.Path != "/healthz" },
+	}))
+
+	var received string
+	r.Post("/items", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		received = string(body)
+		return err
+	})
+	r.Get("/healthz", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	req := httptest.NewRequest("POST", "/items?debug=1", strings.NewReader("payload"))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Trace", "abc")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.Equal(t, "payload", received, "handler should receive the full body")
+
+	records, err := middleware.ReadRecords(&buf)
+	require.NoError(t, err, "records should be readable")
+	require.Len(t, records, 1, "filtered requests should not be recorded")
+
+	rec := records[0]
+	assert.Equal(t, "POST", rec.Method, "method should be recorded")
+	assert.Equal(t, "/items?debug=1", rec.URL, "url should be recorded")
+	assert.Equal(t, "[REDACTED]", rec.Header.Get("Authorization"), "sensitive headers should be redacted")
+	assert.Equal(t, "abc", rec.Header.Get("X-Trace"), "other headers should be recorded")
+	assert.Equal(t, []byte("payl"), rec.Body, "body should be truncated to MaxBodySize")
+	assert.True(t, rec.Truncated, "truncation should be flagged")
+}
+
+func TestRecordRedactsBody(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := chu.New()
+	r.Use(middleware.Record(&buf, middleware.RecordOptions{
+		Redactor: redact.MustNew(redact.WithPaths("$.password"), redact.WithFields("token")),
+	}))
+	r.Post("/login", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		assert.Contains(t, string(body), "hunter2", "handler should receive the original body")
+		return nil
+	})
+
+	for _, body := range []string{`{"user":"ana","password":"hunter2","meta":{"token":"abc"}}`, `password=hunter2`} {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	records, err := middleware.ReadRecords(&buf)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.JSONEq(t, `{"user":"ana","password":"[REDACTED]","meta":{"token":"[REDACTED]"}}`, string(records[0].Body), "json body should be redacted")
+	assert.Equal(t, "[REDACTED]", string(records[1].Body), "unparsable body should be masked")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+func TestRecordWriteFailure(t *testing.T) {
+	var logs bytes.Buffer
+
+	r := chu.New()
+	r.Use(middleware.Record(failingWriter{}, middleware.RecordOptions{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := io.WriteString(w, "ok")
+		return err
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "ok", w.Body.String(), "request should be served")
+	assert.Contains(t, logs.String(), "no space left on device", "failure should be logged")
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrResponseTooLarge = errors.New("response body too large")
+
+type ResponseSize struct {
+	Bytes    int64
+	Status   int
+	Exceeded bool
+}
+
+type ResponseLimitOptions struct {
+	MaxBytes   int64
+	OnResponse func(r *http.Request, size ResponseSize)
+}
+
+func ResponseLimit(opts ResponseLimitOptions) func(chu.Handler) chu.Handler {
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			lw := &limitedResponseWriter{ResponseWriter: w, max: opts.MaxBytes}
+
+			err := next(ctx, lw, r)
+
+			if opts.OnResponse != nil {
+				opts.OnResponse(r, ResponseSize{Bytes: lw.written, Status: lw.statusCode(), Exceeded: lw.exceeded})
+			}
+
+			if !lw.exceeded {
+				return err
+			}
+
+			if !lw.committed {
+				w.Header().Del("Content-Length")
+				return chu.NewError(http.StatusInternalServerError, ErrResponseTooLarge)
+			}
+
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+type limitedResponseWriter struct {
+	http.ResponseWriter
+	max       int64
+	written   int64
+	status    int
+	committed bool
+	exceeded  bool
+}
+
+func (w *limitedResponseWriter) WriteHeader(status int) {
+	if w.committed || w.exceeded {
+		return
+	}
+
+	if w.max > 0 {
+		if n, err := strconv.ParseInt(w.Header().Get("Content-Length"), 10, 64); err == nil && n > w.max {
+			w.exceeded = true
+			return
+		}
+	}
+
+	w.status, w.committed = status, true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *limitedResponseWriter) Write(b []byte) (int, error) {
+	if w.exceeded {
+		return 0, ErrResponseTooLarge
+	}
+
+	if w.max > 0 && w.written+int64(len(b)) > w.max {
+		w.exceeded = true
+		return 0, ErrResponseTooLarge
+	}
+
+	if !w.committed {
+		w.WriteHeader(http.StatusOK)
+
+		if w.exceeded {
+			return 0, ErrResponseTooLarge
+		}
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+
+	return n, err
+}
+
+func (w *limitedResponseWriter) Flush() {
+	if w.exceeded {
+		return
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.committed {
+			w.WriteHeader(http.StatusOK)
+		}
+
+		f.Flush()
+	}
+}
+
+func (w *limitedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *limitedResponseWriter) statusCode() int {
+	if w.status == 0 && !w.exceeded {
+		return http.StatusOK
+	}
+
+	return w.status
+}
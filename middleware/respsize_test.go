@@ -0,0 +1,89 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseLimit(t *testing.T) {
+	var sizes []middleware.ResponseSize
+
+	limit := middleware.ResponseLimit(middleware.ResponseLimitOptions{
+		MaxBytes:   10,
+		OnResponse: func(r *http.Request, size middleware.ResponseSize) { sizes = append(sizes, size) },
+	})
+
+	r := chu.New()
+	r.Get("/small", limit(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte("hello"))
+		return err
+	}))
+	r.Get("/large", limit(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte(strings.Repeat("x", 11)))
+		assert.ErrorIs(t, err, middleware.ErrResponseTooLarge, "oversized write should fail")
+		return nil
+	}))
+	r.Get("/declared", limit(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Length", "100")
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}))
+	r.Get("/unlimited", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte(strings.Repeat("x", 100)))
+		return err
+	})
+
+	tests := []struct {
+		path     string
+		expected int
+		body     string
+		size     middleware.ResponseSize
+	}{
+		{path: "/small", expected: http.StatusOK, body: "hello", size: middleware.ResponseSize{Bytes: 5, Status: http.StatusOK}},
+		{path: "/large", expected: http.StatusInternalServerError, size: middleware.ResponseSize{Exceeded: true}},
+		{path: "/declared", expected: http.StatusInternalServerError, size: middleware.ResponseSize{Exceeded: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			sizes = nil
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+
+			require.Len(t, sizes, 1, "size should be recorded")
+			assert.Equal(t, tt.size, sizes[0], "recorded size should match expected")
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/unlimited", nil))
+	assert.Equal(t, 100, w.Body.Len(), "routes without the limit should be unaffected")
+}
+
+func TestResponseLimit_AbortsCommittedResponse(t *testing.T) {
+	r := chu.New()
+	r.Use(middleware.ResponseLimit(middleware.ResponseLimitOptions{MaxBytes: 4}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("abc"))
+		_, _ = w.Write([]byte("def"))
+		return nil
+	})
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}, "partially written response should abort the connection")
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var (
+	ErrBotBlocked     = errors.New("automated client blocked")
+	ErrBotRateLimited = errors.New("automated client rate limited")
+)
+
+type userAgentCtxKey struct{}
+
+type AgentClass int
+
+const (
+	AgentUnknown AgentClass = iota
+	AgentBrowser
+	AgentBot
+	AgentCrawler
+)
+
+func (c AgentClass) String() string {
+	switch c {
+	case AgentBrowser:
+		return "browser"
+	case AgentBot:
+		return "bot"
+	case AgentCrawler:
+		return "crawler"
+	default:
+		return "unknown"
+	}
+}
+
+type UserAgent struct {
+	Raw   string
+	Class AgentClass
+	Name  string
+}
+
+func (ua UserAgent) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("class", ua.Class.String()),
+		slog.String("name", ua.Name),
+	)
+}
+
+type agentRule struct {
+	token string
+	name  string
+	class AgentClass
+}
+
+// Rules are checked in order against the lowercased user agent, so specific
+// tokens come before generic ones.
+var agentRules = []agentRule{
+	{"googlebot", "Googlebot", AgentCrawler},
+	{"bingbot", "Bingbot", AgentCrawler},
+	{"duckduckbot", "DuckDuckBot", AgentCrawler},
+	{"baiduspider", "Baiduspider", AgentCrawler},
+	{"yandexbot", "YandexBot", AgentCrawler},
+	{"applebot", "Applebot", AgentCrawler},
+	{"slurp", "Yahoo Slurp", AgentCrawler},
+	{"facebookexternalhit", "Facebook", AgentCrawler},
+	{"gptbot", "GPTBot", AgentCrawler},
+	{"headlesschrome", "HeadlessChrome", AgentBot},
+	{"curl/", "curl", AgentBot},
+	{"wget/", "Wget", AgentBot},
+	{"python-requests", "python-requests", AgentBot},
+	{"go-http-client", "Go-http-client", AgentBot},
+	{"okhttp", "OkHttp", AgentBot},
+	{"bot", "", AgentBot},
+	{"spider", "", AgentBot},
+	{"crawl", "", AgentBot},
+	{"edg/", "Edge", AgentBrowser},
+	{"opr/", "Opera", AgentBrowser},
+	{"firefox/", "Firefox", AgentBrowser},
+	{"chrome/", "Chrome", AgentBrowser},
+	{"safari/", "Safari", AgentBrowser},
+}
+
+// ClassifyUserAgent is the default classifier. It recognizes the major search
+// engine crawlers, common HTTP libraries and the mainstream browsers.
+func ClassifyUserAgent(raw string) UserAgent {
+	ua := UserAgent{Raw: raw}
+
+	lower := strings.ToLower(raw)
+	for _, rule := range agentRules {
+		if strings.Contains(lower, rule.token) {
+			ua.Class, ua.Name = rule.class, rule.name
+			return ua
+		}
+	}
+
+	return ua
+}
+
+type UserAgentOptions struct {
+	// Classify replaces the default ClassifyUserAgent matcher.
+	Classify func(raw string) UserAgent
+	// Block rejects the given classes with 403 Forbidden.
+	Block []AgentClass
+	// Limit caps requests from each named agent of the RateLimited classes to
+	// Limit route cost units per Window. Zero disables rate limiting.
+	Limit       int
+	Window      time.Duration
+	RateLimited []AgentClass
+	Now         func() time.Time
+}
+
+// UserAgentFilter classifies the client, stores the classification in the
+// context and blocks or rate limits automated clients.
+func UserAgentFilter(opts UserAgentOptions) func(chu.Handler) chu.Handler {
+	if opts.Classify == nil {
+		opts.Classify = ClassifyUserAgent
+	}
+
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+
+	if opts.RateLimited == nil {
+		opts.RateLimited = []AgentClass{AgentBot, AgentCrawler}
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	limiter := &agentLimiter{window: opts.Window, counts: make(map[string]int)}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ua := opts.Classify(r.UserAgent())
+
+			if slices.Contains(opts.Block, ua.Class) {
+				return chu.NewError(http.StatusForbidden, ErrBotBlocked)
+			}
+
+			if opts.Limit > 0 && slices.Contains(opts.RateLimited, ua.Class) {
+				key := ua.Class.String() + ":" + ua.Name
+				if ua.Name == "" {
+					key += ua.Raw
+				}
+
+				if retry, ok := limiter.allow(key, chu.RouteCost(r), opts.Limit, opts.Now()); !ok {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+					return chu.NewError(http.StatusTooManyRequests, ErrBotRateLimited)
+				}
+			}
+
+			ctx = context.WithValue(ctx, userAgentCtxKey{}, ua)
+
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+func UserAgentFromContext(ctx context.Context) (UserAgent, bool) {
+	ua, ok := ctx.Value(userAgentCtxKey{}).(UserAgent)
+	return ua, ok
+}
+
+type agentLimiter struct {
+	mu     sync.Mutex
+	window time.Duration
+	start  time.Time
+	counts map[string]int
+}
+
+func (l *agentLimiter) allow(key string, cost, limit int, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.start) >= l.window {
+		l.start = now.Truncate(l.window)
+		clear(l.counts)
+	}
+
+	if l.counts[key]+cost > limit {
+		return l.start.Add(l.window).Sub(now), false
+	}
+
+	l.counts[key] += cost
+	return 0, true
+}
@@ -0,0 +1,93 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyUserAgent(t *testing.T) {
+	tests := []struct {
+		ua    string
+		class middleware.AgentClass
+		name  string
+	}{
+		{ua: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", class: middleware.AgentCrawler, name: "Googlebot"},
+		{ua: "curl/8.4.0", class: middleware.AgentBot, name: "curl"},
+		{ua: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0 Safari/537.36", class: middleware.AgentBot, name: "HeadlessChrome"},
+		{ua: "SomeMonitoringBot/1.0", class: middleware.AgentBot},
+		{ua: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36 Edg/120.0", class: middleware.AgentBrowser, name: "Edge"},
+		{ua: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15", class: middleware.AgentBrowser, name: "Safari"},
+		{ua: "", class: middleware.AgentUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ua, func(t *testing.T) {
+			ua := middleware.ClassifyUserAgent(tt.ua)
+
+			assert.Equal(t, tt.class, ua.Class, "class should match expected")
+			assert.Equal(t, tt.name, ua.Name, "name should match expected")
+		})
+	}
+}
+
+func TestUserAgentFilter(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 30, 0, time.UTC)
+
+	r := chu.New()
+	r.Group(func(r *chu.Router) {
+		r.Use(middleware.UserAgentFilter(middleware.UserAgentOptions{
+			Block:  []middleware.AgentClass{middleware.AgentBot},
+			Limit:  3,
+			Window: time.Minute,
+			Now:    func() time.Time { return now },
+		}))
+		r.Get("/page", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ua, _ := middleware.UserAgentFromContext(ctx)
+			_, err := w.Write([]byte(ua.Class.String()))
+			return err
+		})
+		r.Get("/search", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return nil
+		}, chu.Cost(2))
+	})
+
+	call := func(path, ua string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("User-Agent", ua)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	const googlebot = "Mozilla/5.0 (compatible; Googlebot/2.1)"
+	const browser = "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0"
+
+	w := call("/page", browser)
+	assert.Equal(t, http.StatusOK, w.Code, "browsers should pass")
+	assert.Equal(t, "browser", w.Body.String(), "classification should be in the context")
+
+	assert.Equal(t, http.StatusForbidden, call("/page", "curl/8.4.0").Code, "blocked class should be rejected")
+
+	assert.Equal(t, http.StatusOK, call("/search", googlebot).Code, "crawler within limit should pass")
+	assert.Equal(t, "crawler", call("/page", googlebot).Body.String(), "crawler within limit should pass")
+
+	w = call("/page", googlebot)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "crawler over limit should be rate limited")
+	assert.Equal(t, "30", w.Header().Get("Retry-After"), "retry should be at the next window")
+
+	for range 5 {
+		assert.Equal(t, http.StatusOK, call("/page", browser).Code, "browsers should not be rate limited")
+	}
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, http.StatusOK, call("/page", googlebot).Code, "limit should reset in the next window")
+}
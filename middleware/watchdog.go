@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+type WatchdogReport struct {
+	Method      string
+	Path        string
+	Elapsed     time.Duration
+	GoroutineID uint64
+	Stack       []byte
+	Stages      []string
+}
+
+type WatchdogOptions struct {
+	Threshold time.Duration
+	Report    func(ctx context.Context, report WatchdogReport)
+	Logger    *slog.Logger
+}
+
+func Watchdog(opts WatchdogOptions) func(chu.Handler) chu.Handler {
+	if opts.Threshold <= 0 {
+		opts.Threshold = 30 * time.Second
+	}
+
+	if opts.Report == nil {
+		logger := opts.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		opts.Report = func(ctx context.Context, report WatchdogReport) {
+			logger.WarnContext(ctx, "slow request",
+				"method", report.Method,
+				"path", report.Path,
+				"elapsed", report.Elapsed,
+				"goroutine", report.GoroutineID,
+				"stages", report.Stages,
+				"stack", string(report.Stack),
+			)
+		}
+	}
+
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			id := goroutineID()
+			start := time.Now()
+
+			timer := time.AfterFunc(opts.Threshold, func() {
+				opts.Report(ctx, WatchdogReport{
+					Method:      r.Method,
+					Path:        r.URL.Path,
+					Elapsed:     time.Since(start),
+					GoroutineID: id,
+					Stack:       goroutineStack(id),
+					Stages:      chu.ActiveStages(ctx),
+				})
+			})
+			defer timer.Stop()
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		buf = buf[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+func goroutineStack(id uint64) []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	prefix := []byte("goroutine " + strconv.FormatUint(id, 10) + " ")
+
+	for _, block := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(block, prefix) {
+			return block
+		}
+	}
+
+	return nil
+}
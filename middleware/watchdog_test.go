@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stuckHandler(release chan struct{}) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		<-release
+		return nil
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	reports := make(chan middleware.WatchdogReport, 1)
+	release := make(chan struct{})
+
+	r := chu.New()
+	r.Use(middleware.Watchdog(middleware.WatchdogOptions{
+		Threshold: 10 * time.Millisecond,
+		Report: func(ctx context.Context, report middleware.WatchdogReport) {
+			reports <- report
+			close(release)
+		},
+	}))
+	r.Get("/stuck", stuckHandler(release))
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/stuck", nil))
+
+	report := <-reports
+	assert.Equal(t, "/stuck", report.Path, "path should match expected")
+	assert.NotZero(t, report.GoroutineID, "goroutine id should be captured")
+	require.NotEmpty(t, report.Stack, "stack should be captured")
+	assert.Contains(t, string(report.Stack), "middleware_test.stuckHandler", "stack should belong to the handling goroutine")
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestWatchdog_Logger(t *testing.T) {
+	var buf syncBuffer
+	release := make(chan struct{})
+
+	r := chu.New()
+	r.Use(middleware.Watchdog(middleware.WatchdogOptions{
+		Threshold: 10 * time.Millisecond,
+		Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+	}))
+	r.Get("/stuck", stuckHandler(release))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/stuck", nil))
+
+	assert.Contains(t, buf.String(), "slow request", "slow request should be logged")
+	assert.Contains(t, buf.String(), "path=/stuck", "log should include path")
+}
+
+func TestWatchdog_Fast(t *testing.T) {
+	reported := make(chan struct{}, 1)
+
+	r := chu.New()
+	r.Use(middleware.Watchdog(middleware.WatchdogOptions{
+		Threshold: 50 * time.Millisecond,
+		Report:    func(context.Context, middleware.WatchdogReport) { reported <- struct{}{} },
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Empty(t, reported, "fast requests should not be reported")
+}
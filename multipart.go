@@ -0,0 +1,188 @@
+package chu
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+var (
+	ErrPartTooLarge       = errors.New("multipart part too large")
+	ErrMultipartTooLarge  = errors.New("multipart body too large")
+	ErrPartTypeNotAllowed = errors.New("multipart part content type not allowed")
+	ErrTooManyParts       = errors.New("too many multipart parts")
+)
+
+type Part struct {
+	Name        string
+	FileName    string
+	ContentType string
+	Header      textproto.MIMEHeader
+
+	r io.Reader
+}
+
+func (p *Part) Read(b []byte) (int, error) {
+	return p.r.Read(b)
+}
+
+func (p *Part) IsFile() bool {
+	return p.FileName != ""
+}
+
+type MultipartOption func(*multipartConfig)
+
+type multipartConfig struct {
+	maxPartSize  int64
+	maxTotalSize int64
+	maxParts     int
+	allowedTypes []string
+}
+
+func WithMaxPartSize(size int64) MultipartOption {
+	return func(c *multipartConfig) {
+		c.maxPartSize = size
+	}
+}
+
+func WithMaxTotalSize(size int64) MultipartOption {
+	return func(c *multipartConfig) {
+		c.maxTotalSize = size
+	}
+}
+
+func WithMaxParts(n int) MultipartOption {
+	return func(c *multipartConfig) {
+		c.maxParts = n
+	}
+}
+
+func WithAllowedTypes(types ...string) MultipartOption {
+	return func(c *multipartConfig) {
+		c.allowedTypes = append(c.allowedTypes, types...)
+	}
+}
+
+func Multipart(r *http.Request, fn func(part *Part) error, opts ...MultipartOption) error {
+	cfg := &multipartConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	body := r.Body
+	if cfg.maxTotalSize > 0 {
+		r.Body = &limitedBody{ReadCloser: body, remaining: cfg.maxTotalSize, err: NewError(http.StatusRequestEntityTooLarge, ErrMultipartTooLarge)}
+		defer func() { r.Body = body }()
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return NewError(http.StatusUnsupportedMediaType, err)
+	}
+
+	for count := 1; ; count++ {
+		p, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return multipartError(err)
+		}
+
+		if cfg.maxParts > 0 && count > cfg.maxParts {
+			p.Close()
+			return NewError(http.StatusRequestEntityTooLarge, ErrTooManyParts)
+		}
+
+		part := &Part{
+			Name:        p.FormName(),
+			FileName:    p.FileName(),
+			ContentType: p.Header.Get("Content-Type"),
+			Header:      p.Header,
+			r:           p,
+		}
+
+		if part.IsFile() && !typeAllowed(cfg.allowedTypes, part.ContentType) {
+			p.Close()
+			return NewError(http.StatusUnsupportedMediaType, fmt.Errorf("%w: %q", ErrPartTypeNotAllowed, part.ContentType))
+		}
+
+		if cfg.maxPartSize > 0 {
+			part.r = &limitedBody{ReadCloser: io.NopCloser(p), remaining: cfg.maxPartSize, err: NewError(http.StatusRequestEntityTooLarge, ErrPartTooLarge)}
+		}
+
+		err = fn(part)
+		p.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func multipartError(err error) error {
+	var limitErr *Error
+	if errors.As(err, &limitErr) {
+		return err
+	}
+
+	return NewError(http.StatusBadRequest, err)
+}
+
+func typeAllowed(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range allowed {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(mediaType, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		var probe [1]byte
+		if n, _ := b.ReadCloser.Read(probe[:]); n > 0 {
+			return 0, b.err
+		}
+
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+
+	return n, err
+}
@@ -0,0 +1,172 @@
+package chu_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPart struct {
+	name, fileName, contentType, body string
+}
+
+func multipartBody(t *testing.T, parts ...testPart) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	for _, p := range parts {
+		var (
+			w   io.Writer
+			err error
+		)
+
+		if p.fileName != "" {
+			h := textproto.MIMEHeader{}
+			h.Set("Content-Disposition", `form-data; name="`+p.name+`"; filename="`+p.fileName+`"`)
+			h.Set("Content-Type", p.contentType)
+			w, err = mw.CreatePart(h)
+		} else {
+			w, err = mw.CreateFormField(p.name)
+		}
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte(p.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	return &buf, mw.FormDataContentType()
+}
+
+func TestMultipart(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    []testPart
+		opts     []chu.MultipartOption
+		expected int
+		seen     string
+		err      error
+	}{
+		{
+			name:     "fields and files",
+			parts:    []testPart{{name: "title", body: "hello"}, {name: "file", fileName: "a.png", contentType: "image/png", body: "PNG"}},
+			expected: http.StatusOK,
+			seen:     "title=hello;file:a.png(image/png)=PNG;",
+		},
+		{
+			name:     "allowed wildcard type",
+			parts:    []testPart{{name: "file", fileName: "a.jpg", contentType: "image/jpeg", body: "JPG"}},
+			opts:     []chu.MultipartOption{chu.WithAllowedTypes("image/*")},
+			expected: http.StatusOK,
+			seen:     "file:a.jpg(image/jpeg)=JPG;",
+		},
+		{
+			name:     "disallowed type",
+			parts:    []testPart{{name: "file", fileName: "a.exe", contentType: "application/octet-stream", body: "MZ"}},
+			opts:     []chu.MultipartOption{chu.WithAllowedTypes("image/png", "text/plain")},
+			expected: http.StatusUnsupportedMediaType,
+			err:      chu.ErrPartTypeNotAllowed,
+		},
+		{
+			name:     "part too large",
+			parts:    []testPart{{name: "file", fileName: "a.txt", contentType: "text/plain", body: strings.Repeat("a", 100)}},
+			opts:     []chu.MultipartOption{chu.WithMaxPartSize(10)},
+			expected: http.StatusRequestEntityTooLarge,
+			err:      chu.ErrPartTooLarge,
+		},
+		{
+			name:     "body too large",
+			parts:    []testPart{{name: "a", body: strings.Repeat("a", 600)}, {name: "b", body: strings.Repeat("b", 600)}},
+			opts:     []chu.MultipartOption{chu.WithMaxTotalSize(1000)},
+			expected: http.StatusRequestEntityTooLarge,
+			err:      chu.ErrMultipartTooLarge,
+		},
+		{
+			name:     "too many parts",
+			parts:    []testPart{{name: "a", body: "1"}, {name: "b", body: "2"}},
+			opts:     []chu.MultipartOption{chu.WithMaxParts(1)},
+			expected: http.StatusRequestEntityTooLarge,
+			err:      chu.ErrTooManyParts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handlerErr error
+
+			r := chu.New(chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+				handlerErr = err
+				w.WriteHeader(chu.StatusCode(err))
+			}))
+			r.Post("/upload", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				var seen strings.Builder
+
+				err := chu.Multipart(r, func(part *chu.Part) error {
+					data, err := io.ReadAll(part)
+					if err != nil {
+						return err
+					}
+
+					if part.IsFile() {
+						seen.WriteString(part.Name + ":" + part.FileName + "(" + part.ContentType + ")=" + string(data) + ";")
+					} else {
+						seen.WriteString(part.Name + "=" + string(data) + ";")
+					}
+
+					return nil
+				}, tt.opts...)
+				if err != nil {
+					return err
+				}
+
+				_, err = w.Write([]byte(seen.String()))
+				return err
+			})
+
+			body, contentType := multipartBody(t, tt.parts...)
+			req := httptest.NewRequest(http.MethodPost, "/upload", body)
+			req.Header.Set("Content-Type", contentType)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code, "status code should match expected")
+			if tt.seen != "" {
+				assert.Equal(t, tt.seen, rec.Body.String(), "parts should be streamed in order")
+			}
+
+			if tt.err != nil {
+				assert.True(t, errors.Is(handlerErr, tt.err), "error should match expected")
+			}
+		})
+	}
+}
+
+func TestMultipart_NotMultipart(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+
+	err := chu.Multipart(req, func(part *chu.Part) error { return nil })
+	assert.Equal(t, http.StatusUnsupportedMediaType, chu.StatusCode(err), "status code should match expected")
+}
+
+func TestMultipart_CallbackError(t *testing.T) {
+	body, contentType := multipartBody(t, testPart{name: "a", body: "1"})
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", contentType)
+
+	boom := errors.New("boom")
+	err := chu.Multipart(req, func(part *chu.Part) error { return boom })
+	assert.ErrorIs(t, err, boom, "callback errors should be returned as is")
+}
@@ -0,0 +1,82 @@
+package chu
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// NamedMiddleware is middleware together with the name it reports in stage
+// timings and MiddlewareChain. Register it with Router.UseNamed.
+type NamedMiddleware struct {
+	Name       string
+	Middleware func(Handler) Handler
+}
+
+// Named gives mw a name for stage timings and MiddlewareChain. Middleware
+// registered with Use is named after its function instead.
+func Named(name string, mw func(Handler) Handler) NamedMiddleware {
+	return NamedMiddleware{Name: name, Middleware: mw}
+}
+
+// MiddlewareName returns the name Use reports for mw: its package-qualified
+// function name.
+func MiddlewareName(mw func(Handler) Handler) string {
+	return funcName(mw)
+}
+
+// middlewareChains records the names of the middleware wrapping each route
+// pattern when the route is registered. It is shared by a router and its
+// sub-routers.
+type middlewareChains struct {
+	mu     sync.RWMutex
+	chains map[string][]string
+}
+
+func newMiddlewareChains() *middlewareChains {
+	return &middlewareChains{chains: make(map[string][]string)}
+}
+
+func (m *middlewareChains) set(pattern string, chain []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.chains[pattern]; !ok {
+		m.chains[pattern] = slices.Clone(chain)
+	}
+}
+
+func (m *middlewareChains) get(pattern string) ([]string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chain, ok := m.chains[pattern]
+	return slices.Clone(chain), ok
+}
+
+// MiddlewareChain returns the names of the middleware registered with Use or
+// UseNamed that wrap the route registered for pattern, outermost first, or nil
+// if no route was registered for it.
+func (r *Router) MiddlewareChain(pattern string) []string {
+	chain, ok := r.chains.get(pattern)
+	if !ok {
+		return nil
+	}
+
+	if chain == nil {
+		chain = []string{}
+	}
+
+	return chain
+}
+
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
@@ -0,0 +1,78 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func passthrough(next chu.Handler) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return next(ctx, w, r)
+	}
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	noop := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	r := chu.New()
+	r.UseNamed(chu.Named("logging", passthrough), chu.Named("recover", passthrough))
+	r.Get("/public", noop)
+
+	r.Group(func(r *chu.Router) {
+		r.UseNamed(chu.Named("auth", passthrough))
+		r.Get("/private", noop)
+	})
+
+	r.Route("/api", func(r *chu.Router) {
+		r.Use(passthrough)
+		r.Get("/users", noop)
+	})
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "/public", expected: []string{"logging", "recover"}},
+		{pattern: "/private", expected: []string{"logging", "recover", "auth"}},
+		{pattern: "/api/users", expected: []string{"logging", "recover", "chu_test.passthrough"}},
+		{pattern: "/missing", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.expected, r.MiddlewareChain(tt.pattern), "middleware chain should match expected")
+		})
+	}
+}
+
+func TestMiddlewareName(t *testing.T) {
+	assert.Equal(t, "chu_test.passthrough", chu.MiddlewareName(passthrough), "middleware should be named after its function")
+}
+
+func TestNamed_Wraps(t *testing.T) {
+	calls := 0
+	counting := func(next chu.Handler) chu.Handler {
+		calls++
+		return next
+	}
+
+	r := chu.New()
+	r.UseNamed(chu.Named("counting", counting))
+	assert.Equal(t, 0, calls, "naming should not build the wrapped middleware")
+
+	called := false
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		called = true
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, called, "named middleware should wrap the next handler")
+	assert.Equal(t, 1, calls, "wrapped middleware should be built once per request")
+	assert.Equal(t, []string{"counting"}, r.MiddlewareChain("/"), "middleware chain should match expected")
+}
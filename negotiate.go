@@ -0,0 +1,145 @@
+package chu
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrNotAcceptable = errors.New("not acceptable")
+
+var formatCtxKey = &contextKey{"format"}
+
+// AcceptPolicy is the response format policy of a group of routes.
+type AcceptPolicy struct {
+	// Offers are the media types the routes can produce, in order of
+	// preference.
+	Offers []string
+	// Default is used when the request has no Accept header, and for Accept
+	// values matching no offer unless Strict is set. Defaults to the first
+	// offer.
+	Default string
+	// Strict rejects requests whose Accept header matches no offer with 406.
+	Strict bool
+}
+
+// Negotiate applies policy to the routes it wraps: the chosen media type is
+// available through Format, and the response varies on Accept.
+func Negotiate(policy AcceptPolicy) func(Handler) Handler {
+	if policy.Default == "" && len(policy.Offers) > 0 {
+		policy.Default = policy.Offers[0]
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			AddVary(w, "Accept")
+
+			format := policy.Default
+			if accept := r.Header.Get("Accept"); accept != "" {
+				format = NegotiateContentType(r, policy.Offers...)
+				if format == "" {
+					if policy.Strict {
+						return Errorf(http.StatusNotAcceptable, "%w: %s", ErrNotAcceptable, accept)
+					}
+
+					format = policy.Default
+				}
+			}
+
+			ctx = context.WithValue(ctx, formatCtxKey, format)
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+// Format returns the media type chosen by Negotiate, or "" outside of it.
+func Format(ctx context.Context) string {
+	format, _ := ctx.Value(formatCtxKey).(string)
+	return format
+}
+
+// NegotiateContentType returns the offer best matching the Accept header of r,
+// honoring quality values and preferring more specific ranges. Ties go to the
+// earlier offer. It returns the first offer when there is no Accept header and
+// "" when nothing is acceptable.
+func NegotiateContentType(r *http.Request, offers ...string) string {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		if len(offers) > 0 {
+			return offers[0]
+		}
+
+		return ""
+	}
+
+	ranges := parseAccept(header)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		typ, _, err := mime.ParseMediaType(offer)
+		if err != nil {
+			continue
+		}
+
+		if q := acceptQuality(ranges, typ); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		typ, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		main, sub, _ := strings.Cut(typ, "/")
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		ranges = append(ranges, acceptRange{typ: main, subtype: sub, q: q})
+	}
+
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range matching typ.
+func acceptQuality(ranges []acceptRange, typ string) float64 {
+	main, sub, _ := strings.Cut(typ, "/")
+
+	q, specificity := 0.0, -1
+	for _, ar := range ranges {
+		s := -1
+		switch {
+		case ar.typ == main && ar.subtype == sub:
+			s = 2
+		case ar.typ == main && ar.subtype == "*":
+			s = 1
+		case ar.typ == "*" && ar.subtype == "*":
+			s = 0
+		}
+
+		if s > specificity {
+			q, specificity = ar.q, s
+		}
+	}
+
+	return q
+}
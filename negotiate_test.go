@@ -0,0 +1,89 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "text/html"}
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: "application/json"},
+		{accept: "*/*", want: "application/json"},
+		{accept: "text/html", want: "text/html"},
+		{accept: "application/xml;q=0.9, application/json;q=0.5", want: "application/xml"},
+		{accept: "text/*, application/json;q=0.1", want: "text/html"},
+		{accept: "*/*;q=0.8, application/json;q=0", want: "application/xml"},
+		{accept: "image/png", want: ""},
+		{accept: "application/*", want: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			assert.Equal(t, tt.want, chu.NegotiateContentType(req, offers...), "negotiated type should match expected")
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	format := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte(chu.Format(ctx)))
+		return err
+	}
+
+	r := chu.New()
+	r.Group(func(r *chu.Router) {
+		r.Use(chu.Negotiate(chu.AcceptPolicy{Offers: []string{"application/json", "text/csv"}, Strict: true}))
+		r.Get("/api", format)
+	})
+	r.Group(func(r *chu.Router) {
+		r.Use(chu.Negotiate(chu.AcceptPolicy{Offers: []string{"text/html", "application/json"}, Default: "text/html"}))
+		r.Get("/page", format)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+		code   int
+		body   string
+	}{
+		{name: "strict default", path: "/api", code: http.StatusOK, body: "application/json"},
+		{name: "strict match", path: "/api", accept: "text/csv", code: http.StatusOK, body: "text/csv"},
+		{name: "strict mismatch", path: "/api", accept: "image/png", code: http.StatusNotAcceptable},
+		{name: "lenient mismatch", path: "/page", accept: "image/png", code: http.StatusOK, body: "text/html"},
+		{name: "lenient match", path: "/page", accept: "application/json", code: http.StatusOK, body: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code, "status code should match expected")
+			assert.Equal(t, "Accept", w.Header().Get("Vary"), "response should vary on accept")
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "format should match expected")
+			}
+		})
+	}
+}
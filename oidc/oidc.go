@@ -0,0 +1,330 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var (
+	ErrInvalidState = errors.New("oidc: invalid state")
+	ErrInvalidToken = errors.New("oidc: invalid id token")
+	ErrNoSession    = errors.New("oidc: no session")
+)
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	AuthURL       string
+	TokenURL      string
+	JWKSURL       string
+	EndSessionURL string
+
+	Sessions    SessionStore
+	CookieName  string
+	AfterLogin  string
+	AfterLogout string
+	HTTPClient  *http.Client
+	Now         func() time.Time
+
+	// InsecureCookies drops the Secure attribute from the session and login
+	// flow cookies, for development over plain HTTP. Cookies are Secure by
+	// default.
+	InsecureCookies bool
+}
+
+type Provider struct {
+	cfg  Config
+	keys *keySet
+}
+
+type discovery struct {
+	Issuer        string `json:"issuer"`
+	AuthURL       string `json:"authorization_endpoint"`
+	TokenURL      string `json:"token_endpoint"`
+	JWKSURL       string `json:"jwks_uri"`
+	EndSessionURL string `json:"end_session_endpoint"`
+}
+
+func New(ctx context.Context, cfg Config) (*Provider, error) {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+
+	if cfg.CookieName == "" {
+		cfg.CookieName = "oidc_session"
+	}
+
+	if cfg.AfterLogin == "" {
+		cfg.AfterLogin = "/"
+	}
+
+	if cfg.AfterLogout == "" {
+		cfg.AfterLogout = "/"
+	}
+
+	if cfg.Sessions == nil {
+		cfg.Sessions = NewMemoryStore()
+	}
+
+	if cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.JWKSURL == "" {
+		if err := discover(ctx, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Provider{cfg: cfg, keys: &keySet{url: cfg.JWKSURL, client: cfg.HTTPClient}}, nil
+}
+
+func discover(ctx context.Context, cfg *Config) error {
+	endpoint := strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := cfg.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc: discovery: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: discovery: unexpected status %d", resp.StatusCode)
+	}
+
+	var d discovery
+	if err := json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return fmt.Errorf("oidc: discovery: %w", err)
+	}
+
+	if d.Issuer != cfg.Issuer {
+		return fmt.Errorf("oidc: discovery: issuer mismatch %q", d.Issuer)
+	}
+
+	cfg.AuthURL = orDefault(cfg.AuthURL, d.AuthURL)
+	cfg.TokenURL = orDefault(cfg.TokenURL, d.TokenURL)
+	cfg.JWKSURL = orDefault(cfg.JWKSURL, d.JWKSURL)
+	cfg.EndSessionURL = orDefault(cfg.EndSessionURL, d.EndSessionURL)
+
+	return nil
+}
+
+func (p *Provider) Routes(r *chu.Router) {
+	r.Get("/login", p.Login)
+	r.Get("/callback", p.Callback)
+	r.Get("/logout", p.Logout)
+	r.Post("/logout", p.Logout)
+}
+
+type flowState struct {
+	State    string `json:"s"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"`
+	ReturnTo string `json:"r"`
+}
+
+func (p *Provider) flowCookie() string {
+	return p.cfg.CookieName + "_flow"
+}
+
+func (p *Provider) Login(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	flow := flowState{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: randomString() + randomString(),
+		ReturnTo: safeReturnTo(r.URL.Query().Get("return_to"), p.cfg.AfterLogin),
+	}
+
+	raw, err := json.Marshal(flow)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     p.flowCookie(),
+		Value:    base64.RawURLEncoding.EncodeToString(raw),
+		Path:     "/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   !p.cfg.InsecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {flow.State},
+		"nonce":                 {flow.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	http.Redirect(w, r, withQuery(p.cfg.AuthURL, q), http.StatusFound)
+	return nil
+}
+
+func (p *Provider) Callback(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	flow, err := p.readFlow(r)
+	if err != nil {
+		return chu.NewError(http.StatusBadRequest, err)
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: p.flowCookie(), Path: "/", MaxAge: -1})
+
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		return chu.Errorf(http.StatusUnauthorized, "oidc: authorization failed: %s: %s", e, q.Get("error_description"))
+	}
+
+	if q.Get("state") == "" || q.Get("state") != flow.State {
+		return chu.NewError(http.StatusBadRequest, ErrInvalidState)
+	}
+
+	tok, err := p.exchange(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {q.Get("code")},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {flow.Verifier},
+	})
+	if err != nil {
+		return chu.NewError(http.StatusBadGateway, err)
+	}
+
+	claims, err := p.verify(ctx, tok.IDToken, flow.Nonce)
+	if err != nil {
+		return chu.NewError(http.StatusUnauthorized, err)
+	}
+
+	sess := &Session{ID: randomString(), Claims: claims}
+	p.applyToken(sess, tok)
+
+	if err := p.cfg.Sessions.Set(ctx, sess); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     p.cfg.CookieName,
+		Value:    sess.ID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   !p.cfg.InsecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, flow.ReturnTo, http.StatusFound)
+	return nil
+}
+
+func (p *Provider) Logout(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	target := p.cfg.AfterLogout
+
+	if c, err := r.Cookie(p.cfg.CookieName); err == nil {
+		if sess, err := p.cfg.Sessions.Get(ctx, c.Value); err == nil && p.cfg.EndSessionURL != "" {
+			target = withQuery(p.cfg.EndSessionURL, url.Values{
+				"id_token_hint":            {sess.IDToken},
+				"post_logout_redirect_uri": {absoluteURL(r, p.cfg.AfterLogout)},
+			})
+		}
+
+		if err := p.cfg.Sessions.Delete(ctx, c.Value); err != nil {
+			return err
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: p.cfg.CookieName, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, target, http.StatusFound)
+
+	return nil
+}
+
+func (p *Provider) readFlow(r *http.Request) (*flowState, error) {
+	c, err := r.Cookie(p.flowCookie())
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(c.Value)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+
+	var flow flowState
+	if err := json.Unmarshal(raw, &flow); err != nil {
+		return nil, ErrInvalidState
+	}
+
+	return &flow, nil
+}
+
+func safeReturnTo(target, fallback string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return fallback
+	}
+
+	return target
+}
+
+func absoluteURL(r *http.Request, path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + path
+}
+
+func withQuery(endpoint string, q url.Values) string {
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+
+	return endpoint + sep + q.Encode()
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func orDefault(v, def string) string {
+	if v != "" {
+		return v
+	}
+
+	return def
+}
@@ -0,0 +1,319 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIdP struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	nonce     string
+	challenge string
+	refreshes atomic.Int32
+
+	kid         string
+	jwksStatus  int
+	jwksDelay   time.Duration
+	jwksFetches atomic.Int32
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "key generation should succeed")
+
+	idp := &fakeIdP{key: key, kid: "k1", jwksStatus: http.StatusOK}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+			"end_session_endpoint":   idp.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.jwksFetches.Add(1)
+		time.Sleep(idp.jwksDelay)
+
+		w.WriteHeader(idp.jwksStatus)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		if id, secret, _ := r.BasicAuth(); id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+			if r.PostForm.Get("code") != "good" || base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		case "refresh_token":
+			idp.refreshes.Add(1)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"id_token":      idp.sign(t, idp.nonce),
+			"expires_in":    3600,
+		})
+	})
+
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+
+	return idp
+}
+
+func (idp *fakeIdP) sign(t *testing.T, nonce string) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": idp.kid})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   idp.URL,
+		"aud":   "client",
+		"sub":   "user-1",
+		"nonce": nonce,
+		"exp":   time.Now().Add(24 * time.Hour).Unix(),
+	})
+
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signing))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	require.NoError(t, err, "signing should succeed")
+
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func newApp(t *testing.T, idp *fakeIdP, now func() time.Time) *chu.Router {
+	t.Helper()
+
+	p, err := oidc.New(context.Background(), oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://app.test/auth/callback",
+		Now:          now,
+	})
+	require.NoError(t, err, "provider discovery should succeed")
+
+	r := chu.New()
+	r.Route("/auth", p.Routes)
+	r.Group(func(r *chu.Router) {
+		r.Use(p.RequireLogin("/auth/login"))
+		r.Get("/me", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			sess, _ := oidc.FromContext(ctx)
+			_, err := w.Write([]byte(sess.Subject))
+			return err
+		})
+	})
+
+	return r
+}
+
+func do(r http.Handler, target string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func login(t *testing.T, idp *fakeIdP, r http.Handler) []*http.Cookie {
+	t.Helper()
+
+	w := do(r, "/me", nil)
+	require.Equal(t, http.StatusFound, w.Code, "unauthenticated request should redirect")
+	assert.Equal(t, "/auth/login?return_to=%2Fme", w.Header().Get("Location"), "redirect should target login")
+
+	w = do(r, "/auth/login?return_to=/me", nil)
+	require.Equal(t, http.StatusFound, w.Code, "login should redirect to the provider")
+
+	loc, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err, "authorize URL should parse")
+	assert.Equal(t, "S256", loc.Query().Get("code_challenge_method"), "login should use PKCE")
+
+	idp.nonce = loc.Query().Get("nonce")
+	idp.challenge = loc.Query().Get("code_challenge")
+
+	flow := w.Result().Cookies()
+	w = do(r, "/auth/callback?code=good&state="+loc.Query().Get("state"), flow)
+	require.Equal(t, http.StatusFound, w.Code, "callback should succeed: %s", w.Body.String())
+	assert.Equal(t, "/me", w.Header().Get("Location"), "callback should return to original path")
+
+	var session []*http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "oidc_session" {
+			session = append(session, c)
+		}
+	}
+
+	return session
+}
+
+func TestProvider_LoginFlow(t *testing.T) {
+	idp := newFakeIdP(t)
+	r := newApp(t, idp, nil)
+
+	session := login(t, idp, r)
+
+	w := do(r, "/me", session)
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "user-1", w.Body.String(), "session should carry subject")
+
+	w = do(r, "/auth/logout", session)
+	require.Equal(t, http.StatusFound, w.Code, "logout should redirect")
+	assert.Contains(t, w.Header().Get("Location"), idp.URL+"/logout", "logout should use end session endpoint")
+
+	w = do(r, "/me", session)
+	assert.Equal(t, http.StatusFound, w.Code, "session should be gone after logout")
+}
+
+func TestProvider_Refresh(t *testing.T) {
+	idp := newFakeIdP(t)
+
+	now := time.Now()
+	r := newApp(t, idp, func() time.Time { return now })
+
+	session := login(t, idp, r)
+	now = now.Add(2 * time.Hour)
+	idp.nonce = ""
+
+	w := do(r, "/me", session)
+	assert.Equal(t, http.StatusOK, w.Code, "expired session should be refreshed")
+	assert.Equal(t, int32(1), idp.refreshes.Load(), "refresh token should be used")
+}
+
+func TestProvider_CallbackErrors(t *testing.T) {
+	idp := newFakeIdP(t)
+	r := newApp(t, idp, nil)
+
+	w := do(r, "/auth/login", nil)
+	flow := w.Result().Cookies()
+	loc, _ := url.Parse(w.Header().Get("Location"))
+	idp.challenge = loc.Query().Get("code_challenge")
+
+	tests := []struct {
+		name     string
+		target   string
+		cookies  []*http.Cookie
+		expected int
+	}{
+		{name: "missing flow cookie", target: "/auth/callback?code=good&state=" + loc.Query().Get("state"), expected: http.StatusBadRequest},
+		{name: "state mismatch", target: "/auth/callback?code=good&state=other", cookies: flow, expected: http.StatusBadRequest},
+		{name: "provider error", target: "/auth/callback?error=access_denied", cookies: flow, expected: http.StatusUnauthorized},
+		{name: "bad code", target: "/auth/callback?code=bad&state=" + loc.Query().Get("state"), cookies: flow, expected: http.StatusBadGateway},
+		{name: "nonce mismatch", target: "/auth/callback?code=good&state=" + loc.Query().Get("state"), cookies: flow, expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(r, tt.target, tt.cookies)
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+		})
+	}
+}
+
+func TestProvider_OpenRedirect(t *testing.T) {
+	idp := newFakeIdP(t)
+	r := newApp(t, idp, nil)
+
+	for _, target := range []string{"https://evil.test", "//evil.test", "/\\evil.test"} {
+		w := do(r, "/auth/login?return_to="+url.QueryEscape(target), nil)
+
+		raw, _ := base64.RawURLEncoding.DecodeString(w.Result().Cookies()[0].Value)
+		assert.Contains(t, string(raw), `"r":"/"`, "external return_to should fall back for %s", target)
+	}
+}
+
+func TestProvider_SecureCookies(t *testing.T) {
+	idp := newFakeIdP(t)
+	r := newApp(t, idp, nil)
+
+	session := login(t, idp, r)
+	require.Len(t, session, 1, "callback should set the session cookie")
+	assert.True(t, session[0].Secure, "session cookie should be secure by default")
+
+	p, err := oidc.New(context.Background(), oidc.Config{Issuer: idp.URL, ClientID: "client", InsecureCookies: true})
+	require.NoError(t, err, "provider discovery should succeed")
+
+	w := httptest.NewRecorder()
+	require.NoError(t, p.Login(context.Background(), w, httptest.NewRequest(http.MethodGet, "/auth/login", nil)), "login should succeed")
+	assert.False(t, w.Result().Cookies()[0].Secure, "insecure cookies should drop the secure attribute")
+}
+
+func TestProvider_KeyRotation(t *testing.T) {
+	idp := newFakeIdP(t)
+
+	p, err := oidc.New(context.Background(), oidc.Config{Issuer: idp.URL, ClientID: "client", ClientSecret: "secret"})
+	require.NoError(t, err, "provider discovery should succeed")
+
+	refresh := func() error {
+		return p.Refresh(context.Background(), &oidc.Session{ID: "s1", RefreshToken: "refresh"})
+	}
+
+	require.NoError(t, refresh(), "refresh should verify with the fetched keys")
+	assert.Equal(t, int32(1), idp.jwksFetches.Load(), "keys should be fetched once")
+
+	idp.kid = "k2"
+	idp.jwksStatus = http.StatusInternalServerError
+	assert.Error(t, refresh(), "unknown key should fail when the JWKS fetch fails")
+	assert.Equal(t, int32(2), idp.jwksFetches.Load(), "unknown key should trigger a fetch")
+
+	idp.kid = "k1"
+	assert.NoError(t, refresh(), "failed fetch should keep the previous keys")
+	assert.Equal(t, int32(2), idp.jwksFetches.Load(), "cached key should not trigger a fetch")
+
+	idp.kid = "k3"
+	idp.jwksStatus = http.StatusOK
+	idp.jwksDelay = 50 * time.Millisecond
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.ErrorIs(t, refresh(), oidc.ErrInvalidToken, "unknown key should be rejected")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), idp.jwksFetches.Load(), "concurrent misses should share one fetch")
+}
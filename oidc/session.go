@@ -0,0 +1,110 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+type sessionCtxKey struct{}
+
+type Session struct {
+	ID           string
+	Subject      string
+	Claims       map[string]any
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	Expiry       time.Time
+}
+
+type SessionStore interface {
+	Get(ctx context.Context, id string) (*Session, error)
+	Set(ctx context.Context, sess *Session) error
+	Delete(ctx context.Context, id string) error
+}
+
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]Session)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNoSession
+	}
+
+	return &sess, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, sess *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[sess.ID] = *sess
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+func (p *Provider) RequireLogin(loginPath string) func(chu.Handler) chu.Handler {
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			sess, err := p.session(ctx, r)
+			if err != nil {
+				target := loginPath + "?" + url.Values{"return_to": {r.URL.RequestURI()}}.Encode()
+				http.Redirect(w, r, target, http.StatusFound)
+
+				return nil
+			}
+
+			ctx = context.WithValue(ctx, sessionCtxKey{}, sess)
+
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+func (p *Provider) session(ctx context.Context, r *http.Request) (*Session, error) {
+	c, err := r.Cookie(p.cfg.CookieName)
+	if err != nil {
+		return nil, ErrNoSession
+	}
+
+	sess, err := p.cfg.Sessions.Get(ctx, c.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sess.Expiry.IsZero() && p.cfg.Now().After(sess.Expiry) {
+		if err := p.Refresh(ctx, sess); err != nil {
+			_ = p.cfg.Sessions.Delete(ctx, sess.ID)
+			return nil, err
+		}
+	}
+
+	return sess, nil
+}
+
+func FromContext(ctx context.Context) (*Session, bool) {
+	sess, ok := ctx.Value(sessionCtxKey{}).(*Session)
+	return sess, ok
+}
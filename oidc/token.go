@@ -0,0 +1,367 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (p *Provider) exchange(ctx context.Context, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: token request: unexpected status %d", resp.StatusCode)
+	}
+
+	var tok tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return nil, fmt.Errorf("oidc: token request: %w", err)
+	}
+
+	return &tok, nil
+}
+
+func (p *Provider) Refresh(ctx context.Context, sess *Session) error {
+	if sess.RefreshToken == "" {
+		return ErrNoSession
+	}
+
+	tok, err := p.exchange(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {sess.RefreshToken},
+	})
+	if err != nil {
+		return err
+	}
+
+	if tok.IDToken != "" {
+		claims, err := p.verify(ctx, tok.IDToken, "")
+		if err != nil {
+			return err
+		}
+
+		sess.Claims = claims
+	}
+
+	p.applyToken(sess, tok)
+
+	return p.cfg.Sessions.Set(ctx, sess)
+}
+
+func (p *Provider) applyToken(sess *Session, tok *tokenResponse) {
+	sess.AccessToken = tok.AccessToken
+
+	if tok.RefreshToken != "" {
+		sess.RefreshToken = tok.RefreshToken
+	}
+
+	if tok.IDToken != "" {
+		sess.IDToken = tok.IDToken
+	}
+
+	if sub, ok := sess.Claims["sub"].(string); ok {
+		sess.Subject = sub
+	}
+
+	sess.Expiry = time.Time{}
+	if tok.ExpiresIn > 0 {
+		sess.Expiry = p.cfg.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (p *Provider) verify(ctx context.Context, raw, nonce string) (map[string]any, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := p.keys.get(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	switch header.Alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return nil, ErrInvalidToken
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return nil, ErrInvalidToken
+		}
+
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return nil, ErrInvalidToken
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, header.Alg)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := p.checkClaims(claims, nonce); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) checkClaims(claims map[string]any, nonce string) error {
+	if iss, _ := claims["iss"].(string); iss != p.cfg.Issuer {
+		return fmt.Errorf("%w: issuer mismatch", ErrInvalidToken)
+	}
+
+	if !hasAudience(claims["aud"], p.cfg.ClientID) {
+		return fmt.Errorf("%w: audience mismatch", ErrInvalidToken)
+	}
+
+	exp, _ := claims["exp"].(float64)
+	if p.cfg.Now().After(time.Unix(int64(exp), 0)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+
+	if nonce != "" {
+		if got, _ := claims["nonce"].(string); got != nonce {
+			return fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+		}
+	}
+
+	return nil
+}
+
+func hasAudience(aud any, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []any:
+		for _, a := range v {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(seg string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keySet caches the provider's signing keys. A miss refetches the JWKS once
+// for all concurrent callers, outside the lock, and keeps the previous keys
+// when the fetch fails.
+type keySet struct {
+	url    string
+	client *http.Client
+
+	mu    sync.Mutex
+	keys  map[string]crypto.PublicKey
+	fetch *keyFetch
+}
+
+type keyFetch struct {
+	done chan struct{}
+	err  error
+}
+
+func (ks *keySet) get(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	if key, ok := ks.keys[kid]; ok {
+		ks.mu.Unlock()
+		return key, nil
+	}
+
+	f := ks.fetch
+	if f == nil {
+		f = &keyFetch{done: make(chan struct{})}
+		ks.fetch = f
+		ks.mu.Unlock()
+
+		ks.refresh(ctx, f)
+	} else {
+		ks.mu.Unlock()
+
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+}
+
+func (ks *keySet) refresh(ctx context.Context, f *keyFetch) {
+	keys, err := ks.fetchKeys(ctx)
+
+	ks.mu.Lock()
+	if err == nil {
+		ks.keys = keys
+	}
+	ks.fetch = nil
+	ks.mu.Unlock()
+
+	f.err = err
+	close(f.done)
+}
+
+func (ks *keySet) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("oidc: jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("oidc: unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, errors.New("oidc: unsupported key type")
+	}
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(raw), nil
+}
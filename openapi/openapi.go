@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI string              `json:"openapi"`
+	Info    Info                `json:"info"`
+	Paths   map[string]PathItem `json:"paths"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema   *Schema             `json:"schema,omitempty"`
+	Examples map[string]*Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	Summary string `json:"summary,omitempty"`
+	Value   any    `json:"value"`
+}
+
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]PathItem),
+	}
+}
+
+func (d *Document) Operation(method, path string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+
+	key := strings.ToLower(method)
+
+	op, ok := item[key]
+	if !ok {
+		op = &Operation{Responses: make(map[string]*Response)}
+		item[key] = op
+	}
+
+	return op
+}
+
+func (d *Document) Lookup(method, path string) (*Operation, bool) {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil, false
+	}
+
+	op, ok := item[strings.ToLower(method)]
+	return op, ok
+}
+
+func (op *Operation) Response(status int) *Response {
+	key := strconv.Itoa(status)
+
+	resp, ok := op.Responses[key]
+	if !ok {
+		resp = &Response{Description: http.StatusText(status)}
+		op.Responses[key] = resp
+	}
+
+	return resp
+}
+
+func (op *Operation) Body() *RequestBody {
+	if op.RequestBody == nil {
+		op.RequestBody = &RequestBody{Content: make(map[string]*MediaType)}
+	}
+
+	return op.RequestBody
+}
+
+var paramRegexp = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+func PathFromPattern(pattern string) string {
+	return paramRegexp.ReplaceAllString(pattern, "{$1}")
+}
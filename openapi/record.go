@@ -0,0 +1,266 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/josearomeroj/chu"
+)
+
+const redacted = "[REDACTED]"
+
+var defaultRedactFields = []string{"password", "token", "secret", "authorization", "api_key", "apikey"}
+
+type RecordOptions struct {
+	SampleRate   float64
+	MaxExamples  int
+	MaxBodySize  int64
+	RedactFields []string
+	Random       func() float64
+}
+
+type Recorder struct {
+	doc  *Document
+	opts RecordOptions
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func RecordExamples(doc *Document, opts RecordOptions) *Recorder {
+	if opts.SampleRate <= 0 {
+		opts.SampleRate = 1
+	}
+
+	if opts.MaxExamples <= 0 {
+		opts.MaxExamples = 3
+	}
+
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 64 << 10
+	}
+
+	if opts.RedactFields == nil {
+		opts.RedactFields = defaultRedactFields
+	}
+
+	if opts.Random == nil {
+		opts.Random = rand.Float64
+	}
+
+	return &Recorder{
+		doc:    doc,
+		opts:   opts,
+		counts: make(map[string]int),
+	}
+}
+
+func (rec *Recorder) Middleware(next chu.Handler) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if rec.opts.Random() >= rec.opts.SampleRate {
+			return next(ctx, w, r)
+		}
+
+		reqBody, err := rec.readBody(r)
+		if err != nil {
+			return err
+		}
+
+		var respBody bytes.Buffer
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&limitedWriter{w: &respBody, n: rec.opts.MaxBodySize})
+
+		err = next(ctx, ww, r)
+
+		rctx := chi.RouteContext(ctx)
+		if rctx == nil || rctx.RoutePattern() == "" {
+			return err
+		}
+
+		status, respType := ww.Status(), ww.Header().Get("Content-Type")
+		switch {
+		case status == 0 && err != nil:
+			// The error handler writes the response later, so only its status
+			// is known.
+			status, respType = chu.StatusCode(err), ""
+		case status == 0:
+			status = http.StatusOK
+		}
+
+		rec.record(r.Method, PathFromPattern(rctx.RoutePattern()), r.Header.Get("Content-Type"), reqBody,
+			status, respType, respBody.Bytes())
+
+		return err
+	}
+}
+
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	rec.mu.Lock()
+	data, err := json.MarshalIndent(rec.doc, "", "  ")
+	rec.mu.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func (rec *Recorder) readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, rec.opts.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+
+	if int64(len(data)) > rec.opts.MaxBodySize {
+		return nil, nil
+	}
+
+	return data, nil
+}
+
+func (rec *Recorder) record(method, path, reqType string, reqBody []byte, status int, respType string, respBody []byte) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	key := fmt.Sprintf("%s %s %d", method, path, status)
+	if rec.counts[key] >= rec.opts.MaxExamples {
+		return
+	}
+
+	rec.counts[key]++
+	name := fmt.Sprintf("recorded-%d", rec.counts[key])
+
+	op := rec.doc.Operation(method, path)
+
+	if value, ok := rec.exampleValue(reqType, reqBody); ok {
+		addExample(op.Body().Content, reqType, name, value)
+	}
+
+	resp := op.Response(status)
+	if value, ok := rec.exampleValue(respType, respBody); ok {
+		if resp.Content == nil {
+			resp.Content = make(map[string]*MediaType)
+		}
+
+		addExample(resp.Content, respType, name, value)
+	}
+}
+
+func (rec *Recorder) exampleValue(contentType string, body []byte) (any, bool) {
+	if len(body) == 0 || contentType == "" {
+		return nil, false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value any
+		if err := json.Unmarshal(body, &value); err != nil {
+			return nil, false
+		}
+
+		return redact(value, rec.opts.RedactFields), true
+	case strings.HasPrefix(mediaType, "text/"):
+		return string(body), true
+	default:
+		return nil, false
+	}
+}
+
+func addExample(content map[string]*MediaType, contentType, name string, value any) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	mt, ok := content[mediaType]
+	if !ok {
+		mt = &MediaType{}
+		content[mediaType] = mt
+	}
+
+	if mt.Examples == nil {
+		mt.Examples = make(map[string]*Example)
+	}
+
+	mt.Examples[name] = &Example{Summary: "Recorded example", Value: value}
+}
+
+func redact(value any, fields []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if isRedacted(key, fields) {
+				v[key] = redacted
+				continue
+			}
+
+			v[key] = redact(child, fields)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = redact(child, fields)
+		}
+	}
+
+	return value
+}
+
+func isRedacted(key string, fields []string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n <= 0 {
+		return len(p), nil
+	}
+
+	chunk := p
+	if int64(len(chunk)) > l.n {
+		chunk = chunk[:l.n]
+	}
+
+	n, err := l.w.Write(chunk)
+	l.n -= int64(n)
+
+	if err != nil {
+		return n, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,118 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathFromPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: "/users", expected: "/users"},
+		{pattern: "/users/{id}", expected: "/users/{id}"},
+		{pattern: "/users/{id:[0-9]+}/posts/{slug}", expected: "/users/{id}/posts/{slug}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.expected, openapi.PathFromPattern(tt.pattern), "path should match expected")
+		})
+	}
+}
+
+func TestRecordExamples(t *testing.T) {
+	doc := openapi.New("test", "1.0.0")
+	rec := openapi.RecordExamples(doc, openapi.RecordOptions{MaxExamples: 1})
+
+	r := chu.New()
+	r.Use(rec.Middleware)
+
+	r.Post("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"` + chu.URLParam(r, "id") + `","echo":` + string(body) + `}`))
+		return nil
+	})
+
+	for _, id := range []string{"1", "2"} {
+		req := httptest.NewRequest("POST", "/users/"+id, strings.NewReader(`{"name":"jose","password":"hunter2"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusCreated, w.Code, "status code should be Created")
+		assert.Contains(t, w.Body.String(), "hunter2", "handler should receive the original body")
+	}
+
+	op, ok := doc.Lookup("POST", "/users/{id}")
+	require.True(t, ok, "operation should be recorded")
+
+	reqExamples := op.RequestBody.Content["application/json"].Examples
+	require.Len(t, reqExamples, 1, "request examples should be capped by MaxExamples")
+	assert.Equal(t, map[string]any{"name": "jose", "password": "[REDACTED]"}, reqExamples["recorded-1"].Value,
+		"request example should be redacted")
+
+	respExamples := op.Responses["201"].Content["application/json"].Examples
+	require.Len(t, respExamples, 1, "response examples should be capped by MaxExamples")
+	assert.Equal(t, "1", respExamples["recorded-1"].Value.(map[string]any)["id"], "response example should be the first request")
+
+	w := httptest.NewRecorder()
+	rec.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+
+	var served openapi.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &served), "served document should be valid JSON")
+	assert.Contains(t, served.Paths, "/users/{id}", "served document should contain recorded path")
+}
+
+func TestRecordExamples_Sampling(t *testing.T) {
+	doc := openapi.New("test", "1.0.0")
+	rec := openapi.RecordExamples(doc, openapi.RecordOptions{
+		SampleRate: 0.5,
+		Random:     func() float64 { return 0.9 },
+	})
+
+	r := chu.New()
+	r.Use(rec.Middleware)
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	assert.Empty(t, doc.Paths, "unsampled requests should not be recorded")
+}
+
+func TestRecordExamples_Error(t *testing.T) {
+	doc := openapi.New("test", "1.0.0")
+	rec := openapi.RecordExamples(doc, openapi.RecordOptions{})
+
+	r := chu.New()
+	r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.Errorf(http.StatusNotFound, "user not found")
+	}, chu.WithMiddleware(rec.Middleware))
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+
+	op, ok := doc.Lookup("GET", "/users/{id}")
+	require.True(t, ok, "operation should be recorded")
+	assert.Contains(t, op.Responses, "404", "error status should be recorded")
+	assert.NotContains(t, op.Responses, "200", "failed request should not be recorded as OK")
+}
@@ -0,0 +1,191 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+func (s *Schema) Validate(value any) error {
+	return s.validate("$", value)
+}
+
+func (s *Schema) validate(path string, value any) error {
+	if s == nil {
+		return nil
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+
+		return &ValidationError{Path: path, Message: "must not be null"}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must be one of %v", s.Enum)}
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return typeError(path, s.Type, value)
+		}
+
+		return s.validateObject(path, obj)
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return typeError(path, s.Type, value)
+		}
+
+		for i, item := range items {
+			if err := s.Items.validate(path+"["+strconv.Itoa(i)+"]", item); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return typeError(path, s.Type, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return typeError(path, s.Type, value)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return typeError(path, s.Type, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeError(path, s.Type, value)
+		}
+	default:
+		return &ValidationError{Path: path, Message: fmt.Sprintf("unsupported schema type %q", s.Type)}
+	}
+
+	return nil
+}
+
+// Validate checks a raw parameter value against the parameter schema. Arrays
+// are comma separated.
+func (p *Parameter) Validate(value string) error {
+	return p.Schema.validate(p.In+"."+p.Name, parameterValue(p.Schema, value))
+}
+
+// parameterValue converts the raw value to the JSON type of the schema, so a
+// value that does not parse fails validation as the wrong type.
+func parameterValue(s *Schema, value string) any {
+	if s == nil {
+		return value
+	}
+
+	switch s.Type {
+	case "number", "integer":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "array":
+		var items []any
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, parameterValue(s.Items, item))
+		}
+
+		return items
+	}
+
+	return value
+}
+
+func (s *Schema) validateObject(path string, obj map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return &ValidationError{Path: path + "." + name, Message: "is required"}
+		}
+	}
+
+	for name, child := range obj {
+		if prop, ok := s.Properties[name]; ok {
+			if err := prop.validate(path+"."+name, child); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if s.AdditionalProperties != nil {
+			if err := s.AdditionalProperties.validate(path+"."+name, child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func inEnum(value any, enum []any) bool {
+	for _, candidate := range enum {
+		if reflect.DeepEqual(value, candidate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeError(path, expected string, value any) error {
+	return &ValidationError{Path: path, Message: fmt.Sprintf("must be %s, got %s", expected, jsonType(value))}
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+
+		return "number"
+	default:
+		return strings.TrimPrefix(fmt.Sprintf("%T", value), "*")
+	}
+}
+
+func (op *Operation) ResponseFor(status int) (*Response, bool) {
+	code := strconv.Itoa(status)
+
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if resp, ok := op.Responses[key]; ok {
+			return resp, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,79 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/josearomeroj/chu/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	schema := &openapi.Schema{
+		Type:     "object",
+		Required: []string{"id", "tags"},
+		Properties: map[string]*openapi.Schema{
+			"id":     {Type: "integer"},
+			"status": {Type: "string", Enum: []any{"active", "disabled"}},
+			"tags":   {Type: "array", Items: &openapi.Schema{Type: "string"}},
+			"parent": {Type: "object", Nullable: true},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{name: "valid", input: `{"id":1,"status":"active","tags":["a"],"parent":null}`},
+		{name: "missing required", input: `{"id":1}`, expectedErr: "$.tags: is required"},
+		{name: "wrong integer", input: `{"id":1.5,"tags":[]}`, expectedErr: "$.id: must be integer, got number"},
+		{name: "bad enum", input: `{"id":1,"tags":[],"status":"gone"}`, expectedErr: "$.status: must be one of"},
+		{name: "bad array item", input: `{"id":1,"tags":["a",2]}`, expectedErr: "$.tags[1]: must be string, got integer"},
+		{name: "wrong root type", input: `[]`, expectedErr: "$: must be object, got array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &value), "input should be valid JSON")
+
+			err := schema.Validate(value)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err, "value should be valid")
+				return
+			}
+
+			require.Error(t, err, "value should be invalid")
+			assert.Contains(t, err.Error(), tt.expectedErr, "error should match expected")
+		})
+	}
+}
+
+func TestParameterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   *openapi.Parameter
+		value   string
+		wantErr string
+	}{
+		{"integer", &openapi.Parameter{Name: "id", In: "path", Schema: &openapi.Schema{Type: "integer"}}, "42", ""},
+		{"not an integer", &openapi.Parameter{Name: "id", In: "path", Schema: &openapi.Schema{Type: "integer"}}, "4.2", "path.id: must be integer"},
+		{"boolean", &openapi.Parameter{Name: "verbose", In: "query", Schema: &openapi.Schema{Type: "boolean"}}, "yes", "query.verbose: must be boolean"},
+		{"enum", &openapi.Parameter{Name: "sort", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []any{"asc", "desc"}}}, "up", "must be one of"},
+		{"array", &openapi.Parameter{Name: "ids", In: "query", Schema: &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "integer"}}}, "1,2,x", "query.ids[2]: must be integer"},
+		{"no schema", &openapi.Parameter{Name: "q", In: "query"}, "anything", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.param.Validate(tt.value)
+			if tt.wantErr == "" {
+				assert.NoError(t, err, "value should be valid")
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr, "error should match expected")
+			}
+		})
+	}
+}
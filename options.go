@@ -11,11 +11,12 @@ type Option func(*Router)
 func WithErrorHandler(handler ErrorHandler) Option {
 	return func(r *Router) {
 		r.errHandler = handler
+		r.ctxErrHandler = nil
 	}
 }
 
 func defaultErrorHandler(w http.ResponseWriter, _ *http.Request, err error) {
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+	http.Error(w, err.Error(), StatusCode(err))
 }
 
 func WithRouterBuilder(builder func() chi.Router) Option {
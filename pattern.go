@@ -0,0 +1,105 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var routeInfoCtxKey = &contextKey{"route-info"}
+
+type routeInfo struct {
+	method string
+	path   string
+
+	once    sync.Once
+	pattern string
+
+	costs    *routeCosts
+	versions *versionSet
+}
+
+func RoutePattern(r *http.Request) string {
+	return RoutePatternFromCtx(r.Context())
+}
+
+func RoutePatternFromCtx(ctx context.Context) string {
+	rctx := chi.RouteContext(ctx)
+	if rctx == nil {
+		return ""
+	}
+
+	info, ok := ctx.Value(routeInfoCtxKey).(*routeInfo)
+	if !ok || rctx.Routes == nil {
+		return rctx.RoutePattern()
+	}
+
+	info.once.Do(func() {
+		info.pattern = rctx.Routes.Find(chi.NewRouteContext(), info.method, info.path)
+	})
+
+	if info.pattern == "" {
+		return rctx.RoutePattern()
+	}
+
+	return info.pattern
+}
+
+func withRouteInfo(req *http.Request, r *Router) *http.Request {
+	path := req.URL.RawPath
+	if path == "" {
+		path = req.URL.Path
+	}
+
+	return req.WithContext(context.WithValue(req.Context(), routeInfoCtxKey, &routeInfo{method: req.Method, path: path, costs: r.costs, versions: r.versions}))
+}
+
+// RoutePatternFor returns the pattern of the route that would handle the
+// request path for method, or "" when none does.
+func RoutePatternFor(r *http.Request, method string) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+
+	return rctx.Routes.Find(chi.NewRouteContext(), method, requestPath(r))
+}
+
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace,
+}
+
+// AllowedMethods returns the methods registered for the request path in the
+// route tree, in a stable order. It returns nil when no route matches the path.
+func AllowedMethods(r *http.Request) []string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return nil
+	}
+
+	path := requestPath(r)
+
+	var methods []string
+	for _, method := range routeMethods {
+		if rctx.Routes.Match(chi.NewRouteContext(), method, path) {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
+
+func requestPath(r *http.Request) string {
+	if info, ok := r.Context().Value(routeInfoCtxKey).(*routeInfo); ok {
+		return info.path
+	}
+
+	if r.URL.RawPath != "" {
+		return r.URL.RawPath
+	}
+
+	return r.URL.Path
+}
@@ -0,0 +1,65 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutePattern(t *testing.T) {
+	var inMiddleware, inSubMiddleware, inHandler string
+
+	r := chu.New()
+	r.Use(func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			inMiddleware = chu.RoutePattern(r)
+			return next(ctx, w, r)
+		}
+	})
+
+	r.Route("/api", func(r *chu.Router) {
+		r.Use(func(next chu.Handler) chu.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				inSubMiddleware = chu.RoutePatternFromCtx(ctx)
+				return next(ctx, w, r)
+			}
+		})
+
+		r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			inHandler = chu.RoutePatternFromCtx(ctx)
+			return nil
+		})
+	})
+
+	r.Get("/static/*", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		inHandler = chu.RoutePattern(r)
+		return nil
+	})
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/api/users/42", expected: "/api/users/{id}"},
+		{path: "/static/css/app.css", expected: "/static/*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.expected, inMiddleware, "global middleware should see the final pattern")
+			assert.Equal(t, tt.expected, inHandler, "handler should see the final pattern")
+		})
+	}
+
+	assert.Equal(t, "/api/users/{id}", inSubMiddleware, "sub-router middleware should see the final pattern")
+
+	inMiddleware = "unset"
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nope", nil))
+	assert.Equal(t, "", inMiddleware, "unmatched requests should have no pattern")
+}
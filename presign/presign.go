@@ -0,0 +1,346 @@
+package presign
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var (
+	ErrInvalidToken = errors.New("presign: invalid callback token")
+	ErrTokenExpired = errors.New("presign: callback token expired")
+	ErrNoKeys       = errors.New("presign: no callback keys")
+	ErrNotUploaded  = errors.New("presign: object not uploaded")
+	ErrTokenUsed    = errors.New("presign: callback token already used")
+)
+
+type Request struct {
+	Method        string
+	Bucket        string
+	Key           string
+	ContentType   string
+	ContentLength int64
+	Metadata      map[string]string
+	Expires       time.Duration
+}
+
+type SignedURL struct {
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	Header    http.Header `json:"headers,omitempty"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+type PostRequest struct {
+	Bucket      string
+	Key         string
+	KeyPrefix   string
+	ContentType string
+	MaxSize     int64
+	Expires     time.Duration
+}
+
+type PostForm struct {
+	URL       string            `json:"url"`
+	Fields    map[string]string `json:"fields"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type Signer interface {
+	Presign(ctx context.Context, req Request) (*SignedURL, error)
+	PresignPost(ctx context.Context, req PostRequest) (*PostForm, error)
+}
+
+type Object struct {
+	ID          string `json:"id"`
+	Bucket      string `json:"bucket"`
+	Key         string `json:"key"`
+	ContentType string `json:"content_type,omitempty"`
+	ExpiresAt   int64  `json:"exp"`
+}
+
+type Ticket struct {
+	Upload *SignedURL `json:"upload"`
+	Token  string     `json:"token"`
+}
+
+type Option func(*Uploads)
+
+func WithExpiry(d time.Duration) Option {
+	return func(u *Uploads) {
+		u.expires = d
+	}
+}
+
+func WithKeys(keys ...[]byte) Option {
+	return func(u *Uploads) {
+		u.keys = keys
+	}
+}
+
+func WithOnComplete(fn func(ctx context.Context, obj Object) error) Option {
+	return func(u *Uploads) {
+		u.onComplete = fn
+	}
+}
+
+// WithHTTPClient sets the client used to check that an object exists before
+// completing its upload. Defaults to http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(u *Uploads) {
+		u.client = client
+	}
+}
+
+// WithStore sets where used callback tokens are remembered, so each token
+// completes one upload. Share a store between instances to enforce it across
+// them. Defaults to an in-memory store.
+func WithStore(store chu.Store) Option {
+	return func(u *Uploads) {
+		u.store = store
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(u *Uploads) {
+		u.now = now
+	}
+}
+
+type Uploads struct {
+	signer     Signer
+	bucket     string
+	expires    time.Duration
+	keys       [][]byte
+	onComplete func(ctx context.Context, obj Object) error
+	client     *http.Client
+	store      chu.Store
+	now        func() time.Time
+}
+
+func New(signer Signer, bucket string, opts ...Option) *Uploads {
+	u := &Uploads{
+		signer:  signer,
+		bucket:  bucket,
+		expires: 15 * time.Minute,
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	if len(u.keys) > 0 && checkKeys(u.keys) != nil {
+		panic("presign: callback keys must not be empty")
+	}
+
+	if u.client == nil {
+		u.client = http.DefaultClient
+	}
+
+	if u.store == nil {
+		u.store = chu.NewMemoryStore(100_000)
+	}
+
+	return u
+}
+
+func (u *Uploads) Issue(ctx context.Context, key, contentType string) (*Ticket, error) {
+	if err := checkKeys(u.keys); err != nil {
+		return nil, err
+	}
+
+	signed, err := u.signer.Presign(ctx, Request{
+		Bucket:      u.bucket,
+		Key:         key,
+		ContentType: contentType,
+		Expires:     u.expires,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := u.token(Object{
+		ID:          randomID(),
+		Bucket:      u.bucket,
+		Key:         key,
+		ContentType: contentType,
+		ExpiresAt:   u.now().Add(u.expires).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ticket{Upload: signed, Token: token}, nil
+}
+
+func (u *Uploads) Verify(token string) (Object, error) {
+	if err := checkKeys(u.keys); err != nil {
+		return Object{}, err
+	}
+
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Object{}, ErrInvalidToken
+	}
+
+	for _, key := range u.keys {
+		if !hmac.Equal([]byte(sig), []byte(signToken(key, payload))) {
+			continue
+		}
+
+		raw, err := base64.RawURLEncoding.DecodeString(payload)
+		if err != nil {
+			return Object{}, ErrInvalidToken
+		}
+
+		var obj Object
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return Object{}, ErrInvalidToken
+		}
+
+		if u.now().Unix() > obj.ExpiresAt {
+			return Object{}, ErrTokenExpired
+		}
+
+		return obj, nil
+	}
+
+	return Object{}, ErrInvalidToken
+}
+
+type callbackRequest struct {
+	Token string `json:"token"`
+}
+
+func (u *Uploads) Callback(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var req callbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return chu.Errorf(http.StatusBadRequest, "presign: invalid request body: %w", err)
+	}
+
+	obj, err := u.Verify(req.Token)
+	if errors.Is(err, ErrNoKeys) {
+		return err
+	}
+
+	if err != nil {
+		return chu.NewError(http.StatusForbidden, err)
+	}
+
+	if obj.ID == "" {
+		return chu.NewError(http.StatusForbidden, ErrInvalidToken)
+	}
+
+	if err := u.exists(ctx, obj); err != nil {
+		return err
+	}
+
+	// The token is marked used only once the object exists, so a client
+	// calling back too early can retry, and unmarked again when completion
+	// fails.
+	used := "presign:token:" + obj.ID
+	ttl := time.Unix(obj.ExpiresAt, 0).Sub(u.now()) + time.Minute
+
+	n, err := u.store.Increment(ctx, used, 1, ttl)
+	if err != nil {
+		return err
+	}
+
+	if n != 1 {
+		return chu.NewError(http.StatusConflict, ErrTokenUsed)
+	}
+
+	if u.onComplete != nil {
+		if err := u.onComplete(ctx, obj); err != nil {
+			return errors.Join(err, u.store.Delete(ctx, used))
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// exists checks the object with a presigned HEAD request, since the signer is
+// the only credential Uploads holds.
+func (u *Uploads) exists(ctx context.Context, obj Object) error {
+	head, err := u.signer.Presign(ctx, Request{
+		Method:  http.MethodHead,
+		Bucket:  obj.Bucket,
+		Key:     obj.Key,
+		Expires: time.Minute,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, head.Method, head.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	for name, values := range head.Header {
+		req.Header[name] = values
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return chu.Errorf(http.StatusBadGateway, "presign: checking object: %w", err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return chu.NewError(http.StatusConflict, ErrNotUploaded)
+	default:
+		return chu.NewError(http.StatusBadGateway, fmt.Errorf("presign: checking object: unexpected status %d", resp.StatusCode))
+	}
+}
+
+func (u *Uploads) token(obj Object) (string, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+
+	return payload + "." + signToken(u.keys[0], payload), nil
+}
+
+// checkKeys rejects missing and empty keys, since an empty HMAC key lets
+// anyone sign tokens.
+func checkKeys(keys [][]byte) error {
+	if len(keys) == 0 || slices.ContainsFunc(keys, func(key []byte) bool { return len(key) == 0 }) {
+		return ErrNoKeys
+	}
+
+	return nil
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signToken(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
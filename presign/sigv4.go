@@ -0,0 +1,379 @@
+package presign
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRequest = errors.New("presign: bucket and key are required")
+
+type S3 struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Endpoint        string
+	PathStyle       bool
+	Now             func() time.Time
+}
+
+func (s *S3) Presign(ctx context.Context, req Request) (*SignedURL, error) {
+	return s.signer().presign(req)
+}
+
+func (s *S3) PresignPost(ctx context.Context, req PostRequest) (*PostForm, error) {
+	return s.signer().presignPost(req)
+}
+
+func (s *S3) signer() *v4Signer {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + s.Region + ".amazonaws.com"
+	}
+
+	return &v4Signer{
+		algorithm:    "AWS4-HMAC-SHA256",
+		keyPrefix:    "AWS4",
+		terminator:   "aws4_request",
+		paramPrefix:  "X-Amz-",
+		headerPrefix: "x-amz-",
+		service:      "s3",
+		region:       s.Region,
+		accessKey:    s.AccessKeyID,
+		secretKey:    s.SecretAccessKey,
+		sessionToken: s.SessionToken,
+		endpoint:     endpoint,
+		pathStyle:    s.PathStyle,
+		now:          s.Now,
+	}
+}
+
+// GCS signs with HMAC interoperability keys using the V4 signing process.
+type GCS struct {
+	AccessID  string
+	Secret    string
+	Endpoint  string
+	PathStyle bool
+	Now       func() time.Time
+}
+
+func (g *GCS) Presign(ctx context.Context, req Request) (*SignedURL, error) {
+	return g.signer().presign(req)
+}
+
+func (g *GCS) PresignPost(ctx context.Context, req PostRequest) (*PostForm, error) {
+	return g.signer().presignPost(req)
+}
+
+func (g *GCS) signer() *v4Signer {
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+
+	return &v4Signer{
+		algorithm:    "GOOG4-HMAC-SHA256",
+		keyPrefix:    "GOOG4",
+		terminator:   "goog4_request",
+		paramPrefix:  "X-Goog-",
+		headerPrefix: "x-goog-",
+		service:      "storage",
+		region:       "auto",
+		accessKey:    g.AccessID,
+		secretKey:    g.Secret,
+		endpoint:     endpoint,
+		pathStyle:    g.PathStyle || g.Endpoint == "",
+		now:          g.Now,
+	}
+}
+
+type v4Signer struct {
+	algorithm    string
+	keyPrefix    string
+	terminator   string
+	paramPrefix  string
+	headerPrefix string
+	service      string
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	endpoint     string
+	pathStyle    bool
+	now          func() time.Time
+}
+
+func (s *v4Signer) presign(req Request) (*SignedURL, error) {
+	if req.Bucket == "" || req.Key == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodPut
+	}
+
+	expires := req.Expires
+	if expires <= 0 {
+		expires = 15 * time.Minute
+	}
+
+	now := s.clock()
+	u, err := s.objectURL(req.Bucket, req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	if req.ContentType != "" {
+		header.Set("Content-Type", req.ContentType)
+	}
+
+	if req.ContentLength > 0 {
+		header.Set("Content-Length", strconv.FormatInt(req.ContentLength, 10))
+	}
+
+	for k, v := range req.Metadata {
+		header.Set(s.headerPrefix+"meta-"+k, v)
+	}
+
+	names := []string{"host"}
+	for k := range header {
+		names = append(names, strings.ToLower(k))
+	}
+	sort.Strings(names)
+	signedHeaders := strings.Join(names, ";")
+
+	query := url.Values{}
+	query.Set(s.paramPrefix+"Algorithm", s.algorithm)
+	query.Set(s.paramPrefix+"Credential", s.accessKey+"/"+s.scope(now))
+	query.Set(s.paramPrefix+"Date", now.Format(amzDateFormat))
+	query.Set(s.paramPrefix+"Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set(s.paramPrefix+"SignedHeaders", signedHeaders)
+	if s.sessionToken != "" {
+		query.Set(s.paramPrefix+"Security-Token", s.sessionToken)
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		value := u.Host
+		if name != "host" {
+			value = strings.TrimSpace(header.Get(name))
+		}
+
+		canonicalHeaders.WriteString(name + ":" + value + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		s.algorithm,
+		now.Format(amzDateFormat),
+		s.scope(now),
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	u.RawQuery = canonicalQuery(query) + "&" + s.paramPrefix + "Signature=" + hex.EncodeToString(hmacSHA256(s.signingKey(now), stringToSign))
+
+	return &SignedURL{
+		Method:    method,
+		URL:       u.String(),
+		Header:    header,
+		ExpiresAt: now.Add(expires),
+	}, nil
+}
+
+func (s *v4Signer) presignPost(req PostRequest) (*PostForm, error) {
+	if req.Bucket == "" || (req.Key == "" && req.KeyPrefix == "") {
+		return nil, ErrInvalidRequest
+	}
+
+	expires := req.Expires
+	if expires <= 0 {
+		expires = 15 * time.Minute
+	}
+
+	now := s.clock()
+	u, err := s.bucketURL(req.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{
+		strings.ToLower(s.paramPrefix) + "algorithm":  s.algorithm,
+		strings.ToLower(s.paramPrefix) + "credential": s.accessKey + "/" + s.scope(now),
+		strings.ToLower(s.paramPrefix) + "date":       now.Format(amzDateFormat),
+	}
+
+	if s.sessionToken != "" {
+		fields[strings.ToLower(s.paramPrefix)+"security-token"] = s.sessionToken
+	}
+
+	conditions := []any{map[string]string{"bucket": req.Bucket}}
+
+	if req.Key != "" {
+		fields["key"] = req.Key
+		conditions = append(conditions, map[string]string{"key": req.Key})
+	} else {
+		conditions = append(conditions, []string{"starts-with", "$key", req.KeyPrefix})
+	}
+
+	if req.ContentType != "" {
+		fields["Content-Type"] = req.ContentType
+		conditions = append(conditions, map[string]string{"Content-Type": req.ContentType})
+	}
+
+	if req.MaxSize > 0 {
+		conditions = append(conditions, []any{"content-length-range", 0, req.MaxSize})
+	}
+
+	for k, v := range fields {
+		if k != "key" && k != "Content-Type" {
+			conditions = append(conditions, map[string]string{k: v})
+		}
+	}
+
+	policy, err := json.Marshal(map[string]any{
+		"expiration": now.Add(expires).Format(time.RFC3339),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(policy)
+	fields["policy"] = encoded
+	fields[strings.ToLower(s.paramPrefix)+"signature"] = hex.EncodeToString(hmacSHA256(s.signingKey(now), encoded))
+
+	return &PostForm{
+		URL:       u.String(),
+		Fields:    fields,
+		ExpiresAt: now.Add(expires),
+	}, nil
+}
+
+const amzDateFormat = "20060102T150405Z"
+
+func (s *v4Signer) clock() time.Time {
+	if s.now != nil {
+		return s.now().UTC()
+	}
+
+	return time.Now().UTC()
+}
+
+func (s *v4Signer) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/" + s.service + "/" + s.terminator
+}
+
+func (s *v4Signer) signingKey(now time.Time) []byte {
+	key := hmacSHA256([]byte(s.keyPrefix+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+
+	return hmacSHA256(key, s.terminator)
+}
+
+func (s *v4Signer) bucketURL(bucket string) (*url.URL, error) {
+	u, err := url.Parse(s.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.pathStyle {
+		u.Path = "/" + bucket + "/"
+	} else {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/"
+	}
+
+	return u, nil
+}
+
+func (s *v4Signer) objectURL(bucket, key string) (*url.URL, error) {
+	u, err := s.bucketURL(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	key = strings.TrimPrefix(key, "/")
+	u.RawPath = u.Path + escapePath(key)
+	u.Path += key
+
+	return u, nil
+}
+
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range values[k] {
+			pairs = append(pairs, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func uriEncode(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&15])
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package chu
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type Profile int
+
+const (
+	Development Profile = iota
+	Production
+)
+
+// ProfileFromEnv returns Production when CHU_ENV (or APP_ENV) is "production"
+// or "prod", and Development otherwise.
+func ProfileFromEnv() Profile {
+	switch strings.ToLower(os.Getenv("CHU_ENV")) {
+	case "production", "prod":
+		return Production
+	case "":
+		if env := strings.ToLower(os.Getenv("APP_ENV")); env == "production" || env == "prod" {
+			return Production
+		}
+	}
+
+	return Development
+}
+
+func (p Profile) String() string {
+	if p == Production {
+		return "production"
+	}
+
+	return "development"
+}
+
+// Config returns the preset used by NewDefault so callers can adjust it before
+// passing it to NewFromConfig.
+func (p Profile) Config() Config {
+	cfg := Config{
+		Middlewares: []func(Handler) Handler{
+			AdaptMiddleware(middleware.RequestID),
+			AdaptMiddleware(middleware.RealIP),
+		},
+	}
+
+	if p == Production {
+		cfg.ErrorHandler = JSONErrorHandler
+		cfg.Middlewares = append(cfg.Middlewares,
+			AdaptMiddleware(accessLog(slog.New(slog.NewJSONHandler(os.Stdout, nil)))),
+			AdaptMiddleware(middleware.Recoverer),
+			AdaptMiddleware(middleware.Timeout(30*time.Second)),
+		)
+
+		return cfg
+	}
+
+	cfg.Middlewares = append(cfg.Middlewares,
+		AdaptMiddleware(middleware.Logger),
+		AdaptMiddleware(middleware.Recoverer),
+	)
+
+	return cfg
+}
+
+func NewDefault(p Profile, opts ...Option) *Router {
+	return NewFromConfig(p.Config(), opts...)
+}
+
+func accessLog(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				if !Sampled(r.Context()) && status < http.StatusInternalServerError {
+					return
+				}
+
+				level := slog.LevelInfo
+				if status >= http.StatusInternalServerError {
+					level = slog.LevelError
+				}
+
+				logger.LogAttrs(context.WithoutCancel(r.Context()), level, "request",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", status),
+					slog.Int("bytes", ww.BytesWritten()),
+					slog.Duration("duration", time.Since(start)),
+					slog.String("remote_addr", r.RemoteAddr),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile chu.Profile
+		path    string
+		status  int
+		body    string
+	}{
+		{name: "production hides internal errors", profile: chu.Production, path: "/fail", status: http.StatusInternalServerError, body: `{"error":{"code":"INTERNAL_SERVER_ERROR","message":"Internal Server Error","status":500}}` + "\n"},
+		{name: "development shows internal errors", profile: chu.Development, path: "/fail", status: http.StatusInternalServerError, body: "database password leaked\n"},
+		{name: "production recovers panics", profile: chu.Production, path: "/panic", status: http.StatusInternalServerError},
+		{name: "development recovers panics", profile: chu.Development, path: "/panic", status: http.StatusInternalServerError},
+		{name: "request metadata", profile: chu.Production, path: "/meta", status: http.StatusOK, body: "203.0.113.7 true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chu.NewDefault(tt.profile)
+			r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				return errors.New("database password leaked")
+			})
+			r.Get("/panic", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				panic("boom")
+			})
+			r.Get("/meta", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				hasID := middleware.GetReqID(ctx) != ""
+				_, err := w.Write([]byte(r.RemoteAddr + " " + strconv.FormatBool(hasID)))
+				return err
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code, "status code should match expected")
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rec.Body.String(), "body should match expected")
+			}
+		})
+	}
+}
+
+func TestProfileConfigOverridable(t *testing.T) {
+	cfg := chu.Production.Config()
+	cfg.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+
+	r := chu.NewFromConfig(cfg)
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("fail")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusTeapot, rec.Code, "overridden error handler should be used")
+}
+
+func TestProfileFromEnv(t *testing.T) {
+	tests := []struct {
+		name   string
+		chuEnv string
+		appEnv string
+		want   chu.Profile
+	}{
+		{name: "unset", want: chu.Development},
+		{name: "chu env", chuEnv: "Production", want: chu.Production},
+		{name: "app env", appEnv: "prod", want: chu.Production},
+		{name: "chu env wins", chuEnv: "dev", appEnv: "production", want: chu.Development},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CHU_ENV", tt.chuEnv)
+			t.Setenv("APP_ENV", tt.appEnv)
+
+			assert.Equal(t, tt.want, chu.ProfileFromEnv(), "profile should match expected")
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package chu
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+var propagatedHeadersCtxKey = &contextKey{"propagated-headers"}
+
+var DefaultPropagatedHeaders = []string{
+	"X-Request-Id",
+	"Traceparent",
+	"Tracestate",
+	"B3",
+	"X-B3-Traceid",
+	"X-B3-Spanid",
+	"X-B3-Parentspanid",
+	"X-B3-Sampled",
+	"X-B3-Flags",
+}
+
+func PropagateHeaders(names ...string) func(Handler) Handler {
+	if len(names) == 0 {
+		names = DefaultPropagatedHeaders
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			headers := make(http.Header)
+			for _, name := range names {
+				if values := r.Header.Values(name); len(values) > 0 {
+					headers[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+				}
+			}
+
+			ctx = context.WithValue(ctx, propagatedHeadersCtxKey, headers)
+
+			return next(ctx, w, r.WithContext(ctx))
+		}
+	}
+}
+
+func PropagatedHeaders(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(propagatedHeadersCtxKey).(http.Header)
+	return headers.Clone()
+}
+
+func OutboundRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, _ := ctx.Value(propagatedHeadersCtxKey).(http.Header)
+	for name, values := range headers {
+		req.Header[name] = append([]string(nil), values...)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,88 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboundRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  []string
+		inbound  map[string]string
+		expected http.Header
+	}{
+		{
+			name: "default headers",
+			inbound: map[string]string{
+				"X-Request-Id":  "req-1",
+				"traceparent":   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"Authorization": "Bearer secret",
+				"Cookie":        "session=1",
+			},
+			expected: http.Header{
+				"X-Request-Id": {"req-1"},
+				"Traceparent":  {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			},
+		},
+		{
+			name:    "configured headers",
+			headers: []string{"authorization", "x-tenant"},
+			inbound: map[string]string{
+				"X-Request-Id":  "req-1",
+				"Authorization": "Bearer secret",
+				"X-Tenant":      "acme",
+			},
+			expected: http.Header{
+				"Authorization": {"Bearer secret"},
+				"X-Tenant":      {"acme"},
+			},
+		},
+		{
+			name:     "nothing to propagate",
+			inbound:  map[string]string{"Accept": "application/json"},
+			expected: http.Header{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var outbound *http.Request
+
+			r := chu.New()
+			r.Use(chu.PropagateHeaders(tt.headers...))
+			r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				var err error
+				outbound, err = chu.OutboundRequest(ctx, http.MethodGet, "http://upstream/items", nil)
+				return err
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.inbound {
+				req.Header.Set(k, v)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+			require.NotNil(t, outbound, "outbound request should be built")
+			assert.Equal(t, tt.expected, outbound.Header, "propagated headers should match expected")
+			assert.Equal(t, "upstream", outbound.URL.Host, "outbound URL should match")
+		})
+	}
+}
+
+func TestOutboundRequest_WithoutMiddleware(t *testing.T) {
+	req, err := chu.OutboundRequest(context.Background(), http.MethodPost, "http://upstream/", nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, req.Header, "no headers should be added without the middleware")
+	assert.Nil(t, chu.PropagatedHeaders(context.Background()), "no headers should be captured without the middleware")
+}
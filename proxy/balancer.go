@@ -0,0 +1,276 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrNoHealthyUpstream = errors.New("no healthy upstream")
+
+// Backend is one upstream target of a balanced proxy.
+type Backend struct {
+	URL *url.URL
+
+	active       atomic.Int64
+	down         atomic.Bool
+	ejectedUntil atomic.Int64
+	consecutive  atomic.Int64
+
+	requests  atomic.Uint64
+	failures  atomic.Uint64
+	ejections atomic.Uint64
+
+	// probeStreak counts consecutive probe results disagreeing with the
+	// current state; only the health check goroutine uses it.
+	probeStreak int
+}
+
+// Active returns the number of requests in flight to the backend.
+func (b *Backend) Active() int64 {
+	return b.active.Load()
+}
+
+// Healthy reports whether the backend passes active health checks and is not
+// ejected by passive ones.
+func (b *Backend) Healthy() bool {
+	return !b.down.Load() && !b.ejected(time.Now())
+}
+
+// SetHealthy marks the backend as able to receive traffic or not. Marking it
+// healthy also ends an ejection.
+func (b *Backend) SetHealthy(healthy bool) {
+	b.down.Store(!healthy)
+	if healthy {
+		b.ejectedUntil.Store(0)
+		b.consecutive.Store(0)
+	}
+}
+
+func (b *Backend) ejected(now time.Time) bool {
+	return now.UnixNano() < b.ejectedUntil.Load()
+}
+
+type BackendStats struct {
+	Requests  uint64
+	Failures  uint64
+	Ejections uint64
+	Active    int64
+	Healthy   bool
+	// EjectedUntil is zero unless the backend is ejected.
+	EjectedUntil time.Time
+}
+
+func (b *Backend) Stats() BackendStats {
+	stats := BackendStats{
+		Requests:  b.requests.Load(),
+		Failures:  b.failures.Load(),
+		Ejections: b.ejections.Load(),
+		Active:    b.Active(),
+		Healthy:   b.Healthy(),
+	}
+
+	if until := b.ejectedUntil.Load(); until > time.Now().UnixNano() {
+		stats.EjectedUntil = time.Unix(0, until)
+	}
+
+	return stats
+}
+
+// Strategy picks the backend for a request among the healthy ones, which are
+// always in the order the targets were given.
+type Strategy interface {
+	Pick(r *http.Request, backends []*Backend) *Backend
+}
+
+type StrategyFunc func(r *http.Request, backends []*Backend) *Backend
+
+func (f StrategyFunc) Pick(r *http.Request, backends []*Backend) *Backend {
+	return f(r, backends)
+}
+
+func RoundRobin() Strategy {
+	var next atomic.Uint64
+
+	return StrategyFunc(func(r *http.Request, backends []*Backend) *Backend {
+		return backends[(next.Add(1)-1)%uint64(len(backends))]
+	})
+}
+
+// LeastConnections picks the backend with the fewest requests in flight,
+// rotating between ties.
+func LeastConnections() Strategy {
+	var next atomic.Uint64
+
+	return StrategyFunc(func(r *http.Request, backends []*Backend) *Backend {
+		start := int((next.Add(1) - 1) % uint64(len(backends)))
+
+		var best *Backend
+		for i := range backends {
+			b := backends[(start+i)%len(backends)]
+			if best == nil || b.Active() < best.Active() {
+				best = b
+			}
+		}
+
+		return best
+	})
+}
+
+// ConsistentHash sends requests with the same key to the same backend, for
+// sticky sessions or cache locality. It uses rendezvous hashing, so a backend
+// leaving or joining only moves the keys it owns. Requests with an empty key
+// are spread round robin.
+func ConsistentHash(key func(r *http.Request) string) Strategy {
+	fallback := RoundRobin()
+
+	return StrategyFunc(func(r *http.Request, backends []*Backend) *Backend {
+		k := key(r)
+		if k == "" {
+			return fallback.Pick(r, backends)
+		}
+
+		var best *Backend
+		var bestScore uint64
+		for _, b := range backends {
+			h := fnv.New64a()
+			h.Write([]byte(b.URL.String()))
+			h.Write([]byte{0})
+			h.Write([]byte(k))
+
+			if score := h.Sum64(); best == nil || score > bestScore {
+				best, bestScore = b, score
+			}
+		}
+
+		return best
+	})
+}
+
+func WithStrategy(s Strategy) Option {
+	return func(p *Proxy) {
+		p.strategy = s
+	}
+}
+
+type backendCtxKey struct{}
+
+// NewBalanced returns a proxy spreading requests over targets with the
+// configured strategy, round robin by default.
+func NewBalanced(targets []*url.URL, opts ...Option) *Proxy {
+	backends := make([]*Backend, len(targets))
+	for i, target := range targets {
+		backends[i] = &Backend{URL: target}
+	}
+
+	p := New(nil, append([]Option{func(p *Proxy) { p.backends = backends }}, opts...)...)
+	if p.strategy == nil {
+		p.strategy = RoundRobin()
+	}
+
+	p.rp.Transport = &observedTransport{next: p.rp.Transport, passive: p.passive}
+	if p.hedging != nil {
+		p.hedging.next = p.rp.Transport
+		p.rp.Transport = p.hedging
+	}
+
+	if p.healthCheck != nil {
+		p.startHealthCheck()
+	}
+
+	return p
+}
+
+// Backends returns the upstreams of a balanced proxy.
+func (p *Proxy) Backends() []*Backend {
+	return p.backends
+}
+
+// Close stops background health checks.
+func (p *Proxy) Close() error {
+	p.closeOnce.Do(func() {
+		if p.stop != nil {
+			close(p.stop)
+		}
+	})
+
+	return nil
+}
+
+// pick chooses among the healthy backends not in tried. When every backend is
+// ejected by passive checks, the ones passing active checks are used anyway,
+// so a burst of errors cannot take the whole upstream out.
+func (p *Proxy) pick(r *http.Request, tried []*Backend) (*Backend, int) {
+	candidates := make([]*Backend, 0, len(p.backends))
+	for _, b := range p.backends {
+		if b.Healthy() && !slices.Contains(tried, b) {
+			candidates = append(candidates, b)
+		}
+	}
+
+	if len(candidates) == 0 {
+		for _, b := range p.backends {
+			if !b.down.Load() && !slices.Contains(tried, b) {
+				candidates = append(candidates, b)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, 0
+	}
+
+	return p.strategy.Pick(r, candidates), len(candidates)
+}
+
+type attempt struct {
+	failover bool
+	err      error
+}
+
+type attemptCtxKey struct{}
+
+// serveBalanced forwards r to the backend chosen for it. Idempotent requests
+// without a body fail over to another backend when the connection fails.
+func (p *Proxy) serveBalanced(w http.ResponseWriter, r *http.Request) error {
+	replayable := idempotent(r.Method) && (r.Body == nil || r.Body == http.NoBody)
+
+	var tried []*Backend
+	for {
+		b, candidates := p.pick(r, tried)
+		if b == nil {
+			return chu.NewError(http.StatusServiceUnavailable, ErrNoHealthyUpstream)
+		}
+
+		a := &attempt{failover: replayable && candidates > 1}
+
+		ctx := context.WithValue(r.Context(), backendCtxKey{}, b)
+		ctx = context.WithValue(ctx, attemptCtxKey{}, a)
+
+		b.active.Add(1)
+		p.rp.ServeHTTP(w, r.WithContext(ctx))
+		b.active.Add(-1)
+
+		if a.err == nil {
+			return nil
+		}
+
+		tried = append(tried, b)
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
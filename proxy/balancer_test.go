@@ -0,0 +1,147 @@
+package proxy_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/proxy"
+)
+
+func newBackends(t *testing.T, names ...string) ([]*url.URL, map[string]*atomic.Bool) {
+	t.Helper()
+
+	var targets []*url.URL
+	up := make(map[string]*atomic.Bool)
+	for _, name := range names {
+		healthy := &atomic.Bool{}
+		healthy.Store(true)
+		up[name] = healthy
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/healthz" && !healthy.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			_, _ = io.WriteString(w, name)
+		}))
+		t.Cleanup(srv.Close)
+
+		target, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		targets = append(targets, target)
+	}
+
+	return targets, up
+}
+
+func TestBalancedRoundRobin(t *testing.T) {
+	targets, _ := newBackends(t, "a", "b", "c")
+	p := proxy.NewBalanced(targets)
+
+	var got []string
+	for range 6 {
+		got = append(got, get(t, p, "/", nil).Body.String())
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "a", "b", "c"}, got, "requests should rotate between backends")
+}
+
+func TestBalancedLeastConnections(t *testing.T) {
+	targets, _ := newBackends(t, "a", "b")
+
+	release := make(chan struct{})
+	p := proxy.NewBalanced(targets, proxy.WithStrategy(proxy.LeastConnections()), proxy.WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == targets[0].Host && req.URL.Path == "/slow" {
+			<-release
+		}
+
+		return http.DefaultTransport.RoundTrip(req)
+	})))
+
+	done := make(chan string)
+	go func() {
+		done <- get(t, p, "/slow", nil).Body.String()
+	}()
+
+	require.Eventually(t, func() bool { return p.Backends()[0].Active() == 1 }, time.Second, time.Millisecond, "slow request should be in flight")
+
+	for range 3 {
+		assert.Equal(t, "b", get(t, p, "/", nil).Body.String(), "backend with fewer connections should be picked")
+	}
+
+	close(release)
+	assert.Equal(t, "a", <-done, "slow request should complete")
+	assert.Zero(t, p.Backends()[0].Active(), "finished requests should not count")
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBalancedConsistentHash(t *testing.T) {
+	targets, _ := newBackends(t, "a", "b", "c")
+	p := proxy.NewBalanced(targets, proxy.WithStrategy(proxy.ConsistentHash(func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	})))
+
+	owners := map[string]string{}
+	for _, user := range []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8"} {
+		owner := get(t, p, "/", http.Header{"X-User": {user}}).Body.String()
+		for range 3 {
+			assert.Equal(t, owner, get(t, p, "/", http.Header{"X-User": {user}}).Body.String(), "same key should stick to a backend")
+		}
+		owners[user] = owner
+	}
+
+	p.Backends()[1].SetHealthy(false)
+
+	for user, owner := range owners {
+		got := get(t, p, "/", http.Header{"X-User": {user}}).Body.String()
+		if owner != "b" {
+			assert.Equal(t, owner, got, "keys of healthy backends should not move")
+		} else {
+			assert.NotEqual(t, "b", got, "keys of unhealthy backends should move")
+		}
+	}
+}
+
+func TestBalancedHealthCheck(t *testing.T) {
+	targets, up := newBackends(t, "a", "b")
+	up["a"].Store(false)
+
+	p := proxy.NewBalanced(targets, proxy.WithHealthCheck(proxy.HealthCheck{Path: "/healthz", Interval: 10 * time.Millisecond}))
+	t.Cleanup(func() { _ = p.Close() })
+
+	assert.False(t, p.Backends()[0].Healthy(), "failing backend should be unhealthy after the first probe")
+
+	for range 3 {
+		assert.Equal(t, "b", get(t, p, "/", nil).Body.String(), "only healthy backends should receive traffic")
+	}
+
+	up["a"].Store(true)
+	assert.Eventually(t, p.Backends()[0].Healthy, time.Second, 5*time.Millisecond, "recovered backend should become healthy")
+
+	up["a"].Store(false)
+	up["b"].Store(false)
+	assert.Eventually(t, func() bool { return !p.Backends()[0].Healthy() && !p.Backends()[1].Healthy() }, time.Second, 5*time.Millisecond)
+
+	rec := get(t, p, "/", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "status code should match expected")
+
+	err := p.Handle(context.Background(), httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.ErrorIs(t, err, proxy.ErrNoHealthyUpstream, "handler should report missing upstreams")
+	assert.Equal(t, http.StatusServiceUnavailable, chu.StatusCode(err), "status code should match expected")
+}
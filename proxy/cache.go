@@ -0,0 +1,379 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/cache"
+)
+
+type Entry struct {
+	Status   int
+	Header   http.Header
+	Body     []byte
+	Vary     map[string]string
+	StoredAt time.Time
+}
+
+type Cache interface {
+	Get(key string) (*Entry, bool)
+	Set(key string, entry *Entry)
+	Delete(key string)
+}
+
+type CacheOptions struct {
+	Store       Cache
+	MaxBodySize int64
+	DefaultTTL  time.Duration
+	Now         func() time.Time
+}
+
+func NewCacheTransport(next http.RoundTripper, opts CacheOptions) http.RoundTripper {
+	if opts.Store == nil {
+		opts.Store = NewMemoryCache(1024)
+	}
+
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	return &cacheTransport{next: next, opts: opts}
+}
+
+type cacheTransport struct {
+	next http.RoundTripper
+	opts CacheOptions
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) || req.Header.Get("Authorization") != "" || hasDirective(req.Header, "no-store") {
+		return t.next.RoundTrip(req)
+	}
+
+	key, err := chu.RequestKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Responses to requests with cookies may be personalized, so they are only
+	// shared when marked public.
+	cookies := req.Header.Get("Cookie") != ""
+
+	entry, ok := t.opts.Store.Get(key)
+	if ok && (!entry.matches(req) || cookies && !hasDirective(entry.Header, "public")) {
+		entry, ok = nil, false
+	}
+
+	if ok && !hasDirective(req.Header, "no-cache") && entry.fresh(t.opts.Now(), t.opts.DefaultTTL) {
+		return entry.response(req, "HIT"), nil
+	}
+
+	outReq := req
+	if ok {
+		outReq = req.Clone(req.Context())
+		outReq.Header.Del("If-None-Match")
+		outReq.Header.Del("If-Modified-Since")
+
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+
+		if modified := entry.Header.Get("Last-Modified"); modified != "" {
+			outReq.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		if ok {
+			return entry.response(req, "STALE"), nil
+		}
+
+		return nil, err
+	}
+
+	switch {
+	case ok && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close()
+
+		refreshed := *entry
+		refreshed.Header = entry.Header.Clone()
+		for _, name := range []string{"Cache-Control", "Date", "Expires", "ETag", "Last-Modified"} {
+			if value := resp.Header.Get(name); value != "" {
+				refreshed.Header.Set(name, value)
+			}
+		}
+		refreshed.StoredAt = t.opts.Now()
+
+		t.opts.Store.Set(key, &refreshed)
+		return refreshed.response(req, "REVALIDATED"), nil
+
+	case ok && resp.StatusCode >= http.StatusInternalServerError:
+		resp.Body.Close()
+		return entry.response(req, "STALE"), nil
+
+	case req.Method == http.MethodGet && cacheable(resp) && (!cookies || hasDirective(resp.Header, "public")):
+		return t.store(key, req, resp)
+
+	default:
+		if ok {
+			t.opts.Store.Delete(key)
+		}
+
+		resp.Header.Set("X-Cache", "MISS")
+		return resp, nil
+	}
+}
+
+func (t *cacheTransport) store(key string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.opts.MaxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if int64(len(body)) > t.opts.MaxBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		resp.Header.Set("X-Cache", "MISS")
+
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	entry := &Entry{
+		Status:   resp.StatusCode,
+		Header:   resp.Header.Clone(),
+		Body:     body,
+		Vary:     make(map[string]string),
+		StoredAt: t.opts.Now(),
+	}
+
+	for _, name := range varyHeaders(resp.Header) {
+		entry.Vary[name] = req.Header.Get(name)
+	}
+
+	t.opts.Store.Set(key, entry)
+
+	return entry.response(req, "MISS"), nil
+}
+
+func (e *Entry) matches(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *Entry) fresh(now time.Time, defaultTTL time.Duration) bool {
+	if hasDirective(e.Header, "no-cache") {
+		return false
+	}
+
+	ttl := defaultTTL
+	if maxAge, ok := directiveSeconds(e.Header, "s-maxage"); ok {
+		ttl = maxAge
+	} else if maxAge, ok := directiveSeconds(e.Header, "max-age"); ok {
+		ttl = maxAge
+	} else if expires, err := http.ParseTime(e.Header.Get("Expires")); err == nil {
+		ttl = expires.Sub(e.StoredAt)
+	}
+
+	return now.Sub(e.StoredAt) < ttl
+}
+
+func (e *Entry) response(req *http.Request, state string) *http.Response {
+	header := e.Header.Clone()
+	header.Set("X-Cache", state)
+
+	status, body := e.Status, e.Body
+	if notModified(req, e.Header) {
+		status, body = http.StatusNotModified, nil
+	}
+
+	if req.Method == http.MethodHead {
+		body = nil
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func notModified(req *http.Request, header http.Header) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(header.Get("ETag"), "W/")
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || (etag != "" && candidate == etag) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+	return err == nil && !modified.After(since)
+}
+
+func cacheable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	if hasDirective(resp.Header, "no-store") || hasDirective(resp.Header, "private") {
+		return false
+	}
+
+	// A stored Set-Cookie would hand one client's session to every other.
+	if len(resp.Header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+
+	if slices.Contains(varyHeaders(resp.Header), "*") {
+		return false
+	}
+
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	return names
+}
+
+func hasDirective(header http.Header, directive string) bool {
+	_, ok := cacheDirective(header, directive)
+	return ok
+}
+
+func directiveSeconds(header http.Header, directive string) (time.Duration, bool) {
+	value, ok := cacheDirective(header, directive)
+	if !ok {
+		return 0, false
+	}
+
+	secs, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(secs) * time.Second, true
+}
+
+func cacheDirective(header http.Header, directive string) (string, bool) {
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if strings.EqualFold(name, directive) {
+				return strings.Trim(arg, `"`), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+type MemoryCache struct {
+	entries *cache.Cache[string, *Entry]
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{entries: cache.New(cache.Options[string, *Entry]{MaxEntries: maxEntries})}
+}
+
+func (c *MemoryCache) Get(key string) (*Entry, bool) {
+	return c.entries.Get(key)
+}
+
+func (c *MemoryCache) Set(key string, entry *Entry) {
+	c.entries.Set(key, entry)
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.entries.Delete(key)
+}
+
+// Stats reports the cache hit ratio and evictions.
+func (c *MemoryCache) Stats() cache.Stats {
+	return c.entries.Stats()
+}
+
+// StoreCache adapts a chu.Store, such as a Redis backed one, so cached
+// responses are shared between instances. Entries are kept for ttl, zero
+// meaning as long as the store keeps them. Store errors are treated as misses.
+func StoreCache(store chu.Store, ttl time.Duration) Cache {
+	return &storeCache{store: store, ttl: ttl}
+}
+
+type storeCache struct {
+	store chu.Store
+	ttl   time.Duration
+}
+
+func (c *storeCache) Get(key string) (*Entry, bool) {
+	data, err := c.store.Get(context.Background(), "proxy:"+key)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+func (c *storeCache) Set(key string, entry *Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	_ = c.store.Set(context.Background(), "proxy:"+key, data, c.ttl)
+}
+
+func (c *storeCache) Delete(key string) {
+	_ = c.store.Delete(context.Background(), "proxy:"+key)
+}
@@ -0,0 +1,187 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type HealthCheck struct {
+	// Path is requested on every backend; 2xx and 3xx responses mean healthy.
+	Path     string
+	Interval time.Duration
+	Timeout  time.Duration
+	Client   *http.Client
+	// HealthyThreshold and UnhealthyThreshold are the consecutive probe
+	// results needed to change a backend's state. Both default to 1.
+	HealthyThreshold   int
+	UnhealthyThreshold int
+}
+
+// WithHealthCheck probes the backends of a balanced proxy in the background
+// and only routes to the healthy ones. Close stops probing.
+func WithHealthCheck(hc HealthCheck) Option {
+	return func(p *Proxy) {
+		p.healthCheck = &hc
+	}
+}
+
+// PassiveHealthCheck ejects backends based on the outcome of proxied requests.
+type PassiveHealthCheck struct {
+	// MaxFailures is the number of consecutive failures that ejects a
+	// backend. Defaults to 5.
+	MaxFailures int
+	// EjectionTime is how long an ejected backend receives no traffic before
+	// it is tried again. Defaults to 30 seconds.
+	EjectionTime time.Duration
+	// Failed classifies outcomes. Defaults to transport errors and 5xx
+	// responses.
+	Failed func(resp *http.Response, err error) bool
+}
+
+// WithPassiveHealthCheck ejects backends of a balanced proxy that keep
+// failing requests, and recovers them once the ejection time passes.
+func WithPassiveHealthCheck(pc PassiveHealthCheck) Option {
+	return func(p *Proxy) {
+		if pc.MaxFailures <= 0 {
+			pc.MaxFailures = 5
+		}
+
+		if pc.EjectionTime <= 0 {
+			pc.EjectionTime = 30 * time.Second
+		}
+
+		if pc.Failed == nil {
+			pc.Failed = func(resp *http.Response, err error) bool {
+				return err != nil || resp.StatusCode >= http.StatusInternalServerError
+			}
+		}
+
+		p.passive = &pc
+	}
+}
+
+// observedTransport records the outcome of every request for backend stats
+// and passive health checks.
+type observedTransport struct {
+	next    http.RoundTripper
+	passive *PassiveHealthCheck
+}
+
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+
+	b, ok := req.Context().Value(backendCtxKey{}).(*Backend)
+	if !ok {
+		return resp, err
+	}
+
+	b.requests.Add(1)
+
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	if t.passive != nil {
+		failed = t.passive.Failed(resp, err)
+	}
+
+	if !failed {
+		b.consecutive.Store(0)
+		return resp, err
+	}
+
+	b.failures.Add(1)
+
+	if t.passive != nil && b.consecutive.Add(1) >= int64(t.passive.MaxFailures) {
+		b.consecutive.Store(0)
+		b.ejectedUntil.Store(time.Now().Add(t.passive.EjectionTime).UnixNano())
+		b.ejections.Add(1)
+	}
+
+	return resp, err
+}
+
+func (p *Proxy) startHealthCheck() {
+	hc := p.healthCheck
+	if hc.Interval <= 0 {
+		hc.Interval = 10 * time.Second
+	}
+
+	if hc.Timeout <= 0 {
+		hc.Timeout = 2 * time.Second
+	}
+
+	if hc.Client == nil {
+		hc.Client = http.DefaultClient
+	}
+
+	hc.HealthyThreshold = max(hc.HealthyThreshold, 1)
+	hc.UnhealthyThreshold = max(hc.UnhealthyThreshold, 1)
+
+	p.stop = make(chan struct{})
+	p.probeAll(hc)
+
+	go func() {
+		ticker := time.NewTicker(hc.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.probeAll(hc)
+			}
+		}
+	}()
+}
+
+func (p *Proxy) probeAll(hc *HealthCheck) {
+	var wg sync.WaitGroup
+	for _, b := range p.backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.observeProbe(hc, probe(hc, b))
+		}()
+	}
+	wg.Wait()
+}
+
+func (b *Backend) observeProbe(hc *HealthCheck, healthy bool) {
+	if healthy != b.down.Load() {
+		b.probeStreak = 0
+		return
+	}
+
+	threshold := hc.UnhealthyThreshold
+	if healthy {
+		threshold = hc.HealthyThreshold
+	}
+
+	if b.probeStreak++; b.probeStreak >= threshold {
+		b.probeStreak = 0
+		b.down.Store(!healthy)
+	}
+}
+
+func probe(hc *HealthCheck, b *Backend) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), hc.Timeout)
+	defer cancel()
+
+	target := *b.URL
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/" + strings.TrimPrefix(hc.Path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := hc.Client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode < http.StatusBadRequest
+}
@@ -0,0 +1,120 @@
+package proxy_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu/proxy"
+)
+
+func newStatusBackend(t *testing.T, name string, status int) *url.URL {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, name)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	return target
+}
+
+func TestPassiveHealthCheck(t *testing.T) {
+	failing := newStatusBackend(t, "a", http.StatusInternalServerError)
+	ok := newStatusBackend(t, "b", http.StatusOK)
+
+	p := proxy.NewBalanced([]*url.URL{failing, ok}, proxy.WithPassiveHealthCheck(proxy.PassiveHealthCheck{
+		MaxFailures:  2,
+		EjectionTime: 50 * time.Millisecond,
+	}))
+
+	var got []string
+	for range 6 {
+		got = append(got, get(t, p, "/", nil).Body.String())
+	}
+
+	assert.Equal(t, []string{"a", "b", "a", "b", "b", "b"}, got, "failing backend should be ejected")
+
+	stats := p.Backends()[0].Stats()
+	assert.Equal(t, uint64(2), stats.Requests, "requests should be counted")
+	assert.Equal(t, uint64(2), stats.Failures, "failures should be counted")
+	assert.Equal(t, uint64(1), stats.Ejections, "ejections should be counted")
+	assert.False(t, stats.Healthy, "ejected backend should be unhealthy")
+	assert.False(t, stats.EjectedUntil.IsZero(), "ejection end should be reported")
+	assert.Equal(t, uint64(4), p.Backends()[1].Stats().Requests, "healthy backend requests should be counted")
+
+	assert.Eventually(t, p.Backends()[0].Healthy, time.Second, 5*time.Millisecond, "ejected backend should recover")
+}
+
+func TestPassiveHealthCheckAllEjected(t *testing.T) {
+	p := proxy.NewBalanced([]*url.URL{
+		newStatusBackend(t, "a", http.StatusBadGateway),
+		newStatusBackend(t, "b", http.StatusBadGateway),
+	}, proxy.WithPassiveHealthCheck(proxy.PassiveHealthCheck{MaxFailures: 1, EjectionTime: time.Minute}))
+
+	for range 2 {
+		get(t, p, "/", nil)
+	}
+
+	assert.False(t, p.Backends()[0].Healthy(), "backend should be ejected")
+	assert.False(t, p.Backends()[1].Healthy(), "backend should be ejected")
+	assert.Equal(t, http.StatusBadGateway, get(t, p, "/", nil).Code, "ejected backends should still be used when none is left")
+}
+
+func TestBalancedFailover(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	dead, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	srv.Close()
+
+	ok := newStatusBackend(t, "b", http.StatusOK)
+	p := proxy.NewBalanced([]*url.URL{dead, ok})
+
+	for range 4 {
+		rec := get(t, p, "/", nil)
+		assert.Equal(t, http.StatusOK, rec.Code, "idempotent requests should fail over")
+		assert.Equal(t, "b", rec.Body.String(), "body should match expected")
+	}
+
+	assert.Equal(t, uint64(4), p.Backends()[0].Stats().Failures, "connection failures should be counted")
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+	codes := map[int]int{}
+	for range 2 {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, req.Clone(req.Context()))
+		codes[rec.Code]++
+	}
+
+	assert.Equal(t, map[int]int{http.StatusOK: 1, http.StatusBadGateway: 1}, codes, "requests with a body should not fail over")
+}
+
+func TestHealthCheckThresholds(t *testing.T) {
+	targets, up := newBackends(t, "a")
+	up["a"].Store(false)
+
+	p := proxy.NewBalanced(targets, proxy.WithHealthCheck(proxy.HealthCheck{
+		Path:               "/healthz",
+		Interval:           20 * time.Millisecond,
+		UnhealthyThreshold: 2,
+		HealthyThreshold:   2,
+	}))
+	t.Cleanup(func() { _ = p.Close() })
+
+	assert.True(t, p.Backends()[0].Healthy(), "a single failed probe should not mark the backend unhealthy")
+	assert.Eventually(t, func() bool { return !p.Backends()[0].Healthy() }, time.Second, 5*time.Millisecond, "repeated failures should mark the backend unhealthy")
+
+	up["a"].Store(true)
+	assert.Eventually(t, p.Backends()[0].Healthy, time.Second, 5*time.Millisecond, "repeated successes should recover the backend")
+}
@@ -0,0 +1,252 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Hedging struct {
+	// Delay is how long an attempt may take before another backend is tried
+	// in parallel. Defaults to 50ms.
+	Delay time.Duration
+	// MaxAttempts bounds the attempts per request, the first included.
+	// Defaults to 2.
+	MaxAttempts int
+	// Budget bounds hedged attempts to this fraction of requests, so a slow
+	// upstream is not met with double the load. One hedge is always allowed
+	// on top. Defaults to 0.1.
+	Budget float64
+	// Methods lists the methods hedged. Defaults to GET and HEAD; requests
+	// with a body are never hedged.
+	Methods []string
+}
+
+// WithHedging hedges slow requests of a balanced proxy: when an attempt takes
+// longer than the delay, another backend is tried in parallel and the first
+// successful response wins.
+func WithHedging(h Hedging) Option {
+	return func(p *Proxy) {
+		if h.Delay <= 0 {
+			h.Delay = 50 * time.Millisecond
+		}
+
+		if h.MaxAttempts <= 0 {
+			h.MaxAttempts = 2
+		}
+
+		if h.Budget <= 0 {
+			h.Budget = 0.1
+		}
+
+		if len(h.Methods) == 0 {
+			h.Methods = []string{http.MethodGet, http.MethodHead}
+		}
+
+		p.hedging = &hedgingTransport{cfg: h, p: p}
+	}
+}
+
+type HedgeStats struct {
+	Requests uint64
+	// Hedged counts the extra attempts sent.
+	Hedged uint64
+	// Won counts the requests answered by an extra attempt.
+	Won uint64
+}
+
+// HedgeStats reports hedging activity; it is zero without WithHedging.
+func (p *Proxy) HedgeStats() HedgeStats {
+	if p.hedging == nil {
+		return HedgeStats{}
+	}
+
+	return HedgeStats{
+		Requests: p.hedging.requests.Load(),
+		Hedged:   p.hedging.hedged.Load(),
+		Won:      p.hedging.won.Load(),
+	}
+}
+
+type hedgingTransport struct {
+	next http.RoundTripper
+	cfg  Hedging
+	p    *Proxy
+
+	requests atomic.Uint64
+	hedged   atomic.Uint64
+	won      atomic.Uint64
+}
+
+type hedgeResult struct {
+	resp    *http.Response
+	err     error
+	cancel  context.CancelFunc
+	attempt int
+}
+
+func (r hedgeResult) ok() bool {
+	return r.err == nil && r.resp.StatusCode < http.StatusInternalServerError
+}
+
+func (r hedgeResult) discard() {
+	if r.resp != nil {
+		r.resp.Body.Close()
+	}
+	r.cancel()
+}
+
+func (t *hedgingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	primary, ok := req.Context().Value(backendCtxKey{}).(*Backend)
+	if !ok || !slices.Contains(t.cfg.Methods, req.Method) || (req.Body != nil && req.Body != http.NoBody) {
+		return t.next.RoundTrip(req)
+	}
+
+	t.requests.Add(1)
+
+	results := make(chan hedgeResult, t.cfg.MaxAttempts)
+	tried := []*Backend{primary}
+	cancels := []context.CancelFunc{t.launch(req, primary, primary, 0, results)}
+
+	// cancelLosers stops the attempts still running once one has answered.
+	cancelLosers := func(winner int) {
+		for i, cancel := range cancels {
+			if i != winner {
+				cancel()
+			}
+		}
+	}
+
+	timer := time.NewTimer(t.cfg.Delay)
+	defer timer.Stop()
+
+	pending := 1
+
+	hedge := func() bool {
+		if len(tried) >= t.cfg.MaxAttempts || !t.allowed() {
+			return false
+		}
+
+		b, _ := t.p.pick(req, tried)
+		if b == nil {
+			return false
+		}
+
+		t.hedged.Add(1)
+		cancels = append(cancels, t.launch(req, primary, b, len(tried), results))
+		tried = append(tried, b)
+		pending++
+
+		return true
+	}
+
+	for {
+		select {
+		case res := <-results:
+			pending--
+
+			if res.ok() {
+				if res.attempt > 0 {
+					t.won.Add(1)
+				}
+
+				cancelLosers(res.attempt)
+				go drain(results, pending)
+				return res.respond()
+			}
+
+			// A failed attempt is hedged right away rather than after the delay.
+			if pending == 0 && !hedge() {
+				return res.respond()
+			}
+
+			res.discard()
+		case <-timer.C:
+			if hedge() {
+				timer.Reset(t.cfg.Delay)
+			}
+		case <-req.Context().Done():
+			cancelLosers(-1)
+			go drain(results, pending)
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// allowed reports whether the hedge budget has room for another attempt.
+func (t *hedgingTransport) allowed() bool {
+	return float64(t.hedged.Load()) <= t.cfg.Budget*float64(t.requests.Load())
+}
+
+func (t *hedgingTransport) launch(req *http.Request, primary, b *Backend, attempt int, results chan<- hedgeResult) context.CancelFunc {
+	ctx, cancel := context.WithCancel(req.Context())
+	ctx = context.WithValue(ctx, backendCtxKey{}, b)
+
+	out := req.Clone(ctx)
+	if b != primary {
+		out.URL = retarget(req.URL, primary.URL, b.URL)
+		out.Host = ""
+	}
+
+	go func() {
+		// The primary attempt is already counted by serveBalanced.
+		if b != primary {
+			b.active.Add(1)
+			defer b.active.Add(-1)
+		}
+
+		resp, err := t.next.RoundTrip(out)
+		results <- hedgeResult{resp: resp, err: err, cancel: cancel, attempt: attempt}
+	}()
+
+	return cancel
+}
+
+// respond hands the response over, releasing its attempt once the body is
+// closed.
+func (r hedgeResult) respond() (*http.Response, error) {
+	if r.err != nil {
+		r.cancel()
+		return nil, r.err
+	}
+
+	r.resp.Body = &cancelBody{ReadCloser: r.resp.Body, cancel: r.cancel}
+	return r.resp, nil
+}
+
+// drain discards the attempts that lost.
+func drain(results <-chan hedgeResult, pending int) {
+	for range pending {
+		(<-results).discard()
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+// retarget moves u from the from backend to the to backend, keeping the part
+// of the path below the backend's own path.
+func retarget(u, from, to *url.URL) *url.URL {
+	out := *u
+	out.Scheme, out.Host = to.Scheme, to.Host
+
+	rest := strings.TrimPrefix(u.Path, strings.TrimSuffix(from.Path, "/"))
+	out.Path = strings.TrimSuffix(to.Path, "/") + rest
+	out.RawPath = ""
+
+	return &out
+}
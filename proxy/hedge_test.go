@@ -0,0 +1,92 @@
+package proxy_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu/proxy"
+)
+
+func newSlowBackend(t *testing.T, name string, delay time.Duration) *url.URL {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+
+		_, _ = io.WriteString(w, name)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	return target
+}
+
+func TestHedging(t *testing.T) {
+	p := proxy.NewBalanced([]*url.URL{
+		newSlowBackend(t, "slow", time.Second),
+		newSlowBackend(t, "fast", 0),
+	}, proxy.WithHedging(proxy.Hedging{Delay: 20 * time.Millisecond, Budget: 1}))
+
+	start := time.Now()
+	rec := get(t, p, "/", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+	assert.Equal(t, "fast", rec.Body.String(), "hedged attempt should win")
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "slow attempt should not be waited for")
+	assert.Equal(t, proxy.HedgeStats{Requests: 1, Hedged: 1, Won: 1}, p.HedgeStats(), "hedge stats should match expected")
+}
+
+func TestHedgingFailure(t *testing.T) {
+	p := proxy.NewBalanced([]*url.URL{
+		newStatusBackend(t, "a", http.StatusServiceUnavailable),
+		newStatusBackend(t, "b", http.StatusOK),
+	}, proxy.WithHedging(proxy.Hedging{Delay: time.Minute, Budget: 1}))
+
+	rec := get(t, p, "/", nil)
+
+	assert.Equal(t, "b", rec.Body.String(), "failed attempt should be hedged without waiting")
+	assert.Equal(t, uint64(1), p.HedgeStats().Won, "hedged attempt should be counted as won")
+}
+
+func TestHedgingBudget(t *testing.T) {
+	p := proxy.NewBalanced([]*url.URL{
+		newSlowBackend(t, "a", 60*time.Millisecond),
+		newSlowBackend(t, "b", 60*time.Millisecond),
+	}, proxy.WithHedging(proxy.Hedging{Delay: 10 * time.Millisecond, Budget: 0.1}))
+
+	for range 5 {
+		assert.Equal(t, http.StatusOK, get(t, p, "/", nil).Code, "status code should match expected")
+	}
+
+	stats := p.HedgeStats()
+	assert.Equal(t, uint64(5), stats.Requests, "requests should be counted")
+	assert.Equal(t, uint64(1), stats.Hedged, "hedges should be limited by the budget")
+}
+
+func TestHedgingMethods(t *testing.T) {
+	p := proxy.NewBalanced([]*url.URL{
+		newSlowBackend(t, "a", 40*time.Millisecond),
+		newSlowBackend(t, "b", 40*time.Millisecond),
+	}, proxy.WithHedging(proxy.Hedging{Delay: 5 * time.Millisecond, Budget: 1}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+
+	assert.Equal(t, "a", rec.Body.String(), "post should be served by the first backend")
+	assert.Equal(t, proxy.HedgeStats{}, p.HedgeStats(), "post should not be hedged")
+}
@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/client"
+)
+
+type Option func(*Proxy)
+
+func WithTransport(rt http.RoundTripper) Option {
+	return func(p *Proxy) {
+		p.transport = rt
+	}
+}
+
+func WithCache(opts CacheOptions) Option {
+	return func(p *Proxy) {
+		p.cache = &opts
+	}
+}
+
+// WithRetry retries failed upstream requests. Only requests without a body
+// are retried, and POST and PATCH only when opts.Methods lists them or the
+// request carries an Idempotency-Key header.
+func WithRetry(opts client.RetryOptions) Option {
+	return func(p *Proxy) {
+		p.retry = &opts
+	}
+}
+
+type Proxy struct {
+	target    *url.URL
+	transport http.RoundTripper
+	cache     *CacheOptions
+	retry     *client.RetryOptions
+	rp        *httputil.ReverseProxy
+
+	backends    []*Backend
+	strategy    Strategy
+	healthCheck *HealthCheck
+	passive     *PassiveHealthCheck
+	hedging     *hedgingTransport
+	stop        chan struct{}
+	closeOnce   sync.Once
+}
+
+type proxyErrCtxKey struct{}
+
+func New(target *url.URL, opts ...Option) *Proxy {
+	p := &Proxy{target: target, transport: http.DefaultTransport}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	transport := p.transport
+	if p.retry != nil {
+		transport = client.Retry(*p.retry)(transport)
+	}
+
+	if p.cache != nil {
+		transport = NewCacheTransport(transport, *p.cache)
+	}
+
+	p.rp = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			target := p.target
+			if b, ok := pr.In.Context().Value(backendCtxKey{}).(*Backend); ok {
+				target = b.URL
+			}
+
+			pr.SetURL(target)
+			pr.SetXForwarded()
+		},
+		Transport:    transport,
+		ErrorHandler: p.handleError,
+	}
+
+	return p
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.backends == nil {
+		p.rp.ServeHTTP(w, r)
+		return
+	}
+
+	if err := p.serveBalanced(w, r); err != nil {
+		w.WriteHeader(chu.StatusCode(err))
+	}
+}
+
+func (p *Proxy) Handle(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var err error
+
+	ctx = context.WithValue(ctx, proxyErrCtxKey{}, &err)
+	r = r.WithContext(ctx)
+
+	if p.backends == nil {
+		p.rp.ServeHTTP(w, r)
+		return err
+	}
+
+	if balanceErr := p.serveBalanced(w, r); balanceErr != nil {
+		return balanceErr
+	}
+
+	return err
+}
+
+func (p *Proxy) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadGateway
+	if r.Context().Err() != nil {
+		status = http.StatusGatewayTimeout
+	}
+
+	if a, ok := r.Context().Value(attemptCtxKey{}).(*attempt); ok && a.failover && r.Context().Err() == nil {
+		a.err = err
+		return
+	}
+
+	if slot, ok := r.Context().Value(proxyErrCtxKey{}).(*error); ok {
+		*slot = chu.NewError(status, err)
+		return
+	}
+
+	w.WriteHeader(status)
+}
@@ -0,0 +1,293 @@
+package proxy_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/client"
+	"github.com/josearomeroj/chu/proxy"
+)
+
+func newUpstream(t *testing.T, handler http.HandlerFunc) (*url.URL, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+
+	var calls, conditional atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			conditional.Add(1)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	return target, &calls, &conditional
+}
+
+func get(t *testing.T, h http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestProxyForwards(t *testing.T) {
+	target, _, _ := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Header().Set("X-Forwarded", r.Header.Get("X-Forwarded-Host"))
+		_, _ = io.WriteString(w, "upstream")
+	})
+
+	rec := get(t, proxy.New(target), "/items/1", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+	assert.Equal(t, "upstream", rec.Body.String(), "body should be forwarded")
+	assert.Equal(t, "/items/1", rec.Header().Get("X-Path"), "path should be forwarded")
+	assert.Equal(t, "example.com", rec.Header().Get("X-Forwarded"), "forwarded host should be set")
+}
+
+func TestProxyUpstreamError(t *testing.T) {
+	target, err := url.Parse("http://127.0.0.1:1")
+	require.NoError(t, err)
+
+	r := chu.New()
+	r.Get("/*", proxy.New(target).Handle)
+
+	rec := get(t, r, "/x", nil)
+	assert.Equal(t, http.StatusBadGateway, rec.Code, "status code should match expected")
+}
+
+func TestProxyCacheRevalidates(t *testing.T) {
+	target, calls, conditional := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = io.WriteString(w, "payload")
+	})
+
+	p := proxy.New(target, proxy.WithCache(proxy.CacheOptions{}))
+
+	first := get(t, p, "/doc", nil)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"), "first request should miss")
+	assert.Equal(t, "payload", first.Body.String(), "body should match upstream")
+
+	second := get(t, p, "/doc", nil)
+	assert.Equal(t, http.StatusOK, second.Code, "status code should match expected")
+	assert.Equal(t, "REVALIDATED", second.Header().Get("X-Cache"), "second request should revalidate")
+	assert.Equal(t, "payload", second.Body.String(), "cached body should be served")
+
+	assert.Equal(t, int32(2), calls.Load(), "upstream should be called twice")
+	assert.Equal(t, int32(1), conditional.Load(), "revalidation should be conditional")
+}
+
+func TestProxyCacheFresh(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	target, calls, _ := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Last-Modified", now.UTC().Format(http.TimeFormat))
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = io.WriteString(w, "fresh")
+	})
+
+	p := proxy.New(target, proxy.WithCache(proxy.CacheOptions{Now: func() time.Time { return now }}))
+
+	get(t, p, "/doc", nil)
+	rec := get(t, p, "/doc", nil)
+
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"), "fresh entry should hit")
+	assert.Equal(t, "fresh", rec.Body.String(), "cached body should be served")
+	assert.Equal(t, int32(1), calls.Load(), "upstream should be called once")
+
+	now = now.Add(2 * time.Minute)
+	rec = get(t, p, "/doc", nil)
+	assert.Equal(t, "REVALIDATED", rec.Header().Get("X-Cache"), "stale entry should revalidate")
+}
+
+func TestProxyCacheStore(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	target, calls, _ := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, "shared")
+	})
+
+	store := chu.NewMemoryStore(0)
+	opts := proxy.CacheOptions{Store: proxy.StoreCache(store, time.Hour), Now: func() time.Time { return now }}
+
+	get(t, proxy.New(target, proxy.WithCache(opts)), "/doc", nil)
+	rec := get(t, proxy.New(target, proxy.WithCache(opts)), "/doc", nil)
+
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"), "entry should be shared through the store")
+	assert.Equal(t, "shared", rec.Body.String(), "cached body should be served")
+	assert.Equal(t, int32(1), calls.Load(), "upstream should be called once")
+	assert.Equal(t, 1, store.Len(), "entry should be kept in the store")
+}
+
+func TestProxyCacheClientConditional(t *testing.T) {
+	target, _, _ := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, "payload")
+	})
+
+	p := proxy.New(target, proxy.WithCache(proxy.CacheOptions{}))
+	get(t, p, "/doc", nil)
+
+	rec := get(t, p, "/doc", http.Header{"If-None-Match": {`"v1"`}})
+	assert.Equal(t, http.StatusNotModified, rec.Code, "status code should match expected")
+	assert.Empty(t, rec.Body.String(), "not modified response should have no body")
+}
+
+func TestProxyCacheSkips(t *testing.T) {
+	tests := []struct {
+		name    string
+		control string
+		etag    string
+		cookie  string
+		header  http.Header
+	}{
+		{name: "no validators", control: "max-age=60"},
+		{name: "no-store", control: "no-store", etag: `"v1"`},
+		{name: "private", control: "private", etag: `"v1"`},
+		{name: "authorization", etag: `"v1"`, header: http.Header{"Authorization": {"Bearer x"}}},
+		{name: "set-cookie", control: "public", etag: `"v1"`, cookie: "session=abc"},
+		{name: "cookie", etag: `"v1"`, header: http.Header{"Cookie": {"session=abc"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, calls, conditional := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.control != "" {
+					w.Header().Set("Cache-Control", tt.control)
+				}
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+				}
+				if tt.cookie != "" {
+					w.Header().Set("Set-Cookie", tt.cookie)
+				}
+				_, _ = io.WriteString(w, "payload")
+			})
+
+			p := proxy.New(target, proxy.WithCache(proxy.CacheOptions{}))
+			get(t, p, "/doc", tt.header)
+			rec := get(t, p, "/doc", tt.header)
+
+			assert.Equal(t, tt.cookie, rec.Header().Get("Set-Cookie"), "cookies should only come from upstream")
+
+			assert.Equal(t, int32(2), calls.Load(), "upstream should be called for each request")
+			assert.Equal(t, int32(0), conditional.Load(), "requests should not be conditional")
+		})
+	}
+}
+
+func TestProxyCachePublicWithCookie(t *testing.T) {
+	target, calls, _ := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, "payload")
+	})
+
+	p := proxy.New(target, proxy.WithCache(proxy.CacheOptions{}))
+	get(t, p, "/doc", http.Header{"Cookie": {"session=abc"}})
+	rec := get(t, p, "/doc", http.Header{"Cookie": {"session=def"}})
+
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"), "public response should be shared")
+	assert.Equal(t, int32(1), calls.Load(), "upstream should be called once")
+}
+
+func TestProxyCacheStaleOnError(t *testing.T) {
+	var fail atomic.Bool
+	target, _, _ := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, "payload")
+	})
+
+	p := proxy.New(target, proxy.WithCache(proxy.CacheOptions{}))
+	get(t, p, "/doc", nil)
+
+	fail.Store(true)
+	rec := get(t, p, "/doc", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "status code should match expected")
+	assert.Equal(t, "STALE", rec.Header().Get("X-Cache"), "stale entry should be served")
+	assert.Equal(t, "payload", rec.Body.String(), "cached body should be served")
+}
+
+func TestProxyCacheVary(t *testing.T) {
+	target, calls, _ := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.Header.Get("Accept-Language")+`"`)
+		w.Header().Set("Vary", "Accept-Language")
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = io.WriteString(w, r.Header.Get("Accept-Language"))
+	})
+
+	p := proxy.New(target, proxy.WithCache(proxy.CacheOptions{}))
+
+	get(t, p, "/doc", http.Header{"Accept-Language": {"en"}})
+	rec := get(t, p, "/doc", http.Header{"Accept-Language": {"es"}})
+
+	assert.Equal(t, "es", rec.Body.String(), "variant should not be served from another language")
+	assert.Equal(t, int32(2), calls.Load(), "upstream should be called for each variant")
+}
+
+func TestProxyRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		attempts int32
+		expected int
+	}{
+		{name: "get is retried", method: http.MethodGet, attempts: 2, expected: http.StatusOK},
+		{name: "post is not retried", method: http.MethodPost, body: "payload", attempts: 1, expected: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failed atomic.Bool
+			target, calls, _ := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if !failed.Swap(true) {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			})
+
+			p := proxy.New(target, proxy.WithRetry(client.RetryOptions{BaseDelay: time.Millisecond}))
+
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expected, rec.Code, "status code should match expected")
+			assert.Equal(t, tt.attempts, calls.Load(), "attempts should match expected")
+		})
+	}
+}
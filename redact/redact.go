@@ -0,0 +1,358 @@
+// Package redact masks sensitive values before they reach logs. Fields are
+// selected with `chu:"redact"` struct tags, JSONPath rules or field names.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const Mask = "[REDACTED]"
+
+type Option func(*Redactor) error
+
+// WithPaths redacts values selected by JSONPath rules. Supported syntax is
+// $.a.b, $.a[0], $.a[*], $.a.* and recursive descent $..a.
+func WithPaths(exprs ...string) Option {
+	return func(r *Redactor) error {
+		for _, expr := range exprs {
+			p, err := parsePath(expr)
+			if err != nil {
+				return err
+			}
+			r.paths = append(r.paths, p)
+		}
+		return nil
+	}
+}
+
+// WithFields redacts object keys with the given names at any depth, compared
+// case-insensitively.
+func WithFields(names ...string) Option {
+	return func(r *Redactor) error {
+		for _, name := range names {
+			r.fields[strings.ToLower(name)] = true
+		}
+		return nil
+	}
+}
+
+func WithMask(mask string) Option {
+	return func(r *Redactor) error {
+		r.mask = mask
+		return nil
+	}
+}
+
+type Redactor struct {
+	mask   string
+	paths  []path
+	fields map[string]bool
+}
+
+func New(opts ...Option) (*Redactor, error) {
+	r := &Redactor{mask: Mask, fields: make(map[string]bool)}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func MustNew(opts ...Option) *Redactor {
+	r, err := New(opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// JSON returns data with sensitive values masked. Input that is not valid JSON
+// is returned unchanged along with the decoding error.
+func (r *Redactor) JSON(data []byte) ([]byte, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return data, err
+	}
+
+	return json.Marshal(r.apply(doc))
+}
+
+// Value converts v to a generic JSON-like value (maps, slices and scalars)
+// honouring json tags, with tagged fields and matching rules masked.
+func (r *Redactor) Value(v any) any {
+	return r.apply(r.convert(reflect.ValueOf(v)))
+}
+
+func (r *Redactor) Attr(key string, v any) slog.Attr {
+	return slog.Any(key, r.Value(v))
+}
+
+func (r *Redactor) apply(doc any) any {
+	if len(r.fields) > 0 {
+		doc = r.maskFields(doc)
+	}
+
+	for _, p := range r.paths {
+		doc = p.mask(doc, r.mask)
+	}
+
+	return doc
+}
+
+func (r *Redactor) maskFields(doc any) any {
+	switch v := doc.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if r.fields[strings.ToLower(key)] {
+				v[key] = r.mask
+				continue
+			}
+			v[key] = r.maskFields(value)
+		}
+	case []any:
+		for i := range v {
+			v[i] = r.maskFields(v[i])
+		}
+	}
+
+	return doc
+}
+
+func (r *Redactor) convert(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.Type().Implements(marshalerType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil
+		}
+
+		var out any
+		if data, err := v.Interface().(json.Marshaler).MarshalJSON(); err == nil && json.Unmarshal(data, &out) == nil {
+			return out
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return r.convert(v.Elem())
+
+	case reflect.Struct:
+		out := make(map[string]any)
+		r.convertStruct(v, out)
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = r.convert(iter.Value())
+		}
+		return out
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes()
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = r.convert(v.Index(i))
+		}
+		return out
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+
+	default:
+		return v.Interface()
+	}
+}
+
+var marshalerType = reflect.TypeFor[json.Marshaler]()
+
+func (r *Redactor) convertStruct(v reflect.Value, out map[string]any) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() || !field.IsExported() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				r.convertStruct(fv, out)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if hasTag(field.Tag.Get("chu"), "redact") {
+			out[name] = r.mask
+			continue
+		}
+
+		out[name] = r.convert(v.Field(i))
+	}
+}
+
+func hasTag(tag, option string) bool {
+	for _, part := range strings.Split(tag, ",") {
+		if strings.TrimSpace(part) == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+type segment struct {
+	key       string
+	index     int
+	wildcard  bool
+	recursive bool
+}
+
+type path []segment
+
+func parsePath(expr string) (path, error) {
+	rest, ok := strings.CutPrefix(expr, "$")
+	if !ok {
+		return nil, fmt.Errorf("redact: path %q must start with $", expr)
+	}
+
+	var p path
+	for rest != "" {
+		recursive := false
+		if strings.HasPrefix(rest, "..") {
+			recursive, rest = true, rest[1:]
+		}
+
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			key := rest[1 : end+1]
+			switch key {
+			case "":
+				return nil, fmt.Errorf("redact: path %q has an empty key", expr)
+			case "*":
+				p = append(p, segment{wildcard: true, index: -1, recursive: recursive})
+			default:
+				p = append(p, segment{key: key, index: -1, recursive: recursive})
+			}
+			rest = rest[end+1:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("redact: path %q has an unclosed index", expr)
+			}
+
+			inner := rest[1:end]
+			switch {
+			case inner == "*":
+				p = append(p, segment{wildcard: true, index: -1, recursive: recursive})
+			case strings.HasPrefix(inner, "'") && strings.HasSuffix(inner, "'") && len(inner) >= 2:
+				p = append(p, segment{key: inner[1 : len(inner)-1], index: -1, recursive: recursive})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("redact: path %q has an invalid index %q", expr, inner)
+				}
+				p = append(p, segment{index: index, recursive: recursive})
+			}
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("redact: path %q is malformed", expr)
+		}
+	}
+
+	if len(p) == 0 {
+		return nil, fmt.Errorf("redact: path %q selects the whole document", expr)
+	}
+
+	return p, nil
+}
+
+func (p path) mask(doc any, mask string) any {
+	if len(p) == 0 {
+		return mask
+	}
+
+	seg, rest := p[0], p[1:]
+
+	if seg.recursive {
+		doc = p.descend(doc, mask)
+	}
+
+	switch v := doc.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if seg.wildcard || (seg.index < 0 && seg.key == key) {
+				v[key] = rest.mask(value, mask)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			if seg.wildcard || seg.index == i {
+				v[i] = rest.mask(value, mask)
+			}
+		}
+	}
+
+	return doc
+}
+
+// descend applies p to every value nested below doc, implementing "..".
+func (p path) descend(doc any, mask string) any {
+	switch v := doc.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = p.mask(value, mask)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = p.mask(value, mask)
+		}
+	}
+
+	return doc
+}
@@ -0,0 +1,121 @@
+package redact_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu/redact"
+)
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password" chu:"redact"`
+}
+
+type account struct {
+	credentials
+	ID      int               `json:"id"`
+	Token   string            `chu:"redact"`
+	Email   string            `json:"email"`
+	Cards   []card            `json:"cards"`
+	Labels  map[string]string `json:"labels"`
+	Created time.Time         `json:"created"`
+	Secret  string            `json:"-"`
+	note    string
+}
+
+type card struct {
+	Last4  string `json:"last4"`
+	Number string `json:"number"`
+}
+
+func TestValue(t *testing.T) {
+	r := redact.MustNew(redact.WithPaths("$.cards[*].number"), redact.WithFields("EMAIL"))
+
+	got := r.Value(&account{
+		credentials: credentials{Username: "ana", Password: "hunter2"},
+		ID:          7,
+		Token:       "tok",
+		Email:       "ana@example.com",
+		Cards:       []card{{Last4: "4242", Number: "4242424242424242"}},
+		Labels:      map[string]string{"tier": "gold"},
+		Created:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Secret:      "hidden",
+		note:        "private",
+	})
+
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"username": "ana",
+		"password": "[REDACTED]",
+		"id": 7,
+		"Token": "[REDACTED]",
+		"email": "[REDACTED]",
+		"cards": [{"last4": "4242", "number": "[REDACTED]"}],
+		"labels": {"tier": "gold"},
+		"created": "2024-01-02T03:04:05Z"
+	}`, string(data), "value should be redacted")
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []redact.Option
+		input string
+		want  string
+	}{
+		{
+			name:  "exact path",
+			opts:  []redact.Option{redact.WithPaths("$.user.password")},
+			input: `{"user":{"name":"ana","password":"x"}}`,
+			want:  `{"user":{"name":"ana","password":"[REDACTED]"}}`,
+		},
+		{
+			name:  "recursive descent",
+			opts:  []redact.Option{redact.WithPaths("$..token")},
+			input: `{"token":"a","nested":{"token":"b","items":[{"token":"c"}]}}`,
+			want:  `{"token":"[REDACTED]","nested":{"token":"[REDACTED]","items":[{"token":"[REDACTED]"}]}}`,
+		},
+		{
+			name:  "index and wildcard",
+			opts:  []redact.Option{redact.WithPaths("$.items[0]", "$.meta.*")},
+			input: `{"items":["a","b"],"meta":{"x":1,"y":2}}`,
+			want:  `{"items":["[REDACTED]","b"],"meta":{"x":"[REDACTED]","y":"[REDACTED]"}}`,
+		},
+		{
+			name:  "field names",
+			opts:  []redact.Option{redact.WithFields("authorization", "ssn"), redact.WithMask("***")},
+			input: `[{"SSN":"123"},{"Authorization":"Bearer x","ok":true}]`,
+			want:  `[{"SSN":"***"},{"Authorization":"***","ok":true}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := redact.New(tt.opts...)
+			require.NoError(t, err)
+
+			got, err := r.JSON([]byte(tt.input))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got), "json should be redacted")
+		})
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	for _, expr := range []string{"user.password", "$.", "$[x]", "$.a[1", "$"} {
+		_, err := redact.New(redact.WithPaths(expr))
+		assert.Error(t, err, "path %q should be rejected", expr)
+	}
+
+	r := redact.MustNew()
+	got, err := r.JSON([]byte("not json"))
+	assert.Error(t, err, "invalid json should be reported")
+	assert.Equal(t, "not json", string(got), "invalid json should be returned unchanged")
+}
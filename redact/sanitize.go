@@ -0,0 +1,109 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/josearomeroj/chu"
+)
+
+// Pattern describes sensitive text to find in free-form strings. Replace
+// receives each match; Validate, when set, must accept a match before it is
+// replaced.
+type Pattern struct {
+	Name     string
+	Regexp   *regexp.Regexp
+	Validate func(match string) bool
+	Replace  func(match string) string
+}
+
+var (
+	Email = Pattern{
+		Name:   "email",
+		Regexp: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Replace: func(match string) string {
+			_, domain, _ := strings.Cut(match, "@")
+			return match[:1] + "***@" + domain
+		},
+	}
+
+	CardNumber = Pattern{
+		Name:     "card",
+		Regexp:   regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		Validate: luhn,
+		Replace: func(match string) string {
+			digits := onlyDigits(match)
+			return "****" + digits[len(digits)-4:]
+		},
+	}
+
+	BearerToken = Pattern{
+		Name:    "bearer",
+		Regexp:  regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+		Replace: func(string) string { return "Bearer " + Mask },
+	}
+)
+
+type Sanitizer struct {
+	patterns []Pattern
+}
+
+// NewSanitizer returns a Sanitizer for patterns, defaulting to Email,
+// CardNumber and BearerToken.
+func NewSanitizer(patterns ...Pattern) *Sanitizer {
+	if len(patterns) == 0 {
+		patterns = []Pattern{BearerToken, Email, CardNumber}
+	}
+
+	return &Sanitizer{patterns: patterns}
+}
+
+func (s *Sanitizer) String(msg string) string {
+	for _, p := range s.patterns {
+		msg = p.Regexp.ReplaceAllStringFunc(msg, func(match string) string {
+			if p.Validate != nil && !p.Validate(match) {
+				return match
+			}
+
+			if p.Replace == nil {
+				return Mask
+			}
+
+			return p.Replace(match)
+		})
+	}
+
+	return msg
+}
+
+// Error wraps err so its message is sanitized; see chu.SanitizeError.
+func (s *Sanitizer) Error(err error) error {
+	return chu.SanitizeError(err, s.String)
+}
+
+func onlyDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func luhn(s string) bool {
+	digits := onlyDigits(s)
+
+	sum := 0
+	for i := range len(digits) {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return sum%10 == 0
+}
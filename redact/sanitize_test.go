@@ -0,0 +1,88 @@
+package redact_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/redact"
+)
+
+func TestSanitizer(t *testing.T) {
+	s := redact.NewSanitizer()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "email", input: "user jane.doe@example.com not found", want: "user j***@example.com not found"},
+		{name: "card", input: "card 4242 4242 4242 4242 declined", want: "card ****4242 declined"},
+		{name: "not a card", input: "order 1234567890123 failed", want: "order 1234567890123 failed"},
+		{name: "bearer", input: "invalid header: Bearer eyJhbGciOi.abc-def_ghi=", want: "invalid header: Bearer [REDACTED]"},
+		{name: "clean", input: "nothing to see", want: "nothing to see"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.String(tt.input), "message should be sanitized")
+		})
+	}
+
+	custom := redact.NewSanitizer(redact.Pattern{Name: "ssn", Regexp: regexp.MustCompile(`\d{3}-\d{2}-\d{4}`)})
+	assert.Equal(t, "ssn [REDACTED] a@b.io", custom.String("ssn 123-45-6789 a@b.io"), "only custom patterns should apply")
+}
+
+func TestSanitizerError(t *testing.T) {
+	base := chu.Errorf(http.StatusNotFound, "no account for jane@example.com")
+	err := redact.NewSanitizer().Error(base)
+
+	assert.Equal(t, "no account for j***@example.com", err.Error(), "message should be sanitized")
+	assert.ErrorIs(t, err, base, "wrapped error should be preserved")
+	assert.Equal(t, http.StatusNotFound, chu.StatusCode(err), "status should be preserved")
+}
+
+func TestRouterErrorSanitizer(t *testing.T) {
+	var logged string
+
+	r := chu.New(
+		chu.WithErrorSanitizer(redact.NewSanitizer().String),
+		chu.WithContextErrorHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error, info chu.ErrorInfo) {
+			logged = err.Error()
+			chu.JSONErrorHandler(w, r, err)
+		}),
+	)
+	r.Post("/pay", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.NewError(http.StatusBadRequest, errors.Join(
+			fmt.Errorf("card 4111111111111111 rejected: %w", chu.Errorf(http.StatusPaymentRequired, "insufficient funds")),
+			chu.Errorf(http.StatusBadRequest, "receipt email bob@example.org bounced"),
+		))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/pay", nil))
+
+	assert.NotContains(t, logged, "4111111111111111", "logged message should be sanitized")
+	assert.NotContains(t, logged, "bob@example.org", "logged message should be sanitized")
+
+	var body struct {
+		Error struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Error.Errors, 2)
+	assert.Equal(t, "card ****1111 rejected: insufficient funds", body.Error.Errors[0].Message, "joined messages should be sanitized")
+	assert.Equal(t, "receipt email b***@example.org bounced", body.Error.Errors[1].Message, "joined messages should be sanitized")
+}
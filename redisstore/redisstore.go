@@ -0,0 +1,250 @@
+// Package redisstore implements chu.Store on top of Redis using the RESP
+// protocol directly, without a client library dependency.
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/internal/connpool"
+)
+
+type Options struct {
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	// Prefix is prepended to every key, so several applications can share a
+	// database.
+	Prefix   string
+	PoolSize int
+	Timeout  time.Duration
+	Dial     func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+type Store struct {
+	prefix string
+	pool   *connpool.Pool
+}
+
+var _ chu.Store = (*Store)(nil)
+
+// Error is an error reply from the server.
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+func New(opts Options) *Store {
+	if opts.Addr == "" {
+		opts.Addr = "localhost:6379"
+	}
+
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	if opts.Dial == nil {
+		opts.Dial = (&net.Dialer{}).DialContext
+	}
+
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return opts.Dial(ctx, "tcp", opts.Addr)
+	}
+
+	init := func(c *connpool.Conn) error {
+		if opts.Password != "" {
+			args := []string{"AUTH", opts.Password}
+			if opts.Username != "" {
+				args = []string{"AUTH", opts.Username, opts.Password}
+			}
+
+			if _, err := do(c, args...); err != nil {
+				return err
+			}
+		}
+
+		if opts.DB != 0 {
+			if _, err := do(c, "SELECT", strconv.Itoa(opts.DB)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return &Store{prefix: opts.Prefix, pool: connpool.New(opts.PoolSize, opts.Timeout, dial, init)}
+}
+
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := s.do(ctx, "GET", s.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply == nil {
+		return nil, chu.ErrStoreMiss
+	}
+
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+
+	return value, nil
+}
+
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", s.prefix + key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+
+	_, err := s.do(ctx, args...)
+	return err
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_, err := s.do(ctx, "DEL", s.prefix+key)
+	return err
+}
+
+// incrementScript adds to the counter and sets the expiry only when the key has
+// none, which makes the ttl apply from the first increment.
+const incrementScript = `local v = redis.call('INCRBY', KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and redis.call('PTTL', KEYS[1]) == -1 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return v`
+
+func (s *Store) Increment(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	var ms int64
+	if ttl > 0 {
+		ms = max(ttl.Milliseconds(), 1)
+	}
+
+	reply, err := s.do(ctx, "EVAL", incrementScript, "1", s.prefix+key, strconv.FormatInt(n, 10), strconv.FormatInt(ms, 10))
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected EVAL reply %T", reply)
+	}
+
+	return value, nil
+}
+
+func (s *Store) Close() error {
+	return s.pool.Close()
+}
+
+func (s *Store) do(ctx context.Context, args ...string) (any, error) {
+	var reply any
+
+	err := s.pool.Do(ctx, func(c *connpool.Conn) error {
+		var err error
+		reply, err = do(c, args...)
+
+		// Error replies leave the connection usable.
+		var replyErr Error
+		if errors.As(err, &replyErr) {
+			reply = replyErr
+			return nil
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if replyErr, ok := reply.(Error); ok {
+		return nil, replyErr
+	}
+
+	return reply, nil
+}
+
+func do(c *connpool.Conn, args ...string) (any, error) {
+	fmt.Fprintf(c.W, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.W, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if err := c.W.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readReply(c)
+}
+
+func readReply(c *connpool.Conn) (any, error) {
+	line, err := c.R.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+
+		if size < 0 {
+			return nil, nil
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.R, buf); err != nil {
+			return nil, err
+		}
+
+		return buf[:size], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+
+		if count < 0 {
+			return nil, nil
+		}
+
+		items := make([]any, count)
+		for i := range items {
+			if items[i], err = readReply(c); err != nil {
+				return nil, err
+			}
+		}
+
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
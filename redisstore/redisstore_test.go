@@ -0,0 +1,171 @@
+package redisstore_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/redisstore"
+)
+
+// fakeRedis implements the handful of commands the store sends. EVAL is
+// interpreted as the increment script.
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	ttls     map[string]int64
+	password string
+}
+
+func newFakeRedis(t *testing.T, password string) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeRedis{data: make(map[string]string), ttls: make(map[string]int64), password: password}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+
+	for {
+		var count int
+		if _, err := fmt.Fscanf(r, "*%d\r\n", &count); err != nil {
+			return
+		}
+
+		args := make([]string, count)
+		for i := range args {
+			var size int
+			if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+				return
+			}
+
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			args[i] = string(buf[:size])
+		}
+
+		f.mu.Lock()
+
+		var reply string
+		switch {
+		case args[0] == "AUTH":
+			authed = args[len(args)-1] == f.password
+			reply = "+OK\r\n"
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "GET":
+			if v, ok := f.data[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case args[0] == "SET":
+			f.data[args[1]] = args[2]
+			if len(args) == 5 {
+				f.ttls[args[1]], _ = strconv.ParseInt(args[4], 10, 64)
+			}
+			reply = "+OK\r\n"
+		case args[0] == "DEL":
+			delete(f.data, args[1])
+			reply = ":1\r\n"
+		case args[0] == "EVAL":
+			current, err := strconv.ParseInt(f.data[args[3]], 10, 64)
+			if _, ok := f.data[args[3]]; ok && err != nil {
+				reply = "-ERR value is not an integer or out of range\r\n"
+				break
+			}
+			n, _ := strconv.ParseInt(args[4], 10, 64)
+			f.data[args[3]] = strconv.FormatInt(current+n, 10)
+			if _, ok := f.ttls[args[3]]; !ok && args[5] != "0" {
+				f.ttls[args[3]], _ = strconv.ParseInt(args[5], 10, 64)
+			}
+			reply = fmt.Sprintf(":%d\r\n", current+n)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	s := redisstore.New(redisstore.Options{Addr: newFakeRedis(t, "secret"), Password: "secret", Prefix: "app:"})
+	t.Cleanup(func() { s.Close() })
+
+	_, err := s.Get(ctx, "missing")
+	assert.ErrorIs(t, err, chu.ErrStoreMiss, "missing key should be a miss")
+
+	require.NoError(t, s.Set(ctx, "greeting", []byte("hello\r\nworld"), time.Minute))
+
+	value, err := s.Get(ctx, "greeting")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\r\nworld", string(value), "value should round trip")
+
+	require.NoError(t, s.Delete(ctx, "greeting"))
+	_, err = s.Get(ctx, "greeting")
+	assert.ErrorIs(t, err, chu.ErrStoreMiss, "deleted key should be a miss")
+
+	n, err := s.Increment(ctx, "hits", 3, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n, "new counter should start at the increment")
+
+	n, err = s.Increment(ctx, "hits", 2, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n, "counter should accumulate")
+
+	require.NoError(t, s.Set(ctx, "name", []byte("chu"), 0))
+	_, err = s.Increment(ctx, "name", 1, 0)
+	var replyErr redisstore.Error
+	assert.ErrorAs(t, err, &replyErr, "server errors should be returned")
+
+	n, err = s.Increment(ctx, "hits", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n, "connection should stay usable after an error reply")
+}
+
+func TestStoreAuthFailure(t *testing.T) {
+	s := redisstore.New(redisstore.Options{Addr: newFakeRedis(t, "secret"), Password: "wrong"})
+	t.Cleanup(func() { s.Close() })
+
+	_, err := s.Get(context.Background(), "key")
+	assert.ErrorContains(t, err, "WRONGPASS", "authentication errors should be returned")
+}
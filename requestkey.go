@@ -0,0 +1,122 @@
+package chu
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var ErrKeyBodyTooLarge = errors.New("chu: request body too large for key")
+
+type RequestKeyOption func(*requestKey)
+
+// WithKeyHeaders includes the values of the named request headers, e.g.
+// Accept-Language for content negotiated responses.
+func WithKeyHeaders(names ...string) RequestKeyOption {
+	return func(k *requestKey) {
+		for _, name := range names {
+			k.headers = append(k.headers, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// WithKeyIgnoredParams excludes query parameters such as tracking or cache
+// busting parameters.
+func WithKeyIgnoredParams(names ...string) RequestKeyOption {
+	return func(k *requestKey) {
+		k.ignored = append(k.ignored, names...)
+	}
+}
+
+// WithKeyBody includes a hash of up to maxBytes of the request body. The body
+// is restored so handlers can still read it.
+func WithKeyBody(maxBytes int64) RequestKeyOption {
+	return func(k *requestKey) {
+		k.body, k.maxBody = true, maxBytes
+	}
+}
+
+// WithKeyPart includes an extra value, such as the authenticated tenant, so
+// requests from different principals never share a key.
+func WithKeyPart(fn func(r *http.Request) string) RequestKeyOption {
+	return func(k *requestKey) {
+		k.parts = append(k.parts, fn)
+	}
+}
+
+type requestKey struct {
+	headers []string
+	ignored []string
+	body    bool
+	maxBody int64
+	parts   []func(r *http.Request) string
+}
+
+// RequestKey computes the canonical key for r used by caching, idempotency and
+// coalescing. It covers the method, host, path and query, with parameters
+// sorted so their order does not matter, plus whatever the options add. The
+// result is a hex encoded SHA-256 digest.
+func RequestKey(r *http.Request, opts ...RequestKeyOption) (string, error) {
+	k := &requestKey{}
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	query := r.URL.Query()
+	for _, name := range k.ignored {
+		query.Del(name)
+	}
+
+	for _, values := range query {
+		slices.Sort(values)
+	}
+
+	h := sha256.New()
+	write := func(parts ...string) {
+		for _, part := range parts {
+			_, _ = io.WriteString(h, part)
+			_, _ = h.Write([]byte{0})
+		}
+	}
+
+	write(r.Method, strings.ToLower(host), r.URL.EscapedPath(), query.Encode())
+
+	for _, name := range slices.Sorted(slices.Values(k.headers)) {
+		write(name, strings.Join(r.Header.Values(name), ","))
+	}
+
+	for _, part := range k.parts {
+		write(part(r))
+	}
+
+	if k.body && r.Body != nil && r.Body != http.NoBody {
+		body, err := io.ReadAll(io.LimitReader(r.Body, k.maxBody+1))
+		if err != nil {
+			return "", err
+		}
+
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+
+		if int64(len(body)) > k.maxBody {
+			return "", ErrKeyBodyTooLarge
+		}
+
+		sum := sha256.Sum256(body)
+		write(hex.EncodeToString(sum[:]))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,84 @@
+package chu_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+)
+
+func TestRequestKey(t *testing.T) {
+	key := func(t *testing.T, method, target string, header http.Header, opts ...chu.RequestKeyOption) string {
+		t.Helper()
+
+		req := httptest.NewRequest(method, target, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+
+		k, err := chu.RequestKey(req, opts...)
+		require.NoError(t, err)
+
+		return k
+	}
+
+	base := key(t, "GET", "http://example.com/items?a=1&b=2", nil)
+
+	tests := []struct {
+		name  string
+		other string
+		same  bool
+	}{
+		{name: "query order", other: key(t, "GET", "http://example.com/items?b=2&a=1", nil), same: true},
+		{name: "host case", other: key(t, "GET", "http://EXAMPLE.com/items?a=1&b=2", nil), same: true},
+		{name: "method", other: key(t, "HEAD", "http://example.com/items?a=1&b=2", nil), same: false},
+		{name: "path", other: key(t, "GET", "http://example.com/other?a=1&b=2", nil), same: false},
+		{name: "host", other: key(t, "GET", "http://example.org/items?a=1&b=2", nil), same: false},
+		{name: "query value", other: key(t, "GET", "http://example.com/items?a=1&b=3", nil), same: false},
+		{name: "ignored param", other: key(t, "GET", "http://example.com/items?a=1&b=2&utm_source=x", nil, chu.WithKeyIgnoredParams("utm_source")), same: true},
+		{name: "unlisted header", other: key(t, "GET", "http://example.com/items?a=1&b=2", http.Header{"Accept-Language": {"es"}}), same: true},
+		{name: "listed header", other: key(t, "GET", "http://example.com/items?a=1&b=2", http.Header{"Accept-Language": {"es"}}, chu.WithKeyHeaders("accept-language")), same: false},
+		{name: "extra part", other: key(t, "GET", "http://example.com/items?a=1&b=2", nil, chu.WithKeyPart(func(r *http.Request) string { return "tenant-a" })), same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.same {
+				assert.Equal(t, base, tt.other, "keys should match")
+			} else {
+				assert.NotEqual(t, base, tt.other, "keys should differ")
+			}
+		})
+	}
+}
+
+func TestRequestKeyBody(t *testing.T) {
+	keyFor := func(body string) (string, error) {
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+
+		k, err := chu.RequestKey(req, chu.WithKeyBody(16))
+		if err == nil {
+			rest, _ := io.ReadAll(req.Body)
+			assert.Equal(t, body, string(rest), "body should be restored")
+		}
+
+		return k, err
+	}
+
+	a, err := keyFor(`{"qty":1}`)
+	require.NoError(t, err)
+
+	b, err := keyFor(`{"qty":2}`)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a, b, "different bodies should produce different keys")
+
+	_, err = keyFor(`{"qty":1,"note":"too long"}`)
+	assert.ErrorIs(t, err, chu.ErrKeyBodyTooLarge, "oversized body should be rejected")
+}
@@ -0,0 +1,75 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+type RouteOption func(*route)
+
+type route struct {
+	method      string
+	pattern     string
+	middlewares []func(Handler) Handler
+	syncCtx     bool
+	cost        *int
+	deprecation *deprecationPolicy
+	doc         *routeDoc
+}
+
+func newRoute(method, pattern string, opts []RouteOption) *route {
+	rt := &route{method: method, pattern: pattern}
+	for _, opt := range opts {
+		opt(rt)
+	}
+
+	return rt
+}
+
+func (rt *route) wrap(h Handler) Handler {
+	h = timeHandler(h)
+
+	for i := len(rt.middlewares) - 1; i >= 0; i-- {
+		if rt.syncCtx {
+			h = enterMiddleware(rt.middlewares[i](SyncContext(h)))
+			continue
+		}
+
+		h = rt.middlewares[i](h)
+	}
+
+	return h
+}
+
+func WithMiddleware(middlewares ...func(Handler) Handler) RouteOption {
+	return func(rt *route) {
+		rt.middlewares = append(rt.middlewares, middlewares...)
+	}
+}
+
+func ParamConstraint(name string, valid func(value string) bool) RouteOption {
+	return WithMiddleware(func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if !valid(URLParam(r, name)) {
+				return Errorf(http.StatusNotFound, "chu: invalid value for route parameter %q", name)
+			}
+
+			return next(ctx, w, r)
+		}
+	})
+}
+
+func IntParam(name string) RouteOption {
+	return ParamConstraint(name, func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	})
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func UUIDParam(name string) RouteOption {
+	return ParamConstraint(name, uuidPattern.MatchString)
+}
@@ -0,0 +1,59 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteConstraints(t *testing.T) {
+	ok := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte(chu.URLParam(r, "id")))
+		return err
+	}
+
+	r := chu.New()
+	r.Get("/regex/{id:[0-9]+}", ok)
+	r.Get("/int/{id}", ok, chu.IntParam("id"))
+	r.Get("/uuid/{id}", ok, chu.UUIDParam("id"))
+	r.Get("/custom/{id}", ok, chu.ParamConstraint("id", func(v string) bool { return len(v) == 3 }))
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{path: "/regex/42", expected: http.StatusOK},
+		{path: "/regex/abc", expected: http.StatusNotFound},
+		{path: "/int/-7", expected: http.StatusOK},
+		{path: "/int/7x", expected: http.StatusNotFound},
+		{path: "/uuid/4f1c2b1e-6a3d-4e8b-9c0f-1a2b3c4d5e6f", expected: http.StatusOK},
+		{path: "/uuid/not-a-uuid", expected: http.StatusNotFound},
+		{path: "/custom/abc", expected: http.StatusOK},
+		{path: "/custom/abcd", expected: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+		})
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	r := chu.New()
+	r.Post("/items", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}, chu.WithMiddleware(tagMiddleware("a"), tagMiddleware("b")))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/items", nil))
+
+	assert.Equal(t, []string{"a", "b"}, w.Header().Values("X-Tags"), "route middleware should run in order")
+}
@@ -118,6 +118,52 @@ func TestRouter_Group(t *testing.T) {
 	assert.Equal(t, "group", string(body), "Response body should match expected content")
 }
 
+func TestRouter_GroupMiddlewareScope(t *testing.T) {
+	tag := func(name string) func(chu.Handler) chu.Handler {
+		return func(next chu.Handler) chu.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Add("X-Middleware", name)
+				return next(ctx, w, r)
+			}
+		}
+	}
+
+	ok := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error { return nil }
+
+	r := chu.New()
+	r.Use(tag("root"))
+	r.Group(func(g *chu.Router) {
+		g.Use(tag("admin"))
+		g.Get("/admin", ok)
+	})
+	r.Group(func(g *chu.Router) {
+		g.Use(tag("public"))
+		g.Get("/public/{id}", ok)
+	})
+	r.Get("/plain", ok)
+
+	tests := []struct {
+		path               string
+		expectedStatus     int
+		expectedMiddleware []string
+	}{
+		{"/admin", http.StatusOK, []string{"root", "admin"}},
+		{"/public/1", http.StatusOK, []string{"root", "public"}},
+		{"/plain", http.StatusOK, []string{"root"}},
+		{"/missing", http.StatusNotFound, []string{"root"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code, "status code should match expected")
+			assert.Equal(t, tt.expectedMiddleware, w.Header().Values("X-Middleware"), "group middleware should only wrap the group's routes")
+		})
+	}
+}
+
 func TestRouter_Route(t *testing.T) {
 	r := chu.New()
 
@@ -275,3 +321,82 @@ func TestRouter_UseWithErrorHandling(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode, "Status code should be Internal Server Error")
 	assert.Equal(t, "middleware error", string(body), "Response body should match expected content")
 }
+
+func TestRouter_Chi(t *testing.T) {
+	router := chu.New()
+	router.Get("/chu", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, err := w.Write([]byte("chu"))
+		return err
+	})
+
+	router.Chi().Get("/chi", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("chi"))
+	})
+
+	for _, path := range []string{"/chu", "/chi"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+		assert.Equal(t, path[1:], w.Body.String(), "body should match expected")
+	}
+}
+
+func TestFromChi(t *testing.T) {
+	existing := chi.NewRouter()
+	existing.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Legacy", "true")
+			next.ServeHTTP(w, r)
+		})
+	})
+	existing.Get("/legacy", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("legacy"))
+	})
+
+	var handled error
+	router := chu.FromChi(existing, chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	router.Get("/migrated", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("migrated failure")
+	})
+
+	router.Route("/v2", func(r *chu.Router) {
+		r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte("v2"))
+			return err
+		})
+	})
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		path     string
+		expected int
+		body     string
+	}{
+		{name: "legacy route through chu", handler: router, path: "/legacy", expected: http.StatusOK, body: "legacy"},
+		{name: "chu route through chu", handler: router, path: "/migrated", expected: http.StatusTeapot},
+		{name: "chu route through chi", handler: existing, path: "/migrated", expected: http.StatusTeapot},
+		{name: "chu subrouter", handler: router, path: "/v2/", expected: http.StatusOK, body: "v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+			assert.Equal(t, "true", w.Header().Get("X-Legacy"), "existing chi middleware should apply")
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+		})
+	}
+
+	assert.EqualError(t, handled, "migrated failure", "chu errors should reach the error handler")
+	assert.Same(t, existing, router.Chi(), "Chi should return the wrapped router")
+}
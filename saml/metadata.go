@@ -0,0 +1,47 @@
+package saml
+
+import "encoding/xml"
+
+type entityDescriptor struct {
+	XMLName         xml.Name        `xml:"EntityDescriptor"`
+	XMLNS           string          `xml:"xmlns,attr"`
+	EntityID        string          `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor `xml:"SPSSODescriptor"`
+}
+
+type spSSODescriptor struct {
+	ProtocolSupportEnumeration string            `xml:"protocolSupportEnumeration,attr"`
+	AuthnRequestsSigned        bool              `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool              `xml:"WantAssertionsSigned,attr"`
+	KeyDescriptor              []keyDescriptor   `xml:"KeyDescriptor,omitempty"`
+	NameIDFormat               string            `xml:"NameIDFormat"`
+	AssertionConsumerService   []indexedEndpoint `xml:"AssertionConsumerService"`
+}
+
+type keyDescriptor struct {
+	Use         string `xml:"use,attr"`
+	Certificate string `xml:"http://www.w3.org/2000/09/xmldsig# KeyInfo>X509Data>X509Certificate"`
+}
+
+type indexedEndpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+	Index    int    `xml:"index,attr"`
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name `xml:"AuthnRequest"`
+	XMLNS                       string   `xml:"xmlns,attr"`
+	ID                          string   `xml:"ID,attr"`
+	Version                     string   `xml:"Version,attr"`
+	IssueInstant                string   `xml:"IssueInstant,attr"`
+	Destination                 string   `xml:"Destination,attr"`
+	AssertionConsumerServiceURL string   `xml:"AssertionConsumerServiceURL,attr"`
+	ProtocolBinding             string   `xml:"ProtocolBinding,attr"`
+	Issuer                      issuer   `xml:"Issuer"`
+}
+
+type issuer struct {
+	XMLNS string `xml:"xmlns,attr"`
+	Value string `xml:",chardata"`
+}
@@ -0,0 +1,451 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var (
+	ErrNoSession       = errors.New("saml: no session")
+	ErrMissingResponse = errors.New("saml: missing SAMLResponse")
+	// ErrUnsolicited is returned for responses not answering an AuthnRequest
+	// issued by the service provider.
+	ErrUnsolicited = errors.New("saml: unsolicited response")
+	ErrReplayed    = errors.New("saml: assertion already used")
+	// ErrTooManyRequests is returned by Login when MaxPendingRequests
+	// AuthnRequests were issued within one RequestTTL.
+	ErrTooManyRequests = errors.New("saml: too many pending requests")
+)
+
+type principalCtxKey struct{}
+
+// Principal is the subject of a validated response. Validators must fill
+// InResponseTo and AssertionID from the signed response so it cannot be
+// replayed.
+type Principal struct {
+	NameID       string
+	SessionIndex string
+	Attributes   map[string][]string
+	ExpiresAt    time.Time
+	InResponseTo string
+	AssertionID  string
+}
+
+func (p *Principal) Attribute(name string) string {
+	if values := p.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+type Validator interface {
+	Validate(ctx context.Context, samlResponse []byte) (*Principal, error)
+}
+
+type ValidatorFunc func(ctx context.Context, samlResponse []byte) (*Principal, error)
+
+func (f ValidatorFunc) Validate(ctx context.Context, samlResponse []byte) (*Principal, error) {
+	return f(ctx, samlResponse)
+}
+
+type Store interface {
+	Get(ctx context.Context, id string) (*Principal, error)
+	Set(ctx context.Context, id string, p *Principal) error
+	Delete(ctx context.Context, id string) error
+}
+
+type Config struct {
+	EntityID    string
+	ACSURL      string
+	IdPSSOURL   string
+	Certificate *x509.Certificate
+	Validator   Validator
+	Store       Store
+	// Requests tracks issued AuthnRequest IDs and Assertions used assertion
+	// IDs. They default to separate in-memory stores that only drop expired
+	// entries, so pending logins and used assertions are never evicted early.
+	// Use shared stores without eviction when running several instances.
+	Requests   chu.Store
+	Assertions chu.Store
+	// RequestTTL is how long an issued AuthnRequest can be answered. Defaults
+	// to 10 minutes.
+	RequestTTL time.Duration
+	// MaxPendingRequests caps the AuthnRequests issued within one RequestTTL;
+	// beyond it Login answers 503 so a flood cannot grow Requests without
+	// bound. Defaults to 10,000.
+	MaxPendingRequests int
+	// AllowIdPInitiated accepts responses without InResponseTo. Their
+	// assertions are still single use.
+	AllowIdPInitiated bool
+	CookieName        string
+	AfterLogin        string
+	Now               func() time.Time
+
+	// InsecureCookies drops the Secure attribute from the session cookie, for
+	// development over plain HTTP. Cookies are Secure by default.
+	InsecureCookies bool
+}
+
+type ServiceProvider struct {
+	cfg Config
+}
+
+// New panics when the config has no Validator, entity ID or ACS URL.
+func New(cfg Config) *ServiceProvider {
+	if cfg.Validator == nil || cfg.EntityID == "" || cfg.ACSURL == "" {
+		panic("saml: config requires a Validator, EntityID and ACSURL")
+	}
+
+	if cfg.Requests == nil {
+		cfg.Requests = chu.NewMemoryStore(0)
+	}
+
+	if cfg.Assertions == nil {
+		cfg.Assertions = chu.NewMemoryStore(0)
+	}
+
+	if cfg.RequestTTL <= 0 {
+		cfg.RequestTTL = 10 * time.Minute
+	}
+
+	if cfg.MaxPendingRequests <= 0 {
+		cfg.MaxPendingRequests = 10_000
+	}
+
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+
+	if cfg.CookieName == "" {
+		cfg.CookieName = "saml_session"
+	}
+
+	if cfg.AfterLogin == "" {
+		cfg.AfterLogin = "/"
+	}
+
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return &ServiceProvider{cfg: cfg}
+}
+
+func (sp *ServiceProvider) Routes(r *chu.Router) {
+	r.Get("/metadata", sp.Metadata)
+	r.Get("/login", sp.Login)
+	r.Post("/acs", sp.ACS)
+	r.Post("/logout", sp.Logout)
+}
+
+func (sp *ServiceProvider) Metadata(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	md := entityDescriptor{
+		XMLNS:    "urn:oasis:names:tc:SAML:2.0:metadata",
+		EntityID: sp.cfg.EntityID,
+		SPSSODescriptor: spSSODescriptor{
+			ProtocolSupportEnumeration: "urn:oasis:names:tc:SAML:2.0:protocol",
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			NameIDFormat:               "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified",
+			AssertionConsumerService: []indexedEndpoint{{
+				Binding:  "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST",
+				Location: sp.cfg.ACSURL,
+				Index:    1,
+			}},
+		},
+	}
+
+	if sp.cfg.Certificate != nil {
+		md.SPSSODescriptor.KeyDescriptor = []keyDescriptor{{
+			Use:         "signing",
+			Certificate: base64.StdEncoding.EncodeToString(sp.cfg.Certificate.Raw),
+		}}
+	}
+
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+
+	return xml.NewEncoder(w).Encode(md)
+}
+
+func (sp *ServiceProvider) Login(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if sp.cfg.IdPSSOURL == "" {
+		return chu.Errorf(http.StatusNotImplemented, "saml: IdP SSO URL not configured")
+	}
+
+	req := authnRequest{
+		XMLNS:                       "urn:oasis:names:tc:SAML:2.0:protocol",
+		ID:                          "id-" + randomHex(16),
+		Version:                     "2.0",
+		IssueInstant:                sp.cfg.Now().UTC().Format(time.RFC3339),
+		Destination:                 sp.cfg.IdPSSOURL,
+		AssertionConsumerServiceURL: sp.cfg.ACSURL,
+		ProtocolBinding:             "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST",
+		Issuer:                      issuer{XMLNS: "urn:oasis:names:tc:SAML:2.0:assertion", Value: sp.cfg.EntityID},
+	}
+
+	raw, err := xml.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	if err := sp.admitRequest(ctx); err != nil {
+		return err
+	}
+
+	if err := sp.cfg.Requests.Set(ctx, requestKey(req.ID), []byte("1"), sp.cfg.RequestTTL); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fw.Write(raw); err != nil {
+		return err
+	}
+
+	if err := fw.Close(); err != nil {
+		return err
+	}
+
+	q := url.Values{"SAMLRequest": {base64.StdEncoding.EncodeToString(buf.Bytes())}}
+	if relay := safeRelayState(r.URL.Query().Get("return_to")); relay != "" {
+		q.Set("RelayState", relay)
+	}
+
+	sep := "?"
+	if strings.Contains(sp.cfg.IdPSSOURL, "?") {
+		sep = "&"
+	}
+
+	http.Redirect(w, r, sp.cfg.IdPSSOURL+sep+q.Encode(), http.StatusFound)
+	return nil
+}
+
+func (sp *ServiceProvider) ACS(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return chu.NewError(http.StatusBadRequest, err)
+	}
+
+	encoded := r.PostForm.Get("SAMLResponse")
+	if encoded == "" {
+		return chu.NewError(http.StatusBadRequest, ErrMissingResponse)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return chu.Errorf(http.StatusBadRequest, "saml: invalid SAMLResponse encoding: %w", err)
+	}
+
+	principal, err := sp.cfg.Validator.Validate(ctx, raw)
+	if err != nil {
+		return chu.NewError(http.StatusUnauthorized, err)
+	}
+
+	if err := sp.checkReplay(ctx, principal); err != nil {
+		return err
+	}
+
+	id := randomHex(24)
+	if err := sp.cfg.Store.Set(ctx, id, principal); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sp.cfg.CookieName,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   !sp.cfg.InsecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	target := sp.cfg.AfterLogin
+	if relay := safeRelayState(r.PostForm.Get("RelayState")); relay != "" {
+		target = relay
+	}
+
+	http.Redirect(w, r, target, http.StatusSeeOther)
+	return nil
+}
+
+func (sp *ServiceProvider) Logout(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if c, err := r.Cookie(sp.cfg.CookieName); err == nil {
+		if err := sp.cfg.Store.Delete(ctx, c.Value); err != nil {
+			return err
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: sp.cfg.CookieName, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, sp.cfg.AfterLogin, http.StatusSeeOther)
+
+	return nil
+}
+
+func (sp *ServiceProvider) RequireAuth(next chu.Handler) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		c, err := r.Cookie(sp.cfg.CookieName)
+		if err != nil {
+			return chu.NewError(http.StatusUnauthorized, ErrNoSession)
+		}
+
+		principal, err := sp.cfg.Store.Get(ctx, c.Value)
+		if errors.Is(err, ErrNoSession) {
+			return chu.NewError(http.StatusUnauthorized, err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !principal.ExpiresAt.IsZero() && sp.cfg.Now().After(principal.ExpiresAt) {
+			_ = sp.cfg.Store.Delete(ctx, c.Value)
+			return chu.NewError(http.StatusUnauthorized, ErrNoSession)
+		}
+
+		ctx = context.WithValue(ctx, principalCtxKey{}, principal)
+
+		return next(ctx, w, r.WithContext(ctx))
+	}
+}
+
+// checkReplay consumes the request the response answers and marks its
+// assertion as used.
+func (sp *ServiceProvider) checkReplay(ctx context.Context, p *Principal) error {
+	if p.InResponseTo != "" {
+		// The issued request holds 1, so only the first answer sees 2.
+		n, err := sp.cfg.Requests.Increment(ctx, requestKey(p.InResponseTo), 1, sp.cfg.RequestTTL)
+		if err != nil {
+			return err
+		}
+
+		if n != 2 {
+			return chu.NewError(http.StatusUnauthorized, ErrUnsolicited)
+		}
+	} else if !sp.cfg.AllowIdPInitiated {
+		return chu.NewError(http.StatusUnauthorized, ErrUnsolicited)
+	}
+
+	if p.AssertionID == "" {
+		return chu.Errorf(http.StatusUnauthorized, "saml: assertion without ID")
+	}
+
+	// Assertions are remembered until the session they grant ends.
+	ttl := time.Hour
+	if !p.ExpiresAt.IsZero() {
+		ttl = max(p.ExpiresAt.Sub(sp.cfg.Now()), time.Minute)
+	}
+
+	n, err := sp.cfg.Assertions.Increment(ctx, "saml:assertion:"+p.AssertionID, 1, ttl)
+	if err != nil {
+		return err
+	}
+
+	if n != 1 {
+		return chu.NewError(http.StatusUnauthorized, ErrReplayed)
+	}
+
+	return nil
+}
+
+// admitRequest counts AuthnRequests per RequestTTL window. Requests live for
+// one window, so at most twice MaxPendingRequests are pending at once.
+func (sp *ServiceProvider) admitRequest(ctx context.Context) error {
+	window := sp.cfg.Now().UnixNano() / int64(sp.cfg.RequestTTL)
+	key := "saml:requests:" + strconv.FormatInt(window, 10)
+
+	n, err := sp.cfg.Requests.Increment(ctx, key, 1, 2*sp.cfg.RequestTTL)
+	if err != nil {
+		return err
+	}
+
+	if n > int64(sp.cfg.MaxPendingRequests) {
+		return chu.NewError(http.StatusServiceUnavailable, ErrTooManyRequests)
+	}
+
+	return nil
+}
+
+func requestKey(id string) string {
+	return "saml:request:" + id
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(*Principal)
+	return p, ok
+}
+
+type MemoryStore struct {
+	mu         sync.RWMutex
+	principals map[string]Principal
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{principals: make(map[string]Principal)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Principal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.principals[id]
+	if !ok {
+		return nil, ErrNoSession
+	}
+
+	return &p, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, id string, p *Principal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.principals[id] = *p
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.principals, id)
+	return nil
+}
+
+func safeRelayState(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return ""
+	}
+
+	return target
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,257 @@
+package saml_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var requestIDPattern = regexp.MustCompile(`ID="([^"]+)"`)
+
+// samlResponse stands in for a signed response; the test validator reads
+// InResponseTo and the assertion ID from it.
+func samlResponse(inResponseTo, assertionID string) string {
+	return base64.StdEncoding.EncodeToString([]byte("ok|" + inResponseTo + "|" + assertionID))
+}
+
+func newApp(configure ...func(*saml.Config)) *chu.Router {
+	cfg := saml.Config{
+		EntityID:  "https://app.test/saml",
+		ACSURL:    "https://app.test/saml/acs",
+		IdPSSOURL: "https://idp.test/sso",
+		Validator: saml.ValidatorFunc(func(ctx context.Context, resp []byte) (*saml.Principal, error) {
+			parts := strings.Split(string(resp), "|")
+			if len(parts) != 3 || parts[0] != "ok" {
+				return nil, errors.New("invalid assertion")
+			}
+
+			return &saml.Principal{
+				NameID:       "jose@example.com",
+				Attributes:   map[string][]string{"role": {"admin"}},
+				InResponseTo: parts[1],
+				AssertionID:  parts[2],
+			}, nil
+		}),
+	}
+
+	for _, fn := range configure {
+		fn(&cfg)
+	}
+
+	sp := saml.New(cfg)
+
+	r := chu.New()
+	r.Route("/saml", sp.Routes)
+	r.Group(func(r *chu.Router) {
+		r.Use(sp.RequireAuth)
+		r.Get("/me", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			p, _ := saml.FromContext(ctx)
+			_, err := w.Write([]byte(p.NameID + ":" + p.Attribute("role")))
+			return err
+		})
+	})
+
+	return r
+}
+
+func postACS(r http.Handler, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+// login starts a login and returns the ID of the issued AuthnRequest.
+func login(t *testing.T, r http.Handler) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/saml/login", nil))
+	require.Equal(t, http.StatusFound, w.Code, "login should redirect to IdP")
+
+	loc, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err, "redirect should be a valid URL")
+
+	raw, err := base64.StdEncoding.DecodeString(loc.Query().Get("SAMLRequest"))
+	require.NoError(t, err, "SAMLRequest should be base64")
+
+	xml, err := io.ReadAll(flate.NewReader(bytes.NewReader(raw)))
+	require.NoError(t, err, "SAMLRequest should be deflated")
+
+	match := requestIDPattern.FindSubmatch(xml)
+	require.NotNil(t, match, "AuthnRequest should have an ID")
+
+	return string(match[1])
+}
+
+func TestServiceProvider_Metadata(t *testing.T) {
+	w := httptest.NewRecorder()
+	newApp().ServeHTTP(w, httptest.NewRequest("GET", "/saml/metadata", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "application/samlmetadata+xml", w.Header().Get("Content-Type"), "content type should match expected")
+	assert.Contains(t, w.Body.String(), `entityID="https://app.test/saml"`, "metadata should contain entity id")
+	assert.Contains(t, w.Body.String(), `Location="https://app.test/saml/acs"`, "metadata should contain ACS URL")
+}
+
+func TestServiceProvider_Login(t *testing.T) {
+	w := httptest.NewRecorder()
+	newApp().ServeHTTP(w, httptest.NewRequest("GET", "/saml/login?return_to=/me", nil))
+
+	require.Equal(t, http.StatusFound, w.Code, "login should redirect to IdP")
+
+	loc, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err, "redirect should be a valid URL")
+	assert.Equal(t, "idp.test", loc.Host, "redirect should target IdP")
+	assert.Equal(t, "/me", loc.Query().Get("RelayState"), "relay state should carry return path")
+
+	raw, err := base64.StdEncoding.DecodeString(loc.Query().Get("SAMLRequest"))
+	require.NoError(t, err, "SAMLRequest should be base64")
+
+	xml, err := io.ReadAll(flate.NewReader(bytes.NewReader(raw)))
+	require.NoError(t, err, "SAMLRequest should be deflated")
+	assert.Contains(t, string(xml), "<Issuer", "AuthnRequest should include issuer")
+	assert.Contains(t, string(xml), "https://app.test/saml</Issuer>", "issuer should be entity id")
+}
+
+func TestServiceProvider_ACS(t *testing.T) {
+	r := newApp()
+
+	w := postACS(r, url.Values{
+		"SAMLResponse": {samlResponse(login(t, r), "a1")},
+		"RelayState":   {"/me"},
+	})
+	require.Equal(t, http.StatusSeeOther, w.Code, "ACS should redirect after login")
+	assert.Equal(t, "/me", w.Header().Get("Location"), "ACS should honour relay state")
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.AddCookie(w.Result().Cookies()[0])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "jose@example.com:admin", w.Body.String(), "principal should be in context")
+}
+
+func TestServiceProvider_Errors(t *testing.T) {
+	r := newApp()
+
+	tests := []struct {
+		name     string
+		form     url.Values
+		expected int
+	}{
+		{name: "missing response", form: url.Values{}, expected: http.StatusBadRequest},
+		{name: "bad encoding", form: url.Values{"SAMLResponse": {"%%%"}}, expected: http.StatusBadRequest},
+		{name: "invalid assertion", form: url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString([]byte("nope"))}}, expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, postACS(r, tt.form).Code, "status code should match expected")
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "unauthenticated request should be rejected")
+}
+
+func TestServiceProvider_Replay(t *testing.T) {
+	r := newApp()
+
+	requestID := login(t, r)
+	w := postACS(r, url.Values{"SAMLResponse": {samlResponse(requestID, "a1")}})
+	require.Equal(t, http.StatusSeeOther, w.Code, "first response should be accepted")
+
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{name: "replayed response", response: samlResponse(requestID, "a1")},
+		{name: "answered request", response: samlResponse(requestID, "a2")},
+		{name: "unknown request", response: samlResponse("id-unknown", "a3")},
+		{name: "unsolicited", response: samlResponse("", "a4")},
+		{name: "replayed assertion", response: samlResponse(login(t, r), "a1")},
+		{name: "missing assertion id", response: samlResponse(login(t, r), "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, http.StatusUnauthorized, postACS(r, url.Values{"SAMLResponse": {tt.response}}).Code, "status code should match expected")
+		})
+	}
+}
+
+func TestServiceProvider_IdPInitiated(t *testing.T) {
+	r := newApp(func(cfg *saml.Config) { cfg.AllowIdPInitiated = true })
+
+	form := url.Values{"SAMLResponse": {samlResponse("", "a1")}}
+	assert.Equal(t, http.StatusSeeOther, postACS(r, form).Code, "IdP-initiated response should be accepted")
+	assert.Equal(t, http.StatusUnauthorized, postACS(r, form).Code, "IdP-initiated assertion should be single use")
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() {
+		saml.New(saml.Config{EntityID: "https://app.test/saml", ACSURL: "https://app.test/saml/acs"})
+	}, "config without a validator should be rejected")
+}
+
+func TestServiceProvider_PendingRequests(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	r := newApp(func(cfg *saml.Config) {
+		cfg.MaxPendingRequests = 2
+		cfg.Now = func() time.Time { return now }
+	})
+
+	requestID := login(t, r)
+	login(t, r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/saml/login", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "logins beyond the cap should be refused")
+
+	w = postACS(r, url.Values{"SAMLResponse": {samlResponse(requestID, "a1")}})
+	assert.Equal(t, http.StatusSeeOther, w.Code, "pending requests should survive a login flood")
+}
+
+func TestServiceProvider_SecureCookie(t *testing.T) {
+	tests := []struct {
+		name     string
+		insecure bool
+		expected bool
+	}{
+		{name: "secure by default", expected: true},
+		{name: "insecure opt-out", insecure: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newApp(func(cfg *saml.Config) { cfg.InsecureCookies = tt.insecure })
+
+			w := postACS(r, url.Values{"SAMLResponse": {samlResponse(login(t, r), "a1")}})
+			require.Equal(t, http.StatusSeeOther, w.Code, "response should be accepted")
+			require.Len(t, w.Result().Cookies(), 1, "session cookie should be set")
+			assert.Equal(t, tt.expected, w.Result().Cookies()[0].Secure, "secure attribute should match expected")
+		})
+	}
+}
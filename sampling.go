@@ -0,0 +1,78 @@
+package chu
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"net/http"
+)
+
+var samplingCtxKey = &contextKey{"sampling"}
+
+// Sampler decides whether the verbose telemetry of a request, such as wide
+// events, audit findings and trace flags, is recorded.
+type Sampler interface {
+	Sample(r *http.Request) bool
+}
+
+type SamplerFunc func(r *http.Request) bool
+
+func (f SamplerFunc) Sample(r *http.Request) bool {
+	return f(r)
+}
+
+// WithSampling makes the router take one sampling decision per request, which
+// the logging, tracing and audit facilities consult through Sampled, so a
+// request is either fully recorded or left out rather than sampled
+// independently by each of them.
+func WithSampling(s Sampler) Option {
+	return func(r *Router) {
+		r.sampler = s
+	}
+}
+
+// Sampled reports whether the telemetry of the request is recorded. Requests
+// are sampled unless the router uses WithSampling.
+func Sampled(ctx context.Context) bool {
+	sampled, ok := ctx.Value(samplingCtxKey).(bool)
+	return sampled || !ok
+}
+
+func withSampling(req *http.Request, s Sampler) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), samplingCtxKey, s.Sample(req)))
+}
+
+// SampleRatio samples a fraction of the requests. Requests carrying a trace
+// context are sampled by trace ID, so services sharing the ratio agree on the
+// traces they record.
+func SampleRatio(ratio float64) Sampler {
+	threshold := uint64(math.Min(math.Max(ratio, 0), 1) * math.MaxUint64)
+
+	return SamplerFunc(func(r *http.Request) bool {
+		if ratio >= 1 {
+			return true
+		}
+
+		if trace, ok := parseCloudTrace(r.Header); ok {
+			h := fnv.New64a()
+			h.Write([]byte(trace.TraceID))
+			return h.Sum64() < threshold
+		}
+
+		return rand.Uint64() < threshold
+	})
+}
+
+// ParentBased follows the sampled flag of an incoming trace context, from the
+// traceparent or X-Cloud-Trace-Context header, and uses root for requests
+// that start a trace.
+func ParentBased(root Sampler) Sampler {
+	return SamplerFunc(func(r *http.Request) bool {
+		if trace, ok := parseCloudTrace(r.Header); ok {
+			return trace.Sampled
+		}
+
+		return root.Sample(r)
+	})
+}
@@ -0,0 +1,106 @@
+package chu_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplers(t *testing.T) {
+	traced := func(flags string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-"+flags)
+		return req
+	}
+
+	tests := []struct {
+		name     string
+		sampler  chu.Sampler
+		req      *http.Request
+		expected bool
+	}{
+		{name: "ratio one", sampler: chu.SampleRatio(1), req: httptest.NewRequest(http.MethodGet, "/", nil), expected: true},
+		{name: "ratio zero", sampler: chu.SampleRatio(0), req: httptest.NewRequest(http.MethodGet, "/", nil), expected: false},
+		{name: "ratio zero with trace", sampler: chu.SampleRatio(0), req: traced("01"), expected: false},
+		{name: "parent sampled", sampler: chu.ParentBased(chu.SampleRatio(0)), req: traced("01"), expected: true},
+		{name: "parent not sampled", sampler: chu.ParentBased(chu.SampleRatio(1)), req: traced("00"), expected: false},
+		{name: "root without parent", sampler: chu.ParentBased(chu.SampleRatio(1)), req: httptest.NewRequest(http.MethodGet, "/", nil), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.sampler.Sample(tt.req), "sampling decision should match expected")
+		})
+	}
+}
+
+func TestSampleRatioByTrace(t *testing.T) {
+	sampler := chu.SampleRatio(0.5)
+
+	var sampled int
+	for i := range 200 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("traceparent", "00-"+strings.Repeat("0", 28)+string(rune('a'+i%26))+strings.Repeat("f", 3)+"-00f067aa0ba902b7-00")
+
+		first := sampler.Sample(req)
+		assert.Equal(t, first, sampler.Sample(req), "a trace should always get the same decision")
+
+		if first {
+			sampled++
+		}
+	}
+
+	assert.Greater(t, sampled, 0, "some traces should be sampled")
+	assert.Less(t, sampled, 200, "some traces should be left out")
+}
+
+func TestWithSampling(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := chu.New(
+		chu.WithSampling(chu.SamplerFunc(func(r *http.Request) bool { return r.URL.Query().Has("sampled") })),
+		chu.WithWideEvents(slog.New(slog.NewJSONHandler(&buf, nil))),
+	)
+
+	var decision bool
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		decision = chu.Sampled(ctx)
+		if r.URL.Query().Has("fail") {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+
+	tests := []struct {
+		name    string
+		target  string
+		sampled bool
+		logged  bool
+	}{
+		{name: "sampled request", target: "/?sampled", sampled: true, logged: true},
+		{name: "request left out", target: "/", sampled: false, logged: false},
+		{name: "failed request left out", target: "/?fail", sampled: false, logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.sampled, decision, "sampling decision should match expected")
+			assert.Equal(t, tt.logged, buf.Len() > 0, "wide event should be logged when expected")
+		})
+	}
+
+	assert.True(t, chu.Sampled(context.Background()), "requests should be sampled without a sampler")
+}
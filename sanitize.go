@@ -0,0 +1,43 @@
+package chu
+
+import "errors"
+
+// WithErrorSanitizer applies fn to error messages before the router's error
+// handler sees them, so configured patterns never reach clients or logs.
+func WithErrorSanitizer(fn func(string) string) Option {
+	return func(r *Router) {
+		r.sanitize = fn
+	}
+}
+
+// SanitizeError wraps err so that Error returns fn applied to the original
+// message. errors.Is, errors.As and StatusCode still see the wrapped error.
+func SanitizeError(err error, fn func(string) string) error {
+	if err == nil || fn == nil {
+		return err
+	}
+
+	return &sanitizedError{err: err, fn: fn}
+}
+
+type sanitizedError struct {
+	err error
+	fn  func(string) string
+}
+
+func (e *sanitizedError) Error() string {
+	return e.fn(e.err.Error())
+}
+
+func (e *sanitizedError) Unwrap() error {
+	return e.err
+}
+
+func sanitizerOf(err error) func(string) string {
+	var s *sanitizedError
+	if errors.As(err, &s) {
+		return s.fn
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package chu
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+type ServerOption func(*serverConfig)
+
+type ExtraServer interface {
+	ListenAndServe() error
+	Close() error
+}
+
+type serverConfig struct {
+	shutdownTimeout time.Duration
+	configure       []func(*http.Server)
+	h2c             bool
+	extra           []func(h http.Handler) ExtraServer
+	tlsConfig       *tls.Config
+	redirectAddr    string
+	autocertCache   autocert.Cache
+	autocertEmail   string
+	lifecycle       *lifecycle
+}
+
+func WithShutdownTimeout(d time.Duration) ServerOption {
+	return func(c *serverConfig) {
+		c.shutdownTimeout = d
+	}
+}
+
+func WithServer(fn func(*http.Server)) ServerOption {
+	return func(c *serverConfig) {
+		c.configure = append(c.configure, fn)
+	}
+}
+
+func WithH2C() ServerOption {
+	return func(c *serverConfig) {
+		c.h2c = true
+	}
+}
+
+func WithExtraServer(fn func(h http.Handler) ExtraServer) ServerOption {
+	return func(c *serverConfig) {
+		c.extra = append(c.extra, fn)
+	}
+}
+
+func WithTLSConfig(cfg *tls.Config) ServerOption {
+	return func(c *serverConfig) {
+		c.tlsConfig = cfg
+	}
+}
+
+func WithHTTPSRedirect(addr string) ServerOption {
+	return func(c *serverConfig) {
+		c.redirectAddr = addr
+	}
+}
+
+func WithAutocertCache(cache autocert.Cache) ServerOption {
+	return func(c *serverConfig) {
+		c.autocertCache = cache
+	}
+}
+
+func WithAutocertEmail(email string) ServerOption {
+	return func(c *serverConfig) {
+		c.autocertEmail = email
+	}
+}
+
+func newServerConfig(opts []ServerOption) *serverConfig {
+	c := &serverConfig{shutdownTimeout: 30 * time.Second}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *serverConfig) newServer(addr string, h http.Handler) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+
+	if c.h2c {
+		srv.Handler = h2c.NewHandler(h, &http2.Server{})
+	}
+
+	for _, fn := range c.configure {
+		fn(srv)
+	}
+
+	return srv
+}
+
+func DefaultTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+}
+
+func Serve(ctx context.Context, addr string, h http.Handler, opts ...ServerOption) error {
+	cfg := newServerConfig(opts)
+	srv := cfg.newServer(addr, h)
+
+	ln, err := net.Listen("tcp", listenAddr(addr))
+	if err != nil {
+		return err
+	}
+
+	return cfg.run(ctx, h, serveUnit{srv: srv, serve: func() error { return srv.Serve(ln) }})
+}
+
+func ServeTLS(ctx context.Context, addr string, h http.Handler, certFile, keyFile string, opts ...ServerOption) error {
+	cfg := newServerConfig(opts)
+
+	srv := cfg.newServer(addr, h)
+	srv.TLSConfig = cfg.tls()
+
+	ln, err := net.Listen("tcp", listenAddr(addr))
+	if err != nil {
+		return err
+	}
+
+	units := []serveUnit{{srv: srv, serve: func() error { return srv.ServeTLS(ln, certFile, keyFile) }}}
+	if cfg.redirectAddr != "" {
+		units = append(units, listenUnit(cfg.newServer(cfg.redirectAddr, http.HandlerFunc(redirectHTTPS))))
+	}
+
+	return cfg.run(ctx, h, units...)
+}
+
+// ServeAutocert serves h over TLS on addr, ":https" when empty, with
+// certificates for domains obtained from Let's Encrypt. ACME challenges and
+// HTTPS redirects are answered on the WithHTTPSRedirect address, ":80" by
+// default. The CA reaches both on the standard ports, so a different addr
+// only suits hosts forwarding those ports.
+func ServeAutocert(ctx context.Context, addr string, h http.Handler, domains []string, opts ...ServerOption) error {
+	cfg := newServerConfig(opts)
+	if cfg.redirectAddr == "" {
+		cfg.redirectAddr = ":80"
+	}
+
+	if cfg.autocertCache == nil {
+		cfg.autocertCache = autocert.DirCache("autocert-cache")
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      cfg.autocertCache,
+		Email:      cfg.autocertEmail,
+	}
+
+	tlsConfig := cfg.tls()
+	tlsConfig.GetCertificate = manager.GetCertificate
+	tlsConfig.NextProtos = append(tlsConfig.NextProtos, "h2", "http/1.1", "acme-tls/1")
+
+	if addr == "" {
+		addr = ":https"
+	}
+
+	srv := cfg.newServer(addr, h)
+	srv.TLSConfig = tlsConfig
+
+	redirect := cfg.newServer(cfg.redirectAddr, manager.HTTPHandler(http.HandlerFunc(redirectHTTPS)))
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return cfg.run(ctx, h,
+		serveUnit{srv: srv, serve: func() error { return srv.ServeTLS(ln, "", "") }},
+		listenUnit(redirect),
+	)
+}
+
+func (c *serverConfig) tls() *tls.Config {
+	if c.tlsConfig != nil {
+		return c.tlsConfig.Clone()
+	}
+
+	return DefaultTLSConfig()
+}
+
+type serveUnit struct {
+	srv   *http.Server
+	serve func() error
+}
+
+func listenUnit(srv *http.Server) serveUnit {
+	return serveUnit{srv: srv, serve: srv.ListenAndServe}
+}
+
+func withLifecycle(l *lifecycle) ServerOption {
+	return func(c *serverConfig) {
+		c.lifecycle = l
+	}
+}
+
+func (c *serverConfig) run(ctx context.Context, h http.Handler, units ...serveUnit) error {
+	lc := c.lifecycle
+	if r, ok := h.(*Router); ok && lc == nil {
+		lc = r.lifecycle
+	}
+
+	stop, err := lc.start(ctx)
+	if err != nil {
+		for _, u := range units {
+			_ = u.srv.Close()
+			_ = u.serve()
+		}
+
+		return err
+	}
+
+	extra := make([]ExtraServer, len(c.extra))
+	for i, fn := range c.extra {
+		extra[i] = fn(h)
+	}
+
+	errCh := make(chan error, len(units)+len(extra))
+
+	for _, u := range units {
+		go func(u serveUnit) {
+			errCh <- u.serve()
+		}(u)
+	}
+
+	for _, s := range extra {
+		go func(s ExtraServer) {
+			errCh <- s.ListenAndServe()
+		}(s)
+	}
+
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+
+	for _, u := range units {
+		if err := u.srv.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, s := range extra {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := stop(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func listenAddr(addr string) string {
+	if addr == "" {
+		return ":http"
+	}
+
+	return addr
+}
+
+func redirectHTTPS(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+
+	target := "https://" + host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusPermanentRedirect)
+}
@@ -0,0 +1,44 @@
+//go:build http3
+
+package chu
+
+import (
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+func WithHTTP3(addr, certFile, keyFile string) ServerOption {
+	return WithExtraServer(func(h http.Handler) ExtraServer {
+		srv := &http3.Server{Addr: addr, Handler: h}
+
+		return extraServerFunc{
+			serve: func() error { return srv.ListenAndServeTLS(certFile, keyFile) },
+			close: srv.Close,
+		}
+	})
+}
+
+func AdvertiseHTTP3(port int) func(Handler) Handler {
+	srv := &http3.Server{Port: port}
+
+	return AdaptMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = srv.SetQUICHeaders(w.Header())
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+type extraServerFunc struct {
+	serve func() error
+	close func() error
+}
+
+func (l extraServerFunc) ListenAndServe() error {
+	return l.serve()
+}
+
+func (l extraServerFunc) Close() error {
+	return l.close()
+}
@@ -0,0 +1,239 @@
+package chu_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+)
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "should be able to reserve a port")
+
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close(), "should be able to release the port")
+
+	return addr
+}
+
+func waitForServer(t *testing.T, addr string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+
+		_ = conn.Close()
+		return true
+	}, 2*time.Second, 10*time.Millisecond, "server should start listening")
+}
+
+func writeSelfSignedCert(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "should generate key")
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err, "should create certificate")
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err, "should marshal key")
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func helloRouter() *chu.Router {
+	r := chu.New()
+	r.Get("/hello", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("hello"))
+		return nil
+	})
+
+	return r
+}
+
+func TestServe(t *testing.T) {
+	addr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- chu.Serve(ctx, addr, helloRouter()) }()
+	waitForServer(t, addr)
+
+	resp, err := http.Get("http://" + addr + "/hello")
+	require.NoError(t, err, "request should succeed")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "should be able to read response body")
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "hello", string(body), "response body should match expected")
+
+	cancel()
+	assert.NoError(t, <-errCh, "server should shut down cleanly")
+}
+
+func TestServeTLS(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	addr, redirectAddr := freeAddr(t), freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- chu.ServeTLS(ctx, addr, helloRouter(), certFile, keyFile, chu.WithHTTPSRedirect(redirectAddr))
+	}()
+	waitForServer(t, addr)
+	waitForServer(t, redirectAddr)
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get("https://" + addr + "/hello")
+	require.NoError(t, err, "TLS request should succeed")
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "status code should be OK")
+	assert.GreaterOrEqual(t, resp.TLS.Version, uint16(tls.VersionTLS12), "TLS version should be modern")
+
+	resp, err = client.Get("http://" + redirectAddr + "/hello?x=1")
+	require.NoError(t, err, "plain request should succeed")
+	_ = resp.Body.Close()
+
+	host, _, _ := net.SplitHostPort(redirectAddr)
+	assert.Equal(t, http.StatusPermanentRedirect, resp.StatusCode, "plain request should be redirected")
+	assert.Equal(t, "https://"+host+"/hello?x=1", resp.Header.Get("Location"), "redirect should target HTTPS")
+
+	cancel()
+	assert.NoError(t, <-errCh, "server should shut down cleanly")
+}
+
+func TestServeAutocert(t *testing.T) {
+	addr, redirectAddr := freeAddr(t), freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- chu.ServeAutocert(ctx, addr, helloRouter(), []string{"example.com"},
+			chu.WithHTTPSRedirect(redirectAddr), chu.WithAutocertCache(autocert.DirCache(t.TempDir())))
+	}()
+	waitForServer(t, addr)
+	waitForServer(t, redirectAddr)
+
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Get("http://" + redirectAddr + "/hello")
+	require.NoError(t, err, "plain request should succeed")
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusPermanentRedirect, resp.StatusCode, "plain request should be redirected")
+
+	cancel()
+	assert.NoError(t, <-errCh, "server should shut down cleanly")
+}
+
+type stubServer struct {
+	started chan struct{}
+	closed  chan struct{}
+}
+
+func (l *stubServer) ListenAndServe() error {
+	close(l.started)
+	<-l.closed
+	return nil
+}
+
+func (l *stubServer) Close() error {
+	close(l.closed)
+	return nil
+}
+
+func TestServe_H2C(t *testing.T) {
+	addr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := chu.New()
+	r.Get("/proto", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte(r.Proto))
+		return nil
+	})
+
+	extra := &stubServer{started: make(chan struct{}), closed: make(chan struct{})}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- chu.Serve(ctx, addr, r, chu.WithH2C(), chu.WithExtraServer(func(h http.Handler) chu.ExtraServer {
+			return extra
+		}))
+	}()
+	waitForServer(t, addr)
+	<-extra.started
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+
+	resp, err := client.Get("http://" + addr + "/proto")
+	require.NoError(t, err, "h2c request should succeed")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "should be able to read response body")
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "HTTP/2.0", string(body), "request should be served over cleartext HTTP/2")
+
+	cancel()
+	assert.NoError(t, <-errCh, "server should shut down cleanly")
+
+	select {
+	case <-extra.closed:
+	default:
+		t.Fatal("extra server should be closed on shutdown")
+	}
+}
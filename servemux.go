@@ -0,0 +1,564 @@
+package chu
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// WithServeMuxBackend routes requests with net/http's pattern-matching
+// ServeMux instead of chi's tree. Patterns keep chi syntax: "{name}",
+// "{name:regexp}" and a trailing "*" are translated to ServeMux wildcards, and
+// parameters are available through both URLParam and r.PathValue. Patterns
+// ServeMux cannot express, such as partial-segment parameters, panic at
+// registration, as do patterns ServeMux considers conflicting.
+func WithServeMuxBackend() Option {
+	return WithRouterBuilder(func() chi.Router {
+		return newServeMux()
+	})
+}
+
+const (
+	muxWildcardKey = "chuwildcard"
+	muxMountKey    = "chumount"
+)
+
+var muxMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+var muxStateCtxKey = &contextKey{"servemux-state"}
+
+type muxState struct {
+	url              *url.URL
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+}
+
+type serveMux struct {
+	tree        *muxTree
+	inline      bool
+	middlewares chi.Middlewares
+}
+
+type muxTree struct {
+	mux   *http.ServeMux
+	paths *http.ServeMux
+	root  *serveMux
+
+	mu        sync.RWMutex
+	routes    []*muxRoute
+	byPattern map[string]*muxRoute
+	byMux     map[string]*muxRoute
+	handler   http.Handler
+
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+}
+
+type muxRoute struct {
+	pattern  string
+	path     string
+	params   []muxParam
+	segments int
+
+	mu       sync.RWMutex
+	handlers map[string]http.Handler
+	sub      http.Handler
+}
+
+type muxParam struct {
+	name string
+	key  string
+	re   *regexp.Regexp
+}
+
+func newServeMux() *serveMux {
+	m := &serveMux{}
+	m.tree = &muxTree{
+		mux:       http.NewServeMux(),
+		paths:     http.NewServeMux(),
+		root:      m,
+		byPattern: make(map[string]*muxRoute),
+		byMux:     make(map[string]*muxRoute),
+	}
+
+	return m
+}
+
+func (m *serveMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rctx := chi.RouteContext(r.Context()); rctx == nil {
+		rctx = chi.NewRouteContext()
+		rctx.Routes = m.tree.root
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	m.tree.chain().ServeHTTP(w, r)
+}
+
+func (t *muxTree) chain() http.Handler {
+	t.mu.RLock()
+	h := t.handler
+	t.mu.RUnlock()
+
+	if h != nil {
+		return h
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.handler == nil {
+		t.handler = chi.Chain(t.root.middlewares...).HandlerFunc(t.routeHTTP)
+	}
+
+	return t.handler
+}
+
+func (t *muxTree) routeHTTP(w http.ResponseWriter, r *http.Request) {
+	rctx := chi.RouteContext(r.Context())
+
+	path := rctx.RoutePath
+	if path == "" {
+		path = cmp.Or(r.URL.RawPath, r.URL.Path)
+	}
+
+	state := &muxState{url: r.URL, notFound: t.notFound, methodNotAllowed: t.methodNotAllowed}
+	if parent, ok := r.Context().Value(muxStateCtxKey).(*muxState); ok {
+		state.notFound = firstHandler(state.notFound, parent.notFound)
+		state.methodNotAllowed = firstHandler(state.methodNotAllowed, parent.methodNotAllowed)
+	}
+
+	routed := r.WithContext(context.WithValue(r.Context(), muxStateCtxKey, state))
+	routed.URL = &url.URL{Path: path, RawQuery: r.URL.RawQuery}
+
+	if _, pattern := t.mux.Handler(routed); pattern != "" {
+		t.mux.ServeHTTP(w, routed)
+		return
+	}
+
+	if _, pattern := t.paths.Handler(routed); pattern == "" {
+		firstHandler(state.notFound, http.NotFoundHandler()).ServeHTTP(w, r)
+		return
+	}
+
+	if state.methodNotAllowed != nil {
+		state.methodNotAllowed.ServeHTTP(w, r)
+		return
+	}
+
+	for _, method := range muxMethods {
+		probe := routed.Clone(routed.Context())
+		probe.Method = method
+
+		if _, pattern := t.mux.Handler(probe); pattern != "" {
+			w.Header().Add("Allow", method)
+		}
+	}
+
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+func firstHandler(handlers ...http.Handler) http.Handler {
+	for _, h := range handlers {
+		if h != nil {
+			return h
+		}
+	}
+
+	return nil
+}
+
+func (t *muxTree) route(pattern string) *muxRoute {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if rt, ok := t.byPattern[pattern]; ok {
+		return rt
+	}
+
+	path, params := translatePattern(pattern)
+	rt := &muxRoute{
+		pattern:  pattern,
+		path:     path,
+		params:   params,
+		segments: strings.Count(strings.TrimSuffix(pattern, "/"), "/"),
+		handlers: make(map[string]http.Handler),
+	}
+
+	t.byPattern[pattern] = rt
+	t.routes = append(t.routes, rt)
+	t.paths.Handle(path, http.NotFoundHandler())
+
+	return rt
+}
+
+func (t *muxTree) register(muxPattern string, rt *muxRoute, h http.Handler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.byMux[muxPattern]; ok {
+		return
+	}
+
+	t.byMux[muxPattern] = rt
+	t.mux.Handle(muxPattern, h)
+}
+
+func (m *serveMux) handle(method, pattern string, h http.Handler) {
+	if pattern == "" || pattern[0] != '/' {
+		panic("chu: routing pattern must begin with '/' in '" + pattern + "'")
+	}
+
+	if m.inline && len(m.middlewares) > 0 {
+		h = chi.Chain(m.middlewares...).Handler(h)
+	}
+
+	rt := m.tree.route(pattern)
+
+	key := method
+	if key == "" {
+		key = "*"
+	}
+
+	rt.mu.Lock()
+	rt.handlers[key] = h
+	rt.mu.Unlock()
+
+	muxPattern := rt.path
+	if method != "" {
+		muxPattern = method + " " + rt.path
+	}
+
+	m.tree.register(muxPattern, rt, rt.endpoint(key))
+}
+
+func (rt *muxRoute) endpoint(key string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rt.mu.RLock()
+		h := rt.handlers[key]
+		rt.mu.RUnlock()
+
+		r2, ok := rt.bind(r)
+		if !ok {
+			state := r.Context().Value(muxStateCtxKey).(*muxState)
+			firstHandler(state.notFound, http.NotFoundHandler()).ServeHTTP(w, r2)
+			return
+		}
+
+		h.ServeHTTP(w, r2)
+	})
+}
+
+func (rt *muxRoute) mountpoint() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2, ok := rt.bind(r)
+		if !ok {
+			state := r.Context().Value(muxStateCtxKey).(*muxState)
+			firstHandler(state.notFound, http.NotFoundHandler()).ServeHTTP(w, r2)
+			return
+		}
+
+		rctx := chi.RouteContext(r2.Context())
+		rctx.RoutePath = "/" + r.PathValue(muxMountKey)
+
+		rt.sub.ServeHTTP(w, r2)
+	})
+}
+
+func (rt *muxRoute) bind(r *http.Request) (*http.Request, bool) {
+	state := r.Context().Value(muxStateCtxKey).(*muxState)
+	rctx := chi.RouteContext(r.Context())
+
+	r2 := r.WithContext(r.Context())
+	r2.URL = state.url
+
+	for _, p := range rt.params {
+		value := r.PathValue(p.key)
+		if p.re != nil && !p.re.MatchString(value) {
+			return r2, false
+		}
+
+		rctx.URLParams.Add(p.name, value)
+	}
+
+	if rt.sub != nil {
+		rctx.RoutePatterns = append(rctx.RoutePatterns, strings.TrimSuffix(rt.pattern, "/")+"/*")
+	} else {
+		rctx.RoutePatterns = append(rctx.RoutePatterns, rt.pattern)
+	}
+
+	for i, key := range rctx.URLParams.Keys {
+		r2.SetPathValue(key, rctx.URLParams.Values[i])
+	}
+
+	return r2, true
+}
+
+func translatePattern(pattern string) (string, []muxParam) {
+	var params []muxParam
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "*" && i == len(segments)-1 {
+			segments[i] = "{" + muxWildcardKey + "...}"
+			params = append(params, muxParam{name: "*", key: muxWildcardKey})
+
+			continue
+		}
+
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+
+		name, expr, _ := strings.Cut(segment[1:len(segment)-1], ":")
+		p := muxParam{name: name, key: "p" + strconv.Itoa(len(params))}
+		if expr != "" {
+			p.re = regexp.MustCompile("^" + expr + "$")
+		}
+
+		segments[i] = "{" + p.key + "}"
+		params = append(params, p)
+	}
+
+	path := strings.Join(segments, "/")
+	if strings.HasSuffix(path, "/") {
+		path += "{$}"
+	}
+
+	return path, params
+}
+
+func (m *serveMux) Use(middlewares ...func(http.Handler) http.Handler) {
+	if m.inline {
+		m.middlewares = append(m.middlewares, middlewares...)
+		return
+	}
+
+	m.tree.mu.Lock()
+	defer m.tree.mu.Unlock()
+
+	m.middlewares = append(m.middlewares, middlewares...)
+	m.tree.handler = nil
+}
+
+func (m *serveMux) With(middlewares ...func(http.Handler) http.Handler) chi.Router {
+	var inline chi.Middlewares
+	if m.inline {
+		inline = slices.Clone(m.middlewares)
+	}
+
+	return &serveMux{tree: m.tree, inline: true, middlewares: append(inline, middlewares...)}
+}
+
+func (m *serveMux) Group(fn func(r chi.Router)) chi.Router {
+	im := m.With()
+	if fn != nil {
+		fn(im)
+	}
+
+	return im
+}
+
+func (m *serveMux) Route(pattern string, fn func(r chi.Router)) chi.Router {
+	sub := newServeMux()
+	if fn != nil {
+		fn(sub)
+	}
+
+	m.Mount(pattern, sub)
+
+	return sub
+}
+
+func (m *serveMux) Mount(pattern string, h http.Handler) {
+	if m.inline && len(m.middlewares) > 0 {
+		h = chi.Chain(m.middlewares...).Handler(h)
+	}
+
+	prefix := strings.TrimSuffix(strings.TrimSuffix(pattern, "*"), "/")
+	if prefix != "" && prefix[0] != '/' {
+		panic("chu: routing pattern must begin with '/' in '" + pattern + "'")
+	}
+
+	rt := m.tree.route(prefix + "/")
+	rt.sub = h
+	rt.path = strings.TrimSuffix(rt.path, "{$}") + "{" + muxMountKey + "...}"
+
+	m.tree.register(rt.path, rt, rt.mountpoint())
+	if prefix != "" {
+		exact := m.tree.route(prefix)
+		exact.sub = h
+		m.tree.register(exact.path, exact, exact.mountpoint())
+	}
+}
+
+func (m *serveMux) Handle(pattern string, h http.Handler) {
+	m.handle("", pattern, h)
+}
+
+func (m *serveMux) HandleFunc(pattern string, h http.HandlerFunc) {
+	m.handle("", pattern, h)
+}
+
+func (m *serveMux) Method(method, pattern string, h http.Handler) {
+	m.handle(strings.ToUpper(method), pattern, h)
+}
+
+func (m *serveMux) MethodFunc(method, pattern string, h http.HandlerFunc) {
+	m.Method(method, pattern, h)
+}
+
+func (m *serveMux) Connect(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodConnect, pattern, h)
+}
+
+func (m *serveMux) Delete(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodDelete, pattern, h)
+}
+
+func (m *serveMux) Get(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodGet, pattern, h)
+}
+
+func (m *serveMux) Head(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodHead, pattern, h)
+}
+
+func (m *serveMux) Options(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodOptions, pattern, h)
+}
+
+func (m *serveMux) Patch(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodPatch, pattern, h)
+}
+
+func (m *serveMux) Post(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodPost, pattern, h)
+}
+
+func (m *serveMux) Put(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodPut, pattern, h)
+}
+
+func (m *serveMux) Trace(pattern string, h http.HandlerFunc) {
+	m.handle(http.MethodTrace, pattern, h)
+}
+
+func (m *serveMux) NotFound(h http.HandlerFunc) {
+	var handler http.Handler = h
+	if m.inline && len(m.middlewares) > 0 {
+		handler = chi.Chain(m.middlewares...).Handler(h)
+	}
+
+	m.tree.mu.Lock()
+	defer m.tree.mu.Unlock()
+
+	m.tree.notFound = handler
+}
+
+func (m *serveMux) MethodNotAllowed(h http.HandlerFunc) {
+	var handler http.Handler = h
+	if m.inline && len(m.middlewares) > 0 {
+		handler = chi.Chain(m.middlewares...).Handler(h)
+	}
+
+	m.tree.mu.Lock()
+	defer m.tree.mu.Unlock()
+
+	m.tree.methodNotAllowed = handler
+}
+
+func (m *serveMux) Routes() []chi.Route {
+	m.tree.mu.RLock()
+	defer m.tree.mu.RUnlock()
+
+	var routes []chi.Route
+	for _, rt := range m.tree.routes {
+		rt.mu.RLock()
+
+		switch {
+		case rt.sub != nil && strings.HasSuffix(rt.pattern, "/"):
+			subRoutes, _ := rt.sub.(chi.Routes)
+			routes = append(routes, chi.Route{
+				Pattern:   rt.pattern + "*",
+				Handlers:  map[string]http.Handler{"*": rt.sub},
+				SubRoutes: subRoutes,
+			})
+		case rt.sub != nil:
+		default:
+			handlers := make(map[string]http.Handler, len(rt.handlers))
+			if all, ok := rt.handlers["*"]; ok {
+				for _, method := range muxMethods {
+					handlers[method] = all
+				}
+			}
+
+			for method, h := range rt.handlers {
+				handlers[method] = h
+			}
+
+			routes = append(routes, chi.Route{Pattern: rt.pattern, Handlers: handlers})
+		}
+
+		rt.mu.RUnlock()
+	}
+
+	return routes
+}
+
+func (m *serveMux) Middlewares() chi.Middlewares {
+	return m.tree.root.middlewares
+}
+
+func (m *serveMux) Match(rctx *chi.Context, method, path string) bool {
+	return m.Find(rctx, method, path) != ""
+}
+
+func (m *serveMux) Find(rctx *chi.Context, method, path string) string {
+	req := &http.Request{Method: method, URL: &url.URL{Path: path}, Header: http.Header{}}
+
+	_, pattern := m.tree.mux.Handler(req)
+	if pattern == "" {
+		return ""
+	}
+
+	m.tree.mu.RLock()
+	rt := m.tree.byMux[pattern]
+	m.tree.mu.RUnlock()
+
+	if rt == nil {
+		return ""
+	}
+
+	if rt.sub == nil {
+		return rt.pattern
+	}
+
+	sub, ok := rt.sub.(chi.Routes)
+	if !ok {
+		return strings.TrimSuffix(rt.pattern, "/") + "/*"
+	}
+
+	segments := strings.Split(path, "/")
+	rest := "/"
+	if len(segments) > rt.segments+1 {
+		rest = "/" + strings.Join(segments[rt.segments+1:], "/")
+	}
+
+	subPattern := sub.Find(rctx, method, rest)
+	if subPattern == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(rt.pattern, "/") + subPattern
+}
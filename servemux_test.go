@@ -0,0 +1,134 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBackendApp(opts ...chu.Option) *chu.Router {
+	write := func(w http.ResponseWriter, s string) error {
+		_, err := w.Write([]byte(s))
+		return err
+	}
+
+	header := func(name string) chu.NamedMiddleware {
+		return chu.Named(name, func(next chu.Handler) chu.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				w.Header().Add("X-Chain", name)
+				return next(ctx, w, r)
+			}
+		})
+	}
+
+	r := chu.New(append([]chu.Option{chu.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(chu.StatusCode(err))
+	})}, opts...)...)
+	r.UseNamed(header("root"))
+
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return write(w, "home")
+	})
+	r.Get("/users/{id:[0-9]+}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return write(w, "user "+chu.URLParam(r, "id")+" "+r.PathValue("id")+" "+chu.RoutePattern(r))
+	})
+	r.Post("/users", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return write(w, "created")
+	})
+	r.Get("/files/*", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return write(w, "file "+chu.Wildcard(r))
+	})
+	r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.NewError(http.StatusTeapot, errors.New("fail"))
+	})
+
+	r.Group(func(r *chu.Router) {
+		r.UseNamed(header("group"))
+		r.Get("/private", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return write(w, "private")
+		})
+	})
+
+	r.Route("/orgs/{org}", func(r *chu.Router) {
+		r.UseNamed(header("orgs"))
+		r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return write(w, "org "+chu.URLParam(r, "org"))
+		})
+		r.Get("/repos/{repo}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return write(w, "repo "+chu.URLParam(r, "org")+"/"+chu.URLParam(r, "repo")+" "+r.URL.Path+" "+chu.RoutePattern(r))
+		})
+	})
+
+	r.NotFound(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNotFound)
+		return write(w, "custom not found")
+	})
+
+	return r
+}
+
+func TestServeMuxBackend(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected int
+		body     string
+		chain    []string
+		allow    []string
+	}{
+		{name: "root", method: http.MethodGet, path: "/", expected: http.StatusOK, body: "home", chain: []string{"root"}},
+		{name: "param with constraint", method: http.MethodGet, path: "/users/42", expected: http.StatusOK, body: "user 42 42 /users/{id:[0-9]+}", chain: []string{"root"}},
+		{name: "param constraint mismatch", method: http.MethodGet, path: "/users/abc", expected: http.StatusNotFound, body: "custom not found"},
+		{name: "wildcard", method: http.MethodGet, path: "/files/a/b.txt", expected: http.StatusOK, body: "file a/b.txt"},
+		{name: "error handler", method: http.MethodGet, path: "/fail", expected: http.StatusTeapot},
+		{name: "group middleware", method: http.MethodGet, path: "/private", expected: http.StatusOK, body: "private", chain: []string{"root", "group"}},
+		{name: "subrouter index", method: http.MethodGet, path: "/orgs/acme/", expected: http.StatusOK, body: "org acme", chain: []string{"root", "orgs"}},
+		{name: "subrouter params", method: http.MethodGet, path: "/orgs/acme/repos/chu", expected: http.StatusOK, body: "repo acme/chu /orgs/acme/repos/chu /orgs/{org}/repos/{repo}", chain: []string{"root", "orgs"}},
+		{name: "not found", method: http.MethodGet, path: "/missing", expected: http.StatusNotFound, body: "custom not found"},
+		{name: "subrouter not found", method: http.MethodGet, path: "/orgs/acme/missing", expected: http.StatusNotFound, body: "custom not found"},
+		{name: "trailing slash is strict", method: http.MethodGet, path: "/private/", expected: http.StatusNotFound},
+		{name: "method not allowed", method: http.MethodDelete, path: "/users", expected: http.StatusMethodNotAllowed, allow: []string{http.MethodPost}},
+	}
+
+	backends := map[string][]chu.Option{
+		"chi":      nil,
+		"servemux": {chu.WithServeMuxBackend()},
+	}
+
+	for backend, opts := range backends {
+		r := newBackendApp(opts...)
+
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+				assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+				if tt.body != "" {
+					assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+				}
+
+				if tt.chain != nil {
+					assert.Equal(t, tt.chain, w.Header().Values("X-Chain"), "middleware chain should match expected")
+				}
+
+				if tt.allow != nil {
+					assert.Equal(t, tt.allow, w.Header().Values("Allow"), "allowed methods should match expected")
+				}
+			})
+		}
+	}
+}
+
+func TestServeMuxBackend_MiddlewareChain(t *testing.T) {
+	r := newBackendApp(chu.WithServeMuxBackend())
+
+	assert.Equal(t, []string{"root", "group"}, r.MiddlewareChain("/private"), "middleware chain should match expected")
+	assert.Equal(t, []string{"root", "orgs"}, r.MiddlewareChain("/orgs/{org}/repos/{repo}"), "middleware chain should match expected")
+}
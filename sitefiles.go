@@ -0,0 +1,177 @@
+package chu
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RobotsRule struct {
+	// UserAgents the rule applies to. Defaults to "*".
+	UserAgents []string
+	Allow      []string
+	Disallow   []string
+	CrawlDelay time.Duration
+}
+
+type RobotsPolicy struct {
+	Rules    []RobotsRule
+	Sitemaps []string
+}
+
+func (p RobotsPolicy) String() string {
+	var b strings.Builder
+
+	for i, rule := range p.Rules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		agents := rule.UserAgents
+		if len(agents) == 0 {
+			agents = []string{"*"}
+		}
+
+		for _, agent := range agents {
+			b.WriteString("User-agent: " + agent + "\n")
+		}
+
+		for _, path := range rule.Allow {
+			b.WriteString("Allow: " + path + "\n")
+		}
+
+		// A group needs at least one directive; an empty Disallow allows all.
+		if len(rule.Disallow) == 0 && len(rule.Allow) == 0 {
+			b.WriteString("Disallow:\n")
+		}
+
+		for _, path := range rule.Disallow {
+			b.WriteString("Disallow: " + path + "\n")
+		}
+
+		if rule.CrawlDelay > 0 {
+			b.WriteString("Crawl-delay: " + strconv.FormatFloat(rule.CrawlDelay.Seconds(), 'f', -1, 64) + "\n")
+		}
+	}
+
+	if len(p.Rules) > 0 && len(p.Sitemaps) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, sitemap := range p.Sitemaps {
+		b.WriteString("Sitemap: " + sitemap + "\n")
+	}
+
+	return b.String()
+}
+
+// Robots serves policy at /robots.txt.
+func (r *Router) Robots(policy RobotsPolicy) {
+	body := []byte(policy.String())
+
+	r.Get("/robots.txt", func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+
+		_, err := w.Write(body)
+		return err
+	})
+}
+
+type SitemapURL struct {
+	// Loc is the absolute URL of the page.
+	Loc     string
+	LastMod time.Time
+	// ChangeFreq is one of "always", "hourly", "daily", "weekly", "monthly",
+	// "yearly" or "never".
+	ChangeFreq string
+	// Priority ranges from 0 to 1; zero omits it.
+	Priority float64
+}
+
+// SitemapProvider lists the URLs of the sitemap. It is called for every
+// request, so it should be cheap or cached.
+type SitemapProvider func(ctx context.Context) ([]SitemapURL, error)
+
+// MaxSitemapURLs is the number of URLs a single sitemap may hold.
+const MaxSitemapURLs = 50000
+
+type sitemapURLSet struct {
+	XMLName xml.Name         `xml:"urlset"`
+	XMLNS   string           `xml:"xmlns,attr"`
+	URLs    []sitemapURLNode `xml:"url"`
+}
+
+type sitemapURLNode struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// Sitemap serves the URLs of provider at /sitemap.xml. Providers returning
+// more than MaxSitemapURLs fail the request.
+func (r *Router) Sitemap(provider SitemapProvider) {
+	r.Get("/sitemap.xml", func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+		urls, err := provider(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(urls) > MaxSitemapURLs {
+			return Errorf(http.StatusInternalServerError, "sitemap has %d URLs, more than %d", len(urls), MaxSitemapURLs)
+		}
+
+		set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: make([]sitemapURLNode, len(urls))}
+		for i, u := range urls {
+			node := sitemapURLNode{Loc: u.Loc, ChangeFreq: u.ChangeFreq}
+			if !u.LastMod.IsZero() {
+				node.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+			}
+
+			if u.Priority > 0 {
+				node.Priority = strconv.FormatFloat(min(u.Priority, 1), 'f', 1, 64)
+			}
+
+			set.URLs[i] = node
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString(xml.Header)
+		if err := xml.NewEncoder(&buf).Encode(set); err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+
+		_, err = w.Write(buf.Bytes())
+		return err
+	})
+}
+
+// Favicon serves favicon.ico from fsys at /favicon.ico.
+func (r *Router) Favicon(fsys fs.FS) {
+	r.Get("/favicon.ico", func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+		info, err := fs.Stat(fsys, "favicon.ico")
+		if err != nil {
+			return NewError(http.StatusNotFound, ErrFileNotFound)
+		}
+
+		content, err := fs.ReadFile(fsys, "favicon.ico")
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "image/x-icon")
+		w.Header().Set("Cache-Control", "public, max-age=604800")
+
+		http.ServeContent(w, req, "favicon.ico", info.ModTime(), bytes.NewReader(content))
+		return nil
+	})
+}
@@ -0,0 +1,98 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRobots(t *testing.T) {
+	r := chu.New()
+	r.Robots(chu.RobotsPolicy{
+		Rules: []chu.RobotsRule{
+			{Disallow: []string{"/admin", "/api"}},
+			{UserAgents: []string{"BadBot"}, Disallow: []string{"/"}, CrawlDelay: 10 * time.Second},
+		},
+		Sitemaps: []string{"https://example.com/sitemap.xml"},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/robots.txt", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"), "content type should match expected")
+	assert.Equal(t, "User-agent: *\nDisallow: /admin\nDisallow: /api\n\n"+
+		"User-agent: BadBot\nDisallow: /\nCrawl-delay: 10\n\n"+
+		"Sitemap: https://example.com/sitemap.xml\n", w.Body.String(), "body should match expected")
+
+	assert.Equal(t, "User-agent: *\nDisallow:\n", chu.RobotsPolicy{Rules: []chu.RobotsRule{{}}}.String(), "empty rule should allow everything")
+}
+
+func TestSitemap(t *testing.T) {
+	var fail bool
+
+	r := chu.New()
+	r.Sitemap(func(ctx context.Context) ([]chu.SitemapURL, error) {
+		if fail {
+			return nil, errors.New("db down")
+		}
+
+		return []chu.SitemapURL{
+			{Loc: "https://example.com/", LastMod: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), ChangeFreq: "daily", Priority: 1},
+			{Loc: "https://example.com/a?x=1&y=2"},
+		}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/sitemap.xml", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"), "content type should match expected")
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+		`<url><loc>https://example.com/</loc><lastmod>2024-05-01T12:00:00Z</lastmod><changefreq>daily</changefreq><priority>1.0</priority></url>`+
+		`<url><loc>https://example.com/a?x=1&amp;y=2</loc></url>`+
+		`</urlset>`, w.Body.String(), "body should match expected")
+
+	fail = true
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/sitemap.xml", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "provider errors should fail the request")
+}
+
+func TestFavicon(t *testing.T) {
+	r := chu.New()
+	r.Favicon(fstest.MapFS{"favicon.ico": {Data: []byte("icon"), ModTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/favicon.ico", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "image/x-icon", w.Header().Get("Content-Type"), "content type should match expected")
+	assert.Equal(t, "public, max-age=604800", w.Header().Get("Cache-Control"), "cache control should match expected")
+	assert.Equal(t, "icon", w.Body.String(), "body should match expected")
+
+	req := httptest.NewRequest("GET", "/favicon.ico", nil)
+	req.Header.Set("If-Modified-Since", w.Header().Get("Last-Modified"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code, "conditional requests should be honored")
+
+	empty := chu.New()
+	empty.Favicon(fstest.MapFS{})
+
+	w = httptest.NewRecorder()
+	empty.ServeHTTP(w, httptest.NewRequest("GET", "/favicon.ico", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "missing favicon should be not found")
+}
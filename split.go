@@ -0,0 +1,59 @@
+package chu
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+)
+
+type Weighted struct {
+	Handler Handler
+	Weight  int
+}
+
+func Split(keyer func(r *http.Request) string, handlers ...Weighted) Handler {
+	total := 0
+	for _, h := range handlers {
+		if h.Weight > 0 {
+			total += h.Weight
+		}
+	}
+
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if total == 0 {
+			return Errorf(http.StatusServiceUnavailable, "chu: no handlers to split between")
+		}
+
+		var point int
+		if key := splitKey(keyer, r); key != "" {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(key))
+			point = int(h.Sum64() % uint64(total))
+		} else {
+			point = rand.IntN(total)
+		}
+
+		for _, h := range handlers {
+			if h.Weight <= 0 {
+				continue
+			}
+
+			if point < h.Weight {
+				return h.Handler(ctx, w, r)
+			}
+
+			point -= h.Weight
+		}
+
+		return nil
+	}
+}
+
+func splitKey(keyer func(r *http.Request) string, r *http.Request) string {
+	if keyer == nil {
+		return ""
+	}
+
+	return keyer(r)
+}
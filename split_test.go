@@ -0,0 +1,89 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func namedHandler(name string) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte(name))
+		return nil
+	}
+}
+
+func TestSplit(t *testing.T) {
+	userKey := func(r *http.Request) string { return r.Header.Get("X-User") }
+
+	tests := []struct {
+		name     string
+		handlers []chu.Weighted
+		check    func(t *testing.T, h chu.Handler)
+	}{
+		{
+			name:     "sticky by key",
+			handlers: []chu.Weighted{{namedHandler("stable"), 50}, {namedHandler("canary"), 50}},
+			check: func(t *testing.T, h chu.Handler) {
+				for i := 0; i < 20; i++ {
+					user := strconv.Itoa(i)
+					first, second := serveSplit(h, user), serveSplit(h, user)
+					assert.Equal(t, first, second, "same key should always reach the same handler")
+				}
+			},
+		},
+		{
+			name:     "weights are respected",
+			handlers: []chu.Weighted{{namedHandler("stable"), 90}, {namedHandler("canary"), 10}},
+			check: func(t *testing.T, h chu.Handler) {
+				counts := map[string]int{}
+				for i := 0; i < 2000; i++ {
+					counts[serveSplit(h, strconv.Itoa(i))]++
+				}
+
+				assert.InDelta(t, 1800, counts["stable"], 100, "stable should get about 90%% of traffic")
+				assert.InDelta(t, 200, counts["canary"], 100, "canary should get about 10%% of traffic")
+			},
+		},
+		{
+			name:     "zero weights are skipped",
+			handlers: []chu.Weighted{{namedHandler("disabled"), 0}, {namedHandler("only"), 1}},
+			check: func(t *testing.T, h chu.Handler) {
+				for i := 0; i < 10; i++ {
+					assert.Equal(t, "only", serveSplit(h, ""), "disabled handler should never be chosen")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, chu.Split(userKey, tt.handlers...))
+		})
+	}
+}
+
+func TestSplit_NoHandlers(t *testing.T) {
+	r := chu.New()
+	r.Get("/", chu.Split(nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "status code should be Service Unavailable")
+}
+
+func serveSplit(h chu.Handler, user string) string {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-User", user)
+
+	w := httptest.NewRecorder()
+	_ = h(req.Context(), w, req)
+
+	return w.Body.String()
+}
@@ -0,0 +1,111 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+var stageCtxKey = &contextKey{"stage"}
+
+type StageReport struct {
+	Method  string
+	Path    string
+	Reason  string
+	Elapsed time.Duration
+	Stages  []string
+}
+
+type stageDiagnostics struct {
+	threshold time.Duration
+	report    func(StageReport)
+}
+
+type stageTracker struct {
+	mu     sync.Mutex
+	active []string
+}
+
+func WithStageDiagnostics(threshold time.Duration, report func(StageReport)) Option {
+	return func(r *Router) {
+		r.diagnostics = &stageDiagnostics{threshold: threshold, report: report}
+	}
+}
+
+func Stage(ctx context.Context, name string) func() {
+	t, ok := ctx.Value(stageCtxKey).(*stageTracker)
+	if !ok {
+		return func() {}
+	}
+
+	t.mu.Lock()
+	t.active = append(t.active, name)
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		for i := len(t.active) - 1; i >= 0; i-- {
+			if t.active[i] == name {
+				t.active = slices.Delete(t.active, i, i+1)
+				return
+			}
+		}
+	}
+}
+
+func ActiveStages(ctx context.Context) []string {
+	t, ok := ctx.Value(stageCtxKey).(*stageTracker)
+	if !ok {
+		return nil
+	}
+
+	return t.snapshot()
+}
+
+func (t *stageTracker) snapshot() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.active)
+}
+
+func (d *stageDiagnostics) watch(req *http.Request) (*http.Request, func()) {
+	tracker := &stageTracker{}
+	req = req.WithContext(context.WithValue(req.Context(), stageCtxKey, tracker))
+
+	start := time.Now()
+	done := make(chan struct{})
+
+	report := func(reason string) {
+		d.report(StageReport{
+			Method:  req.Method,
+			Path:    req.URL.Path,
+			Reason:  reason,
+			Elapsed: time.Since(start),
+			Stages:  tracker.snapshot(),
+		})
+	}
+
+	go func() {
+		timer := time.NewTimer(d.threshold)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+			report("slow")
+		case <-req.Context().Done():
+			select {
+			case <-done:
+			default:
+				report("canceled")
+			}
+		}
+	}()
+
+	return req, func() { close(done) }
+}
@@ -0,0 +1,94 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageDiagnostics_Slow(t *testing.T) {
+	reports := make(chan chu.StageReport, 1)
+	release := make(chan struct{})
+
+	r := chu.New(chu.WithStageDiagnostics(10*time.Millisecond, func(report chu.StageReport) {
+		reports <- report
+		close(release)
+	}))
+	r.UseNamed(chu.Named("auth", func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return next(ctx, w, r)
+		}
+	}))
+	r.Get("/slow", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		defer chu.Stage(ctx, "db.query")()
+
+		<-release
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+
+	report := <-reports
+	assert.Equal(t, "slow", report.Reason, "reason should match expected")
+	assert.Equal(t, "/slow", report.Path, "path should match expected")
+	assert.GreaterOrEqual(t, report.Elapsed, 10*time.Millisecond, "elapsed should exceed threshold")
+	assert.Equal(t, []string{"auth", "handler", "db.query"}, report.Stages, "active stages should match expected")
+}
+
+func TestStageDiagnostics_Canceled(t *testing.T) {
+	reports := make(chan chu.StageReport, 1)
+
+	r := chu.New(chu.WithStageDiagnostics(time.Minute, func(report chu.StageReport) {
+		reports <- report
+	}))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		end := chu.Stage(ctx, "render")
+		<-ctx.Done()
+		end()
+
+		select {
+		case report := <-reports:
+			reports <- report
+		case <-time.After(time.Second):
+		}
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+	select {
+	case report := <-reports:
+		assert.Equal(t, "canceled", report.Reason, "reason should match expected")
+		assert.Contains(t, report.Stages, "handler", "handler stage should be active")
+	default:
+		require.Fail(t, "canceled request should be reported")
+	}
+}
+
+func TestStageDiagnostics_Fast(t *testing.T) {
+	reported := false
+
+	r := chu.New(chu.WithStageDiagnostics(time.Second, func(chu.StageReport) { reported = true }))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		assert.Equal(t, []string{"handler"}, chu.ActiveStages(ctx), "handler stage should be active")
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	assert.False(t, reported, "fast requests should not be reported")
+	assert.Nil(t, chu.ActiveStages(context.Background()), "stages should be nil without diagnostics")
+}
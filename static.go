@@ -0,0 +1,287 @@
+package chu
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var ErrFileNotFound = errors.New("file not found")
+
+// encodingExts maps content codings to the extension of their sidecar files.
+var encodingExts = map[string]string{
+	"br":   ".br",
+	"zstd": ".zst",
+	"gzip": ".gz",
+}
+
+type StaticOption func(*staticConfig)
+
+type staticConfig struct {
+	index     string
+	encodings []string
+	assets    *Assets
+}
+
+// WithStaticIndex sets the file served for directory requests. Defaults to
+// "index.html"; an empty name disables directory requests.
+func WithStaticIndex(name string) StaticOption {
+	return func(c *staticConfig) {
+		c.index = name
+	}
+}
+
+// WithPrecompressed sets the pre-compressed sidecars Static looks for, in order
+// of preference. Defaults to "br" then "gzip"; passing none disables them.
+func WithPrecompressed(encodings ...string) StaticOption {
+	return func(c *staticConfig) {
+		c.encodings = encodings
+	}
+}
+
+// Static serves the files of fsys under prefix. When the client accepts it and
+// a sidecar such as "app.js.br" or "app.js.gz" exists next to the requested
+// file, the sidecar is sent with the matching Content-Encoding instead, so
+// assets are never compressed on the fly. Precompress generates the sidecars.
+func (r *Router) Static(prefix string, fsys fs.FS, opts ...StaticOption) {
+	cfg := &staticConfig{index: "index.html", encodings: []string{"br", "gzip"}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	h := func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+		return serveStatic(w, req, fsys, cfg)
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	r.Get(prefix+"/*", h)
+	r.Head(prefix+"/*", h)
+}
+
+func serveStatic(w http.ResponseWriter, r *http.Request, fsys fs.FS, cfg *staticConfig) error {
+	name := path.Clean("/" + Wildcard(r))[1:]
+	if name == "" {
+		name = "."
+	}
+
+	if cfg.assets != nil {
+		if original, ok := cfg.assets.original[name]; ok {
+			name = original
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+	}
+
+	info, err := fs.Stat(fsys, name)
+	if err == nil && info.IsDir() {
+		if cfg.index == "" {
+			return NewError(http.StatusNotFound, ErrFileNotFound)
+		}
+
+		name = path.Join(name, cfg.index)
+		info, err = fs.Stat(fsys, name)
+	}
+
+	if err != nil || !info.Mode().IsRegular() {
+		return NewError(http.StatusNotFound, ErrFileNotFound)
+	}
+
+	if len(cfg.encodings) > 0 {
+		AddVary(w, "Accept-Encoding")
+	}
+
+	served, encoding := name, ""
+	for _, enc := range cfg.encodings {
+		ext, ok := encodingExts[enc]
+		if !ok || !acceptsEncoding(r.Header.Get("Accept-Encoding"), enc) {
+			continue
+		}
+
+		if sidecar, err := fs.Stat(fsys, name+ext); err == nil && sidecar.Mode().IsRegular() {
+			served, encoding, info = name+ext, enc, sidecar
+			break
+		}
+	}
+
+	f, err := fsys.Open(served)
+	if err != nil {
+		return NewError(http.StatusNotFound, ErrFileNotFound)
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+
+		content = bytes.NewReader(b)
+	}
+
+	if encoding != "" {
+		// The type is that of the original file, which ServeContent cannot
+		// sniff from compressed bytes.
+		ctype := mime.TypeByExtension(path.Ext(name))
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+
+		w.Header().Set("Content-Type", ctype)
+		w.Header().Set("Content-Encoding", encoding)
+	}
+
+	http.ServeContent(w, r, name, info.ModTime(), content)
+	return nil
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header allows coding,
+// honoring q=0 exclusions and the "*" wildcard.
+func acceptsEncoding(header, coding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(part, ";")
+		token = strings.ToLower(strings.TrimSpace(token))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if k, v, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(k, "q") {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+
+		switch token {
+		case coding:
+			return q > 0
+		case "*":
+			wildcard = q > 0
+		}
+	}
+
+	return wildcard
+}
+
+type PrecompressOption func(*precompressConfig)
+
+type precompressConfig struct {
+	minSize  int64
+	encoders []precompressEncoder
+}
+
+type precompressEncoder struct {
+	encoding  string
+	newWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+// WithPrecompressMinSize skips files smaller than n bytes. Defaults to 1024.
+func WithPrecompressMinSize(n int64) PrecompressOption {
+	return func(c *precompressConfig) {
+		c.minSize = n
+	}
+}
+
+// WithPrecompressEncoder adds an encoder for encoding, one of "br", "zstd" or
+// "gzip". The first call replaces the default gzip encoder, so brotli or zstd
+// libraries can be plugged in without chu depending on them.
+func WithPrecompressEncoder(encoding string, newWriter func(w io.Writer) (io.WriteCloser, error)) PrecompressOption {
+	return func(c *precompressConfig) {
+		c.encoders = append(c.encoders, precompressEncoder{encoding: encoding, newWriter: newWriter})
+	}
+}
+
+// Precompress writes compressed sidecars for the files of fsys into dir,
+// mirroring the layout of fsys, for Static to serve. It is meant to run at
+// build time; dir is usually the directory fsys was opened from. Files that
+// are already compressed, below the minimum size, or that do not shrink are
+// skipped.
+func Precompress(fsys fs.FS, dir string, opts ...PrecompressOption) error {
+	cfg := &precompressConfig{minSize: 1024}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if len(cfg.encoders) == 0 {
+		cfg.encoders = []precompressEncoder{{encoding: "gzip", newWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, gzip.BestCompression)
+		}}}
+	}
+
+	for _, enc := range cfg.encoders {
+		if _, ok := encodingExts[enc.encoding]; !ok {
+			return errors.New("chu: unsupported precompress encoding " + strconv.Quote(enc.encoding))
+		}
+	}
+
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || incompressible(name) {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Size() < cfg.minSize {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		for _, enc := range cfg.encoders {
+			var buf bytes.Buffer
+
+			cw, err := enc.newWriter(&buf)
+			if err != nil {
+				return err
+			}
+
+			if _, err := cw.Write(content); err != nil {
+				return err
+			}
+
+			if err := cw.Close(); err != nil {
+				return err
+			}
+
+			if buf.Len() >= len(content) {
+				continue
+			}
+
+			target := filepath.Join(dir, filepath.FromSlash(name)+encodingExts[enc.encoding])
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(target, buf.Bytes(), 0o644); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+var incompressibleExts = map[string]bool{
+	".br": true, ".gz": true, ".zst": true, ".zip": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".avif": true,
+	".woff": true, ".woff2": true, ".mp3": true, ".mp4": true, ".webm": true,
+}
+
+func incompressible(name string) bool {
+	return incompressibleExts[strings.ToLower(path.Ext(name))]
+}
@@ -0,0 +1,106 @@
+package chu_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatic(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":          {Data: []byte("console.log('plain')")},
+		"app.js.br":       {Data: []byte("brotli-bytes")},
+		"app.js.gz":       {Data: []byte("gzip-bytes")},
+		"style.css":       {Data: []byte("body{}")},
+		"style.css.gz":    {Data: []byte("gzip-css")},
+		"docs/index.html": {Data: []byte("<h1>docs</h1>")},
+	}
+
+	r := chu.New()
+	r.Static("/assets/", fsys)
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		body     string
+		encoding string
+		ctype    string
+	}{
+		{name: "brotli preferred", path: "/assets/app.js", accept: "gzip, br", code: http.StatusOK, body: "brotli-bytes", encoding: "br", ctype: "text/javascript; charset=utf-8"},
+		{name: "gzip only", path: "/assets/app.js", accept: "gzip", code: http.StatusOK, body: "gzip-bytes", encoding: "gzip", ctype: "text/javascript; charset=utf-8"},
+		{name: "brotli refused", path: "/assets/app.js", accept: "br;q=0, *", code: http.StatusOK, body: "gzip-bytes", encoding: "gzip", ctype: "text/javascript; charset=utf-8"},
+		{name: "identity", path: "/assets/app.js", code: http.StatusOK, body: "console.log('plain')", ctype: "text/javascript; charset=utf-8"},
+		{name: "missing sidecar", path: "/assets/style.css", accept: "br", code: http.StatusOK, body: "body{}", ctype: "text/css; charset=utf-8"},
+		{name: "index", path: "/assets/docs/", code: http.StatusOK, body: "<h1>docs</h1>", ctype: "text/html; charset=utf-8"},
+		{name: "not found", path: "/assets/missing.js", code: http.StatusNotFound},
+		{name: "traversal", path: "/assets/../static.go", code: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept-Encoding", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code, "status code should match expected")
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			assert.Equal(t, tt.encoding, w.Header().Get("Content-Encoding"), "content encoding should match expected")
+			assert.Equal(t, tt.ctype, w.Header().Get("Content-Type"), "content type should be that of the original file")
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"), "response should vary by encoding")
+		})
+	}
+}
+
+func TestPrecompress(t *testing.T) {
+	large := strings.Repeat("compress me please ", 200)
+
+	fsys := fstest.MapFS{
+		"app.js":       {Data: []byte(large)},
+		"css/site.css": {Data: []byte(large)},
+		"tiny.txt":     {Data: []byte("small")},
+		"logo.png":     {Data: []byte(large)},
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, chu.Precompress(fsys, dir), "precompress should succeed")
+
+	for _, name := range []string{"app.js.gz", "css/site.css.gz"} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err, "sidecar %s should exist", name)
+
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(content), "sidecar should decompress to the original")
+	}
+
+	for _, name := range []string{"tiny.txt.gz", "logo.png.gz"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.True(t, os.IsNotExist(err), "%s should be skipped", name)
+	}
+
+	assert.Error(t, chu.Precompress(fsys, dir, chu.WithPrecompressEncoder("lzma", nil)), "unknown encodings should be rejected")
+}
@@ -0,0 +1,109 @@
+package chu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/josearomeroj/chu/cache"
+)
+
+var ErrStoreMiss = errors.New("chu: store key not found")
+
+// Store is the storage abstraction shared by stateful middleware such as rate
+// limiting, sessions, idempotency and caching. Implementations must be safe
+// for concurrent use; a zero ttl means the entry does not expire.
+type Store interface {
+	// Get returns ErrStoreMiss when the key does not exist or expired.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	// Increment atomically adds n to the counter at key and returns the new
+	// value. A missing counter starts at zero and expires after ttl. Counters
+	// are stored as decimal text.
+	Increment(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+}
+
+type MemoryStoreOption func(*MemoryStore)
+
+// WithStoreClock replaces time.Now, mostly for tests.
+func WithStoreClock(now func() time.Time) MemoryStoreOption {
+	return func(s *MemoryStore) {
+		s.now = now
+	}
+}
+
+// MemoryStore is an in-process Store that evicts the least recently used
+// entries beyond its capacity.
+type MemoryStore struct {
+	now   func() time.Time
+	cache *cache.Cache[string, []byte]
+}
+
+// NewMemoryStore returns a MemoryStore holding at most max entries. A
+// non-positive max means no limit.
+func NewMemoryStore(max int, opts ...MemoryStoreOption) *MemoryStore {
+	s := &MemoryStore{now: time.Now}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.cache = cache.New(cache.Options[string, []byte]{MaxEntries: max, Now: s.now})
+
+	return s
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return nil, ErrStoreMiss
+	}
+
+	return slices.Clone(value), nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.cache.SetTTL(key, slices.Clone(value), ttl)
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
+
+func (s *MemoryStore) Increment(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	var total int64
+	var err error
+
+	s.cache.UpdateTTL(key, ttl, func(value []byte, ok bool) ([]byte, bool) {
+		if !ok {
+			total = n
+			return strconv.AppendInt(nil, n, 10), true
+		}
+
+		current, perr := strconv.ParseInt(string(value), 10, 64)
+		if perr != nil {
+			err = fmt.Errorf("chu: store value at %q is not a counter", key)
+			return nil, false
+		}
+
+		total = current + n
+		return strconv.AppendInt(nil, total, 10), true
+	})
+
+	return total, err
+}
+
+func (s *MemoryStore) Len() int {
+	return s.cache.Len()
+}
+
+// Stats reports the hit and miss counts of Get and the number of evictions.
+func (s *MemoryStore) Stats() cache.Stats {
+	return s.cache.Stats()
+}
@@ -0,0 +1,72 @@
+package chu_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josearomeroj/chu"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	s := chu.NewMemoryStore(2, chu.WithStoreClock(func() time.Time { return now }))
+
+	_, err := s.Get(ctx, "missing")
+	assert.ErrorIs(t, err, chu.ErrStoreMiss, "missing key should be a miss")
+
+	require.NoError(t, s.Set(ctx, "a", []byte("1"), time.Minute))
+	value, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value, "value should round trip")
+
+	require.NoError(t, s.Set(ctx, "b", []byte("2"), 0))
+	_, _ = s.Get(ctx, "a")
+	require.NoError(t, s.Set(ctx, "c", []byte("3"), 0))
+
+	_, err = s.Get(ctx, "b")
+	assert.ErrorIs(t, err, chu.ErrStoreMiss, "least recently used key should be evicted")
+	assert.Equal(t, 2, s.Len(), "store should respect its capacity")
+
+	now = now.Add(time.Minute)
+	_, err = s.Get(ctx, "a")
+	assert.ErrorIs(t, err, chu.ErrStoreMiss, "expired key should be a miss")
+
+	require.NoError(t, s.Delete(ctx, "c"))
+	_, err = s.Get(ctx, "c")
+	assert.ErrorIs(t, err, chu.ErrStoreMiss, "deleted key should be a miss")
+}
+
+func TestMemoryStoreIncrement(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	s := chu.NewMemoryStore(0, chu.WithStoreClock(func() time.Time { return now }))
+
+	n, err := s.Increment(ctx, "hits", 5, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n, "new counter should start at the increment")
+
+	now = now.Add(30 * time.Second)
+	n, err = s.Increment(ctx, "hits", -2, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n, "counter should accumulate")
+
+	value, err := s.Get(ctx, "hits")
+	require.NoError(t, err)
+	assert.Equal(t, "3", string(value), "counter should be stored as decimal text")
+
+	now = now.Add(30 * time.Second)
+	n, err = s.Increment(ctx, "hits", 1, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "ttl should apply from the first increment")
+
+	require.NoError(t, s.Set(ctx, "name", []byte("chu"), 0))
+	_, err = s.Increment(ctx, "name", 1, 0)
+	assert.Error(t, err, "non numeric value should not be incremented")
+}
@@ -0,0 +1,63 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+)
+
+var syncCtxKey = &contextKey{"sync"}
+
+// syncState remembers the context the current middleware was entered with, so
+// a boundary can tell whether the middleware replaced ctx or the request.
+type syncState struct {
+	last context.Context
+}
+
+// WithContextSync makes the router keep ctx and r.Context() identical: every
+// middleware boundary re-attaches whichever of the two the middleware replaced,
+// so values added to either one are visible through both.
+func WithContextSync() Option {
+	return func(r *Router) {
+		r.syncCtx = true
+	}
+}
+
+// SyncContext wraps h so that r.Context() is ctx whenever h runs.
+func SyncContext(h Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		r = syncRequest(ctx, r)
+		return h(r.Context(), w, r)
+	}
+}
+
+func withSyncState(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), syncCtxKey, &syncState{}))
+}
+
+// enterSync records ctx as the context a middleware is being entered with.
+func enterSync(ctx context.Context) {
+	if st, ok := ctx.Value(syncCtxKey).(*syncState); ok {
+		st.last = ctx
+	}
+}
+
+func syncRequest(ctx context.Context, r *http.Request) *http.Request {
+	st, _ := ctx.Value(syncCtxKey).(*syncState)
+
+	switch {
+	case ctx == r.Context():
+	case st != nil && ctx == st.last:
+		ctx = r.Context()
+	default:
+		r = r.WithContext(ctx)
+	}
+
+	return r
+}
+
+func enterMiddleware(h Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		enterSync(ctx)
+		return h(ctx, w, r)
+	}
+}
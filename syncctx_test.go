@@ -0,0 +1,92 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+type syncKey struct{ name string }
+
+func ctxOnly(name string) func(chu.Handler) chu.Handler {
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return next(context.WithValue(ctx, syncKey{name}, true), w, r)
+		}
+	}
+}
+
+func requestOnly(name string) func(chu.Handler) chu.Handler {
+	return func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return next(ctx, w, r.WithContext(context.WithValue(r.Context(), syncKey{name}, true)))
+		}
+	}
+}
+
+func TestContextSync(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []chu.Option
+		inCtx    map[string]bool
+		inReqCtx map[string]bool
+	}{
+		{
+			name:     "default",
+			inCtx:    map[string]bool{"use-ctx": false, "use-req": true, "route-ctx": true, "route-req": false},
+			inReqCtx: map[string]bool{"use-ctx": false, "use-req": true, "route-ctx": false, "route-req": true},
+		},
+		{
+			name:     "synced",
+			opts:     []chu.Option{chu.WithContextSync()},
+			inCtx:    map[string]bool{"use-ctx": true, "use-req": true, "route-ctx": true, "route-req": true},
+			inReqCtx: map[string]bool{"use-ctx": true, "use-req": true, "route-ctx": true, "route-req": true},
+		},
+		{
+			name:     "synced servemux",
+			opts:     []chu.Option{chu.WithContextSync(), chu.WithServeMuxBackend()},
+			inCtx:    map[string]bool{"use-ctx": true, "use-req": true, "route-ctx": true, "route-req": true},
+			inReqCtx: map[string]bool{"use-ctx": true, "use-req": true, "route-ctx": true, "route-req": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inCtx := make(map[string]bool)
+			inReqCtx := make(map[string]bool)
+
+			r := chu.New(tt.opts...)
+			r.Use(requestOnly("use-req"), ctxOnly("use-ctx"))
+			r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				for name := range tt.inCtx {
+					inCtx[name] = ctx.Value(syncKey{name}) != nil
+					inReqCtx[name] = r.Context().Value(syncKey{name}) != nil
+				}
+				return nil
+			}, chu.WithMiddleware(ctxOnly("route-ctx"), requestOnly("route-req")))
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.inCtx, inCtx, "ctx values should match expected")
+			assert.Equal(t, tt.inReqCtx, inReqCtx, "request context values should match expected")
+		})
+	}
+}
+
+func TestSyncContext(t *testing.T) {
+	var same bool
+
+	h := chu.SyncContext(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		same = ctx == r.Context()
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), syncKey{"x"}, true)
+	_ = h(ctx, httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, same, "request context should be the handler ctx")
+}
@@ -0,0 +1,177 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var timingCtxKey = &contextKey{"timing"}
+
+type timingMetric struct {
+	name string
+	dur  time.Duration
+}
+
+type timings struct {
+	mu           sync.Mutex
+	start        time.Time
+	handlerStart time.Time
+	handlerEnd   time.Time
+	metrics      []timingMetric
+}
+
+// Timing records time spent on name, such as "db" or "cache", for the
+// Server-Timing header. Durations recorded under the same name add up. It does
+// nothing unless ServerTiming is in use.
+func Timing(ctx context.Context, name string, dur time.Duration) {
+	t, ok := ctx.Value(timingCtxKey).(*timings)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i := slices.IndexFunc(t.metrics, func(m timingMetric) bool { return m.name == name }); i >= 0 {
+		t.metrics[i].dur += dur
+		return
+	}
+
+	t.metrics = append(t.metrics, timingMetric{name: name, dur: dur})
+}
+
+// StartTiming starts measuring name and returns the function that records it,
+// for use with defer.
+func StartTiming(ctx context.Context, name string) func() {
+	if ctx.Value(timingCtxKey) == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() { Timing(ctx, name, time.Since(start)) }
+}
+
+// ServerTiming adds a Server-Timing header breaking the response time down
+// into the time spent in middleware, in the route handler and in the metrics
+// recorded with Timing. Times are taken when the response headers are written,
+// so a streaming handler only reports the time until its first write. Since
+// the header reveals backend details, allow can restrict it to some requests,
+// such as those from internal networks; nil allows all. Add it first so the
+// middleware time covers the whole chain.
+func ServerTiming(allow func(r *http.Request) bool) func(Handler) Handler {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if allow != nil && !allow(r) {
+				return next(ctx, w, r)
+			}
+
+			t := &timings{start: time.Now()}
+			ctx = context.WithValue(ctx, timingCtxKey, t)
+
+			tw := &timingWriter{ResponseWriter: w, timings: t}
+			if err := next(ctx, tw, r.WithContext(ctx)); err != nil {
+				return err
+			}
+
+			// A handler that writes nothing still gets the header.
+			if !tw.wroteHeader {
+				tw.WriteHeader(http.StatusOK)
+			}
+
+			return nil
+		}
+	}
+}
+
+// timeHandler marks when the route handler runs, to tell it apart from the
+// middleware around it.
+func timeHandler(h Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		t, ok := ctx.Value(timingCtxKey).(*timings)
+		if !ok {
+			return h(ctx, w, r)
+		}
+
+		t.mu.Lock()
+		t.handlerStart = time.Now()
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			t.handlerEnd = time.Now()
+			t.mu.Unlock()
+		}()
+
+		return h(ctx, w, r)
+	}
+}
+
+func (t *timings) header(now time.Time) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	metrics := make([]timingMetric, 0, len(t.metrics)+3)
+	if !t.handlerStart.IsZero() {
+		end := now
+		if !t.handlerEnd.IsZero() {
+			end = t.handlerEnd
+		}
+
+		handler := end.Sub(t.handlerStart)
+		metrics = append(metrics,
+			timingMetric{name: "middleware", dur: now.Sub(t.start) - handler},
+			timingMetric{name: "handler", dur: handler})
+	}
+
+	metrics = append(metrics, t.metrics...)
+	metrics = append(metrics, timingMetric{name: "total", dur: now.Sub(t.start)})
+
+	parts := make([]string, len(metrics))
+	for i, m := range metrics {
+		parts[i] = m.name + ";dur=" + strconv.FormatFloat(float64(m.dur.Microseconds())/1000, 'f', -1, 64)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+type timingWriter struct {
+	http.ResponseWriter
+	timings     *timings
+	wroteHeader bool
+}
+
+func (w *timingWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status >= http.StatusOK {
+		w.wroteHeader = true
+		w.Header().Add("Server-Timing", w.timings.header(time.Now()))
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+
+		f.Flush()
+	}
+}
+
+func (w *timingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
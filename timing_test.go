@@ -0,0 +1,105 @@
+package chu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerTiming(t *testing.T) {
+	r := chu.New()
+	r.Use(chu.ServerTiming(func(r *http.Request) bool { return r.Header.Get("X-Internal") != "" }))
+	r.Use(func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			time.Sleep(5 * time.Millisecond)
+			return next(ctx, w, r)
+		}
+	})
+
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		chu.Timing(ctx, "db", 2*time.Millisecond)
+		chu.Timing(ctx, "db", 3*time.Millisecond)
+
+		stop := chu.StartTiming(ctx, "cache")
+		time.Sleep(time.Millisecond)
+		stop()
+
+		_, _ = w.Write([]byte("ok"))
+		return nil
+	})
+	r.Get("/empty", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+	r.Get("/error", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.NewError(http.StatusConflict, errors.New("conflict"))
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		internal bool
+		metrics  []string
+	}{
+		{name: "handler with metrics", path: "/", internal: true, metrics: []string{"middleware", "handler", "db", "cache", "total"}},
+		{name: "empty response", path: "/empty", internal: true, metrics: []string{"middleware", "handler", "total"}},
+		{name: "error response", path: "/error", internal: true, metrics: []string{"middleware", "handler", "total"}},
+		{name: "not allowed", path: "/", internal: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.internal {
+				req.Header.Set("X-Internal", "1")
+			}
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			header := rec.Header().Get("Server-Timing")
+			if tt.metrics == nil {
+				assert.Empty(t, header, "server timing should not be sent")
+				return
+			}
+
+			var names []string
+			for _, part := range strings.Split(header, ", ") {
+				name, _, _ := strings.Cut(part, ";")
+				names = append(names, name)
+				assert.Regexp(t, regexp.MustCompile(`^[a-z]+;dur=[0-9.]+$`), part, "metric should be well formed")
+			}
+
+			assert.Equal(t, tt.metrics, names, "metrics should match expected")
+		})
+	}
+}
+
+func TestServerTimingDurations(t *testing.T) {
+	r := chu.New()
+	r.Use(chu.ServerTiming(nil))
+	r.Get("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		chu.Timing(ctx, "db", 2500*time.Microsecond)
+		chu.Timing(ctx, "db", 500*time.Microsecond)
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Contains(t, rec.Header().Get("Server-Timing"), "db;dur=3,", "durations with the same name should add up")
+}
+
+func TestTimingWithoutMiddleware(t *testing.T) {
+	assert.NotPanics(t, func() {
+		chu.Timing(context.Background(), "db", time.Millisecond)
+		chu.StartTiming(context.Background(), "db")()
+	}, "timing without the middleware should be a no-op")
+}
@@ -0,0 +1,71 @@
+package chu
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// DeclareTrailers announces in the Trailer header the trailers the handler
+// sets with SetTrailer, merging with the ones already declared. It must be
+// called before the response is written.
+func DeclareTrailers(w http.ResponseWriter, names ...string) {
+	h := w.Header()
+
+	values := headerList(h, "Trailer")
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name == "" || slices.Contains(values, name) {
+			continue
+		}
+
+		values = append(values, name)
+	}
+
+	if len(values) > 0 {
+		h.Set("Trailer", strings.Join(values, ", "))
+	}
+}
+
+// SetTrailer sets a trailer sent after the body, such as a checksum or the
+// time taken to produce it. Unlike setting a declared trailer as a header, it
+// can be called before the body is written. Trailers not declared with
+// DeclareTrailers must be set before the body, and some clients drop them.
+func SetTrailer(w http.ResponseWriter, name, value string) {
+	w.Header().Set(http.TrailerPrefix+http.CanonicalHeaderKey(name), value)
+}
+
+// trailerValues collects the trailers set in h, declared or not.
+func trailerValues(h http.Header) http.Header {
+	trailers := make(http.Header)
+	for _, name := range headerList(h, "Trailer") {
+		if values, ok := h[name]; ok {
+			trailers[name] = values
+		}
+	}
+
+	for key, values := range h {
+		if name, ok := strings.CutPrefix(key, http.TrailerPrefix); ok {
+			trailers[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+
+	return trailers
+}
+
+// promoteTrailers turns the trailers of a fully buffered response into plain
+// headers, since they are known before the body is sent.
+func promoteTrailers(h http.Header) {
+	trailers := trailerValues(h)
+
+	h.Del("Trailer")
+	for key := range h {
+		if strings.HasPrefix(key, http.TrailerPrefix) {
+			delete(h, key)
+		}
+	}
+
+	for name, values := range trailers {
+		h[name] = values
+	}
+}
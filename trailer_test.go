@@ -0,0 +1,103 @@
+package chu_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trailerRouter() *chu.Router {
+	r := chu.New()
+	r.Get("/declared", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		chu.DeclareTrailers(w, "x-checksum", "X-Elapsed")
+		chu.DeclareTrailers(w, "X-Checksum")
+		chu.SetTrailer(w, "X-Elapsed", "3ms")
+
+		_, _ = io.WriteString(w, "body")
+		chu.SetTrailer(w, "X-Checksum", "abc")
+		return nil
+	})
+	r.Get("/undeclared", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		chu.SetTrailer(w, "x-checksum", "abc")
+		_, _ = io.WriteString(w, "body")
+		return nil
+	})
+	r.Get("/buffered", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		chu.DeclareTrailers(w, "X-Checksum")
+		_, _ = io.WriteString(w, "body")
+		chu.SetTrailer(w, "X-Checksum", "abc")
+		return nil
+	}, chu.WithMiddleware(chu.Intercept(func(ctx context.Context, r *http.Request, res *chu.Response) error {
+		return nil
+	})))
+
+	return r
+}
+
+func TestTrailers(t *testing.T) {
+	srv := httptest.NewServer(trailerRouter())
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		declared []string
+		trailers http.Header
+		header   http.Header
+	}{
+		{
+			name:     "declared trailers",
+			path:     "/declared",
+			declared: []string{"X-Checksum", "X-Elapsed"},
+			trailers: http.Header{"X-Checksum": {"abc"}, "X-Elapsed": {"3ms"}},
+		},
+		{
+			name:     "undeclared trailer",
+			path:     "/undeclared",
+			trailers: http.Header{"X-Checksum": {"abc"}},
+		},
+		{
+			name:     "buffered response",
+			path:     "/buffered",
+			trailers: http.Header{},
+			header:   http.Header{"X-Checksum": {"abc"}},
+		},
+	}
+
+	clients := map[string]*http.Client{"server": srv.Client(), "in-process": chu.Client(trailerRouter())}
+
+	for clientName, client := range clients {
+		for _, tt := range tests {
+			t.Run(clientName+"/"+tt.name, func(t *testing.T) {
+				resp, err := client.Get(srv.URL + tt.path)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, "body", string(body), "body should match expected")
+				assert.Empty(t, resp.Header.Get("X-Elapsed"), "trailers should not be sent as headers")
+				assert.Empty(t, resp.Header.Values("Trailer:X-Checksum"), "trailer bookkeeping should not leak into headers")
+
+				for _, name := range tt.declared {
+					assert.Contains(t, resp.Trailer, name, "declared trailer %s should be announced", name)
+				}
+
+				for name, values := range tt.trailers {
+					assert.Equal(t, values, resp.Trailer.Values(name), "trailer %s should match expected", name)
+				}
+
+				for name, values := range tt.header {
+					assert.Equal(t, values, resp.Header.Values(name), "header %s should match expected", name)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package upload
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type FileStore struct {
+	dir   string
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir, locks: make(map[string]*sync.Mutex)}, nil
+}
+
+func (s *FileStore) Create(_ context.Context, upload *Upload) error {
+	if !validID(upload.ID) {
+		return ErrNotFound
+	}
+
+	f, err := os.OpenFile(s.dataPath(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return s.writeInfo(upload)
+}
+
+func (s *FileStore) Get(_ context.Context, id string) (*Upload, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+
+	return s.readInfo(id)
+}
+
+func (s *FileStore) WriteChunk(_ context.Context, id string, offset int64, src io.Reader) (int64, error) {
+	if !validID(id) {
+		return 0, ErrNotFound
+	}
+
+	lock := s.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	upload, err := s.readInfo(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if offset != upload.Offset {
+		return 0, ErrOffsetMismatch
+	}
+
+	f, err := os.OpenFile(s.dataPath(id), os.O_WRONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, copyErr := io.Copy(f, src)
+
+	upload.Offset += n
+	if err := s.writeInfo(upload); err != nil {
+		return n, err
+	}
+
+	return n, copyErr
+}
+
+func (s *FileStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(s.dataPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+
+	lock := s.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := os.Remove(s.infoPath(id)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.locks, id)
+	s.mu.Unlock()
+
+	if err := os.Remove(s.dataPath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileStore) lock(id string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[id] = lock
+	}
+
+	return lock
+}
+
+func (s *FileStore) readInfo(id string) (*Upload, error) {
+	data, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+func (s *FileStore) writeInfo(upload *Upload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.infoPath(upload.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.infoPath(upload.ID))
+}
+
+func (s *FileStore) dataPath(id string) string {
+	return filepath.Join(s.dir, id+".bin")
+}
+
+func (s *FileStore) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
+
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,99 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+type memoryUpload struct {
+	write  sync.Mutex
+	upload Upload
+	data   []byte
+}
+
+type MemoryStore struct {
+	mu      sync.RWMutex
+	uploads map[string]*memoryUpload
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{uploads: make(map[string]*memoryUpload)}
+}
+
+func (s *MemoryStore) Create(_ context.Context, upload *Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.uploads[upload.ID] = &memoryUpload{upload: *upload}
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Upload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.uploads[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	upload := entry.upload
+	return &upload, nil
+}
+
+func (s *MemoryStore) WriteChunk(_ context.Context, id string, offset int64, src io.Reader) (int64, error) {
+	s.mu.RLock()
+	entry, ok := s.uploads[id]
+	s.mu.RUnlock()
+
+	if !ok {
+		return 0, ErrNotFound
+	}
+
+	entry.write.Lock()
+	defer entry.write.Unlock()
+
+	s.mu.RLock()
+	current := entry.upload.Offset
+	s.mu.RUnlock()
+
+	if offset != current {
+		return 0, ErrOffsetMismatch
+	}
+
+	var chunk bytes.Buffer
+	n, err := io.Copy(&chunk, src)
+
+	s.mu.Lock()
+	entry.data = append(entry.data, chunk.Bytes()...)
+	entry.upload.Offset += n
+	s.mu.Unlock()
+
+	return n, err
+}
+
+func (s *MemoryStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.uploads[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(entry.data)), nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.uploads[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.uploads, id)
+	return nil
+}
@@ -0,0 +1,291 @@
+package upload
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+const (
+	TusVersion        = "1.0.0"
+	TusExtensions     = "creation,termination"
+	OffsetContentType = "application/offset+octet-stream"
+)
+
+var (
+	ErrNotFound       = errors.New("upload: not found")
+	ErrOffsetMismatch = errors.New("upload: offset mismatch")
+)
+
+type Upload struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func (u *Upload) Done() bool {
+	return u.Offset >= u.Size
+}
+
+type Store interface {
+	Create(ctx context.Context, upload *Upload) error
+	Get(ctx context.Context, id string) (*Upload, error)
+	WriteChunk(ctx context.Context, id string, offset int64, src io.Reader) (int64, error)
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type Option func(*Server)
+
+func WithMaxSize(size int64) Option {
+	return func(s *Server) {
+		s.maxSize = size
+	}
+}
+
+func WithOnComplete(fn func(ctx context.Context, upload *Upload) error) Option {
+	return func(s *Server) {
+		s.onComplete = fn
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+type Server struct {
+	store      Store
+	maxSize    int64
+	onComplete func(ctx context.Context, upload *Upload) error
+	now        func() time.Time
+}
+
+func New(store Store, opts ...Option) *Server {
+	s := &Server{
+		store: store,
+		now:   time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *Server) Routes(r *chu.Router) {
+	r.Use(s.protocol)
+	r.Options("/", s.options)
+	r.Post("/", s.create)
+	r.Head("/{id}", s.head)
+	r.Patch("/{id}", s.patch)
+	r.Delete("/{id}", s.terminate)
+}
+
+func (s *Server) protocol(next chu.Handler) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Tus-Resumable", TusVersion)
+
+		if r.Method != http.MethodOptions && r.Header.Get("Tus-Resumable") != TusVersion {
+			w.Header().Set("Tus-Version", TusVersion)
+			return chu.Errorf(http.StatusPreconditionFailed, "upload: unsupported Tus-Resumable version %q", r.Header.Get("Tus-Resumable"))
+		}
+
+		return next(ctx, w, r)
+	}
+}
+
+func (s *Server) options(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Tus-Version", TusVersion)
+	w.Header().Set("Tus-Extension", TusExtensions)
+	if s.maxSize > 0 {
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(s.maxSize, 10))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	size, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		return chu.Errorf(http.StatusBadRequest, "upload: invalid Upload-Length")
+	}
+
+	if s.maxSize > 0 && size > s.maxSize {
+		return chu.Errorf(http.StatusRequestEntityTooLarge, "upload: size %d exceeds maximum %d", size, s.maxSize)
+	}
+
+	metadata, err := ParseMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		return chu.NewError(http.StatusBadRequest, err)
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+
+	upload := &Upload{
+		ID:        id,
+		Size:      size,
+		Metadata:  metadata,
+		CreatedAt: s.now().UTC(),
+	}
+
+	if err := s.store.Create(ctx, upload); err != nil {
+		return err
+	}
+
+	if upload.Done() {
+		if err := s.complete(ctx, upload); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+id)
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+func (s *Server) head(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	upload, err := s.store.Get(ctx, chu.URLParam(r, "id"))
+	if err != nil {
+		return statusError(err)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	if len(upload.Metadata) > 0 {
+		w.Header().Set("Upload-Metadata", FormatMetadata(upload.Metadata))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) patch(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if r.Header.Get("Content-Type") != OffsetContentType {
+		return chu.Errorf(http.StatusUnsupportedMediaType, "upload: Content-Type must be %s", OffsetContentType)
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return chu.Errorf(http.StatusBadRequest, "upload: invalid Upload-Offset")
+	}
+
+	id := chu.URLParam(r, "id")
+
+	upload, err := s.store.Get(ctx, id)
+	if err != nil {
+		return statusError(err)
+	}
+
+	if offset != upload.Offset {
+		return chu.NewError(http.StatusConflict, ErrOffsetMismatch)
+	}
+
+	remaining := upload.Size - upload.Offset
+	if r.ContentLength > remaining {
+		return chu.Errorf(http.StatusRequestEntityTooLarge, "upload: chunk exceeds remaining %d bytes", remaining)
+	}
+
+	n, err := s.store.WriteChunk(ctx, id, offset, io.LimitReader(r.Body, remaining))
+	if err != nil && n == 0 {
+		return statusError(err)
+	}
+
+	upload.Offset = offset + n
+	if upload.Done() {
+		if err := s.complete(ctx, upload); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) terminate(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if err := s.store.Delete(ctx, chu.URLParam(r, "id")); err != nil {
+		return statusError(err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) complete(ctx context.Context, upload *Upload) error {
+	if s.onComplete == nil {
+		return nil
+	}
+
+	return s.onComplete(ctx, upload)
+}
+
+func ParseMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("upload: invalid Upload-Metadata")
+		}
+
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("upload: invalid Upload-Metadata value for " + key)
+		}
+
+		metadata[key] = string(value)
+	}
+
+	return metadata, nil
+}
+
+func FormatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return chu.NewError(http.StatusNotFound, err)
+	case errors.Is(err, ErrOffsetMismatch):
+		return chu.NewError(http.StatusConflict, err)
+	default:
+		return err
+	}
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,165 @@
+package upload_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func call(r http.Handler, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Tus-Resumable", upload.TusVersion)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func stores(t *testing.T) map[string]upload.Store {
+	files, err := upload.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	return map[string]upload.Store{
+		"memory": upload.NewMemoryStore(),
+		"file":   files,
+	}
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			var (
+				mu        sync.Mutex
+				completed []string
+			)
+
+			s := upload.New(store, upload.WithMaxSize(1024), upload.WithOnComplete(func(ctx context.Context, u *upload.Upload) error {
+				rc, err := store.Open(ctx, u.ID)
+				if err != nil {
+					return err
+				}
+				defer rc.Close()
+
+				data, err := io.ReadAll(rc)
+				if err != nil {
+					return err
+				}
+
+				mu.Lock()
+				completed = append(completed, u.Metadata["filename"]+":"+string(data))
+				mu.Unlock()
+				return nil
+			}))
+
+			r := chu.New()
+			r.Route("/files", s.Routes)
+
+			w := call(r, http.MethodOptions, "/files/", "", nil)
+			assert.Equal(t, http.StatusNoContent, w.Code, "status code should match expected")
+			assert.Equal(t, upload.TusVersion, w.Header().Get("Tus-Version"), "Tus-Version should be advertised")
+			assert.Equal(t, "1024", w.Header().Get("Tus-Max-Size"), "Tus-Max-Size should be advertised")
+
+			w = call(r, http.MethodPost, "/files/", "", map[string]string{
+				"Upload-Length":   "11",
+				"Upload-Metadata": "filename aGVsbG8udHh0,public",
+			})
+			require.Equal(t, http.StatusCreated, w.Code, "status code should match expected")
+
+			location := w.Header().Get("Location")
+			require.True(t, strings.HasPrefix(location, "/files/"), "location should point into the mount")
+
+			w = call(r, http.MethodHead, location, "", nil)
+			assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+			assert.Equal(t, "0", w.Header().Get("Upload-Offset"), "offset should start at zero")
+			assert.Equal(t, "11", w.Header().Get("Upload-Length"), "length should match creation")
+
+			patch := func(offset, body string) *httptest.ResponseRecorder {
+				return call(r, http.MethodPatch, location, body, map[string]string{
+					"Content-Type":  upload.OffsetContentType,
+					"Upload-Offset": offset,
+				})
+			}
+
+			w = patch("0", "hello ")
+			assert.Equal(t, http.StatusNoContent, w.Code, "status code should match expected")
+			assert.Equal(t, "6", w.Header().Get("Upload-Offset"), "offset should advance")
+
+			w = patch("0", "again")
+			assert.Equal(t, http.StatusConflict, w.Code, "stale offset should conflict")
+
+			w = patch("6", "world")
+			assert.Equal(t, http.StatusNoContent, w.Code, "status code should match expected")
+			assert.Equal(t, "11", w.Header().Get("Upload-Offset"), "offset should reach the length")
+
+			mu.Lock()
+			assert.Equal(t, []string{"hello.txt:hello world"}, completed, "completion callback should run once")
+			mu.Unlock()
+
+			w = call(r, http.MethodDelete, location, "", nil)
+			assert.Equal(t, http.StatusNoContent, w.Code, "status code should match expected")
+
+			w = call(r, http.MethodHead, location, "", nil)
+			assert.Equal(t, http.StatusNotFound, w.Code, "terminated upload should be gone")
+		})
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := upload.New(upload.NewMemoryStore(), upload.WithMaxSize(10))
+
+	r := chu.New()
+	r.Route("/files", s.Routes)
+
+	w := call(r, http.MethodPost, "/files/", "", map[string]string{"Upload-Length": "5"})
+	require.Equal(t, http.StatusCreated, w.Code, "status code should match expected")
+	location := w.Header().Get("Location")
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		header   map[string]string
+		expected int
+	}{
+		{name: "missing length", method: http.MethodPost, path: "/files/", expected: http.StatusBadRequest},
+		{name: "too large", method: http.MethodPost, path: "/files/", header: map[string]string{"Upload-Length": "11"}, expected: http.StatusRequestEntityTooLarge},
+		{name: "bad metadata", method: http.MethodPost, path: "/files/", header: map[string]string{"Upload-Length": "1", "Upload-Metadata": "name !!!"}, expected: http.StatusBadRequest},
+		{name: "wrong version", method: http.MethodHead, path: location, header: map[string]string{"Tus-Resumable": "0.2.2"}, expected: http.StatusPreconditionFailed},
+		{name: "unknown upload", method: http.MethodHead, path: "/files/abcd", expected: http.StatusNotFound},
+		{name: "wrong content type", method: http.MethodPatch, path: location, body: "abc", header: map[string]string{"Upload-Offset": "0"}, expected: http.StatusUnsupportedMediaType},
+		{name: "missing offset", method: http.MethodPatch, path: location, body: "abc", header: map[string]string{"Content-Type": upload.OffsetContentType}, expected: http.StatusBadRequest},
+		{name: "chunk too large", method: http.MethodPatch, path: location, body: "abcdefgh", header: map[string]string{"Content-Type": upload.OffsetContentType, "Upload-Offset": "0"}, expected: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := call(r, tt.method, tt.path, tt.body, tt.header)
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+			assert.Equal(t, upload.TusVersion, w.Header().Get("Tus-Resumable"), "Tus-Resumable should always be set")
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	metadata, err := upload.ParseMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"filename": "world_domination_plan.pdf", "is_confidential": ""}, metadata, "metadata should decode")
+
+	roundTrip, err := upload.ParseMetadata(upload.FormatMetadata(metadata))
+	require.NoError(t, err)
+	assert.Equal(t, metadata, roundTrip, "metadata should round trip")
+}
@@ -0,0 +1,174 @@
+package chu
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var varyAuditCtxKey = &contextKey{"vary-audit"}
+
+// AddVary adds headers to the Vary header of w, merging with the values
+// already present so several middlewares can declare what they negotiate on
+// without duplicating entries. "*" replaces every other value.
+func AddVary(w http.ResponseWriter, headers ...string) {
+	h := w.Header()
+
+	values := varyValues(h)
+	for _, name := range headers {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name == "" || slices.Contains(values, name) {
+			continue
+		}
+
+		values = append(values, name)
+	}
+
+	if slices.Contains(values, "*") {
+		values = []string{"*"}
+	}
+
+	if len(values) > 0 {
+		h.Set("Vary", strings.Join(values, ", "))
+	}
+}
+
+// Varies reports whether the Vary header of h lists name.
+func Varies(h http.Header, name string) bool {
+	values := varyValues(h)
+	return slices.Contains(values, "*") || slices.Contains(values, http.CanonicalHeaderKey(name))
+}
+
+func varyValues(h http.Header) []string {
+	return headerList(h, "Vary")
+}
+
+// headerList returns the canonical names listed in the comma-separated header
+// key, without duplicates.
+func headerList(h http.Header, key string) []string {
+	var values []string
+	for _, line := range h.Values(key) {
+		for _, v := range strings.Split(line, ",") {
+			if v = http.CanonicalHeaderKey(strings.TrimSpace(v)); v != "" && !slices.Contains(values, v) {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values
+}
+
+type varyUsage struct {
+	mu      sync.Mutex
+	headers []string
+}
+
+// VaryingHeader returns the request header name, recording under VaryAudit that
+// the response depends on it.
+func VaryingHeader(r *http.Request, name string) string {
+	if usage, ok := r.Context().Value(varyAuditCtxKey).(*varyUsage); ok {
+		name = http.CanonicalHeaderKey(name)
+
+		usage.mu.Lock()
+		if !slices.Contains(usage.headers, name) {
+			usage.headers = append(usage.headers, name)
+		}
+		usage.mu.Unlock()
+	}
+
+	return r.Header.Get(name)
+}
+
+// VaryAudit reports responses that depend on request headers missing from
+// their Vary header: headers read with VaryingHeader, Accept-Encoding for
+// encoded responses and Accept-Language for responses with a Content-Language.
+// report defaults to a warning log. It is meant for development and tests.
+func VaryAudit(report func(r *http.Request, missing []string)) func(Handler) Handler {
+	if report == nil {
+		report = func(r *http.Request, missing []string) {
+			slog.WarnContext(r.Context(), "response varies on undeclared headers",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Any("missing", missing))
+		}
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			usage := &varyUsage{}
+			ctx = context.WithValue(ctx, varyAuditCtxKey, usage)
+			r = r.WithContext(ctx)
+
+			return next(ctx, &varyAuditWriter{ResponseWriter: w, req: r, usage: usage, report: report}, r)
+		}
+	}
+}
+
+type varyAuditWriter struct {
+	http.ResponseWriter
+	req         *http.Request
+	usage       *varyUsage
+	report      func(r *http.Request, missing []string)
+	wroteHeader bool
+}
+
+func (w *varyAuditWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.audit()
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *varyAuditWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *varyAuditWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+
+		f.Flush()
+	}
+}
+
+func (w *varyAuditWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *varyAuditWriter) audit() {
+	h := w.Header()
+
+	w.usage.mu.Lock()
+	used := slices.Clone(w.usage.headers)
+	w.usage.mu.Unlock()
+
+	if h.Get("Content-Encoding") != "" {
+		used = append(used, "Accept-Encoding")
+	}
+
+	if h.Get("Content-Language") != "" {
+		used = append(used, "Accept-Language")
+	}
+
+	var missing []string
+	for _, name := range used {
+		if !Varies(h, name) && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		w.report(w.req, missing)
+	}
+}
@@ -0,0 +1,75 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddVary(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		add      []string
+		want     string
+	}{
+		{name: "empty", add: []string{"accept-encoding"}, want: "Accept-Encoding"},
+		{name: "dedup", existing: []string{"Accept-Encoding, Origin"}, add: []string{"origin", "Accept-Language"}, want: "Accept-Encoding, Origin, Accept-Language"},
+		{name: "merges lines", existing: []string{"Origin", "Origin, Cookie"}, add: []string{"Cookie"}, want: "Origin, Cookie"},
+		{name: "wildcard", existing: []string{"Origin"}, add: []string{"*"}, want: "*"},
+		{name: "nothing", add: []string{" "}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			for _, v := range tt.existing {
+				w.Header().Add("Vary", v)
+			}
+
+			chu.AddVary(w, tt.add...)
+
+			assert.Equal(t, tt.want, w.Header().Get("Vary"), "vary should match expected")
+			assert.LessOrEqual(t, len(w.Header().Values("Vary")), 1, "vary should be a single line")
+		})
+	}
+
+	h := http.Header{"Vary": {"accept-language"}}
+	assert.True(t, chu.Varies(h, "Accept-Language"), "vary lookup should ignore case")
+	assert.False(t, chu.Varies(h, "Cookie"), "undeclared headers should not vary")
+	assert.True(t, chu.Varies(http.Header{"Vary": {"*"}}, "Cookie"), "wildcard should vary on everything")
+}
+
+func TestVaryAudit(t *testing.T) {
+	var reported [][]string
+
+	r := chu.New()
+	r.Use(chu.VaryAudit(func(r *http.Request, missing []string) {
+		reported = append(reported, missing)
+	}))
+	r.Get("/declared", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		chu.VaryingHeader(r, "X-Tenant")
+		chu.AddVary(w, "X-Tenant")
+
+		_, err := w.Write([]byte("ok"))
+		return err
+	})
+	r.Get("/undeclared", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		chu.VaryingHeader(r, "x-tenant")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Language", "es")
+
+		_, err := w.Write([]byte("ok"))
+		return err
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/declared", nil))
+	assert.Empty(t, reported, "declared headers should not be reported")
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/undeclared", nil))
+	assert.Equal(t, [][]string{{"X-Tenant", "Accept-Encoding", "Accept-Language"}}, reported, "undeclared headers should be reported")
+}
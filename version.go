@@ -0,0 +1,163 @@
+package chu
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var versionCtxKey = &contextKey{"version"}
+
+type versionRoute struct {
+	version string
+	router  *Router
+}
+
+type versionSet struct {
+	mu     sync.RWMutex
+	routes []*versionRoute
+}
+
+func (s *versionSet) add(vr *versionRoute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routes = append(s.routes, vr)
+	slices.SortStableFunc(s.routes, func(a, b *versionRoute) int { return cmp.Compare(a.version, b.version) })
+}
+
+// match returns the router of the newest version not after version that has a
+// route for the request.
+func (s *versionSet) match(version string, req *http.Request) *Router {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	path := requestPath(req)
+	for i := len(s.routes) - 1; i >= 0; i-- {
+		vr := s.routes[i]
+		if vr.version > version {
+			continue
+		}
+
+		if vr.router.chi.Match(chi.NewRouteContext(), req.Method, path) {
+			return vr.router
+		}
+	}
+
+	return nil
+}
+
+// Version registers the routes in fn as the behavior of version. Requests
+// pinned to a version by PinVersion are served by the newest version not after
+// the pin that has a matching route, falling back to r's own routes, so a
+// version only needs to register the routes that changed. Versions compare as
+// strings, so dated versions such as "2024-06-01" order naturally. Middleware
+// added to r with Use before Version runs for the version routes too. Version
+// is only honored on the router that serves requests.
+func (r *Router) Version(version string, fn func(r *Router)) {
+	sub := r.subRouter(r.routerBuilder(), "")
+	sub.chain = nil
+	sub.UseNamed(r.middlewares...)
+	sub.recipe = nil
+
+	fn(sub)
+
+	r.versions.add(&versionRoute{version: version, router: sub})
+	r.record(func(c *Router) { c.Version(version, sub.replay) })
+}
+
+type VersionOptions struct {
+	// Header carries the version the client asks for. Defaults to
+	// "API-Version".
+	Header string
+	// Pin returns the version a client is pinned to, e.g. the one stored with
+	// its API key, for requests without the header.
+	Pin func(r *http.Request) string
+	// Default is the version of requests with neither. Empty means the newest
+	// registered version.
+	Default string
+}
+
+type versionState struct {
+	version string
+	routed  bool
+}
+
+// PinVersion resolves the version of each request and routes it to the
+// matching group registered with Version. The resolved version is echoed in
+// the response header and available through APIVersion. It must be added to
+// the serving router with Use, before the versions are registered.
+func PinVersion(opts VersionOptions) func(Handler) Handler {
+	if opts.Header == "" {
+		opts.Header = "API-Version"
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if state, ok := ctx.Value(versionCtxKey).(*versionState); ok && state.routed {
+				return next(ctx, w, r)
+			}
+
+			info, _ := ctx.Value(routeInfoCtxKey).(*routeInfo)
+
+			version := r.Header.Get(opts.Header)
+			if version == "" && opts.Pin != nil {
+				version = opts.Pin(r)
+			}
+			if version == "" {
+				version = opts.Default
+			}
+			if version == "" && info != nil {
+				version = info.versions.latest()
+			}
+
+			if version != "" {
+				w.Header().Set(opts.Header, version)
+				AddVary(w, opts.Header)
+			}
+
+			state := &versionState{version: version}
+			ctx = context.WithValue(ctx, versionCtxKey, state)
+			r = r.WithContext(ctx)
+
+			if info != nil && info.versions != nil {
+				if router := info.versions.match(version, r); router != nil {
+					state.routed = true
+					router.chi.ServeHTTP(w, r)
+					return nil
+				}
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// APIVersion returns the version resolved by PinVersion.
+func APIVersion(ctx context.Context) string {
+	state, _ := ctx.Value(versionCtxKey).(*versionState)
+	if state == nil {
+		return ""
+	}
+
+	return state.version
+}
+
+func (s *versionSet) latest() string {
+	if s == nil {
+		return ""
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.routes) == 0 {
+		return ""
+	}
+
+	return s.routes[len(s.routes)-1].version
+}
@@ -0,0 +1,88 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	reply := func(body string) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte(body + " " + chu.APIVersion(ctx) + " " + chu.URLParam(r, "id")))
+			return err
+		}
+	}
+
+	pins := map[string]string{"old-client": "2023-01-01"}
+
+	r := chu.New()
+	r.Use(chu.PinVersion(chu.VersionOptions{
+		Pin: func(r *http.Request) string { return pins[r.Header.Get("X-API-Key")] },
+	}))
+	r.Get("/users/{id}", reply("base-user"))
+	r.Get("/health", reply("health"))
+	r.Version("2024-01-01", func(r *chu.Router) {
+		r.Get("/users/{id}", reply("v2024-user"))
+	})
+	r.Version("2024-06-01", func(r *chu.Router) {
+		r.Post("/users", reply("v2024-06-create"))
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		version string
+		key     string
+		code    int
+		body    string
+	}{
+		{name: "exact version", method: "GET", path: "/users/7", version: "2024-01-01", code: http.StatusOK, body: "v2024-user 2024-01-01 7"},
+		{name: "newest version not after pin", method: "GET", path: "/users/7", version: "2024-09-01", code: http.StatusOK, body: "v2024-user 2024-09-01 7"},
+		{name: "before every version", method: "GET", path: "/users/7", version: "2020-01-01", code: http.StatusOK, body: "base-user 2020-01-01 7"},
+		{name: "default is latest", method: "GET", path: "/users/7", code: http.StatusOK, body: "v2024-user 2024-06-01 7"},
+		{name: "key pin", method: "GET", path: "/users/7", key: "old-client", code: http.StatusOK, body: "base-user 2023-01-01 7"},
+		{name: "unversioned route", method: "GET", path: "/health", version: "2024-06-01", code: http.StatusOK, body: "health 2024-06-01 "},
+		{name: "route added in version", method: "POST", path: "/users", version: "2024-06-01", code: http.StatusOK, body: "v2024-06-create 2024-06-01 "},
+		{name: "route missing before version", method: "POST", path: "/users", version: "2024-01-01", code: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.version != "" {
+				req.Header.Set("API-Version", tt.version)
+			}
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code, "status code should match expected")
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+			assert.NotEmpty(t, w.Header().Get("API-Version"), "resolved version should be echoed")
+		})
+	}
+
+	c := r.Clone()
+	c.Version("2025-01-01", func(r *chu.Router) {
+		r.Get("/users/{id}", reply("v2025-user"))
+	})
+
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest("GET", "/users/1", nil))
+	assert.Equal(t, "v2025-user 2025-01-01 1", w.Body.String(), "clone should serve its own versions")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/users/1", nil))
+	assert.Equal(t, "v2024-user 2024-06-01 1", w.Body.String(), "original should not see clone versions")
+}
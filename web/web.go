@@ -0,0 +1,306 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/josearomeroj/chu"
+)
+
+var ErrInvalidCSRFToken = errors.New("web: invalid CSRF token")
+
+const csrfKey = "_csrf"
+
+type sessionCtxKey struct{}
+
+type Options struct {
+	Key        []byte
+	OldKeys    [][]byte
+	CookieName string
+	MaxAge     time.Duration
+	Templates  *template.Template
+	CSRFField  string
+	CSRFHeader string
+
+	// InsecureCookies drops the Secure attribute from the session cookie, for
+	// development over plain HTTP. The cookie is Secure by default.
+	InsecureCookies bool
+}
+
+type Web struct {
+	opts Options
+}
+
+// New panics when the key is shorter than 32 bytes, since sessions signed
+// with a weak key can be forged.
+func New(opts Options) *Web {
+	if len(opts.Key) < 32 {
+		panic("web: session key must be at least 32 bytes")
+	}
+
+	if opts.CookieName == "" {
+		opts.CookieName = "web_session"
+	}
+
+	if opts.MaxAge == 0 {
+		opts.MaxAge = 14 * 24 * time.Hour
+	}
+
+	if opts.CSRFField == "" {
+		opts.CSRFField = "csrf_token"
+	}
+
+	if opts.CSRFHeader == "" {
+		opts.CSRFHeader = "X-CSRF-Token"
+	}
+
+	return &Web{opts: opts}
+}
+
+type Flash struct {
+	Kind    string `json:"k"`
+	Message string `json:"m"`
+}
+
+type Session struct {
+	Values  map[string]string `json:"v"`
+	Flashes []Flash           `json:"f,omitempty"`
+	dirty   bool
+}
+
+func (s *Session) Get(key string) string {
+	return s.Values[key]
+}
+
+func (s *Session) Set(key, value string) {
+	s.Values[key] = value
+	s.dirty = true
+}
+
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.dirty = true
+}
+
+func (s *Session) Clear() {
+	token := s.Values[csrfKey]
+	s.Values = map[string]string{csrfKey: token}
+	s.Flashes = nil
+	s.dirty = true
+}
+
+func (a *Web) Middleware(next chu.Handler) chu.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		sess := a.load(r)
+		ctx = context.WithValue(ctx, sessionCtxKey{}, sess)
+		r = r.WithContext(ctx)
+
+		if !isSafeMethod(r.Method) && !a.validCSRF(r, sess) {
+			return chu.NewError(http.StatusForbidden, ErrInvalidCSRFToken)
+		}
+
+		sw := &sessionWriter{ResponseWriter: w, save: func() { a.save(w, r, sess) }}
+
+		if err := next(ctx, sw, r); err != nil {
+			return err
+		}
+
+		sw.commit()
+
+		return nil
+	}
+}
+
+func SessionFromContext(ctx context.Context) *Session {
+	sess, _ := ctx.Value(sessionCtxKey{}).(*Session)
+	return sess
+}
+
+func CSRFToken(ctx context.Context) string {
+	if sess := SessionFromContext(ctx); sess != nil {
+		return sess.Values[csrfKey]
+	}
+
+	return ""
+}
+
+func AddFlash(ctx context.Context, kind, message string) {
+	if sess := SessionFromContext(ctx); sess != nil {
+		sess.Flashes = append(sess.Flashes, Flash{Kind: kind, Message: message})
+		sess.dirty = true
+	}
+}
+
+func Flashes(ctx context.Context) []Flash {
+	sess := SessionFromContext(ctx)
+	if sess == nil || len(sess.Flashes) == 0 {
+		return nil
+	}
+
+	flashes := sess.Flashes
+	sess.Flashes, sess.dirty = nil, true
+
+	return flashes
+}
+
+func Redirect(w http.ResponseWriter, r *http.Request, url string) error {
+	http.Redirect(w, r, url, http.StatusSeeOther)
+	return nil
+}
+
+type View struct {
+	Data      any
+	CSRFToken string
+	CSRFField template.HTML
+	Flashes   []Flash
+	CSPNonce  string
+}
+
+func (a *Web) Render(ctx context.Context, w http.ResponseWriter, status int, name string, data any) error {
+	if a.opts.Templates == nil {
+		return errors.New("web: no templates configured")
+	}
+
+	token := CSRFToken(ctx)
+
+	view := View{
+		Data:      data,
+		CSRFToken: token,
+		CSRFField: template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(a.opts.CSRFField) +
+			`" value="` + template.HTMLEscapeString(token) + `">`),
+		Flashes:  Flashes(ctx),
+		CSPNonce: chu.CSPNonce(ctx),
+	}
+
+	var buf strings.Builder
+	if err := a.opts.Templates.ExecuteTemplate(&buf, name, view); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+
+	_, err := w.Write([]byte(buf.String()))
+	return err
+}
+
+func (a *Web) validCSRF(r *http.Request, sess *Session) bool {
+	token := r.Header.Get(a.opts.CSRFHeader)
+	if token == "" {
+		token = r.PostFormValue(a.opts.CSRFField)
+	}
+
+	expected := sess.Values[csrfKey]
+
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+func (a *Web) load(r *http.Request) *Session {
+	if value, err := chu.GetSignedCookie(r, a.opts.CookieName, a.keys()...); err == nil {
+		var sess Session
+		if err := json.Unmarshal([]byte(value), &sess); err == nil && sess.Values[csrfKey] != "" {
+			return &sess
+		}
+	}
+
+	return &Session{Values: map[string]string{csrfKey: randomToken()}, dirty: true}
+}
+
+func (a *Web) save(w http.ResponseWriter, r *http.Request, sess *Session) {
+	if !sess.dirty {
+		return
+	}
+
+	raw, err := json.Marshal(sess)
+	if err != nil {
+		return
+	}
+
+	err = chu.SetSignedCookie(w, &http.Cookie{
+		Name:     a.opts.CookieName,
+		Value:    string(raw),
+		Path:     "/",
+		MaxAge:   int(a.opts.MaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   !a.opts.InsecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	}, a.keys()...)
+	if err != nil {
+		return
+	}
+
+	sess.dirty = false
+}
+
+func (a *Web) keys() [][]byte {
+	return append([][]byte{a.opts.Key}, a.opts.OldKeys...)
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+func randomToken() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+type sessionWriter struct {
+	http.ResponseWriter
+	save      func()
+	committed bool
+}
+
+func (w *sessionWriter) commit() {
+	if !w.committed {
+		w.committed = true
+		w.save()
+	}
+}
+
+func (w *sessionWriter) WriteHeader(status int) {
+	w.commit()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionWriter) Flush() {
+	w.commit()
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+
+	return nil, nil, http.ErrNotSupported
+}
+
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
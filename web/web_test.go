@@ -0,0 +1,149 @@
+package web_test
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/josearomeroj/chu/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var templates = template.Must(template.New("form").Parse(
+	`{{range .Flashes}}[{{.Kind}}:{{.Message}}]{{end}}<form method="post">{{.CSRFField}}{{.Data}}</form>`))
+
+var tokenPattern = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+func newApp() *chu.Router {
+	app := web.New(web.Options{Key: []byte("0123456789abcdef0123456789abcdef"), Templates: templates})
+
+	r := chu.New()
+	r.Use(app.Middleware)
+	r.Get("/form", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return app.Render(ctx, w, http.StatusOK, "form", "hello")
+	})
+	r.Post("/form", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		web.SessionFromContext(ctx).Set("name", r.PostFormValue("name"))
+		web.AddFlash(ctx, "success", "saved "+r.PostFormValue("name"))
+
+		return web.Redirect(w, r, "/form")
+	})
+
+	return r
+}
+
+type client struct {
+	t       *testing.T
+	r       http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func (c *client) do(req *http.Request) *httptest.ResponseRecorder {
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	c.r.ServeHTTP(w, req)
+
+	for _, cookie := range w.Result().Cookies() {
+		c.cookies[cookie.Name] = cookie
+	}
+
+	return w
+}
+
+func (c *client) post(form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/form", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return c.do(req)
+}
+
+func TestWeb_PostRedirectGet(t *testing.T) {
+	c := &client{t: t, r: newApp(), cookies: map[string]*http.Cookie{}}
+
+	w := c.do(httptest.NewRequest("GET", "/form", nil))
+	require.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"), "content type should be HTML")
+
+	match := tokenPattern.FindStringSubmatch(w.Body.String())
+	require.Len(t, match, 2, "form should contain CSRF field")
+
+	w = c.post(url.Values{"name": {"jose"}, "csrf_token": {match[1]}})
+	require.Equal(t, http.StatusSeeOther, w.Code, "post should redirect with See Other")
+	assert.Equal(t, "/form", w.Header().Get("Location"), "redirect should target the form")
+
+	w = c.do(httptest.NewRequest("GET", "/form", nil))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "[success:saved jose]"), "flash should be rendered once")
+	assert.Contains(t, w.Body.String(), match[1], "CSRF token should be stable across requests")
+
+	w = c.do(httptest.NewRequest("GET", "/form", nil))
+	assert.NotContains(t, w.Body.String(), "[success", "flash should be consumed")
+}
+
+func TestWeb_CSRF(t *testing.T) {
+	c := &client{t: t, r: newApp(), cookies: map[string]*http.Cookie{}}
+
+	w := c.post(url.Values{"name": {"jose"}})
+	assert.Equal(t, http.StatusForbidden, w.Code, "post without session should be rejected")
+
+	w = c.do(httptest.NewRequest("GET", "/form", nil))
+	token := tokenPattern.FindStringSubmatch(w.Body.String())[1]
+
+	w = c.post(url.Values{"name": {"jose"}, "csrf_token": {"wrong"}})
+	assert.Equal(t, http.StatusForbidden, w.Code, "wrong token should be rejected")
+
+	req := httptest.NewRequest("POST", "/form", strings.NewReader("name=jose"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-CSRF-Token", token)
+
+	w = c.do(req)
+	assert.Equal(t, http.StatusSeeOther, w.Code, "header token should be accepted")
+}
+
+func TestWeb_TamperedCookie(t *testing.T) {
+	c := &client{t: t, r: newApp(), cookies: map[string]*http.Cookie{}}
+
+	w := c.do(httptest.NewRequest("GET", "/form", nil))
+	token := tokenPattern.FindStringSubmatch(w.Body.String())[1]
+
+	cookie := c.cookies["web_session"]
+	cookie.Value = "x" + cookie.Value
+
+	w = c.post(url.Values{"name": {"jose"}, "csrf_token": {token}})
+	assert.Equal(t, http.StatusForbidden, w.Code, "tampered session should not be trusted")
+}
+
+func TestWeb_SecureCookie(t *testing.T) {
+	w := httptest.NewRecorder()
+	newApp().ServeHTTP(w, httptest.NewRequest("GET", "/form", nil))
+
+	session := w.Result().Cookies()
+	require.Len(t, session, 1, "session cookie should be set")
+	assert.True(t, session[0].Secure, "session cookie should be secure by default")
+
+	app := web.New(web.Options{Key: []byte("0123456789abcdef0123456789abcdef"), Templates: templates, InsecureCookies: true})
+
+	r := chu.New()
+	r.Use(app.Middleware)
+	r.Get("/form", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return app.Render(ctx, w, http.StatusOK, "form", "hello")
+	})
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/form", nil))
+	assert.False(t, w.Result().Cookies()[0].Secure, "insecure cookies should drop the secure attribute")
+}
+
+func TestNewShortKey(t *testing.T) {
+	assert.Panics(t, func() { web.New(web.Options{}) }, "empty key should be rejected")
+	assert.Panics(t, func() { web.New(web.Options{Key: []byte("secret")}) }, "short key should be rejected")
+}
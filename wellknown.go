@@ -0,0 +1,187 @@
+package chu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrWebFingerNotFound = errors.New("webfinger resource not found")
+
+// SecurityTxt is an RFC 9116 security.txt document.
+type SecurityTxt struct {
+	// Contact lists URIs for reporting vulnerabilities, e.g.
+	// "mailto:security@example.com". At least one is required.
+	Contact []string
+	// Expires is required; documents should not be valid for over a year.
+	Expires            time.Time
+	Encryption         []string
+	Acknowledgments    []string
+	PreferredLanguages []string
+	Canonical          []string
+	Policy             []string
+	Hiring             []string
+}
+
+func (s SecurityTxt) String() string {
+	var b strings.Builder
+
+	field := func(name string, values ...string) {
+		for _, v := range values {
+			b.WriteString(name + ": " + v + "\n")
+		}
+	}
+
+	field("Contact", s.Contact...)
+	if !s.Expires.IsZero() {
+		field("Expires", s.Expires.UTC().Format(time.RFC3339))
+	}
+	field("Encryption", s.Encryption...)
+	field("Acknowledgments", s.Acknowledgments...)
+	if len(s.PreferredLanguages) > 0 {
+		field("Preferred-Languages", strings.Join(s.PreferredLanguages, ", "))
+	}
+	field("Canonical", s.Canonical...)
+	field("Policy", s.Policy...)
+	field("Hiring", s.Hiring...)
+
+	return b.String()
+}
+
+// AuthorizationServerMetadata is an RFC 8414 OAuth authorization server
+// metadata document.
+type AuthorizationServerMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                     string   `json:"token_endpoint,omitempty"`
+	JWKSURI                           string   `json:"jwks_uri,omitempty"`
+	RegistrationEndpoint              string   `json:"registration_endpoint,omitempty"`
+	RevocationEndpoint                string   `json:"revocation_endpoint,omitempty"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported,omitempty"`
+	GrantTypesSupported               []string `json:"grant_types_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+type WebFingerLink struct {
+	Rel        string             `json:"rel"`
+	Type       string             `json:"type,omitempty"`
+	Href       string             `json:"href,omitempty"`
+	Titles     map[string]string  `json:"titles,omitempty"`
+	Properties map[string]*string `json:"properties,omitempty"`
+}
+
+// WebFingerResource is an RFC 7033 JSON Resource Descriptor.
+type WebFingerResource struct {
+	Subject    string             `json:"subject"`
+	Aliases    []string           `json:"aliases,omitempty"`
+	Properties map[string]*string `json:"properties,omitempty"`
+	Links      []WebFingerLink    `json:"links,omitempty"`
+}
+
+// WebFingerResolver describes the resource, such as "acct:ana@example.com".
+// It returns ErrWebFingerNotFound, or a nil resource, for unknown resources.
+type WebFingerResolver interface {
+	ResolveWebFinger(ctx context.Context, resource string) (*WebFingerResource, error)
+}
+
+type WebFingerResolverFunc func(ctx context.Context, resource string) (*WebFingerResource, error)
+
+func (f WebFingerResolverFunc) ResolveWebFinger(ctx context.Context, resource string) (*WebFingerResource, error) {
+	return f(ctx, resource)
+}
+
+// WellKnown lists the documents served under /.well-known. Nil or empty fields
+// are not served.
+type WellKnown struct {
+	SecurityTxt *SecurityTxt
+	// ChangePassword is the URL of the password change page.
+	ChangePassword      string
+	AuthorizationServer *AuthorizationServerMetadata
+	WebFinger           WebFingerResolver
+	// Documents serves further documents by name, relative to /.well-known.
+	Documents map[string]Handler
+}
+
+// WellKnown mounts the documents of wk under /.well-known.
+func (r *Router) WellKnown(wk WellKnown) {
+	r.Route("/.well-known", func(r *Router) {
+		if wk.SecurityTxt != nil {
+			body := []byte(wk.SecurityTxt.String())
+
+			r.Get("/security.txt", func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+				_, err := w.Write(body)
+				return err
+			})
+		}
+
+		if wk.ChangePassword != "" {
+			r.Get("/change-password", func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+				http.Redirect(w, req, wk.ChangePassword, http.StatusFound)
+				return nil
+			})
+		}
+
+		if wk.AuthorizationServer != nil {
+			body, err := json.Marshal(wk.AuthorizationServer)
+			if err != nil {
+				panic(err)
+			}
+
+			r.Get("/oauth-authorization-server", func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Cache-Control", "public, max-age=3600")
+
+				_, err := w.Write(body)
+				return err
+			})
+		}
+
+		if wk.WebFinger != nil {
+			r.Get("/webfinger", func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+				return serveWebFinger(ctx, w, req, wk.WebFinger)
+			})
+		}
+
+		for name, h := range wk.Documents {
+			r.Get("/"+strings.TrimPrefix(name, "/"), h)
+		}
+	})
+}
+
+func serveWebFinger(ctx context.Context, w http.ResponseWriter, r *http.Request, resolver WebFingerResolver) error {
+	query := r.URL.Query()
+
+	resource := query.Get("resource")
+	if resource == "" {
+		return Errorf(http.StatusBadRequest, "webfinger: missing resource parameter")
+	}
+
+	jrd, err := resolver.ResolveWebFinger(ctx, resource)
+	if errors.Is(err, ErrWebFingerNotFound) || (err == nil && jrd == nil) {
+		return NewError(http.StatusNotFound, ErrWebFingerNotFound)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if rels := query["rel"]; len(rels) > 0 {
+		filtered := *jrd
+		filtered.Links = slices.DeleteFunc(slices.Clone(jrd.Links), func(l WebFingerLink) bool { return !slices.Contains(rels, l.Rel) })
+		jrd = &filtered
+	}
+
+	w.Header().Set("Content-Type", "application/jrd+json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	return json.NewEncoder(w).Encode(jrd)
+}
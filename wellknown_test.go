@@ -0,0 +1,95 @@
+package chu_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWellKnown(t *testing.T) {
+	r := chu.New()
+	r.WellKnown(chu.WellKnown{
+		SecurityTxt: &chu.SecurityTxt{
+			Contact:            []string{"mailto:security@example.com"},
+			Expires:            time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			PreferredLanguages: []string{"en", "es"},
+		},
+		ChangePassword:      "/account/password",
+		AuthorizationServer: &chu.AuthorizationServerMetadata{Issuer: "https://auth.example.com", TokenEndpoint: "https://auth.example.com/token"},
+		WebFinger: chu.WebFingerResolverFunc(func(ctx context.Context, resource string) (*chu.WebFingerResource, error) {
+			if resource != "acct:ana@example.com" {
+				return nil, chu.ErrWebFingerNotFound
+			}
+
+			return &chu.WebFingerResource{
+				Subject: resource,
+				Links: []chu.WebFingerLink{
+					{Rel: "self", Type: "application/activity+json", Href: "https://example.com/users/ana"},
+					{Rel: "http://webfinger.net/rel/profile-page", Href: "https://example.com/@ana"},
+				},
+			}, nil
+		}),
+		Documents: map[string]chu.Handler{
+			"apple-app-site-association": func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				_, err := w.Write([]byte("{}"))
+				return err
+			},
+		},
+	})
+
+	tests := []struct {
+		name  string
+		path  string
+		code  int
+		ctype string
+		body  string
+	}{
+		{name: "security.txt", path: "/.well-known/security.txt", code: http.StatusOK, ctype: "text/plain; charset=utf-8",
+			body: "Contact: mailto:security@example.com\nExpires: 2025-01-01T00:00:00Z\nPreferred-Languages: en, es\n"},
+		{name: "change password", path: "/.well-known/change-password", code: http.StatusFound},
+		{name: "oauth metadata", path: "/.well-known/oauth-authorization-server", code: http.StatusOK, ctype: "application/json",
+			body: `{"issuer":"https://auth.example.com","token_endpoint":"https://auth.example.com/token"}`},
+		{name: "webfinger missing resource", path: "/.well-known/webfinger", code: http.StatusBadRequest},
+		{name: "webfinger unknown", path: "/.well-known/webfinger?resource=acct:bob@example.com", code: http.StatusNotFound},
+		{name: "custom document", path: "/.well-known/apple-app-site-association", code: http.StatusOK, body: "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.code, w.Code, "status code should match expected")
+			if tt.ctype != "" {
+				assert.Equal(t, tt.ctype, w.Header().Get("Content-Type"), "content type should match expected")
+			}
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/change-password", nil))
+	assert.Equal(t, "/account/password", w.Header().Get("Location"), "change password should redirect")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/webfinger?resource=acct:ana@example.com&rel=self", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "status code should match expected")
+	assert.Equal(t, "application/jrd+json", w.Header().Get("Content-Type"), "content type should match expected")
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"), "webfinger should allow any origin")
+
+	var jrd chu.WebFingerResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jrd))
+	assert.Equal(t, "acct:ana@example.com", jrd.Subject, "subject should match expected")
+	require.Len(t, jrd.Links, 1, "links should be filtered by rel")
+	assert.Equal(t, "self", jrd.Links[0].Rel, "rel should match expected")
+}
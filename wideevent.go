@@ -0,0 +1,121 @@
+package chu
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+var wideEventCtxKey = &contextKey{"wide-event"}
+
+type wideEvent struct {
+	mu    sync.Mutex
+	route string
+	err   error
+	attrs []slog.Attr
+}
+
+// WithWideEvents makes the router log one wide record per request when it
+// completes, a "canonical log line" with the method, route, status, size,
+// duration and error of the request plus every field added with Annotate.
+// Requests left out by WithSampling are only logged when they fail. logger
+// defaults to slog.Default.
+func WithWideEvents(logger *slog.Logger) Option {
+	return func(r *Router) {
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		r.wideEvents = logger
+	}
+}
+
+// Annotate adds a field to the wide event of the request, replacing any value
+// already set under key. It does nothing unless the router uses
+// WithWideEvents.
+func Annotate(ctx context.Context, key string, value any) {
+	e, ok := ctx.Value(wideEventCtxKey).(*wideEvent)
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range e.attrs {
+		if e.attrs[i].Key == key {
+			e.attrs[i].Value = slog.AnyValue(value)
+			return
+		}
+	}
+
+	e.attrs = append(e.attrs, slog.Any(key, value))
+}
+
+func annotateRoute(req *http.Request) {
+	if e, ok := req.Context().Value(wideEventCtxKey).(*wideEvent); ok {
+		route := RoutePattern(req)
+
+		e.mu.Lock()
+		e.route = route
+		e.mu.Unlock()
+	}
+}
+
+func annotateError(ctx context.Context, err error) {
+	if e, ok := ctx.Value(wideEventCtxKey).(*wideEvent); ok {
+		e.mu.Lock()
+		e.err = err
+		e.mu.Unlock()
+	}
+}
+
+func (r *Router) startWideEvent(w http.ResponseWriter, req *http.Request) (http.ResponseWriter, *http.Request, func()) {
+	start := time.Now()
+	e := &wideEvent{}
+	ww := middleware.NewWrapResponseWriter(w, req.ProtoMajor)
+	req = req.WithContext(context.WithValue(req.Context(), wideEventCtxKey, e))
+
+	return ww, req, func() {
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		if !Sampled(req.Context()) && e.err == nil && status < http.StatusInternalServerError {
+			return
+		}
+
+		attrs := make([]slog.Attr, 0, len(e.attrs)+8)
+		attrs = append(attrs,
+			slog.String("method", req.Method),
+			slog.String("route", e.route),
+			slog.String("path", req.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Duration("duration", time.Since(start)),
+		)
+
+		if id := middleware.GetReqID(req.Context()); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+
+		if e.err != nil {
+			attrs = append(attrs, slog.String("error", e.err.Error()))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		r.wideEvents.LogAttrs(context.WithoutCancel(req.Context()), level, "request", append(attrs, e.attrs...)...)
+	}
+}
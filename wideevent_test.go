@@ -0,0 +1,89 @@
+package chu_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWideEvents(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := chu.New(chu.WithWideEvents(logger))
+	r.Use(func(next chu.Handler) chu.Handler {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			chu.Annotate(ctx, "tenant", "acme")
+			return next(ctx, w, r)
+		}
+	})
+	r.Get("/users/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		chu.Annotate(ctx, "user_id", chu.URLParam(r, "id"))
+		chu.Annotate(ctx, "cache", "miss")
+		chu.Annotate(ctx, "cache", "hit")
+
+		_, _ = w.Write([]byte("ok"))
+		return nil
+	})
+	r.Get("/fail", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return chu.NewError(http.StatusInternalServerError, errors.New("database unavailable"))
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		expected map[string]any
+	}{
+		{
+			name: "annotated request",
+			path: "/users/42",
+			expected: map[string]any{
+				"level": "INFO", "msg": "request", "method": "GET", "route": "/users/{id}", "path": "/users/42",
+				"status": float64(200), "bytes": float64(2), "tenant": "acme", "user_id": "42", "cache": "hit",
+			},
+		},
+		{
+			name: "failed request",
+			path: "/fail",
+			expected: map[string]any{
+				"level": "ERROR", "route": "/fail", "status": float64(500), "error": "database unavailable", "tenant": "acme",
+			},
+		},
+		{
+			name:     "unmatched request",
+			path:     "/missing",
+			expected: map[string]any{"route": "", "status": float64(404)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record), "exactly one record should be logged")
+
+			for key, value := range tt.expected {
+				assert.Equal(t, value, record[key], "field %s should match expected", key)
+			}
+			assert.Contains(t, record, "duration", "duration should be logged")
+		})
+	}
+}
+
+func TestAnnotateWithoutWideEvents(t *testing.T) {
+	assert.NotPanics(t, func() {
+		chu.Annotate(context.Background(), "user_id", 1)
+	}, "annotating without wide events should be a no-op")
+}
@@ -0,0 +1,83 @@
+package chu
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func Wildcard(r *http.Request) string {
+	return URLParam(r, "*")
+}
+
+func WildcardFromCtx(ctx context.Context) string {
+	return URLParamFromCtx(ctx, "*")
+}
+
+var subtreeCtxKey = &contextKey{"subtree"}
+
+func StripRoutePrefix(h http.Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		r2, err := stripToWildcard(r)
+		if err != nil {
+			return err
+		}
+
+		h.ServeHTTP(w, r2)
+		return nil
+	}
+}
+
+func (r *Router) Subtree(prefix string, h Handler, opts ...RouteOption) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	subtree := func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+		r2, err := stripToWildcard(req)
+		if err != nil {
+			return err
+		}
+
+		ctx = context.WithValue(r2.Context(), subtreeCtxKey, r2.URL.Path)
+		r2 = r2.WithContext(ctx)
+
+		return h(ctx, w, r2)
+	}
+
+	// The root subtree is "/*" alone, which also matches "/".
+	if prefix != "" {
+		r.Handle(prefix, subtree, opts...)
+	}
+	r.Handle(prefix+"/*", subtree, opts...)
+}
+
+func SubtreePath(ctx context.Context) string {
+	path, _ := ctx.Value(subtreeCtxKey).(string)
+	return path
+}
+
+func stripToWildcard(r *http.Request) (*http.Request, error) {
+	rest := "/" + Wildcard(r)
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+
+	if r.URL.RawPath != "" {
+		path, err := url.PathUnescape(rest)
+		if err != nil {
+			return nil, NewError(http.StatusBadRequest, err)
+		}
+
+		r2.URL.Path, r2.URL.RawPath = path, rest
+	} else {
+		r2.URL.Path = rest
+	}
+
+	return r2, nil
+}
+
+func FileServer(root http.FileSystem) Handler {
+	return StripRoutePrefix(http.FileServer(root))
+}
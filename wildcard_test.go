@@ -0,0 +1,118 @@
+package chu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/josearomeroj/chu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWildcard(t *testing.T) {
+	var fromReq, fromCtx string
+
+	r := chu.New()
+	r.Get("/files/*", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		fromReq, fromCtx = chu.Wildcard(r), chu.WildcardFromCtx(ctx)
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/files/docs/readme.md", nil))
+
+	assert.Equal(t, "docs/readme.md", fromReq, "wildcard should match expected")
+	assert.Equal(t, "docs/readme.md", fromCtx, "wildcard from context should match expected")
+}
+
+func TestStripRoutePrefix(t *testing.T) {
+	var seen []string
+
+	r := chu.New()
+	r.Route("/v1", func(r *chu.Router) {
+		r.Get("/proxy/*", chu.StripRoutePrefix(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = append(seen, r.URL.Path, r.URL.RawPath)
+		})))
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/proxy/users/42", nil))
+	assert.Equal(t, []string{"/users/42", ""}, seen, "prefix should be stripped")
+
+	seen = nil
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/proxy/a%2Fb/c", nil))
+	assert.Equal(t, []string{"/a/b/c", "/a%2Fb/c"}, seen, "escaped paths should keep their raw form")
+}
+
+func TestFileServer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"css/app.css": {Data: []byte("body{}")},
+	}
+
+	r := chu.New()
+	r.Get("/assets/*", chu.FileServer(http.FS(fsys)))
+
+	tests := []struct {
+		path     string
+		expected int
+		body     string
+	}{
+		{path: "/assets/css/app.css", expected: http.StatusOK, body: "body{}"},
+		{path: "/assets/missing.js", expected: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.expected, w.Code, "status code should match expected")
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String(), "body should match expected")
+			}
+		})
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	var paths, ctxPaths []string
+
+	r := chu.New()
+	r.Route("/api", func(r *chu.Router) {
+		r.Subtree("/files/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			paths = append(paths, r.URL.Path)
+			ctxPaths = append(ctxPaths, chu.SubtreePath(ctx))
+			return nil
+		})
+	})
+
+	for _, path := range []string{"/api/files", "/api/files/", "/api/files/a/b.txt"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, "status code should match expected for %s", path)
+	}
+
+	assert.Equal(t, []string{"/", "/", "/a/b.txt"}, paths, "paths should be relative to the subtree")
+	assert.Equal(t, paths, ctxPaths, "context should carry the relative path")
+}
+
+func TestSubtreeRoot(t *testing.T) {
+	var paths []string
+
+	r := chu.New()
+	require.NotPanics(t, func() {
+		r.Subtree("/", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			paths = append(paths, chu.SubtreePath(ctx))
+			return nil
+		})
+	}, "root subtree should register")
+
+	for _, path := range []string{"/", "/a/b.txt"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, "status code should match expected for %s", path)
+	}
+
+	assert.Equal(t, []string{"/", "/a/b.txt"}, paths, "paths should be relative to the root")
+}